@@ -15,9 +15,14 @@ import (
 	"projects/emergency-messages/internal/controllers"
 	v2 "projects/emergency-messages/internal/controllers/grpc"
 	client "projects/emergency-messages/internal/databases/client/postgres"
+	"projects/emergency-messages/internal/forecasts"
+	"projects/emergency-messages/internal/forecasts/gismeteo"
 	"projects/emergency-messages/internal/logging"
 	mdlware "projects/emergency-messages/internal/middlewares"
+	"projects/emergency-messages/internal/models"
 	"projects/emergency-messages/internal/providers"
+	"projects/emergency-messages/internal/providers/email/mail_gun"
+	"projects/emergency-messages/internal/providers/sms/twil"
 	"projects/emergency-messages/internal/queue"
 	"projects/emergency-messages/internal/router"
 	"projects/emergency-messages/internal/senders"
@@ -25,6 +30,7 @@ import (
 	"projects/emergency-messages/internal/stores/postgres"
 	"projects/emergency-messages/internal/workers"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -42,7 +48,12 @@ var (
 )
 
 const (
-	envLocal = "local"
+	envLocal             = "local"
+	forecastPollSchedule = "@every 1h"
+	defaultCapSender     = "emergency-messages"
+	defaultCapFeedSize   = 50
+	// defaultPhoneCountry reads the national numbers as Russian ones, the numbers are stored inactive without a country
+	defaultPhoneCountry = "RU"
 )
 
 func Run() {
@@ -130,9 +141,23 @@ func registerEntities(db *bun.DB, grpcServer *grpc.Server, l *slog.Logger, r *ch
 	// processing should be stopped.
 	r.Use(middleware.Timeout(contextTimeout))
 
+	phoneCountry := os.Getenv("PHONE_DEFAULT_COUNTRY")
+	if phoneCountry == "" {
+		phoneCountry = defaultPhoneCountry
+	}
+	phoneCountry, err := models.ParsePhoneCountry(phoneCountry)
+	if err != nil {
+		log.Fatal(fmt.Errorf("invalid PHONE_DEFAULT_COUNTRY: %w", err))
+	}
 	receiverStore := postgres.NewReceiverStore(db)
-	receiverService := services.NewReceiverService(receiverStore, l)
+	consentStore := postgres.NewContactConsent(db)
+	receiverService := services.NewReceiverService(receiverStore, consentStore, phoneCountry, l)
 	receiverController := controllers.NewReceiver(receiverService, l)
+	v2.RegisterReceiver(grpcServer, receiverService, l)
+
+	importStore := postgres.NewImport(db)
+	importService := services.NewImport(importStore, l)
+	importController := controllers.NewImport(importService, l)
 
 	templateStore := postgres.NewTemplate(db)
 	templateService := services.NewTemplate(templateStore, l)
@@ -146,11 +171,39 @@ func registerEntities(db *bun.DB, grpcServer *grpc.Server, l *slog.Logger, r *ch
 		log.Fatal(err)
 	}
 
+	broadcastStore := postgres.NewBroadcast(db)
+	broadcastService := services.NewBroadcast(broadcastStore, l)
+	broadcastController := controllers.NewBroadcast(broadcastService, l)
+
 	messageStore := postgres.NewMessage(db)
-	messageService := services.NewMessage(producer, templateStore, l)
+	messageService := services.NewMessage(producer, templateStore, broadcastStore, l)
 	messageController := controllers.NewMessage(messageService, l)
 
-	sender := senders.New(messageStore, receiverStore, l)
+	capAlertStore := postgres.NewCapAlert(db)
+	capService := services.NewCap(templateStore, capAlertStore, messageStore, messageService, os.Getenv("CAP_LANGUAGE"), l)
+	capController := controllers.NewCap(capService, l)
+	v2.RegisterCap(grpcServer, capService, l)
+
+	capFeedSize, err := loadCapFeedSize()
+	if err != nil {
+		log.Fatal(err)
+	}
+	capSender := os.Getenv("CAP_SENDER")
+	if capSender == "" {
+		capSender = defaultCapSender
+	}
+	capFeedService := services.NewCapFeed(broadcastStore, capSender, os.Getenv("CAP_FEED_URL"), capFeedSize, l)
+	capFeedController := controllers.NewCapFeed(capFeedService, l)
+
+	languageFallback, err := loadLanguageFallback()
+	if err != nil {
+		log.Fatal(err)
+	}
+	verifiedOnly, err := loadVerifiedOnly()
+	if err != nil {
+		log.Fatal(err)
+	}
+	sender := senders.New(messageStore, receiverStore, broadcastStore, languageFallback, verifiedOnly, l)
 	messageConsumer := consumers.New(sender, messageStore, l)
 	consumer, err := queue.NewConsumer(brokerAddr, messageConsumer, l)
 	if err != nil {
@@ -158,16 +211,215 @@ func registerEntities(db *bun.DB, grpcServer *grpc.Server, l *slog.Logger, r *ch
 	}
 	go consumer.Read()
 
-	routers := router.New(r, messageController, receiverController, templateController)
+	suppliers := providers.New(l)
+
+	verificationPolicy, err := loadVerificationPolicy()
+	if err != nil {
+		log.Fatal(err)
+	}
+	verificationStore := postgres.NewContactVerification(db)
+	verificationService := services.NewContactVerification(verificationStore, receiverStore, suppliers, verificationPolicy, phoneCountry, l)
+	verificationController := controllers.NewContactVerification(verificationService, l)
+
+	consentService := services.NewConsent(receiverStore, consentStore, phoneCountry, l)
+	twilValidator := twil.NewRequestValidator(os.Getenv("MOBILE_TWIL_AUTH_TOKEN"))
+	consentController := controllers.NewConsent(consentService, twilValidator, os.Getenv("WEBHOOK_BASE_URL"), l)
+
+	webhookTokenStore := postgres.NewWebhookToken(db)
+	deliveryService := services.NewDelivery(messageStore, webhookTokenStore, l)
+	mailgunValidator := mail_gun.NewWebhookValidator(os.Getenv("MAILGUN_WEBHOOK_SIGNING_KEY"))
+	deliveryController := controllers.NewDelivery(deliveryService, twilValidator, mailgunValidator, os.Getenv("WEBHOOK_BASE_URL"), l)
+
+	routers := router.New(r, messageController, receiverController, templateController, broadcastController, capController, capFeedController, importController, verificationController, consentController, deliveryController)
 	routers.Load()
 
-	suppliers := providers.New(l)
+	retryPolicy, err := loadRetryPolicy()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	workerSendMessage := workers.NewSendMessage(messageStore, producer, suppliers, l)
+	claimPolicy, err := loadClaimPolicy()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	workerSendMessage := workers.NewSendMessage(messageStore, producer, suppliers, retryPolicy, claimPolicy, l)
 	c := cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
 	_, err = c.AddFunc("@every 30s", workerSendMessage.Send)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	workerImport := workers.NewImport(importStore, receiverService, claimPolicy.LeaseDuration, l)
+	_, err = c.AddFunc("@every 10s", workerImport.Run)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// forecast ingestion is enabled when the threshold rules are configured
+	if rulesFile := os.Getenv("FORECAST_RULES_FILE"); rulesFile != "" {
+		rules, err := forecasts.LoadRules(rulesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		schedule := os.Getenv("FORECAST_POLL_SCHEDULE")
+		if schedule == "" {
+			schedule = forecastPollSchedule
+		}
+
+		forecastAlertStore := postgres.NewForecastAlert(db)
+		ingester := forecasts.NewIngester(gismeteo.NewGismeteoClient(l), forecastAlertStore, messageService, rules, l)
+		_, err = c.AddFunc(schedule, ingester.Poll)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 	c.Start()
 }
+
+// loadRetryPolicy reads the retry policy of the send worker from the environment.
+// Variables which are not set keep their default values, the delays must be positive
+// and the max delay must not be less than the base delay.
+func loadRetryPolicy() (workers.RetryPolicy, error) {
+	policy := workers.DefaultRetryPolicy()
+
+	if v := os.Getenv("MESSAGE_MAX_ATTEMPTS"); v != "" {
+		maxAttempts, err := strconv.Atoi(v)
+		if err != nil || maxAttempts < 1 {
+			return policy, fmt.Errorf("invalid MESSAGE_MAX_ATTEMPTS: %s", v)
+		}
+		policy.MaxAttempts = maxAttempts
+	}
+	if v := os.Getenv("MESSAGE_RETRY_BASE_DELAY"); v != "" {
+		baseDelay, err := time.ParseDuration(v)
+		if err != nil || baseDelay <= 0 {
+			return policy, fmt.Errorf("invalid MESSAGE_RETRY_BASE_DELAY: %s", v)
+		}
+		policy.BaseDelay = baseDelay
+	}
+	if v := os.Getenv("MESSAGE_RETRY_MAX_DELAY"); v != "" {
+		maxDelay, err := time.ParseDuration(v)
+		if err != nil || maxDelay <= 0 {
+			return policy, fmt.Errorf("invalid MESSAGE_RETRY_MAX_DELAY: %s", v)
+		}
+		policy.MaxDelay = maxDelay
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		return policy, fmt.Errorf("invalid MESSAGE_RETRY_MAX_DELAY: %s is less than MESSAGE_RETRY_BASE_DELAY %s", policy.MaxDelay, policy.BaseDelay)
+	}
+	return policy, nil
+}
+
+// loadClaimPolicy reads the batch size and the lease duration of the send worker from the environment.
+// Variables which are not set keep their default values, the lease duration must be positive.
+func loadClaimPolicy() (workers.ClaimPolicy, error) {
+	policy := workers.DefaultClaimPolicy()
+
+	if v := os.Getenv("MESSAGE_BATCH_SIZE"); v != "" {
+		batchSize, err := strconv.Atoi(v)
+		if err != nil || batchSize < 1 {
+			return policy, fmt.Errorf("invalid MESSAGE_BATCH_SIZE: %s", v)
+		}
+		policy.BatchSize = batchSize
+	}
+	if v := os.Getenv("MESSAGE_LEASE_DURATION"); v != "" {
+		leaseDuration, err := time.ParseDuration(v)
+		if err != nil || leaseDuration <= 0 {
+			return policy, fmt.Errorf("invalid MESSAGE_LEASE_DURATION: %s", v)
+		}
+		policy.LeaseDuration = leaseDuration
+	}
+	return policy, nil
+}
+
+// loadVerificationPolicy reads the policy of the codes sent to verify contacts from the environment.
+// Variables which are not set keep their default values.
+func loadVerificationPolicy() (services.VerificationPolicy, error) {
+	policy := services.DefaultVerificationPolicy()
+
+	if v := os.Getenv("CONTACT_VERIFICATION_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl < time.Minute {
+			return policy, fmt.Errorf("invalid CONTACT_VERIFICATION_TTL: %s", v)
+		}
+		policy.TTL = ttl
+	}
+	if v := os.Getenv("CONTACT_VERIFICATION_MAX_ATTEMPTS"); v != "" {
+		maxAttempts, err := strconv.Atoi(v)
+		if err != nil || maxAttempts < 1 {
+			return policy, fmt.Errorf("invalid CONTACT_VERIFICATION_MAX_ATTEMPTS: %s", v)
+		}
+		policy.MaxAttempts = maxAttempts
+	}
+	if v := os.Getenv("CONTACT_VERIFICATION_COOLDOWN"); v != "" {
+		cooldown, err := time.ParseDuration(v)
+		if err != nil || cooldown < 0 {
+			return policy, fmt.Errorf("invalid CONTACT_VERIFICATION_COOLDOWN: %s", v)
+		}
+		policy.Cooldown = cooldown
+	}
+	if v := os.Getenv("CONTACT_VERIFICATION_MAX_PER_HOUR"); v != "" {
+		maxPerHour, err := strconv.Atoi(v)
+		if err != nil || maxPerHour < 1 {
+			return policy, fmt.Errorf("invalid CONTACT_VERIFICATION_MAX_PER_HOUR: %s", v)
+		}
+		policy.MaxPerHour = maxPerHour
+	}
+	if v := os.Getenv("CONTACT_VERIFICATION_MAX_PER_DAY"); v != "" {
+		maxPerDay, err := strconv.Atoi(v)
+		if err != nil || maxPerDay < 1 {
+			return policy, fmt.Errorf("invalid CONTACT_VERIFICATION_MAX_PER_DAY: %s", v)
+		}
+		policy.MaxPerDay = maxPerDay
+	}
+	policy.WhatsAppTemplate = os.Getenv("WHATSAPP_VERIFICATION_TEMPLATE")
+	policy.WhatsAppLanguage = os.Getenv("WHATSAPP_VERIFICATION_LANGUAGE")
+	return policy, nil
+}
+
+// loadVerifiedOnly reads whether non-critical alerts are sent to verified contacts only from the environment.
+func loadVerifiedOnly() (bool, error) {
+	v := os.Getenv("SEND_VERIFIED_ONLY")
+	if v == "" {
+		return false, nil
+	}
+	verifiedOnly, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid SEND_VERIFIED_ONLY: %s", v)
+	}
+	return verifiedOnly, nil
+}
+
+// loadCapFeedSize reads the number of alerts in the CAP feed from the environment.
+func loadCapFeedSize() (int, error) {
+	v := os.Getenv("CAP_FEED_SIZE")
+	if v == "" {
+		return defaultCapFeedSize, nil
+	}
+	size, err := strconv.Atoi(v)
+	if err != nil || size < 1 {
+		return 0, fmt.Errorf("invalid CAP_FEED_SIZE: %s", v)
+	}
+	return size, nil
+}
+
+// loadLanguageFallback reads the comma separated languages receivers fall back to from the environment,
+// e.g. ru,en. Messages are rendered in the first language of the chain the template is translated into.
+func loadLanguageFallback() ([]string, error) {
+	v := os.Getenv("LANGUAGE_FALLBACK")
+	if v == "" {
+		return nil, nil
+	}
+	fallback := make([]string, 0)
+	for _, tag := range strings.Split(v, ",") {
+		language, err := models.ParseLanguage(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LANGUAGE_FALLBACK: %w", err)
+		}
+		if language != "" {
+			fallback = append(fallback, language)
+		}
+	}
+	return fallback, nil
+}
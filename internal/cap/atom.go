@@ -0,0 +1,50 @@
+package cap
+
+import "encoding/xml"
+
+const (
+	// ContentType is the media type of CAP documents
+	ContentType = "application/cap+xml"
+	// FeedContentType is the media type of Atom feeds
+	FeedContentType = "application/atom+xml"
+)
+
+// Feed is an Atom feed indexing CAP alerts.
+// Every entry links to the CAP document of an alert.
+type Feed struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Author  *Author  `xml:"author,omitempty"`
+	Links   []Link   `xml:"link"`
+	Entries []Entry  `xml:"entry"`
+}
+
+// Author is the author of an Atom feed.
+type Author struct {
+	Name string `xml:"name"`
+}
+
+// Entry is an entry of an Atom feed.
+type Entry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Summary   string     `xml:"summary"`
+	Category  []Category `xml:"category,omitempty"`
+	Links     []Link     `xml:"link"`
+}
+
+// Category is a category of an Atom entry.
+type Category struct {
+	Term string `xml:"term,attr"`
+}
+
+// Link is a link of an Atom feed or entry.
+type Link struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
@@ -0,0 +1,279 @@
+// Package cap
+// Implements parsing and validation of OASIS Common Alerting Protocol 1.2 documents
+package cap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Namespace is the XML namespace of CAP 1.2 documents.
+const Namespace = "urn:oasis:names:tc:emergency:cap:1.2"
+
+const (
+	StatusActual   = "Actual"
+	StatusExercise = "Exercise"
+	StatusSystem   = "System"
+	StatusTest     = "Test"
+	StatusDraft    = "Draft"
+
+	MsgTypeAlert  = "Alert"
+	MsgTypeUpdate = "Update"
+	MsgTypeCancel = "Cancel"
+	MsgTypeAck    = "Ack"
+	MsgTypeError  = "Error"
+
+	ScopePublic     = "Public"
+	ScopeRestricted = "Restricted"
+	ScopePrivate    = "Private"
+
+	CategoryOther    = "Other"
+	UrgencyUnknown   = "Unknown"
+	SeverityUnknown  = "Unknown"
+	SeverityExtreme  = "Extreme"
+	CertaintyUnknown = "Unknown"
+
+	defaultLanguage = "en-US"
+	// timeLayout is the CAP date time format, which does not allow the Z time zone
+	timeLayout = "2006-01-02T15:04:05-07:00"
+)
+
+var (
+	statuses   = []string{StatusActual, StatusExercise, StatusSystem, StatusTest, StatusDraft}
+	msgTypes   = []string{MsgTypeAlert, MsgTypeUpdate, MsgTypeCancel, MsgTypeAck, MsgTypeError}
+	scopes     = []string{ScopePublic, ScopeRestricted, ScopePrivate}
+	categories = []string{"Geo", "Met", "Safety", "Security", "Rescue", "Fire", "Health", "Env", "Transport", "Infra", "CBRNE", "Other"}
+	urgencies  = []string{"Immediate", "Expected", "Future", "Past", "Unknown"}
+	severities = []string{"Extreme", "Severe", "Moderate", "Minor", "Unknown"}
+	certainty  = []string{"Observed", "Likely", "Possible", "Unlikely", "Unknown"}
+)
+
+// Alert is the <alert> element of a CAP 1.2 document.
+type Alert struct {
+	XMLName     xml.Name `xml:"urn:oasis:names:tc:emergency:cap:1.2 alert"`
+	Identifier  string   `xml:"identifier"`
+	Sender      string   `xml:"sender"`
+	Sent        string   `xml:"sent"`
+	Status      string   `xml:"status"`
+	MsgType     string   `xml:"msgType"`
+	Source      string   `xml:"source,omitempty"`
+	Scope       string   `xml:"scope"`
+	Restriction string   `xml:"restriction,omitempty"`
+	Addresses   string   `xml:"addresses,omitempty"`
+	Code        []string `xml:"code,omitempty"`
+	Note        string   `xml:"note,omitempty"`
+	References  string   `xml:"references,omitempty"`
+	Incidents   string   `xml:"incidents,omitempty"`
+	Info        []Info   `xml:"info"`
+}
+
+// Info is the <info> element of a CAP 1.2 document.
+type Info struct {
+	Language     string      `xml:"language,omitempty"`
+	Category     []string    `xml:"category"`
+	Event        string      `xml:"event"`
+	ResponseType []string    `xml:"responseType,omitempty"`
+	Urgency      string      `xml:"urgency"`
+	Severity     string      `xml:"severity"`
+	Certainty    string      `xml:"certainty"`
+	Audience     string      `xml:"audience,omitempty"`
+	Effective    string      `xml:"effective,omitempty"`
+	Onset        string      `xml:"onset,omitempty"`
+	Expires      string      `xml:"expires,omitempty"`
+	SenderName   string      `xml:"senderName,omitempty"`
+	Headline     string      `xml:"headline,omitempty"`
+	Description  string      `xml:"description,omitempty"`
+	Instruction  string      `xml:"instruction,omitempty"`
+	Web          string      `xml:"web,omitempty"`
+	Contact      string      `xml:"contact,omitempty"`
+	Parameter    []Parameter `xml:"parameter,omitempty"`
+	Area         []Area      `xml:"area,omitempty"`
+}
+
+// Parameter is the <parameter> element of a CAP 1.2 document.
+type Parameter struct {
+	ValueName string `xml:"valueName"`
+	Value     string `xml:"value"`
+}
+
+// Area is the <area> element of a CAP 1.2 document.
+type Area struct {
+	AreaDesc string   `xml:"areaDesc"`
+	Polygon  []string `xml:"polygon,omitempty"`
+	Circle   []string `xml:"circle,omitempty"`
+}
+
+// Reference is an earlier alert referenced by an Update, Cancel, Ack or Error message.
+type Reference struct {
+	Sender     string
+	Identifier string
+	Sent       string
+}
+
+// Parse decodes and validates a CAP 1.2 document.
+// It returns a *ValidationError describing every problem of a malformed document.
+func Parse(r io.Reader) (*Alert, error) {
+	var alert Alert
+	if err := xml.NewDecoder(r).Decode(&alert); err != nil {
+		return nil, &ValidationError{Problems: []Problem{{Field: "alert", Message: fmt.Sprintf("malformed xml: %s", err)}}}
+	}
+	if err := alert.Validate(); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// Validate checks the required elements and the enumerated values of the alert.
+// Besides the CAP 1.2 rules, an info must have an area and a headline or a description,
+// because its cities and its text are needed to deliver it.
+func (a *Alert) Validate() error {
+	v := &validator{}
+
+	v.required("alert.identifier", a.Identifier)
+	v.noForbiddenChars("alert.identifier", a.Identifier)
+	v.required("alert.sender", a.Sender)
+	v.noForbiddenChars("alert.sender", a.Sender)
+	v.dateTime("alert.sent", a.Sent, true)
+	v.oneOf("alert.status", a.Status, statuses)
+	v.oneOf("alert.msgType", a.MsgType, msgTypes)
+	v.oneOf("alert.scope", a.Scope, scopes)
+	if a.Scope == ScopeRestricted {
+		v.required("alert.restriction", a.Restriction)
+	}
+	if a.Scope == ScopePrivate {
+		v.required("alert.addresses", a.Addresses)
+	}
+
+	switch a.MsgType {
+	case MsgTypeUpdate, MsgTypeCancel, MsgTypeAck, MsgTypeError:
+		v.required("alert.references", a.References)
+	}
+	if a.References != "" {
+		if _, err := a.ParseReferences(); err != nil {
+			v.add("alert.references", err.Error())
+		}
+	}
+
+	if a.Status == StatusActual && (a.MsgType == MsgTypeAlert || a.MsgType == MsgTypeUpdate) && len(a.Info) == 0 {
+		v.add("alert.info", "is required to deliver an alert")
+	}
+
+	for i, info := range a.Info {
+		field := fmt.Sprintf("alert.info[%d]", i)
+		if len(info.Category) == 0 {
+			v.add(field+".category", "is required")
+		}
+		for j, c := range info.Category {
+			v.oneOf(fmt.Sprintf("%s.category[%d]", field, j), c, categories)
+		}
+		v.required(field+".event", info.Event)
+		v.oneOf(field+".urgency", info.Urgency, urgencies)
+		v.oneOf(field+".severity", info.Severity, severities)
+		v.oneOf(field+".certainty", info.Certainty, certainty)
+		v.dateTime(field+".effective", info.Effective, false)
+		v.dateTime(field+".onset", info.Onset, false)
+		v.dateTime(field+".expires", info.Expires, false)
+		if strings.TrimSpace(info.Headline) == "" && strings.TrimSpace(info.Description) == "" {
+			v.add(field+".description", "headline or description is required")
+		}
+		if len(info.Area) == 0 {
+			v.add(field+".area", "is required to find the receivers")
+		}
+		for j, area := range info.Area {
+			v.required(fmt.Sprintf("%s.area[%d].areaDesc", field, j), area.AreaDesc)
+		}
+	}
+
+	return v.err()
+}
+
+// ParseReferences parses the "sender,identifier,sent" triples of the references element.
+func (a *Alert) ParseReferences() ([]Reference, error) {
+	fields := strings.Fields(a.References)
+	references := make([]Reference, 0, len(fields))
+	for _, f := range fields {
+		parts := strings.Split(f, ",")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid reference %q, expected sender,identifier,sent", f)
+		}
+		references = append(references, Reference{Sender: parts[0], Identifier: parts[1], Sent: parts[2]})
+	}
+	return references, nil
+}
+
+// Deliverable reports whether the infos of the alert are sent to receivers.
+// Exercises, tests, drafts and system messages are only recorded.
+func (a *Alert) Deliverable() bool {
+	if a.Status != StatusActual {
+		return false
+	}
+	switch a.MsgType {
+	case MsgTypeAlert, MsgTypeUpdate, MsgTypeCancel:
+		return true
+	}
+	return false
+}
+
+// Supersedes reports whether the alert replaces the actual alerts it references.
+func (a *Alert) Supersedes() bool {
+	return a.Status == StatusActual && (a.MsgType == MsgTypeUpdate || a.MsgType == MsgTypeCancel)
+}
+
+// SentAt returns the time the alert was sent.
+func (a *Alert) SentAt() time.Time {
+	t, _ := time.Parse(time.RFC3339, a.Sent)
+	return t
+}
+
+// FormatTime formats the time in the CAP date time format.
+func FormatTime(t time.Time) string {
+	return t.Format(timeLayout)
+}
+
+// IsSeverity reports whether the value is a CAP severity.
+func IsSeverity(value string) bool {
+	return contains(severities, value)
+}
+
+// IsUrgency reports whether the value is a CAP urgency.
+func IsUrgency(value string) bool {
+	return contains(urgencies, value)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Lang returns the language of the info, which is en-US when it is not set.
+func (i Info) Lang() string {
+	if i.Language == "" {
+		return defaultLanguage
+	}
+	return i.Language
+}
+
+// Cities returns the names of the areas of the info.
+// An areaDesc may list several cities separated by semicolons.
+func (i Info) Cities() []string {
+	cities := make([]string, 0, len(i.Area))
+	seen := make(map[string]bool)
+	for _, area := range i.Area {
+		for _, city := range strings.Split(area.AreaDesc, ";") {
+			city = strings.TrimSpace(city)
+			if city == "" || seen[city] {
+				continue
+			}
+			seen[city] = true
+			cities = append(cities, city)
+		}
+	}
+	return cities
+}
@@ -0,0 +1,105 @@
+package cap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validAlert = `<?xml version="1.0" encoding="UTF-8"?>
+<alert xmlns="urn:oasis:names:tc:emergency:cap:1.2">
+  <identifier>KSN-2024-001</identifier>
+  <sender>mchs@example.org</sender>
+  <sent>2024-04-15T18:45:00+03:00</sent>
+  <status>Actual</status>
+  <msgType>Alert</msgType>
+  <scope>Public</scope>
+  <info>
+    <language>ru-RU</language>
+    <category>Met</category>
+    <event>Storm</event>
+    <urgency>Immediate</urgency>
+    <severity>Severe</severity>
+    <certainty>Observed</certainty>
+    <headline>Storm warning</headline>
+    <description>Wind gusts up to 25 m/s</description>
+    <area>
+      <areaDesc>Kazan; Zelenodolsk</areaDesc>
+    </area>
+    <area>
+      <areaDesc>Kazan</areaDesc>
+    </area>
+  </info>
+</alert>`
+
+func TestParse(t *testing.T) {
+	t.Run("when document is valid then alert", func(t *testing.T) {
+		alert, err := Parse(strings.NewReader(validAlert))
+		assert.NoError(t, err)
+		assert.Equal(t, "KSN-2024-001", alert.Identifier)
+		assert.True(t, alert.Deliverable())
+		assert.False(t, alert.Supersedes())
+		assert.Len(t, alert.Info, 1)
+		assert.Equal(t, []string{"Kazan", "Zelenodolsk"}, alert.Info[0].Cities())
+		assert.Equal(t, "ru-RU", alert.Info[0].Lang())
+	})
+	t.Run("when xml is malformed then report", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("<alert"))
+		var report *ValidationError
+		assert.True(t, errors.As(err, &report))
+		assert.Len(t, report.Problems, 1)
+		assert.Equal(t, "alert", report.Problems[0].Field)
+	})
+	t.Run("when namespace is wrong then report", func(t *testing.T) {
+		doc := strings.Replace(validAlert, "cap:1.2", "cap:1.1", 1)
+		_, err := Parse(strings.NewReader(doc))
+		var report *ValidationError
+		assert.True(t, errors.As(err, &report))
+	})
+	t.Run("when elements are invalid then every problem is reported", func(t *testing.T) {
+		doc := strings.NewReplacer(
+			"<sent>2024-04-15T18:45:00+03:00</sent>", "<sent>yesterday</sent>",
+			"<severity>Severe</severity>", "<severity>Huge</severity>",
+			"<msgType>Alert</msgType>", "<msgType>Update</msgType>",
+		).Replace(validAlert)
+
+		_, err := Parse(strings.NewReader(doc))
+		var report *ValidationError
+		assert.True(t, errors.As(err, &report))
+		fields := make([]string, 0, len(report.Problems))
+		for _, p := range report.Problems {
+			fields = append(fields, p.Field)
+		}
+		assert.Equal(t, []string{"alert.sent", "alert.references", "alert.info[0].severity"}, fields)
+	})
+	t.Run("when info has no area then report", func(t *testing.T) {
+		doc := strings.NewReplacer(
+			"<area>\n      <areaDesc>Kazan; Zelenodolsk</areaDesc>\n    </area>", "",
+			"<area>\n      <areaDesc>Kazan</areaDesc>\n    </area>", "",
+		).Replace(validAlert)
+
+		_, err := Parse(strings.NewReader(doc))
+		var report *ValidationError
+		assert.True(t, errors.As(err, &report))
+		assert.Equal(t, []Problem{{Field: "alert.info[0].area", Message: "is required to find the receivers"}}, report.Problems)
+	})
+}
+
+func TestAlert_ParseReferences(t *testing.T) {
+	t.Run("when references are valid then parsed", func(t *testing.T) {
+		alert := Alert{References: "mchs@example.org,KSN-1,2024-04-15T18:45:00+03:00 mchs@example.org,KSN-2,2024-04-15T19:00:00+03:00"}
+		references, err := alert.ParseReferences()
+		assert.NoError(t, err)
+		assert.Equal(t, []Reference{
+			{Sender: "mchs@example.org", Identifier: "KSN-1", Sent: "2024-04-15T18:45:00+03:00"},
+			{Sender: "mchs@example.org", Identifier: "KSN-2", Sent: "2024-04-15T19:00:00+03:00"},
+		}, references)
+	})
+	t.Run("when reference is incomplete then error", func(t *testing.T) {
+		alert := Alert{References: "mchs@example.org,KSN-1"}
+		_, err := alert.ParseReferences()
+		assert.Error(t, err)
+	})
+}
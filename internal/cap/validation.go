@@ -0,0 +1,78 @@
+package cap
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Problem is a single validation problem of a CAP document.
+type Problem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is a type representing the validation report of a malformed CAP document.
+type ValidationError struct {
+	Problems []Problem `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		messages = append(messages, p.Field+": "+p.Message)
+	}
+	return "invalid cap document: " + strings.Join(messages, "; ")
+}
+
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) add(field, message string) {
+	v.problems = append(v.problems, Problem{Field: field, Message: message})
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+// noForbiddenChars checks that the value has no spaces, commas or restricted characters.
+func (v *validator) noForbiddenChars(field, value string) {
+	if strings.ContainsAny(value, " ,<&") {
+		v.add(field, "must not contain spaces, commas or restricted characters")
+	}
+}
+
+func (v *validator) oneOf(field, value string, allowed []string) {
+	if value == "" {
+		v.add(field, "is required")
+		return
+	}
+	if contains(allowed, value) {
+		return
+	}
+	v.add(field, fmt.Sprintf("unknown value %q, expected one of %s", value, strings.Join(allowed, ", ")))
+}
+
+// dateTime checks the CAP date time format, e.g. 2002-05-24T16:49:00-07:00.
+func (v *validator) dateTime(field, value string, required bool) {
+	if value == "" {
+		if required {
+			v.add(field, "is required")
+		}
+		return
+	}
+	if _, err := time.Parse(time.RFC3339, value); err != nil || strings.HasSuffix(value, "Z") {
+		v.add(field, fmt.Sprintf("invalid date time %q, expected format 2002-05-24T16:49:00-07:00", value))
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"projects/emergency-messages/internal/models"
+)
+
+type BroadcastService interface {
+	GetByID(ctx context.Context, id string) (*models.BroadcastSummary, error)
+}
+
+type Broadcast struct {
+	broadcastService BroadcastService
+	log              *slog.Logger
+}
+
+func NewBroadcast(broadcastService BroadcastService, log *slog.Logger) *Broadcast {
+	return &Broadcast{
+		broadcastService: broadcastService,
+		log:              log,
+	}
+}
+
+func (b Broadcast) GetByID(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	id := chi.URLParam(r, "id")
+
+	broadcast, err := b.broadcastService.GetByID(ctx, id)
+	if assertError(err, w) {
+		b.log.Error("getting broadcast", err)
+		return
+	}
+
+	broadcastBytes, err := json.Marshal(broadcast)
+	if err != nil {
+		b.log.Error("cannot marshalling broadcast")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(broadcastBytes)
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"projects/emergency-messages/internal/cap"
+	"projects/emergency-messages/internal/models"
+)
+
+type CapService interface {
+	Ingest(ctx context.Context, r io.Reader) (*models.CapAlert, error)
+}
+
+type Cap struct {
+	capService CapService
+	log        *slog.Logger
+}
+
+func NewCap(capService CapService, log *slog.Logger) *Cap {
+	return &Cap{
+		capService: capService,
+		log:        log,
+	}
+}
+
+// Ingest receives a CAP 1.2 XML document.
+// A malformed document is rejected with the report of all its problems.
+func (c Cap) Ingest(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	alert, err := c.capService.Ingest(ctx, r.Body)
+	if err != nil {
+		var report *cap.ValidationError
+		if errors.As(err, &report) {
+			c.writeReport(w, report)
+			return
+		}
+		assertError(err, w)
+		c.log.Error("Cap.Ingest() error:", err)
+		return
+	}
+
+	alertBytes, err := json.Marshal(alert)
+	if err != nil {
+		c.log.Error("cannot marshalling cap alert")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(alertBytes)
+}
+
+func (c Cap) writeReport(w http.ResponseWriter, report *cap.ValidationError) {
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		c.log.Error("cannot marshalling cap validation report")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(reportBytes)
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"encoding/xml"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"projects/emergency-messages/internal/cap"
+)
+
+type CapFeedService interface {
+	Feed(ctx context.Context) (*cap.Feed, error)
+	GetAlert(ctx context.Context, id string) (*cap.Alert, error)
+}
+
+type CapFeed struct {
+	capFeedService CapFeedService
+	log            *slog.Logger
+}
+
+func NewCapFeed(capFeedService CapFeedService, log *slog.Logger) *CapFeed {
+	return &CapFeed{
+		capFeedService: capFeedService,
+		log:            log,
+	}
+}
+
+// Feed returns the Atom feed of the latest issued alerts.
+func (c CapFeed) Feed(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	feed, err := c.capFeedService.Feed(ctx)
+	if assertError(err, w) {
+		c.log.Error("getting cap feed", err)
+		return
+	}
+
+	c.writeXML(w, cap.FeedContentType, feed)
+}
+
+// GetAlert returns the CAP 1.2 document of an issued alert.
+func (c CapFeed) GetAlert(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	id := chi.URLParam(r, "id")
+
+	alert, err := c.capFeedService.GetAlert(ctx, id)
+	if assertError(err, w) {
+		c.log.Error("getting cap alert", err)
+		return
+	}
+
+	c.writeXML(w, cap.ContentType, alert)
+}
+
+func (c CapFeed) writeXML(w http.ResponseWriter, contentType string, v any) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		c.log.Error("cannot marshalling xml", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	w.Write(b)
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"log/slog"
+	"net/http"
+	"projects/emergency-messages/internal/models"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxWebhookBodySize is the greatest size of the form of a webhook
+const maxWebhookBodySize = 64 << 10
+
+type ConsentService interface {
+	Receive(ctx context.Context, sms models.InboundSMS) (string, error)
+	Consents(ctx context.Context, id string) ([]models.ContactConsent, error)
+}
+
+// SignatureValidator checks the signature of the URL and the form params of a webhook request.
+type SignatureValidator interface {
+	Validate(url string, params map[string]string, signature string) bool
+}
+
+type Consent struct {
+	consentService ConsentService
+	validator      SignatureValidator
+	// baseURL is the public URL of the service the webhooks are called at, the URL of the request is used if it is empty
+	baseURL string
+	log     *slog.Logger
+}
+
+// twimlResponse is the TwiML document replying to an inbound SMS, an empty document sends no reply
+type twimlResponse struct {
+	XMLName xml.Name `xml:"Response"`
+	Message string   `xml:"Message,omitempty"`
+}
+
+func NewConsent(consentService ConsentService, validator SignatureValidator, baseURL string, log *slog.Logger) *Consent {
+	return &Consent{
+		consentService: consentService,
+		validator:      validator,
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		log:            log,
+	}
+}
+
+// InboundSMS receives an SMS sent to the number of the service by the Twilio messaging webhook,
+// the request must be signed with X-Twilio-Signature.
+// It responds with the TwiML reply to a keyword.
+func (c *Consent) InboundSMS(w http.ResponseWriter, r *http.Request) {
+	params, ok := signedForm(w, r, c.validator, c.baseURL, c.log)
+	if !ok {
+		return
+	}
+
+	sms := models.InboundSMS{
+		MessageSID: params["MessageSid"],
+		From:       params["From"],
+		To:         params["To"],
+		Body:       params["Body"],
+	}
+	ctx := context.Background()
+	reply, err := c.consentService.Receive(ctx, sms)
+	if assertError(err, w) {
+		c.log.Error("receiving inbound sms", err)
+		return
+	}
+
+	responseBytes, err := xml.Marshal(twimlResponse{Message: reply})
+	if err != nil {
+		c.log.Error("cannot marshalling twiml")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	w.Write(responseBytes)
+}
+
+// List responds with the changes of the consents of the receiver, the latest first.
+func (c *Consent) List(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	consents, err := c.consentService.Consents(ctx, chi.URLParam(r, "id"))
+	if assertError(err, w) {
+		c.log.Error("listing receiver consents", err)
+		return
+	}
+
+	consentsBytes, err := json.Marshal(consents)
+	if err != nil {
+		c.log.Error("cannot marshalling consents")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(consentsBytes)
+}
+
+// signedForm parses the form of a webhook request and checks its X-Twilio-Signature.
+// It responds with an error and returns false if the form is invalid or the signature does not match.
+func signedForm(w http.ResponseWriter, r *http.Request, validator SignatureValidator, baseURL string, log *slog.Logger) (map[string]string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBodySize)
+	if err := r.ParseForm(); err != nil {
+		log.Error("cannot parsing form", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+
+	params := make(map[string]string, len(r.PostForm))
+	for name, values := range r.PostForm {
+		params[name] = values[0]
+	}
+	if !validator.Validate(webhookURL(r, baseURL), params, r.Header.Get("X-Twilio-Signature")) {
+		log.Error("invalid twilio signature", r.URL.Path)
+		w.WriteHeader(http.StatusForbidden)
+		return nil, false
+	}
+	return params, true
+}
+
+// webhookURL returns the URL the webhook request is sent to, the URL the provider signs.
+func webhookURL(r *http.Request, baseURL string) string {
+	if baseURL != "" {
+		return baseURL + r.URL.RequestURI()
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	mock_controllers "projects/emergency-messages/internal/controllers/mocks"
+	"projects/emergency-messages/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// signatureValidator accepts the signature "valid" of the URL
+type signatureValidator struct {
+	url    string
+	params map[string]string
+}
+
+func (v *signatureValidator) Validate(url string, params map[string]string, signature string) bool {
+	v.url, v.params = url, params
+	return signature == "valid"
+}
+
+func newConsentServer(service ConsentService, validator SignatureValidator) *httptest.Server {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	c := NewConsent(service, validator, "https://alerts.example.com/", log)
+
+	r := chi.NewRouter()
+	r.Post("/sms/inbound", c.InboundSMS)
+	r.Get("/receivers/{id}/consents", c.List)
+	return httptest.NewServer(r)
+}
+
+func TestConsent_InboundSMS(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockConsentService(controller)
+	validator := &signatureValidator{}
+	ts := newConsentServer(service, validator)
+	defer ts.Close()
+
+	form := url.Values{"MessageSid": {"SM1"}, "From": {"+79001234567"}, "To": {"+78001234567"}, "Body": {"STOP"}}
+	post := func(signature string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/sms/inbound", strings.NewReader(form.Encode()))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Twilio-Signature", signature)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	t.Run("when keyword is received then twiml reply", func(t *testing.T) {
+		service.EXPECT().
+			Receive(ctx, models.InboundSMS{MessageSID: "SM1", From: "+79001234567", To: "+78001234567", Body: "STOP"}).
+			Return("You are unsubscribed", nil)
+
+		resp := post("valid")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/xml", resp.Header.Get("Content-Type"))
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "<Response><Message>You are unsubscribed</Message></Response>")
+		assert.Equal(t, "https://alerts.example.com/sms/inbound", validator.url)
+		assert.Equal(t, "STOP", validator.params["Body"])
+	})
+	t.Run("when sms is not a keyword then empty twiml", func(t *testing.T) {
+		service.EXPECT().
+			Receive(ctx, gomock.Any()).
+			Return("", nil)
+
+		resp := post("valid")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "<Response></Response>")
+	})
+	t.Run("when signature is invalid then forbidden", func(t *testing.T) {
+		resp := post("forged")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestConsent_List(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockConsentService(controller)
+	ts := newConsentServer(service, &signatureValidator{})
+	defer ts.Close()
+
+	id := "7d603549-b079-4016-b81e-9e4386c1de21"
+	service.EXPECT().
+		Consents(ctx, id).
+		Return([]models.ContactConsent{{Action: models.ConsentOptOut}}, nil)
+
+	resp, err := http.Get(ts.URL + "/receivers/" + id + "/consents")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
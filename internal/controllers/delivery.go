@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"strings"
+)
+
+type DeliveryService interface {
+	Report(ctx context.Context, report models.DeliveryReport) error
+	ReportOnce(ctx context.Context, report models.DeliveryReport, token string) error
+}
+
+// EventSignatureValidator checks the signature of the timestamp and the token of a Mailgun webhook request.
+type EventSignatureValidator interface {
+	Validate(timestamp, token, signature string) bool
+}
+
+type Delivery struct {
+	deliveryService  DeliveryService
+	twilioValidator  SignatureValidator
+	mailgunValidator EventSignatureValidator
+	// baseURL is the public URL of the service the webhooks are called at, the URL of the request is used if it is empty
+	baseURL string
+	log     *slog.Logger
+}
+
+// twilioStatuses are the statuses of a message by the MessageStatus of a Twilio status callback
+var twilioStatuses = map[string]models.MessageStatus{
+	"queued":      models.Accepted,
+	"accepted":    models.Accepted,
+	"scheduled":   models.Accepted,
+	"sending":     models.Sent,
+	"sent":        models.Sent,
+	"delivered":   models.Delivered,
+	"read":        models.Delivered,
+	"undelivered": models.Undelivered,
+	"failed":      models.Undelivered,
+	"canceled":    models.Undelivered,
+}
+
+// mailgunEvent is the body of a Mailgun webhook request
+type mailgunEvent struct {
+	Signature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	} `json:"signature"`
+	EventData struct {
+		Event    string `json:"event"`
+		Severity string `json:"severity"`
+		Reason   string `json:"reason"`
+		Message  struct {
+			Headers struct {
+				MessageID string `json:"message-id"`
+			} `json:"headers"`
+		} `json:"message"`
+		DeliveryStatus struct {
+			Message     string `json:"message"`
+			Description string `json:"description"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+func NewDelivery(deliveryService DeliveryService, twilioValidator SignatureValidator, mailgunValidator EventSignatureValidator, baseURL string, log *slog.Logger) *Delivery {
+	return &Delivery{
+		deliveryService:  deliveryService,
+		twilioValidator:  twilioValidator,
+		mailgunValidator: mailgunValidator,
+		baseURL:          strings.TrimRight(baseURL, "/"),
+		log:              log,
+	}
+}
+
+// TwilioStatus receives the status of an SMS by the Twilio status callback,
+// the request must be signed with X-Twilio-Signature.
+// A status of an unknown SMS is accepted and dropped, Twilio does not retry a callback.
+func (d *Delivery) TwilioStatus(w http.ResponseWriter, r *http.Request) {
+	params, ok := signedForm(w, r, d.twilioValidator, d.baseURL, d.log)
+	if !ok {
+		return
+	}
+
+	report := models.DeliveryReport{
+		Type:              models.ContactTypeSMS,
+		ProviderMessageID: params["MessageSid"],
+		Status:            twilioStatuses[params["MessageStatus"]],
+		ProviderStatus:    params["MessageStatus"],
+	}
+	if code := params["ErrorCode"]; code != "" {
+		report.Reason = "twilio error " + code
+	}
+
+	ctx := context.Background()
+	err := d.deliveryService.Report(ctx, report)
+	if errors.Is(err, errorx.ErrNotFound) {
+		d.log.With(slog.Any("report", report)).
+			Warn("dropping twilio status of unknown message")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if assertError(err, w) {
+		d.log.Error("receiving twilio status", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MailgunEvent receives an event of an email by the Mailgun webhook, the request must be signed with the signing key.
+// Delivered, failed and complained events are reported once by the token of the request, other events are ignored.
+func (d *Delivery) MailgunEvent(w http.ResponseWriter, r *http.Request) {
+	var event mailgunEvent
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		d.log.Error("cannot decoding mailgun event", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !d.mailgunValidator.Validate(event.Signature.Timestamp, event.Signature.Token, event.Signature.Signature) {
+		d.log.Error("invalid mailgun signature", r.URL.Path)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	report, ok := mailgunReport(event)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	ctx := context.Background()
+	if err := d.deliveryService.ReportOnce(ctx, report, event.Signature.Token); assertError(err, w) {
+		d.log.Error("receiving mailgun event", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// mailgunReport returns the delivery report of the event.
+// A permanent failure of a bounce is bounced, other permanent failures are undelivered,
+// a temporary failure keeps the status, Mailgun retries the delivery.
+// A complaint keeps the status too, the email was delivered.
+// It returns false if the event is not a delivery event, e.g. opened or clicked.
+func mailgunReport(event mailgunEvent) (models.DeliveryReport, bool) {
+	data := event.EventData
+	report := models.DeliveryReport{
+		Type:              models.ContactTypeEmail,
+		ProviderMessageID: strings.Trim(data.Message.Headers.MessageID, "<>"),
+		ProviderStatus:    data.Event,
+	}
+
+	switch data.Event {
+	case "accepted":
+		report.Status = models.Accepted
+	case "delivered":
+		report.Status = models.Delivered
+	case "failed":
+		report.Reason = mailgunReason(event)
+		switch {
+		case data.Severity == "temporary":
+			report.ProviderStatus = "temporary_failed"
+		case data.Reason == "bounce" || data.Reason == "suppress-bounce":
+			report.Status = models.Bounced
+		default:
+			report.Status = models.Undelivered
+		}
+	case "bounced":
+		report.Status = models.Bounced
+		report.Reason = mailgunReason(event)
+	case "dropped":
+		report.Status = models.Undelivered
+		report.Reason = mailgunReason(event)
+	case "complained":
+	default:
+		return models.DeliveryReport{}, false
+	}
+	return report, true
+}
+
+// mailgunReason returns the error of a failed event, the message of the mail server if there is one.
+func mailgunReason(event mailgunEvent) string {
+	status := event.EventData.DeliveryStatus
+	switch {
+	case status.Message != "":
+		return status.Message
+	case status.Description != "":
+		return status.Description
+	default:
+		return event.EventData.Reason
+	}
+}
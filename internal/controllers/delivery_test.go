@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	mock_controllers "projects/emergency-messages/internal/controllers/mocks"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// eventSignatureValidator accepts the signature "valid"
+type eventSignatureValidator struct{}
+
+func (eventSignatureValidator) Validate(_, _, signature string) bool {
+	return signature == "valid"
+}
+
+func newDeliveryServer(service DeliveryService, validator SignatureValidator) *httptest.Server {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	c := NewDelivery(service, validator, eventSignatureValidator{}, "https://alerts.example.com", log)
+
+	r := chi.NewRouter()
+	r.Post("/webhooks/twilio/status", c.TwilioStatus)
+	r.Post("/webhooks/mailgun/events", c.MailgunEvent)
+	return httptest.NewServer(r)
+}
+
+func TestDelivery_TwilioStatus(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockDeliveryService(controller)
+	validator := &signatureValidator{}
+	ts := newDeliveryServer(service, validator)
+	defer ts.Close()
+
+	post := func(form url.Values, signature string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/webhooks/twilio/status", strings.NewReader(form.Encode()))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Twilio-Signature", signature)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	t.Run("when sms is delivered then delivered is reported", func(t *testing.T) {
+		service.EXPECT().
+			Report(ctx, models.DeliveryReport{Type: models.ContactTypeSMS, ProviderMessageID: "SM1", Status: models.Delivered, ProviderStatus: "delivered"}).
+			Return(nil)
+
+		resp := post(url.Values{"MessageSid": {"SM1"}, "MessageStatus": {"delivered"}}, "valid")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "https://alerts.example.com/webhooks/twilio/status", validator.url)
+	})
+	t.Run("when sms is failed then undelivered with error code", func(t *testing.T) {
+		service.EXPECT().
+			Report(ctx, models.DeliveryReport{Type: models.ContactTypeSMS, ProviderMessageID: "SM1", Status: models.Undelivered, ProviderStatus: "failed", Reason: "twilio error 30008"}).
+			Return(nil)
+
+		resp := post(url.Values{"MessageSid": {"SM1"}, "MessageStatus": {"failed"}, "ErrorCode": {"30008"}}, "valid")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+	t.Run("when message is unknown then status is dropped", func(t *testing.T) {
+		service.EXPECT().
+			Report(ctx, gomock.Any()).
+			Return(errorx.ErrNotFound)
+
+		resp := post(url.Values{"MessageSid": {"SM2"}, "MessageStatus": {"sent"}}, "valid")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+	t.Run("when signature is invalid then forbidden", func(t *testing.T) {
+		resp := post(url.Values{"MessageSid": {"SM1"}, "MessageStatus": {"delivered"}}, "forged")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestDelivery_MailgunEvent(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockDeliveryService(controller)
+	ts := newDeliveryServer(service, &signatureValidator{})
+	defer ts.Close()
+
+	post := func(signature, eventData string) *http.Response {
+		body := `{"signature":{"timestamp":"1721640000","token":"token","signature":"` + signature + `"},"event-data":` + eventData + `}`
+		resp, err := http.Post(ts.URL+"/webhooks/mailgun/events", "application/json", strings.NewReader(body))
+		assert.NoError(t, err)
+		return resp
+	}
+
+	t.Run("when email is delivered then delivered is reported", func(t *testing.T) {
+		service.EXPECT().
+			ReportOnce(ctx, models.DeliveryReport{Type: models.ContactTypeEmail, ProviderMessageID: "1@mg.example.com", Status: models.Delivered, ProviderStatus: "delivered"}, "token").
+			Return(nil)
+
+		resp := post("valid", `{"event":"delivered","message":{"headers":{"message-id":"1@mg.example.com"}}}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+	t.Run("when email bounces then bounced with server message", func(t *testing.T) {
+		service.EXPECT().
+			ReportOnce(ctx, models.DeliveryReport{Type: models.ContactTypeEmail, ProviderMessageID: "1@mg.example.com", Status: models.Bounced, ProviderStatus: "failed", Reason: "No such user"}, "token").
+			Return(nil)
+
+		resp := post("valid", `{"event":"failed","severity":"permanent","reason":"bounce","message":{"headers":{"message-id":"1@mg.example.com"}},"delivery-status":{"message":"No such user"}}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+	t.Run("when email fails permanently then undelivered", func(t *testing.T) {
+		service.EXPECT().
+			ReportOnce(ctx, models.DeliveryReport{Type: models.ContactTypeEmail, ProviderMessageID: "1@mg.example.com", Status: models.Undelivered, ProviderStatus: "failed", Reason: "old"}, "token").
+			Return(nil)
+
+		resp := post("valid", `{"event":"failed","severity":"permanent","reason":"old","message":{"headers":{"message-id":"1@mg.example.com"}}}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+	t.Run("when email is complained then provider status only", func(t *testing.T) {
+		service.EXPECT().
+			ReportOnce(ctx, models.DeliveryReport{Type: models.ContactTypeEmail, ProviderMessageID: "1@mg.example.com", ProviderStatus: "complained"}, "token").
+			Return(nil)
+
+		resp := post("valid", `{"event":"complained","message":{"headers":{"message-id":"1@mg.example.com"}}}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+	t.Run("when event is not a delivery event then ignored", func(t *testing.T) {
+		resp := post("valid", `{"event":"opened","message":{"headers":{"message-id":"1@mg.example.com"}}}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+	t.Run("when signature is invalid then forbidden", func(t *testing.T) {
+		resp := post("forged", `{"event":"delivered","message":{"headers":{"message-id":"1@mg.example.com"}}}`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -10,10 +10,10 @@ func assertError(err error, w http.ResponseWriter) bool {
 	if err == nil {
 		return false
 	}
-	switch errors.Cause(err) {
-	case errorx.ErrNotFound:
+	switch {
+	case errors.Is(err, errorx.ErrNotFound):
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-	case errorx.ErrValidation:
+	case errors.Is(err, errorx.ErrValidation):
 		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 	default:
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -0,0 +1,83 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"log/slog"
+	"projects/emergency-messages/internal/cap"
+	"projects/emergency-messages/internal/models"
+	api "projects/emergency-messages/protos"
+)
+
+type CapService interface {
+	Ingest(ctx context.Context, r io.Reader) (*models.CapAlert, error)
+}
+
+type capServer struct {
+	capService CapService
+	api.UnimplementedCapServer
+	log *slog.Logger
+}
+
+func RegisterCap(grpcServer *grpc.Server, capService CapService, log *slog.Logger) {
+	api.RegisterCapServer(
+		grpcServer,
+		&capServer{
+			capService: capService,
+			log:        log,
+		},
+	)
+}
+
+// Ingest receives a CAP 1.2 XML document.
+// A malformed document is rejected with the report of all its problems as BadRequest details.
+func (c *capServer) Ingest(ctx context.Context, req *api.IngestRequest) (*api.IngestResponse, error) {
+	alert, err := c.capService.Ingest(ctx, bytes.NewReader(req.GetDocument()))
+	if err != nil {
+		var report *cap.ValidationError
+		if errors.As(err, &report) {
+			return nil, validationStatus(report)
+		}
+		return nil, status.Error(codes.Internal, "error while ingesting")
+	}
+
+	resp := &api.IngestResponse{
+		Identifier: alert.Identifier,
+		Sender:     alert.Sender,
+		Status:     alert.Status,
+		MsgType:    alert.MsgType,
+		Broadcasts: make([]*api.Broadcast, 0, len(alert.Broadcasts)),
+		Cancelled:  int32(alert.Cancelled),
+	}
+	for _, b := range alert.Broadcasts {
+		resp.Broadcasts = append(resp.Broadcasts, &api.Broadcast{
+			Id:       b.ID.String(),
+			City:     b.City,
+			Strength: b.Strength,
+		})
+	}
+	return resp, nil
+}
+
+func validationStatus(report *cap.ValidationError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(report.Problems))
+	for _, p := range report.Problems {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       p.Field,
+			Description: p.Message,
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, report.Error()).
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, report.Error())
+	}
+	return st.Err()
+}
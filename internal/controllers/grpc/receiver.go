@@ -0,0 +1,223 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log/slog"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	api "projects/emergency-messages/protos"
+)
+
+type ReceiverService interface {
+	Create(ctx context.Context, receiver *models.ReceiverCreate) (*models.Receiver, error)
+	GetByID(ctx context.Context, id string) (*models.Receiver, error)
+	FindByCity(ctx context.Context, city string) ([]models.Receiver, error)
+	List(ctx context.Context, filter models.ReceiverFilter) (*models.ReceiverList, error)
+	Update(ctx context.Context, update *models.ReceiverUpdate) (*models.Receiver, error)
+	Delete(ctx context.Context, id string) error
+	AddContact(ctx context.Context, id string, contact models.Contact) (*models.Receiver, error)
+	RemoveContact(ctx context.Context, id string, key models.ContactKey) (*models.Receiver, error)
+	SetContactActive(ctx context.Context, id string, key models.ContactKey, active bool) (*models.Receiver, error)
+}
+
+type receiverServer struct {
+	receiverService ReceiverService
+	api.UnimplementedReceiverServer
+	log *slog.Logger
+}
+
+func RegisterReceiver(grpcServer *grpc.Server, receiverService ReceiverService, log *slog.Logger) {
+	api.RegisterReceiverServer(
+		grpcServer,
+		&receiverServer{
+			receiverService: receiverService,
+			log:             log,
+		},
+	)
+}
+
+func (r *receiverServer) Create(ctx context.Context, req *api.CreateReceiverRequest) (*api.ReceiverResponse, error) {
+	newReceiver := &models.ReceiverCreate{
+		FirstName: req.GetFirstName(),
+		LastName:  req.GetLastName(),
+		City:      req.GetCity(),
+		Language:  req.GetLanguage(),
+		Tags:      req.GetTags(),
+		Contacts:  transformContacts(req.GetContacts()),
+	}
+	created, err := r.receiverService.Create(ctx, newReceiver)
+	if err != nil {
+		return nil, receiverStatus(err, "error while creating")
+	}
+	return transformReceiver(created), nil
+}
+
+func (r *receiverServer) Get(ctx context.Context, req *api.GetReceiverRequest) (*api.ReceiverResponse, error) {
+	found, err := r.receiverService.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, receiverStatus(err, "error while getting")
+	}
+	return transformReceiver(found), nil
+}
+
+// List returns a page of the receivers matching the filter of the request.
+func (r *receiverServer) List(ctx context.Context, req *api.ListReceiversRequest) (*api.ListReceiversResponse, error) {
+	filter := models.ReceiverFilter{
+		City:        req.GetCity(),
+		Name:        req.GetName(),
+		ContactType: models.ContactType(req.GetContactType()),
+		Active:      req.Active,
+		Language:    req.GetLanguage(),
+		Tags:        req.GetTags(),
+		Cursor:      req.GetCursor(),
+		Limit:       int(req.GetLimit()),
+	}
+	list, err := r.receiverService.List(ctx, filter)
+	if err != nil {
+		return nil, receiverStatus(err, "error while listing")
+	}
+
+	resp := &api.ListReceiversResponse{
+		Receivers:  make([]*api.ReceiverResponse, 0, len(list.Receivers)),
+		Total:      int32(list.Total),
+		NextCursor: list.NextCursor,
+	}
+	for i := range list.Receivers {
+		resp.Receivers = append(resp.Receivers, transformReceiver(&list.Receivers[i]))
+	}
+	return resp, nil
+}
+
+// Update changes the fields of the receiver which are set in the request.
+func (r *receiverServer) Update(ctx context.Context, req *api.UpdateReceiverRequest) (*api.ReceiverResponse, error) {
+	update := &models.ReceiverUpdate{
+		ID:        req.GetId(),
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		City:      req.City,
+		Language:  req.Language,
+	}
+	if req.Tags != nil {
+		tags := append([]string{}, req.GetTags().GetValues()...)
+		update.Tags = &tags
+	}
+	updated, err := r.receiverService.Update(ctx, update)
+	if err != nil {
+		return nil, receiverStatus(err, "error while updating")
+	}
+	return transformReceiver(updated), nil
+}
+
+func (r *receiverServer) Delete(ctx context.Context, req *api.DeleteReceiverRequest) (*api.DeleteReceiverResponse, error) {
+	if err := r.receiverService.Delete(ctx, req.GetId()); err != nil {
+		return nil, receiverStatus(err, "error while deleting")
+	}
+	return &api.DeleteReceiverResponse{}, nil
+}
+
+func (r *receiverServer) AddContact(ctx context.Context, req *api.AddContactRequest) (*api.ReceiverResponse, error) {
+	contact := models.Contact{
+		Type:     models.ContactType(req.GetContact().GetType()),
+		Value:    req.GetContact().GetValue(),
+		IsActive: req.GetContact().GetIsActive(),
+	}
+	receiver, err := r.receiverService.AddContact(ctx, req.GetId(), contact)
+	if err != nil {
+		return nil, receiverStatus(err, "error while adding contact")
+	}
+	return transformReceiver(receiver), nil
+}
+
+func (r *receiverServer) RemoveContact(ctx context.Context, req *api.ContactRequest) (*api.ReceiverResponse, error) {
+	receiver, err := r.receiverService.RemoveContact(ctx, req.GetId(), transformContactKey(req))
+	if err != nil {
+		return nil, receiverStatus(err, "error while removing contact")
+	}
+	return transformReceiver(receiver), nil
+}
+
+func (r *receiverServer) ActivateContact(ctx context.Context, req *api.ContactRequest) (*api.ReceiverResponse, error) {
+	receiver, err := r.receiverService.SetContactActive(ctx, req.GetId(), transformContactKey(req), true)
+	if err != nil {
+		return nil, receiverStatus(err, "error while activating contact")
+	}
+	return transformReceiver(receiver), nil
+}
+
+func (r *receiverServer) DeactivateContact(ctx context.Context, req *api.ContactRequest) (*api.ReceiverResponse, error) {
+	receiver, err := r.receiverService.SetContactActive(ctx, req.GetId(), transformContactKey(req), false)
+	if err != nil {
+		return nil, receiverStatus(err, "error while deactivating contact")
+	}
+	return transformReceiver(receiver), nil
+}
+
+// receiverStatus maps an error of the receiver service to the status of the response.
+func receiverStatus(err error, internal string) error {
+	switch {
+	case errors.Is(err, errorx.ErrValidation):
+		return status.Error(codes.InvalidArgument, "invalid input queue")
+	case errors.Is(err, errorx.ErrNotFound):
+		return status.Error(codes.NotFound, "not found")
+	default:
+		return status.Error(codes.Internal, internal)
+	}
+}
+
+func transformReceiver(r *models.Receiver) *api.ReceiverResponse {
+	resp := &api.ReceiverResponse{
+		Id:        r.ID.String(),
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		City:      r.City,
+		Language:  r.Language,
+		Tags:      r.Tags,
+		Contacts:  make([]*api.Contact, 0, len(r.Contacts)),
+	}
+	for _, c := range r.Contacts {
+		contact := &api.Contact{
+			Type:           string(c.Type),
+			Value:          c.Value,
+			IsActive:       c.IsActive,
+			InactiveReason: c.InactiveReason,
+		}
+		if c.VerifiedAt != nil {
+			contact.VerifiedAt = timestamppb.New(*c.VerifiedAt)
+		}
+		if c.OptedOutAt != nil {
+			contact.OptedOutAt = timestamppb.New(*c.OptedOutAt)
+		}
+		resp.Contacts = append(resp.Contacts, contact)
+	}
+	if r.CreatedAt != nil {
+		resp.CreatedAt = timestamppb.New(*r.CreatedAt)
+	}
+	if r.UpdatedAt != nil {
+		resp.UpdatedAt = timestamppb.New(*r.UpdatedAt)
+	}
+	return resp
+}
+
+func transformContacts(contacts []*api.Contact) []models.Contact {
+	result := make([]models.Contact, 0, len(contacts))
+	for _, c := range contacts {
+		result = append(result, models.Contact{
+			Type:     models.ContactType(c.GetType()),
+			Value:    c.GetValue(),
+			IsActive: c.GetIsActive(),
+		})
+	}
+	return result
+}
+
+func transformContactKey(req *api.ContactRequest) models.ContactKey {
+	return models.ContactKey{
+		Type:  models.ContactType(req.GetType()),
+		Value: req.GetValue(),
+	}
+}
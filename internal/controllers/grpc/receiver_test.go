@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	mock_controllers "projects/emergency-messages/internal/controllers/mocks"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	api "projects/emergency-messages/protos"
+	"testing"
+	"time"
+)
+
+func Test_receiverServer_Create(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	service := mock_controllers.NewMockReceiverService(controller)
+	server := receiverServer{receiverService: service}
+	ctx := context.Background()
+
+	t.Run("when receiver is valid then created", func(t *testing.T) {
+		req := &api.CreateReceiverRequest{
+			FirstName: "Ivan",
+			LastName:  "Petrov",
+			City:      "Kazan",
+			Contacts:  []*api.Contact{{Type: "sms", Value: "+79001234567", IsActive: true}},
+		}
+		id := uuid.New()
+		now := time.Now()
+		service.EXPECT().
+			Create(ctx, &models.ReceiverCreate{
+				FirstName: "Ivan",
+				LastName:  "Petrov",
+				City:      "Kazan",
+				Contacts:  []models.Contact{{Type: models.ContactTypeSMS, Value: "+79001234567", IsActive: true}},
+			}).
+			Return(&models.Receiver{ID: id, FirstName: "Ivan", CreatedAt: &now}, nil)
+
+		resp, err := server.Create(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, id.String(), resp.GetId())
+		assert.NotNil(t, resp.GetCreatedAt())
+	})
+	t.Run("when receiver is invalid then invalid argument", func(t *testing.T) {
+		service.EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil, errorx.ErrValidation)
+
+		resp, err := server.Create(ctx, &api.CreateReceiverRequest{})
+		assert.Nil(t, resp)
+		s, _ := status.FromError(err)
+		assert.Equal(t, codes.InvalidArgument, s.Code())
+	})
+}
+
+func Test_receiverServer_List(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	service := mock_controllers.NewMockReceiverService(controller)
+	server := receiverServer{receiverService: service}
+	ctx := context.Background()
+
+	t.Run("when filter is set then page of receivers", func(t *testing.T) {
+		active := false
+		service.EXPECT().
+			List(ctx, models.ReceiverFilter{City: "Kazan", Active: &active, Tags: []string{"volunteer"}, Limit: 2}).
+			Return(&models.ReceiverList{
+				Receivers:  []models.Receiver{{FirstName: "Ivan", Tags: []string{"volunteer"}}, {FirstName: "Olga"}},
+				Total:      5,
+				NextCursor: "abc",
+			}, nil)
+
+		resp, err := server.List(ctx, &api.ListReceiversRequest{City: "Kazan", Active: &active, Tags: []string{"volunteer"}, Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, resp.GetReceivers(), 2)
+		assert.Equal(t, []string{"volunteer"}, resp.GetReceivers()[0].GetTags())
+		assert.Equal(t, int32(5), resp.GetTotal())
+		assert.Equal(t, "abc", resp.GetNextCursor())
+	})
+	t.Run("when filter is invalid then invalid argument", func(t *testing.T) {
+		service.EXPECT().
+			List(ctx, gomock.Any()).
+			Return(nil, errorx.ErrValidation)
+
+		resp, err := server.List(ctx, &api.ListReceiversRequest{Cursor: "abc"})
+		assert.Nil(t, resp)
+		s, _ := status.FromError(err)
+		assert.Equal(t, codes.InvalidArgument, s.Code())
+	})
+}
+
+func Test_receiverServer_Update(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	service := mock_controllers.NewMockReceiverService(controller)
+	server := receiverServer{receiverService: service}
+	ctx := context.Background()
+
+	t.Run("when field is set then it is changed", func(t *testing.T) {
+		city := "Kazan"
+		service.EXPECT().
+			Update(ctx, &models.ReceiverUpdate{ID: "abc", City: &city}).
+			Return(&models.Receiver{City: city}, nil)
+
+		resp, err := server.Update(ctx, &api.UpdateReceiverRequest{Id: "abc", City: &city})
+		assert.NoError(t, err)
+		assert.Equal(t, city, resp.GetCity())
+	})
+	t.Run("when tags are set then they are replaced", func(t *testing.T) {
+		tags := []string{}
+		service.EXPECT().
+			Update(ctx, &models.ReceiverUpdate{ID: "abc", Tags: &tags}).
+			Return(&models.Receiver{}, nil)
+
+		_, err := server.Update(ctx, &api.UpdateReceiverRequest{Id: "abc", Tags: &api.ReceiverTags{}})
+		assert.NoError(t, err)
+	})
+	t.Run("when receiver is not found then not found", func(t *testing.T) {
+		service.EXPECT().
+			Update(ctx, gomock.Any()).
+			Return(nil, errorx.ErrNotFound)
+
+		resp, err := server.Update(ctx, &api.UpdateReceiverRequest{Id: "abc"})
+		assert.Nil(t, resp)
+		s, _ := status.FromError(err)
+		assert.Equal(t, codes.NotFound, s.Code())
+	})
+}
+
+func Test_receiverServer_Contacts(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	service := mock_controllers.NewMockReceiverService(controller)
+	server := receiverServer{receiverService: service}
+	ctx := context.Background()
+	key := models.ContactKey{Type: models.ContactTypeEmail, Value: "ivan@example.com"}
+
+	t.Run("when contact is activated then receiver", func(t *testing.T) {
+		service.EXPECT().
+			SetContactActive(ctx, "abc", key, true).
+			Return(&models.Receiver{Contacts: []models.Contact{{Type: key.Type, Value: key.Value, IsActive: true}}}, nil)
+
+		resp, err := server.ActivateContact(ctx, &api.ContactRequest{Id: "abc", Type: "email", Value: "ivan@example.com"})
+		assert.NoError(t, err)
+		assert.True(t, resp.GetContacts()[0].GetIsActive())
+	})
+	t.Run("when service fails then internal", func(t *testing.T) {
+		service.EXPECT().
+			RemoveContact(ctx, "abc", key).
+			Return(nil, errorx.ErrInternal)
+
+		resp, err := server.RemoveContact(ctx, &api.ContactRequest{Id: "abc", Type: "email", Value: "ivan@example.com"})
+		assert.Nil(t, resp)
+		s, _ := status.FromError(err)
+		assert.Equal(t, codes.Internal, s.Code())
+	})
+}
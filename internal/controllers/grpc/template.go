@@ -6,6 +6,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"log/slog"
 	"projects/emergency-messages/internal/errorx"
 	"projects/emergency-messages/internal/models"
@@ -13,9 +14,14 @@ import (
 )
 
 type TemplateService interface {
-	Create(ctx context.Context, template *models.TemplateCreate) error
+	Create(ctx context.Context, template *models.TemplateCreate) (*models.Template, error)
+	GetByID(ctx context.Context, id string) (*models.Template, error)
+	List(ctx context.Context, filter models.TemplateFilter) (*models.TemplateList, error)
+	Versions(ctx context.Context, id string) ([]models.TemplateVersion, error)
+	Rollback(ctx context.Context, rollback *models.TemplateRollback) (*models.Template, error)
 	Delete(ctx context.Context, id string) error
 	Update(ctx context.Context, template *models.TemplateUpdate) error
+	Preview(ctx context.Context, preview *models.TemplatePreview) (*models.TemplatePreviewResult, error)
 }
 
 type template struct {
@@ -45,24 +51,85 @@ func (t *template) Delete(ctx context.Context, req *api.DeleteRequest) (*api.Emp
 	return &api.EmptyResponse{}, nil
 }
 
-func (t *template) Create(ctx context.Context, req *api.CreateRequest) (*api.EmptyResponse, error) {
+func (t *template) Create(ctx context.Context, req *api.CreateRequest) (*api.TemplateResponse, error) {
 	newTemplate := &models.TemplateCreate{
-		Subject: req.GetSubject(),
-		Text:    req.GetText(),
+		Subject:            req.GetSubject(),
+		Text:               req.GetText(),
+		WhatsAppName:       req.GetWhatsappName(),
+		WhatsAppLanguage:   req.GetWhatsappLanguage(),
+		WhatsAppParameters: req.GetWhatsappParameters(),
+		Variables:          transformVariables(req.GetVariables()),
+		Variants:           transformVariants(req.GetVariants()),
+		Language:           req.GetLanguage(),
+		Translations:       transformTranslations(req.GetTranslations()),
+		MaxSegments:        int(req.GetMaxSegments()),
+		SegmentPolicy:      models.SegmentPolicy(req.GetSegmentPolicy()),
+		Author:             req.GetAuthor(),
 	}
-	if err := t.templateService.Create(ctx, newTemplate); err != nil {
+	created, err := t.templateService.Create(ctx, newTemplate)
+	if err != nil {
 		if errors.Is(err, errorx.ErrValidation) {
 			return nil, status.Error(codes.InvalidArgument, "invalid input queue")
 		}
 		return nil, status.Error(codes.Internal, "error while creating")
 	}
-	return &api.EmptyResponse{}, nil
+	return transformTemplate(created), nil
+}
+
+func (t *template) Get(ctx context.Context, req *api.GetRequest) (*api.TemplateResponse, error) {
+	found, err := t.templateService.GetByID(ctx, req.GetId())
+	if err != nil {
+		switch {
+		case errors.Is(err, errorx.ErrValidation):
+			return nil, status.Error(codes.InvalidArgument, "invalid input queue")
+		case errors.Is(err, errorx.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "not found")
+		default:
+			return nil, status.Error(codes.Internal, "error while getting")
+		}
+	}
+	return transformTemplate(found), nil
+}
+
+func (t *template) List(ctx context.Context, req *api.ListRequest) (*api.ListResponse, error) {
+	filter := models.TemplateFilter{
+		Subject: req.GetSubject(),
+		Limit:   int(req.GetLimit()),
+		Offset:  int(req.GetOffset()),
+	}
+	list, err := t.templateService.List(ctx, filter)
+	if err != nil {
+		if errors.Is(err, errorx.ErrValidation) {
+			return nil, status.Error(codes.InvalidArgument, "invalid input queue")
+		}
+		return nil, status.Error(codes.Internal, "error while listing")
+	}
+
+	resp := &api.ListResponse{
+		Templates: make([]*api.TemplateResponse, 0, len(list.Templates)),
+		Total:     int32(list.Total),
+	}
+	for i := range list.Templates {
+		resp.Templates = append(resp.Templates, transformTemplate(&list.Templates[i]))
+	}
+	return resp, nil
 }
 
 func (t *template) Update(ctx context.Context, req *api.UpdateRequest) (*api.EmptyResponse, error) {
 	updateTemplate := &models.TemplateUpdate{
-		Subject: req.GetSubject(),
-		Text:    req.GetText(),
+		ID:                 req.GetId(),
+		Subject:            req.GetSubject(),
+		Text:               req.GetText(),
+		WhatsAppName:       req.GetWhatsappName(),
+		WhatsAppLanguage:   req.GetWhatsappLanguage(),
+		WhatsAppParameters: req.GetWhatsappParameters(),
+		Variables:          transformVariables(req.GetVariables()),
+		Variants:           transformVariants(req.GetVariants()),
+		Language:           req.GetLanguage(),
+		Translations:       transformTranslations(req.GetTranslations()),
+		MaxSegments:        int(req.GetMaxSegments()),
+		SegmentPolicy:      models.SegmentPolicy(req.GetSegmentPolicy()),
+		Author:             req.GetAuthor(),
 	}
 	if err := t.templateService.Update(ctx, updateTemplate); err != nil {
 		switch {
@@ -76,3 +143,236 @@ func (t *template) Update(ctx context.Context, req *api.UpdateRequest) (*api.Emp
 	}
 	return &api.EmptyResponse{}, nil
 }
+
+func (t *template) Versions(ctx context.Context, req *api.VersionsRequest) (*api.VersionsResponse, error) {
+	versions, err := t.templateService.Versions(ctx, req.GetId())
+	if err != nil {
+		switch {
+		case errors.Is(err, errorx.ErrValidation):
+			return nil, status.Error(codes.InvalidArgument, "invalid input queue")
+		case errors.Is(err, errorx.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "not found")
+		default:
+			return nil, status.Error(codes.Internal, "error while getting versions")
+		}
+	}
+
+	resp := &api.VersionsResponse{
+		Versions: make([]*api.TemplateVersion, 0, len(versions)),
+	}
+	for _, v := range versions {
+		version := &api.TemplateVersion{
+			Version:            int32(v.Version),
+			Subject:            v.Subject,
+			Text:               v.Text,
+			WhatsappName:       v.WhatsAppName,
+			WhatsappLanguage:   v.WhatsAppLanguage,
+			WhatsappParameters: v.WhatsAppParameters,
+			Variables:          transformTemplateVariables(v.Variables),
+			Variants:           transformTemplateVariants(v.Variants),
+			Language:           v.Language,
+			Translations:       transformTemplateTranslations(v.Translations),
+			MaxSegments:        int32(v.MaxSegments),
+			SegmentPolicy:      string(v.SegmentPolicy),
+			Author:             v.Author,
+			RestoredFrom:       int32(v.RestoredFrom),
+			Current:            v.Current,
+		}
+		if v.CreatedAt != nil {
+			version.CreatedAt = timestamppb.New(*v.CreatedAt)
+		}
+		resp.Versions = append(resp.Versions, version)
+	}
+	return resp, nil
+}
+
+func (t *template) Rollback(ctx context.Context, req *api.RollbackRequest) (*api.TemplateResponse, error) {
+	rollback := &models.TemplateRollback{
+		ID:      req.GetId(),
+		Version: int(req.GetVersion()),
+		Author:  req.GetAuthor(),
+	}
+	restored, err := t.templateService.Rollback(ctx, rollback)
+	if err != nil {
+		switch {
+		case errors.Is(err, errorx.ErrValidation):
+			return nil, status.Error(codes.InvalidArgument, "invalid input queue")
+		case errors.Is(err, errorx.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "not found")
+		default:
+			return nil, status.Error(codes.Internal, "error while rolling back")
+		}
+	}
+	return transformTemplate(restored), nil
+}
+
+func (t *template) Preview(ctx context.Context, req *api.PreviewRequest) (*api.PreviewResponse, error) {
+	preview := &models.TemplatePreview{
+		ID:       req.GetId(),
+		City:     req.GetCity(),
+		Strength: req.GetStrength(),
+		Receiver: models.Receiver{
+			FirstName: req.GetReceiver().GetFirstName(),
+			LastName:  req.GetReceiver().GetLastName(),
+			City:      req.GetReceiver().GetCity(),
+		},
+		Params: req.GetParams(),
+	}
+	result, err := t.templateService.Preview(ctx, preview)
+	if err != nil {
+		switch {
+		case errors.Is(err, errorx.ErrValidation):
+			return nil, status.Error(codes.InvalidArgument, "invalid input queue")
+		case errors.Is(err, errorx.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "not found")
+		default:
+			return nil, status.Error(codes.Internal, "error while previewing")
+		}
+	}
+	return transformPreviewResult(result), nil
+}
+
+func transformPreviewResult(result *models.TemplatePreviewResult) *api.PreviewResponse {
+	resp := &api.PreviewResponse{
+		Channels: make([]*api.ChannelPreview, 0, len(result.Channels)),
+		Errors:   make([]*api.VariableError, 0, len(result.Errors)),
+	}
+	for _, c := range result.Channels {
+		channel := &api.ChannelPreview{
+			Type:    string(c.Type),
+			Subject: c.Subject,
+			Text:    c.Text,
+			Html:    c.HTML,
+			Format:  string(c.Format),
+		}
+		if c.SMS != nil {
+			channel.Sms = &api.SmsSegments{
+				Encoding:   c.SMS.Encoding,
+				Characters: int32(c.SMS.Characters),
+				Segments:   int32(c.SMS.Segments),
+			}
+		}
+		if c.WhatsApp != nil {
+			channel.Whatsapp = &api.WhatsAppPreview{
+				Name:       c.WhatsApp.Name,
+				Language:   c.WhatsApp.Language,
+				Parameters: c.WhatsApp.Parameters,
+			}
+		}
+		resp.Channels = append(resp.Channels, channel)
+	}
+	for _, e := range result.Errors {
+		resp.Errors = append(resp.Errors, &api.VariableError{
+			Variable: e.Variable,
+			Message:  e.Message,
+		})
+	}
+	return resp
+}
+
+func transformTemplate(t *models.Template) *api.TemplateResponse {
+	resp := &api.TemplateResponse{
+		Id:                 t.ID.String(),
+		Subject:            t.Subject,
+		Text:               t.Text,
+		WhatsappName:       t.WhatsAppName,
+		WhatsappLanguage:   t.WhatsAppLanguage,
+		WhatsappParameters: t.WhatsAppParameters,
+		Variables:          transformTemplateVariables(t.Variables),
+		Variants:           transformTemplateVariants(t.Variants),
+		Language:           t.Language,
+		Translations:       transformTemplateTranslations(t.Translations),
+		MaxSegments:        int32(t.MaxSegments),
+		SegmentPolicy:      string(t.SegmentPolicy),
+		Version:            int32(t.Version),
+		UpdatedBy:          t.UpdatedBy,
+	}
+	if t.CreatedAt != nil {
+		resp.CreatedAt = timestamppb.New(*t.CreatedAt)
+	}
+	if t.UpdatedAt != nil {
+		resp.UpdatedAt = timestamppb.New(*t.UpdatedAt)
+	}
+	return resp
+}
+
+func transformTemplateVariables(variables []models.TemplateVariable) []*api.Variable {
+	result := make([]*api.Variable, 0, len(variables))
+	for _, v := range variables {
+		result = append(result, &api.Variable{
+			Name:        v.Name,
+			Required:    v.Required,
+			Description: v.Description,
+		})
+	}
+	return result
+}
+
+func transformTemplateVariants(variants *models.TemplateVariants) *api.Variants {
+	if variants == nil {
+		return nil
+	}
+	return &api.Variants{
+		Sms:       variants.SMS,
+		Email:     variants.Email,
+		EmailHtml: variants.EmailHTML,
+		Markdown:  variants.Markdown,
+	}
+}
+
+func transformVariants(variants *api.Variants) *models.TemplateVariants {
+	if variants == nil {
+		return nil
+	}
+	return &models.TemplateVariants{
+		SMS:       variants.GetSms(),
+		Email:     variants.GetEmail(),
+		EmailHTML: variants.GetEmailHtml(),
+		Markdown:  variants.GetMarkdown(),
+	}
+}
+
+func transformVariables(variables []*api.Variable) []models.TemplateVariable {
+	if len(variables) == 0 {
+		return nil
+	}
+	result := make([]models.TemplateVariable, 0, len(variables))
+	for _, v := range variables {
+		result = append(result, models.TemplateVariable{
+			Name:        v.GetName(),
+			Required:    v.GetRequired(),
+			Description: v.GetDescription(),
+		})
+	}
+	return result
+}
+
+func transformTemplateTranslations(translations models.Translations) map[string]*api.Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	result := make(map[string]*api.Translation, len(translations))
+	for language, t := range translations {
+		result[language] = &api.Translation{
+			Subject:  t.Subject,
+			Text:     t.Text,
+			Variants: transformTemplateVariants(t.Variants),
+		}
+	}
+	return result
+}
+
+func transformTranslations(translations map[string]*api.Translation) models.Translations {
+	if len(translations) == 0 {
+		return nil
+	}
+	result := make(models.Translations, len(translations))
+	for language, t := range translations {
+		result[language] = models.TemplateTranslation{
+			Subject:  t.GetSubject(),
+			Text:     t.GetText(),
+			Variants: transformVariants(t.GetVariants()),
+		}
+	}
+	return result
+}
@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc/codes"
@@ -11,6 +12,7 @@ import (
 	"projects/emergency-messages/internal/models"
 	api "projects/emergency-messages/protos"
 	"testing"
+	"time"
 )
 
 func Test_template_Delete(t *testing.T) {
@@ -88,12 +90,13 @@ func Test_template_Create(t *testing.T) {
 
 		service.EXPECT().
 			Create(ctx, newTemplate).
-			Return(nil)
+			Return(&models.Template{ID: uuid.MustParse("7d603549-b079-4016-b81e-9e4386c1de21"), Text: tmpl.Text, Subject: tmpl.Subject}, nil)
 
 		temp := template{templateService: service}
 		resp, err := temp.Create(ctx, tmpl)
 		assert.Nil(t, err)
-		assert.NotNil(t, resp)
+		assert.Equal(t, "7d603549-b079-4016-b81e-9e4386c1de21", resp.GetId())
+		assert.Equal(t, tmpl.Subject, resp.GetSubject())
 	})
 
 	t.Run("when store returns validation error then error", func(t *testing.T) {
@@ -109,7 +112,7 @@ func Test_template_Create(t *testing.T) {
 
 		service.EXPECT().
 			Create(ctx, newTemplate).
-			Return(errorx.ErrValidation)
+			Return(nil, errorx.ErrValidation)
 
 		temp := template{templateService: service}
 		resp, err := temp.Create(ctx, tmpl)
@@ -135,7 +138,7 @@ func Test_template_Create(t *testing.T) {
 
 		service.EXPECT().
 			Create(ctx, newTemplate).
-			Return(errorx.ErrInternal)
+			Return(nil, errorx.ErrInternal)
 
 		temp := template{templateService: service}
 		resp, err := temp.Create(ctx, tmpl)
@@ -149,6 +152,76 @@ func Test_template_Create(t *testing.T) {
 	})
 }
 
+func Test_template_Get(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	service := mock_controllers.NewMockTemplateService(controller)
+
+	ctx := context.Background()
+	t.Run("when template exists then template", func(t *testing.T) {
+		id := "7d603549-b079-4016-b81e-9e4386c1de21"
+		createdAt := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+		service.EXPECT().
+			GetByID(ctx, id).
+			Return(&models.Template{ID: uuid.MustParse(id), Subject: "Flood", CreatedAt: &createdAt}, nil)
+
+		temp := template{templateService: service}
+		resp, err := temp.Get(ctx, &api.GetRequest{Id: id})
+		assert.Nil(t, err)
+		assert.Equal(t, id, resp.GetId())
+		assert.Equal(t, createdAt, resp.GetCreatedAt().AsTime())
+		assert.Nil(t, resp.GetUpdatedAt())
+	})
+
+	t.Run("when service returns not found error then error", func(t *testing.T) {
+		service.EXPECT().
+			GetByID(ctx, "1").
+			Return(nil, errorx.ErrNotFound)
+
+		temp := template{templateService: service}
+		resp, err := temp.Get(ctx, &api.GetRequest{Id: "1"})
+		assert.Nil(t, resp)
+
+		s, ok := status.FromError(err)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, codes.NotFound, s.Code())
+	})
+}
+
+func Test_template_List(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	service := mock_controllers.NewMockTemplateService(controller)
+
+	ctx := context.Background()
+	t.Run("when request is valid then page of templates", func(t *testing.T) {
+		service.EXPECT().
+			List(ctx, models.TemplateFilter{Subject: "flood", Limit: 2, Offset: 4}).
+			Return(&models.TemplateList{Templates: []models.Template{{Subject: "Flood"}, {Subject: "Flood warning"}}, Total: 7}, nil)
+
+		temp := template{templateService: service}
+		resp, err := temp.List(ctx, &api.ListRequest{Subject: "flood", Limit: 2, Offset: 4})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(7), resp.GetTotal())
+		assert.Len(t, resp.GetTemplates(), 2)
+	})
+
+	t.Run("when service returns validation error then error", func(t *testing.T) {
+		service.EXPECT().
+			List(ctx, models.TemplateFilter{Limit: 1000}).
+			Return(nil, errorx.ErrValidation)
+
+		temp := template{templateService: service}
+		resp, err := temp.List(ctx, &api.ListRequest{Limit: 1000})
+		assert.Nil(t, resp)
+
+		s, ok := status.FromError(err)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, codes.InvalidArgument, s.Code())
+	})
+}
+
 func Test_template_Update(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"projects/emergency-messages/internal/models"
+	"strconv"
+	"strings"
+)
+
+type ImportService interface {
+	Create(ctx context.Context, fileName, contentType string, file io.Reader, options models.ImportOptions) (*models.ImportJob, error)
+	GetByID(ctx context.Context, id string) (*models.ImportJob, error)
+	Cancel(ctx context.Context, id string) (*models.ImportJob, error)
+	WriteErrors(ctx context.Context, id string, w io.Writer) error
+}
+
+type Import struct {
+	importService ImportService
+	log           *slog.Logger
+}
+
+func NewImport(importService ImportService, log *slog.Logger) *Import {
+	return &Import{
+		importService: importService,
+		log:           log,
+	}
+}
+
+// Upload stores the file of the multipart form field "file" while it is received and creates a job importing it
+// in the background, with the query parameter dry_run=true the rows are only validated.
+// The format is detected by the content type and the name of the file, the query parameter format overrides it.
+// The columns of the file are found by their names, the query parameters column={layout column}:{file column} override them.
+// It responds with the job, its progress is available at the Location.
+func (c *Import) Upload(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	options := models.ImportOptions{
+		Format: models.ImportFormat(query.Get("format")),
+	}
+	if value := query.Get("dry_run"); value != "" {
+		var err error
+		if options.DryRun, err = strconv.ParseBool(value); err != nil {
+			c.log.Error("cannot parsing dry_run", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	for _, value := range query["column"] {
+		name, column, ok := strings.Cut(value, ":")
+		if !ok {
+			c.log.Error("cannot parsing column", value)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if options.Columns == nil {
+			options.Columns = make(map[string]string)
+		}
+		options.Columns[name] = column
+	}
+
+	file, err := formFile(r, "file")
+	if err != nil {
+		c.log.Error("cannot reading file", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	job, err := c.importService.Create(ctx, file.FileName(), file.Header.Get("Content-Type"), file, options)
+	if assertError(err, w) {
+		c.log.Error("creating import", err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/imports/"+job.ID.String())
+	c.writeImport(w, http.StatusAccepted, job)
+}
+
+func (c *Import) GetByID(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	id := chi.URLParam(r, "id")
+
+	job, err := c.importService.GetByID(ctx, id)
+	if assertError(err, w) {
+		c.log.Error("getting import", err)
+		return
+	}
+	c.writeImport(w, http.StatusOK, job)
+}
+
+// Cancel stops the import, the rows imported before are kept.
+func (c *Import) Cancel(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	id := chi.URLParam(r, "id")
+
+	job, err := c.importService.Cancel(ctx, id)
+	if assertError(err, w) {
+		c.log.Error("cancelling import", err)
+		return
+	}
+	c.writeImport(w, http.StatusOK, job)
+}
+
+// Errors responds with the CSV file of the rows which cannot be imported.
+func (c *Import) Errors(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	id := chi.URLParam(r, "id")
+
+	job, err := c.importService.GetByID(ctx, id)
+	if assertError(err, w) {
+		c.log.Error("getting import", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="import-`+job.ID.String()+`-errors.csv"`)
+	w.WriteHeader(http.StatusOK)
+	if err = c.importService.WriteErrors(ctx, id, w); err != nil {
+		c.log.Error("writing import errors", err)
+	}
+}
+
+func (c *Import) writeImport(w http.ResponseWriter, status int, job *models.ImportJob) {
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		c.log.Error("cannot marshalling import")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(jobBytes)
+}
+
+// formFile returns the part of the multipart form field, the file is read from the request body without buffering it.
+func formFile(r *http.Request, name string) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == name {
+			return part, nil
+		}
+	}
+}
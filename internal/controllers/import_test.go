@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	mock_controllers "projects/emergency-messages/internal/controllers/mocks"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"testing"
+)
+
+func newImportServer(service ImportService) *httptest.Server {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	c := NewImport(service, log)
+
+	r := chi.NewRouter()
+	r.Post("/receivers/upload", c.Upload)
+	r.Route("/imports/{id}", func(r chi.Router) {
+		r.Get("/", c.GetByID)
+		r.Get("/errors", c.Errors)
+		r.Post("/cancel", c.Cancel)
+	})
+	return httptest.NewServer(r)
+}
+
+func TestImport_Upload(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockImportService(controller)
+	ts := newImportServer(service)
+	defer ts.Close()
+
+	id := uuid.New()
+	data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nRobert;Smith;+48178323;true;;;Kazan"
+	upload := func(query string) (*http.Response, error) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		assert.NoError(t, form.WriteField("comment", "volunteers"))
+		part, err := form.CreateFormFile("file", "receivers.csv")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(data))
+		assert.NoError(t, err)
+		assert.NoError(t, form.Close())
+		return http.Post(ts.URL+"/receivers/upload"+query, form.FormDataContentType(), &body)
+	}
+
+	t.Run("when file is uploaded then import is created", func(t *testing.T) {
+		service.EXPECT().
+			Create(ctx, "receivers.csv", "application/octet-stream", gomock.Any(), models.ImportOptions{DryRun: true}).
+			DoAndReturn(func(_ context.Context, _, _ string, r io.Reader, _ models.ImportOptions) (*models.ImportJob, error) {
+				b, err := io.ReadAll(r)
+				assert.NoError(t, err)
+				assert.Equal(t, data, string(b))
+				return &models.ImportJob{ID: id, Status: models.ImportJobPending, DryRun: true}, nil
+			})
+
+		resp, err := upload("?dry_run=true")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Equal(t, "/api/v1/imports/"+id.String(), resp.Header.Get("Location"))
+		var job models.ImportJob
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+		assert.Equal(t, models.ImportJobPending, job.Status)
+		assert.True(t, job.DryRun)
+	})
+	t.Run("when format and columns are set then they are options", func(t *testing.T) {
+		options := models.ImportOptions{
+			Format:  models.ImportFormatJSONLines,
+			Columns: map[string]string{"MobilePhone": "Телефон", "City": "Город: район"},
+		}
+		service.EXPECT().
+			Create(ctx, "receivers.csv", "application/octet-stream", gomock.Any(), options).
+			Return(&models.ImportJob{ID: id, Status: models.ImportJobPending, Format: models.ImportFormatJSONLines}, nil)
+
+		query := url.Values{"format": {"jsonl"}, "column": {"MobilePhone:Телефон", "City:Город: район"}}
+		resp, err := upload("?" + query.Encode())
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	})
+	t.Run("when column has no file column then error", func(t *testing.T) {
+		resp, err := upload("?column=MobilePhone")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("when dry run is not bool then error", func(t *testing.T) {
+		resp, err := upload("?dry_run=maybe")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("when body is not multipart then error", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+"/receivers/upload", "text/csv", bytes.NewBufferString(data))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestImport_GetByIDAndCancel(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockImportService(controller)
+	ts := newImportServer(service)
+	defer ts.Close()
+
+	id := "7d603549-b079-4016-b81e-9e4386c1de21"
+	total := 10
+
+	t.Run("when import exists then progress", func(t *testing.T) {
+		service.EXPECT().
+			GetByID(ctx, id).
+			Return(&models.ImportJob{Status: models.ImportJobRunning, TotalRows: &total, ImportProgress: models.ImportProgress{Processed: 4}}, nil)
+
+		resp, err := http.Get(ts.URL + "/imports/" + id)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var job map[string]any
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+		assert.Equal(t, float64(4), job["processed_rows"])
+		assert.Equal(t, float64(10), job["total_rows"])
+	})
+	t.Run("when import is not found then error", func(t *testing.T) {
+		service.EXPECT().
+			GetByID(ctx, id).
+			Return(nil, errorx.ErrNotFound)
+
+		resp, err := http.Get(ts.URL + "/imports/" + id)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+	t.Run("when import is finished then it is not cancelled", func(t *testing.T) {
+		service.EXPECT().
+			Cancel(ctx, id).
+			Return(nil, errorx.ErrValidation)
+
+		resp, err := http.Post(ts.URL+"/imports/"+id+"/cancel", "application/json", nil)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestImport_Errors(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockImportService(controller)
+	ts := newImportServer(service)
+	defer ts.Close()
+
+	id := uuid.New()
+
+	t.Run("when import exists then csv of errors", func(t *testing.T) {
+		service.EXPECT().
+			GetByID(ctx, id.String()).
+			Return(&models.ImportJob{ID: id}, nil)
+		service.EXPECT().
+			WriteErrors(ctx, id.String(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, w io.Writer) error {
+				_, err := io.WriteString(w, "Line;Reason\n2;last name is empty\n")
+				return err
+			})
+
+		resp, err := http.Get(ts.URL + "/imports/" + id.String() + "/errors")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "Line;Reason\n2;last name is empty\n", string(b))
+	})
+}
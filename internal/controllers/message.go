@@ -38,10 +38,19 @@ func (m Message) Send(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	err = m.messageService.Send(ctx, message)
+	broadcast, err := m.messageService.Send(ctx, message)
 	if assertError(err, w) {
 		m.log.Error("Message.Send() error:", err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+
+	broadcastBytes, err := json.Marshal(broadcast)
+	if err != nil {
+		m.log.Error("cannot marshalling broadcast")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(broadcastBytes)
 }
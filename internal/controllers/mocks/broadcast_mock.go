@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/controllers/broadcast.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/controllers/broadcast.go -destination internal/controllers/mocks/broadcast_mock.go
+//
+// Package mock_controllers is a generated GoMock package.
+package mock_controllers
+
+import (
+	context "context"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBroadcastService is a mock of BroadcastService interface.
+type MockBroadcastService struct {
+	ctrl     *gomock.Controller
+	recorder *MockBroadcastServiceMockRecorder
+}
+
+// MockBroadcastServiceMockRecorder is the mock recorder for MockBroadcastService.
+type MockBroadcastServiceMockRecorder struct {
+	mock *MockBroadcastService
+}
+
+// NewMockBroadcastService creates a new mock instance.
+func NewMockBroadcastService(ctrl *gomock.Controller) *MockBroadcastService {
+	mock := &MockBroadcastService{ctrl: ctrl}
+	mock.recorder = &MockBroadcastServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBroadcastService) EXPECT() *MockBroadcastServiceMockRecorder {
+	return m.recorder
+}
+
+// GetByID mocks base method.
+func (m *MockBroadcastService) GetByID(ctx context.Context, id string) (*models.BroadcastSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.BroadcastSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockBroadcastServiceMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockBroadcastService)(nil).GetByID), ctx, id)
+}
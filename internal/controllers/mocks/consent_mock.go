@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/controllers/consent.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/controllers/consent.go -destination internal/controllers/mocks/consent_mock.go
+//
+// Package mock_controllers is a generated GoMock package.
+package mock_controllers
+
+import (
+	context "context"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockConsentService is a mock of ConsentService interface.
+type MockConsentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockConsentServiceMockRecorder
+}
+
+// MockConsentServiceMockRecorder is the mock recorder for MockConsentService.
+type MockConsentServiceMockRecorder struct {
+	mock *MockConsentService
+}
+
+// NewMockConsentService creates a new mock instance.
+func NewMockConsentService(ctrl *gomock.Controller) *MockConsentService {
+	mock := &MockConsentService{ctrl: ctrl}
+	mock.recorder = &MockConsentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConsentService) EXPECT() *MockConsentServiceMockRecorder {
+	return m.recorder
+}
+
+// Consents mocks base method.
+func (m *MockConsentService) Consents(ctx context.Context, id string) ([]models.ContactConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consents", ctx, id)
+	ret0, _ := ret[0].([]models.ContactConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consents indicates an expected call of Consents.
+func (mr *MockConsentServiceMockRecorder) Consents(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consents", reflect.TypeOf((*MockConsentService)(nil).Consents), ctx, id)
+}
+
+// Receive mocks base method.
+func (m *MockConsentService) Receive(ctx context.Context, sms models.InboundSMS) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Receive", ctx, sms)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Receive indicates an expected call of Receive.
+func (mr *MockConsentServiceMockRecorder) Receive(ctx, sms any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Receive", reflect.TypeOf((*MockConsentService)(nil).Receive), ctx, sms)
+}
+
+// MockSignatureValidator is a mock of SignatureValidator interface.
+type MockSignatureValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignatureValidatorMockRecorder
+}
+
+// MockSignatureValidatorMockRecorder is the mock recorder for MockSignatureValidator.
+type MockSignatureValidatorMockRecorder struct {
+	mock *MockSignatureValidator
+}
+
+// NewMockSignatureValidator creates a new mock instance.
+func NewMockSignatureValidator(ctrl *gomock.Controller) *MockSignatureValidator {
+	mock := &MockSignatureValidator{ctrl: ctrl}
+	mock.recorder = &MockSignatureValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSignatureValidator) EXPECT() *MockSignatureValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method.
+func (m *MockSignatureValidator) Validate(url string, params map[string]string, signature string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", url, params, signature)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockSignatureValidatorMockRecorder) Validate(url, params, signature any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockSignatureValidator)(nil).Validate), url, params, signature)
+}
@@ -0,0 +1,105 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/controllers/delivery.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/controllers/delivery.go -destination internal/controllers/mocks/delivery_mock.go
+//
+// Package mock_controllers is a generated GoMock package.
+package mock_controllers
+
+import (
+	context "context"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeliveryService is a mock of DeliveryService interface.
+type MockDeliveryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryServiceMockRecorder
+}
+
+// MockDeliveryServiceMockRecorder is the mock recorder for MockDeliveryService.
+type MockDeliveryServiceMockRecorder struct {
+	mock *MockDeliveryService
+}
+
+// NewMockDeliveryService creates a new mock instance.
+func NewMockDeliveryService(ctrl *gomock.Controller) *MockDeliveryService {
+	mock := &MockDeliveryService{ctrl: ctrl}
+	mock.recorder = &MockDeliveryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryService) EXPECT() *MockDeliveryServiceMockRecorder {
+	return m.recorder
+}
+
+// Report mocks base method.
+func (m *MockDeliveryService) Report(ctx context.Context, report models.DeliveryReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", ctx, report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockDeliveryServiceMockRecorder) Report(ctx, report any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockDeliveryService)(nil).Report), ctx, report)
+}
+
+// ReportOnce mocks base method.
+func (m *MockDeliveryService) ReportOnce(ctx context.Context, report models.DeliveryReport, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportOnce", ctx, report, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportOnce indicates an expected call of ReportOnce.
+func (mr *MockDeliveryServiceMockRecorder) ReportOnce(ctx, report, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportOnce", reflect.TypeOf((*MockDeliveryService)(nil).ReportOnce), ctx, report, token)
+}
+
+// MockEventSignatureValidator is a mock of EventSignatureValidator interface.
+type MockEventSignatureValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventSignatureValidatorMockRecorder
+}
+
+// MockEventSignatureValidatorMockRecorder is the mock recorder for MockEventSignatureValidator.
+type MockEventSignatureValidatorMockRecorder struct {
+	mock *MockEventSignatureValidator
+}
+
+// NewMockEventSignatureValidator creates a new mock instance.
+func NewMockEventSignatureValidator(ctrl *gomock.Controller) *MockEventSignatureValidator {
+	mock := &MockEventSignatureValidator{ctrl: ctrl}
+	mock.recorder = &MockEventSignatureValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventSignatureValidator) EXPECT() *MockEventSignatureValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method.
+func (m *MockEventSignatureValidator) Validate(timestamp, token, signature string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", timestamp, token, signature)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockEventSignatureValidatorMockRecorder) Validate(timestamp, token, signature any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockEventSignatureValidator)(nil).Validate), timestamp, token, signature)
+}
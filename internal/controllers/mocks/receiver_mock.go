@@ -0,0 +1,189 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/controllers/receiver.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/controllers/receiver.go -destination internal/controllers/mocks/receiver_mock.go
+//
+// Package mock_controllers is a generated GoMock package.
+package mock_controllers
+
+import (
+	context "context"
+	io "io"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReceiverService is a mock of ReceiverService interface.
+type MockReceiverService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReceiverServiceMockRecorder
+}
+
+// MockReceiverServiceMockRecorder is the mock recorder for MockReceiverService.
+type MockReceiverServiceMockRecorder struct {
+	mock *MockReceiverService
+}
+
+// NewMockReceiverService creates a new mock instance.
+func NewMockReceiverService(ctrl *gomock.Controller) *MockReceiverService {
+	mock := &MockReceiverService{ctrl: ctrl}
+	mock.recorder = &MockReceiverServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReceiverService) EXPECT() *MockReceiverServiceMockRecorder {
+	return m.recorder
+}
+
+// AddContact mocks base method.
+func (m *MockReceiverService) AddContact(ctx context.Context, id string, contact models.Contact) (*models.Receiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddContact", ctx, id, contact)
+	ret0, _ := ret[0].(*models.Receiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddContact indicates an expected call of AddContact.
+func (mr *MockReceiverServiceMockRecorder) AddContact(ctx, id, contact any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddContact", reflect.TypeOf((*MockReceiverService)(nil).AddContact), ctx, id, contact)
+}
+
+// Create mocks base method.
+func (m *MockReceiverService) Create(ctx context.Context, receiver *models.ReceiverCreate) (*models.Receiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, receiver)
+	ret0, _ := ret[0].(*models.Receiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReceiverServiceMockRecorder) Create(ctx, receiver any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReceiverService)(nil).Create), ctx, receiver)
+}
+
+// Delete mocks base method.
+func (m *MockReceiverService) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockReceiverServiceMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReceiverService)(nil).Delete), ctx, id)
+}
+
+// Export mocks base method.
+func (m *MockReceiverService) Export(ctx context.Context, export models.ReceiverExport, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, export, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockReceiverServiceMockRecorder) Export(ctx, export, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockReceiverService)(nil).Export), ctx, export, w)
+}
+
+// FindByCity mocks base method.
+func (m *MockReceiverService) FindByCity(ctx context.Context, city string) ([]models.Receiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByCity", ctx, city)
+	ret0, _ := ret[0].([]models.Receiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByCity indicates an expected call of FindByCity.
+func (mr *MockReceiverServiceMockRecorder) FindByCity(ctx, city any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCity", reflect.TypeOf((*MockReceiverService)(nil).FindByCity), ctx, city)
+}
+
+// GetByID mocks base method.
+func (m *MockReceiverService) GetByID(ctx context.Context, id string) (*models.Receiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.Receiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockReceiverServiceMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockReceiverService)(nil).GetByID), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockReceiverService) List(ctx context.Context, filter models.ReceiverFilter) (*models.ReceiverList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].(*models.ReceiverList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockReceiverServiceMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReceiverService)(nil).List), ctx, filter)
+}
+
+// RemoveContact mocks base method.
+func (m *MockReceiverService) RemoveContact(ctx context.Context, id string, key models.ContactKey) (*models.Receiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveContact", ctx, id, key)
+	ret0, _ := ret[0].(*models.Receiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveContact indicates an expected call of RemoveContact.
+func (mr *MockReceiverServiceMockRecorder) RemoveContact(ctx, id, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveContact", reflect.TypeOf((*MockReceiverService)(nil).RemoveContact), ctx, id, key)
+}
+
+// SetContactActive mocks base method.
+func (m *MockReceiverService) SetContactActive(ctx context.Context, id string, key models.ContactKey, active bool) (*models.Receiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetContactActive", ctx, id, key, active)
+	ret0, _ := ret[0].(*models.Receiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetContactActive indicates an expected call of SetContactActive.
+func (mr *MockReceiverServiceMockRecorder) SetContactActive(ctx, id, key, active any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetContactActive", reflect.TypeOf((*MockReceiverService)(nil).SetContactActive), ctx, id, key, active)
+}
+
+// Update mocks base method.
+func (m *MockReceiverService) Update(ctx context.Context, update *models.ReceiverUpdate) (*models.Receiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, update)
+	ret0, _ := ret[0].(*models.Receiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockReceiverServiceMockRecorder) Update(ctx, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockReceiverService)(nil).Update), ctx, update)
+}
@@ -40,11 +40,12 @@ func (m *MockTemplateService) EXPECT() *MockTemplateServiceMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockTemplateService) Create(ctx context.Context, template *models.TemplateCreate) error {
+func (m *MockTemplateService) Create(ctx context.Context, template *models.TemplateCreate) (*models.Template, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Create", ctx, template)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*models.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
@@ -67,6 +68,66 @@ func (mr *MockTemplateServiceMockRecorder) Delete(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTemplateService)(nil).Delete), ctx, id)
 }
 
+// GetByID mocks base method.
+func (m *MockTemplateService) GetByID(ctx context.Context, id string) (*models.Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockTemplateServiceMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockTemplateService)(nil).GetByID), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockTemplateService) List(ctx context.Context, filter models.TemplateFilter) (*models.TemplateList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].(*models.TemplateList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTemplateServiceMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTemplateService)(nil).List), ctx, filter)
+}
+
+// Preview mocks base method.
+func (m *MockTemplateService) Preview(ctx context.Context, preview *models.TemplatePreview) (*models.TemplatePreviewResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preview", ctx, preview)
+	ret0, _ := ret[0].(*models.TemplatePreviewResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preview indicates an expected call of Preview.
+func (mr *MockTemplateServiceMockRecorder) Preview(ctx, preview any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preview", reflect.TypeOf((*MockTemplateService)(nil).Preview), ctx, preview)
+}
+
+// Rollback mocks base method.
+func (m *MockTemplateService) Rollback(ctx context.Context, rollback *models.TemplateRollback) (*models.Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", ctx, rollback)
+	ret0, _ := ret[0].(*models.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockTemplateServiceMockRecorder) Rollback(ctx, rollback any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockTemplateService)(nil).Rollback), ctx, rollback)
+}
+
 // Update mocks base method.
 func (m *MockTemplateService) Update(ctx context.Context, template *models.TemplateUpdate) error {
 	m.ctrl.T.Helper()
@@ -80,3 +141,18 @@ func (mr *MockTemplateServiceMockRecorder) Update(ctx, template any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTemplateService)(nil).Update), ctx, template)
 }
+
+// Versions mocks base method.
+func (m *MockTemplateService) Versions(ctx context.Context, id string) ([]models.TemplateVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Versions", ctx, id)
+	ret0, _ := ret[0].([]models.TemplateVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Versions indicates an expected call of Versions.
+func (mr *MockTemplateServiceMockRecorder) Versions(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Versions", reflect.TypeOf((*MockTemplateService)(nil).Versions), ctx, id)
+}
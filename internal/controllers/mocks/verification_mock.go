@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/controllers/verification.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/controllers/verification.go -destination internal/controllers/mocks/verification_mock.go
+//
+// Package mock_controllers is a generated GoMock package.
+package mock_controllers
+
+import (
+	context "context"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockContactVerificationService is a mock of ContactVerificationService interface.
+type MockContactVerificationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockContactVerificationServiceMockRecorder
+}
+
+// MockContactVerificationServiceMockRecorder is the mock recorder for MockContactVerificationService.
+type MockContactVerificationServiceMockRecorder struct {
+	mock *MockContactVerificationService
+}
+
+// NewMockContactVerificationService creates a new mock instance.
+func NewMockContactVerificationService(ctrl *gomock.Controller) *MockContactVerificationService {
+	mock := &MockContactVerificationService{ctrl: ctrl}
+	mock.recorder = &MockContactVerificationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContactVerificationService) EXPECT() *MockContactVerificationServiceMockRecorder {
+	return m.recorder
+}
+
+// Confirm mocks base method.
+func (m *MockContactVerificationService) Confirm(ctx context.Context, id string, confirm models.ContactConfirm) (*models.Receiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, id, confirm)
+	ret0, _ := ret[0].(*models.Receiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockContactVerificationServiceMockRecorder) Confirm(ctx, id, confirm any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockContactVerificationService)(nil).Confirm), ctx, id, confirm)
+}
+
+// Verify mocks base method.
+func (m *MockContactVerificationService) Verify(ctx context.Context, id string, key models.ContactKey) (*models.ContactVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, id, key)
+	ret0, _ := ret[0].(*models.ContactVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockContactVerificationServiceMockRecorder) Verify(ctx, id, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockContactVerificationService)(nil).Verify), ctx, id, key)
+}
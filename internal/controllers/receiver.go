@@ -3,64 +3,371 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
-	"projects/emergency-messages/internal/services"
+	"net/url"
+	"projects/emergency-messages/internal/models"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
 )
 
+type ReceiverService interface {
+	Create(ctx context.Context, receiver *models.ReceiverCreate) (*models.Receiver, error)
+	GetByID(ctx context.Context, id string) (*models.Receiver, error)
+	FindByCity(ctx context.Context, city string) ([]models.Receiver, error)
+	List(ctx context.Context, filter models.ReceiverFilter) (*models.ReceiverList, error)
+	Export(ctx context.Context, export models.ReceiverExport, w io.Writer) error
+	Update(ctx context.Context, update *models.ReceiverUpdate) (*models.Receiver, error)
+	Delete(ctx context.Context, id string) error
+	AddContact(ctx context.Context, id string, contact models.Contact) (*models.Receiver, error)
+	RemoveContact(ctx context.Context, id string, key models.ContactKey) (*models.Receiver, error)
+	SetContactActive(ctx context.Context, id string, key models.ContactKey, active bool) (*models.Receiver, error)
+}
+
 type Receiver struct {
-	receiverService *services.ReceiverService
+	receiverService ReceiverService
 	log             *slog.Logger
 }
 
-func NewReceiver(receiverService *services.ReceiverService, log *slog.Logger) *Receiver {
+type receiverCreate struct {
+	FirstName string           `json:"first_name"`
+	LastName  string           `json:"last_name"`
+	City      string           `json:"city"`
+	Language  string           `json:"language"`
+	Tags      []string         `json:"tags"`
+	Contacts  []models.Contact `json:"contacts"`
+}
+
+// receiverUpdate is a partial update of a receiver, the fields which are not sent are not changed
+type receiverUpdate struct {
+	FirstName *string   `json:"first_name"`
+	LastName  *string   `json:"last_name"`
+	City      *string   `json:"city"`
+	Language  *string   `json:"language"`
+	Tags      *[]string `json:"tags"`
+}
+
+func NewReceiver(receiverService ReceiverService, log *slog.Logger) *Receiver {
 	return &Receiver{
 		receiverService: receiverService,
 		log:             log,
 	}
 }
 
-func (c *Receiver) GetByCity(w http.ResponseWriter, r *http.Request) {
-	city := r.URL.Query().Get("city")
+func (c *Receiver) Create(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		c.log.Error("cannot reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var receiver *receiverCreate
+	if err = json.Unmarshal(b, &receiver); err != nil || receiver == nil {
+		c.log.Error("cannot unmarshalling body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	newReceiver := &models.ReceiverCreate{
+		FirstName: receiver.FirstName,
+		LastName:  receiver.LastName,
+		City:      receiver.City,
+		Language:  receiver.Language,
+		Tags:      receiver.Tags,
+		Contacts:  receiver.Contacts,
+	}
+
 	ctx := context.Background()
-	receivers, err := c.receiverService.FindByCity(ctx, city)
+	created, err := c.receiverService.Create(ctx, newReceiver)
 	if assertError(err, w) {
-		c.log.Error("getting by city", err)
+		c.log.Error("creating receiver", err)
+		return
+	}
+	c.writeReceiver(w, http.StatusCreated, created)
+}
+
+// List returns a page of the receivers filtered by the query parameters
+// city, name, contact_type, active, language and tag (repeated, all must match),
+// the next page is requested with the cursor of the previous one.
+func (c *Receiver) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.ReceiverFilter{
+		City:        query.Get("city"),
+		Name:        query.Get("name"),
+		ContactType: models.ContactType(query.Get("contact_type")),
+		Language:    query.Get("language"),
+		Tags:        query["tag"],
+		Cursor:      query.Get("cursor"),
+	}
+
+	var err error
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			c.log.Error("cannot parsing limit", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			c.log.Error("cannot parsing active", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		filter.Active = &active
+	}
+
+	ctx := context.Background()
+	receivers, err := c.receiverService.List(ctx, filter)
+	if assertError(err, w) {
+		c.log.Error("listing receivers", err)
 		return
 	}
 
 	receiversBytes, err := json.Marshal(receivers)
 	if err != nil {
 		c.log.Error("cannot marshalling receivers")
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	w.WriteHeader(http.StatusOK)
 	w.Write(receiversBytes)
+}
+
+// Export responds with the receivers filtered by the query parameters city and contact_type
+// in the layout of the imported files, format=csv (by default) or format=jsonl, with mask=true the contact values are masked.
+// The receivers are written while they are read.
+func (c *Receiver) Export(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	export := models.ReceiverExport{
+		City:        query.Get("city"),
+		ContactType: models.ContactType(query.Get("contact_type")),
+		Format:      models.ImportFormat(query.Get("format")),
+	}
+	if value := query.Get("mask"); value != "" {
+		var err error
+		if export.Mask, err = strconv.ParseBool(value); err != nil {
+			c.log.Error("cannot parsing mask", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	contentType, fileName := "text/csv; charset=utf-8", "receivers.csv"
+	if export.Format == models.ImportFormatJSONLines {
+		contentType, fileName = "application/x-ndjson", "receivers.jsonl"
+	}
+	body := &exportWriter{w: w, contentType: contentType, fileName: fileName}
+
+	ctx := context.Background()
+	err := c.receiverService.Export(ctx, export, body)
+	if err != nil && body.started {
+		c.log.Error("exporting receivers", err)
+		return
+	}
+	if assertError(err, w) {
+		c.log.Error("exporting receivers", err)
+		return
+	}
+	// nothing is written by an export without receivers
+	body.start()
+}
+
+// exportWriter responds with the headers of the exported file when the first bytes are written,
+// so an export failing before it responds with the status of its error.
+type exportWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	fileName    string
+	started     bool
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	e.start()
+	return e.w.Write(p)
+}
+
+func (e *exportWriter) start() {
+	if e.started {
+		return
+	}
+	e.started = true
+	e.w.Header().Set("Content-Type", e.contentType)
+	e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.fileName+`"`)
+	e.w.WriteHeader(http.StatusOK)
+}
+
+func (c *Receiver) GetByID(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	id := chi.URLParam(r, "id")
+
+	receiver, err := c.receiverService.GetByID(ctx, id)
+	if assertError(err, w) {
+		c.log.Error("getting receiver", err)
+		return
+	}
+	c.writeReceiver(w, http.StatusOK, receiver)
+}
+
+// Update changes the fields of the receiver which are sent.
+func (c *Receiver) Update(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		c.log.Error("cannot reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var receiver *receiverUpdate
+	if err = json.Unmarshal(b, &receiver); err != nil || receiver == nil {
+		c.log.Error("cannot unmarshalling body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	update := &models.ReceiverUpdate{
+		ID:        chi.URLParam(r, "id"),
+		FirstName: receiver.FirstName,
+		LastName:  receiver.LastName,
+		City:      receiver.City,
+		Language:  receiver.Language,
+		Tags:      receiver.Tags,
+	}
+
+	ctx := context.Background()
+	updated, err := c.receiverService.Update(ctx, update)
+	if assertError(err, w) {
+		c.log.Error("updating receiver", err)
+		return
+	}
+	c.writeReceiver(w, http.StatusOK, updated)
+}
+
+func (c *Receiver) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	id := chi.URLParam(r, "id")
+
+	err := c.receiverService.Delete(ctx, id)
+	if assertError(err, w) {
+		c.log.Error("deleting receiver", err)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
-func (c *Receiver) Upload(w http.ResponseWriter, r *http.Request) {
-	file, _, err := r.FormFile("file")
+// AddContact adds the contact sent in the body to the receiver.
+func (c *Receiver) AddContact(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
 	if err != nil {
+		c.log.Error("cannot reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var contact models.Contact
+	if err = json.Unmarshal(b, &contact); err != nil {
+		c.log.Error("cannot unmarshalling body")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
 
-	receiversCreated, err := c.receiverService.Upload(file)
+	ctx := context.Background()
+	receiver, err := c.receiverService.AddContact(ctx, chi.URLParam(r, "id"), contact)
 	if assertError(err, w) {
-		c.log.Error("uploading receiver", err)
+		c.log.Error("adding receiver contact", err)
 		return
 	}
+	c.writeReceiver(w, http.StatusCreated, receiver)
+}
 
-	receiversBytes, err := json.Marshal(receiversCreated)
+// RemoveContact removes the contact of the type and the value in the path from the receiver.
+func (c *Receiver) RemoveContact(w http.ResponseWriter, r *http.Request) {
+	key, ok := c.contactKey(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	receiver, err := c.receiverService.RemoveContact(ctx, chi.URLParam(r, "id"), key)
+	if assertError(err, w) {
+		c.log.Error("removing receiver contact", err)
+		return
+	}
+	c.writeReceiver(w, http.StatusOK, receiver)
+}
+
+// ActivateContact activates the contact of the type and the value in the path.
+func (c *Receiver) ActivateContact(w http.ResponseWriter, r *http.Request) {
+	c.setContactActive(w, r, true)
+}
+
+// DeactivateContact deactivates the contact of the type and the value in the path.
+func (c *Receiver) DeactivateContact(w http.ResponseWriter, r *http.Request) {
+	c.setContactActive(w, r, false)
+}
+
+func (c *Receiver) setContactActive(w http.ResponseWriter, r *http.Request, active bool) {
+	key, ok := c.contactKey(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	receiver, err := c.receiverService.SetContactActive(ctx, chi.URLParam(r, "id"), key, active)
+	if assertError(err, w) {
+		c.log.Error("changing receiver contact", err)
+		return
+	}
+	c.writeReceiver(w, http.StatusOK, receiver)
+}
+
+// contactKey reads the type and the value of a contact from the path, the value is escaped, e.g. %2B7900 for +7900.
+func (c *Receiver) contactKey(w http.ResponseWriter, r *http.Request) (models.ContactKey, bool) {
+	value, err := url.PathUnescape(chi.URLParam(r, "value"))
+	if err != nil {
+		c.log.Error("cannot unescaping contact value", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return models.ContactKey{}, false
+	}
+	return models.ContactKey{
+		Type:  models.ContactType(chi.URLParam(r, "type")),
+		Value: value,
+	}, true
+}
+
+func (c *Receiver) writeReceiver(w http.ResponseWriter, status int, receiver *models.Receiver) {
+	receiverBytes, err := json.Marshal(receiver)
+	if err != nil {
+		c.log.Error("cannot marshalling receiver")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(receiverBytes)
+}
+
+func (c *Receiver) GetByCity(w http.ResponseWriter, r *http.Request) {
+	city := chi.URLParam(r, "city")
+	ctx := context.Background()
+	receivers, err := c.receiverService.FindByCity(ctx, city)
+	if assertError(err, w) {
+		c.log.Error("getting by city", err)
+		return
+	}
+
+	receiversBytes, err := json.Marshal(receivers)
 	if err != nil {
 		c.log.Error("cannot marshalling receivers")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
+	w.WriteHeader(http.StatusOK)
 	w.Write(receiversBytes)
-	w.WriteHeader(http.StatusCreated)
 }
@@ -0,0 +1,344 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	mock_controllers "projects/emergency-messages/internal/controllers/mocks"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"testing"
+)
+
+func newReceiverServer(service ReceiverService) *httptest.Server {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	c := NewReceiver(service, log)
+
+	r := chi.NewRouter()
+	r.Route("/receivers", func(r chi.Router) {
+		r.Get("/", c.List)
+		r.Post("/", c.Create)
+		r.Get("/city/{city}", c.GetByCity)
+		r.Get("/export", c.Export)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", c.GetByID)
+			r.Patch("/", c.Update)
+			r.Delete("/", c.Delete)
+			r.Post("/contacts", c.AddContact)
+			r.Delete("/contacts/{type}/{value}", c.RemoveContact)
+			r.Post("/contacts/{type}/{value}/activate", c.ActivateContact)
+			r.Post("/contacts/{type}/{value}/deactivate", c.DeactivateContact)
+		})
+	})
+	return httptest.NewServer(r)
+}
+
+func TestReceiver_Create(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockReceiverService(controller)
+	ts := newReceiverServer(service)
+	defer ts.Close()
+
+	t.Run("when body is valid then created", func(t *testing.T) {
+		body := `{"first_name":"Ivan","last_name":"Petrov","city":"Kazan","language":"tt","contacts":[{"type":"sms","value":"+79001234567","is_active":true}]}`
+		newReceiver := &models.ReceiverCreate{
+			FirstName: "Ivan",
+			LastName:  "Petrov",
+			City:      "Kazan",
+			Language:  "tt",
+			Contacts:  []models.Contact{{Type: models.ContactTypeSMS, Value: "+79001234567", IsActive: true}},
+		}
+		service.EXPECT().
+			Create(ctx, newReceiver).
+			Return(&models.Receiver{ID: uuid.New(), FirstName: "Ivan"}, nil)
+
+		resp, err := http.Post(ts.URL+"/receivers", "application/json", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		var created models.Receiver
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, "Ivan", created.FirstName)
+	})
+	t.Run("when body is invalid then error", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+"/receivers", "application/json", bytes.NewBufferString(`{"first_name":`))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("when receiver is invalid then error", func(t *testing.T) {
+		service.EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil, errorx.ErrValidation)
+
+		resp, err := http.Post(ts.URL+"/receivers", "application/json", bytes.NewBufferString(`{"first_name":"Ivan"}`))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestReceiver_List(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockReceiverService(controller)
+	ts := newReceiverServer(service)
+	defer ts.Close()
+
+	t.Run("when query is valid then page of receivers", func(t *testing.T) {
+		active := true
+		service.EXPECT().
+			List(ctx, models.ReceiverFilter{
+				City:        "Kazan",
+				Name:        "Iv",
+				ContactType: models.ContactTypeSMS,
+				Active:      &active,
+				Tags:        []string{"volunteer", "doctor"},
+				Cursor:      "abc",
+				Limit:       10,
+			}).
+			Return(&models.ReceiverList{Receivers: []models.Receiver{{FirstName: "Ivan"}}, Total: 11, NextCursor: "def"}, nil)
+
+		resp, err := http.Get(ts.URL + "/receivers?city=Kazan&name=Iv&contact_type=sms&active=true&tag=volunteer&tag=doctor&cursor=abc&limit=10")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var list models.ReceiverList
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		assert.Equal(t, 11, list.Total)
+		assert.Equal(t, "def", list.NextCursor)
+	})
+	t.Run("when active is not bool then error", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/receivers?active=maybe")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("when filter is invalid then error", func(t *testing.T) {
+		service.EXPECT().
+			List(ctx, gomock.Any()).
+			Return(nil, errorx.ErrValidation)
+
+		resp, err := http.Get(ts.URL + "/receivers?limit=1000")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestReceiver_Export(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockReceiverService(controller)
+	ts := newReceiverServer(service)
+	defer ts.Close()
+
+	t.Run("when query is valid then file is streamed", func(t *testing.T) {
+		service.EXPECT().
+			Export(ctx, models.ReceiverExport{City: "Kazan", ContactType: models.ContactTypeSMS, Format: models.ImportFormatJSONLines, Mask: true}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ models.ReceiverExport, w io.Writer) error {
+				_, err := io.WriteString(w, `{"firstName":"Ildar"}`+"\n")
+				return err
+			})
+
+		resp, err := http.Get(ts.URL + "/receivers/export?city=Kazan&contact_type=sms&format=jsonl&mask=true")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="receivers.jsonl"`, resp.Header.Get("Content-Disposition"))
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"firstName":"Ildar"}`+"\n", string(body))
+	})
+	t.Run("when export fails before writing then error status", func(t *testing.T) {
+		service.EXPECT().
+			Export(ctx, models.ReceiverExport{ContactType: "fax"}, gomock.Any()).
+			Return(errorx.ErrValidation)
+
+		resp, err := http.Get(ts.URL + "/receivers/export?contact_type=fax")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("when mask is not bool then error", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/receivers/export?mask=maybe")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestReceiver_Update(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockReceiverService(controller)
+	ts := newReceiverServer(service)
+	defer ts.Close()
+
+	id := "7d603549-b079-4016-b81e-9e4386c1de21"
+
+	t.Run("when fields are sent then only they are changed", func(t *testing.T) {
+		city := "Kazan"
+		service.EXPECT().
+			Update(ctx, &models.ReceiverUpdate{ID: id, City: &city}).
+			Return(&models.Receiver{City: city}, nil)
+
+		req, err := http.NewRequest(http.MethodPatch, ts.URL+"/receivers/"+id, bytes.NewBufferString(`{"city":"Kazan"}`))
+		assert.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("when receiver is not found then error", func(t *testing.T) {
+		service.EXPECT().
+			Update(ctx, gomock.Any()).
+			Return(nil, errorx.ErrNotFound)
+
+		req, err := http.NewRequest(http.MethodPatch, ts.URL+"/receivers/"+id, bytes.NewBufferString(`{}`))
+		assert.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestReceiver_GetByIDAndDelete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockReceiverService(controller)
+	ts := newReceiverServer(service)
+	defer ts.Close()
+
+	id := "7d603549-b079-4016-b81e-9e4386c1de21"
+
+	t.Run("when receiver exists then receiver", func(t *testing.T) {
+		service.EXPECT().
+			GetByID(ctx, id).
+			Return(&models.Receiver{FirstName: "Ivan"}, nil)
+
+		resp, err := http.Get(ts.URL + "/receivers/" + id)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("when receiver is deleted then ok", func(t *testing.T) {
+		service.EXPECT().
+			Delete(ctx, id).
+			Return(nil)
+
+		req, err := http.NewRequest(http.MethodDelete, ts.URL+"/receivers/"+id, nil)
+		assert.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("when city is in the path then receivers of the city", func(t *testing.T) {
+		service.EXPECT().
+			FindByCity(ctx, "Kazan").
+			Return([]models.Receiver{{City: "Kazan"}}, nil)
+
+		resp, err := http.Get(ts.URL + "/receivers/city/Kazan")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestReceiver_Contacts(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockReceiverService(controller)
+	ts := newReceiverServer(service)
+	defer ts.Close()
+
+	id := "7d603549-b079-4016-b81e-9e4386c1de21"
+	key := models.ContactKey{Type: models.ContactTypeSMS, Value: "+79001234567"}
+
+	t.Run("when contact is added then created", func(t *testing.T) {
+		service.EXPECT().
+			AddContact(ctx, id, models.Contact{Type: models.ContactTypeEmail, Value: "ivan@example.com", IsActive: true}).
+			Return(&models.Receiver{}, nil)
+
+		body := `{"type":"email","value":"ivan@example.com","is_active":true}`
+		resp, err := http.Post(ts.URL+"/receivers/"+id+"/contacts", "application/json", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+	t.Run("when contact is removed then value is unescaped", func(t *testing.T) {
+		service.EXPECT().
+			RemoveContact(ctx, id, key).
+			Return(&models.Receiver{}, nil)
+
+		req, err := http.NewRequest(http.MethodDelete, ts.URL+"/receivers/"+id+"/contacts/sms/%2B79001234567", nil)
+		assert.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("when contact is deactivated then ok", func(t *testing.T) {
+		service.EXPECT().
+			SetContactActive(ctx, id, key, false).
+			Return(&models.Receiver{}, nil)
+
+		resp, err := http.Post(ts.URL+"/receivers/"+id+"/contacts/sms/%2B79001234567/deactivate", "application/json", nil)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("when contact is not found then error", func(t *testing.T) {
+		service.EXPECT().
+			SetContactActive(ctx, id, key, true).
+			Return(nil, errorx.ErrNotFound)
+
+		resp, err := http.Post(ts.URL+"/receivers/"+id+"/contacts/sms/%2B79001234567/activate", "application/json", nil)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -8,12 +8,18 @@ import (
 	"log/slog"
 	"net/http"
 	"projects/emergency-messages/internal/models"
+	"strconv"
 )
 
 type TemplateService interface {
-	Create(ctx context.Context, template *models.TemplateCreate) error
+	Create(ctx context.Context, template *models.TemplateCreate) (*models.Template, error)
+	GetByID(ctx context.Context, id string) (*models.Template, error)
+	List(ctx context.Context, filter models.TemplateFilter) (*models.TemplateList, error)
+	Versions(ctx context.Context, id string) ([]models.TemplateVersion, error)
+	Rollback(ctx context.Context, rollback *models.TemplateRollback) (*models.Template, error)
 	Delete(ctx context.Context, id string) error
 	Update(ctx context.Context, template *models.TemplateUpdate) error
+	Preview(ctx context.Context, preview *models.TemplatePreview) (*models.TemplatePreviewResult, error)
 }
 
 type Template struct {
@@ -22,14 +28,50 @@ type Template struct {
 }
 
 type templateUpdate struct {
-	ID      string `json:"id"`
-	Subject string `json:"subject"`
-	Text    string `json:"text"`
+	ID                 string                    `json:"id"`
+	Subject            string                    `json:"subject"`
+	Text               string                    `json:"text"`
+	Variables          []models.TemplateVariable `json:"variables"`
+	Variants           *models.TemplateVariants  `json:"variants"`
+	Language           string                    `json:"language"`
+	Translations       models.Translations       `json:"translations"`
+	MaxSegments        int                       `json:"max_segments"`
+	SegmentPolicy      models.SegmentPolicy      `json:"segment_policy"`
+	WhatsAppName       string                    `json:"whatsapp_name"`
+	WhatsAppLanguage   string                    `json:"whatsapp_language"`
+	WhatsAppParameters []string                  `json:"whatsapp_parameters"`
+	Author             string                    `json:"author"`
 }
 
 type templateCreate struct {
-	Subject string `json:"subject"`
-	Text    string `json:"text"`
+	Subject            string                    `json:"subject"`
+	Text               string                    `json:"text"`
+	Variables          []models.TemplateVariable `json:"variables"`
+	Variants           *models.TemplateVariants  `json:"variants"`
+	Language           string                    `json:"language"`
+	Translations       models.Translations       `json:"translations"`
+	MaxSegments        int                       `json:"max_segments"`
+	SegmentPolicy      models.SegmentPolicy      `json:"segment_policy"`
+	WhatsAppName       string                    `json:"whatsapp_name"`
+	WhatsAppLanguage   string                    `json:"whatsapp_language"`
+	WhatsAppParameters []string                  `json:"whatsapp_parameters"`
+	Author             string                    `json:"author"`
+}
+
+type templateRollback struct {
+	Version int    `json:"version"`
+	Author  string `json:"author"`
+}
+
+type templatePreview struct {
+	City     string `json:"city"`
+	Strength string `json:"strength"`
+	Receiver struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		City      string `json:"city"`
+	} `json:"receiver"`
+	Params map[string]string `json:"params"`
 }
 
 func NewTemplate(templateService TemplateService, log *slog.Logger) *Template {
@@ -55,18 +97,99 @@ func (t Template) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	newTemplate := &models.TemplateCreate{
-		Subject: temp.Subject,
-		Text:    temp.Text,
+		Subject:            temp.Subject,
+		Text:               temp.Text,
+		Variables:          temp.Variables,
+		Variants:           temp.Variants,
+		Language:           temp.Language,
+		Translations:       temp.Translations,
+		MaxSegments:        temp.MaxSegments,
+		SegmentPolicy:      temp.SegmentPolicy,
+		WhatsAppName:       temp.WhatsAppName,
+		WhatsAppLanguage:   temp.WhatsAppLanguage,
+		WhatsAppParameters: temp.WhatsAppParameters,
+		Author:             temp.Author,
 	}
 
 	ctx := context.Background()
-	err = t.templateService.Create(ctx, newTemplate)
+	created, err := t.templateService.Create(ctx, newTemplate)
 	if assertError(err, w) {
 		t.log.Error("creating template", err)
 		return
 	}
 
+	templateBytes, err := json.Marshal(created)
+	if err != nil {
+		t.log.Error("cannot marshalling template")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	w.WriteHeader(http.StatusCreated)
+	w.Write(templateBytes)
+}
+
+func (t Template) GetByID(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	id := chi.URLParam(r, "id")
+
+	template, err := t.templateService.GetByID(ctx, id)
+	if assertError(err, w) {
+		t.log.Error("getting template", err)
+		return
+	}
+
+	templateBytes, err := json.Marshal(template)
+	if err != nil {
+		t.log.Error("cannot marshalling template")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(templateBytes)
+}
+
+// List returns a page of the templates filtered by the subject query parameter,
+// the page is set by the limit and offset query parameters.
+func (t Template) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.TemplateFilter{
+		Subject: query.Get("subject"),
+	}
+
+	var err error
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			t.log.Error("cannot parsing limit", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if filter.Offset, err = strconv.Atoi(value); err != nil {
+			t.log.Error("cannot parsing offset", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := context.Background()
+	templates, err := t.templateService.List(ctx, filter)
+	if assertError(err, w) {
+		t.log.Error("listing templates", err)
+		return
+	}
+
+	templatesBytes, err := json.Marshal(templates)
+	if err != nil {
+		t.log.Error("cannot marshalling templates")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(templatesBytes)
 }
 
 func (t Template) Update(w http.ResponseWriter, r *http.Request) {
@@ -86,9 +209,19 @@ func (t Template) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	u := &models.TemplateUpdate{
-		ID:      id,
-		Subject: template.Subject,
-		Text:    template.Text,
+		ID:                 id,
+		Subject:            template.Subject,
+		Text:               template.Text,
+		Variables:          template.Variables,
+		Variants:           template.Variants,
+		Language:           template.Language,
+		Translations:       template.Translations,
+		MaxSegments:        template.MaxSegments,
+		SegmentPolicy:      template.SegmentPolicy,
+		WhatsAppName:       template.WhatsAppName,
+		WhatsAppLanguage:   template.WhatsAppLanguage,
+		WhatsAppParameters: template.WhatsAppParameters,
+		Author:             template.Author,
 	}
 
 	ctx := context.Background()
@@ -112,3 +245,113 @@ func (t Template) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+// Versions returns the history of the template, the newest version first.
+func (t Template) Versions(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	id := chi.URLParam(r, "id")
+
+	versions, err := t.templateService.Versions(ctx, id)
+	if assertError(err, w) {
+		t.log.Error("finding template versions", err)
+		return
+	}
+
+	versionsBytes, err := json.Marshal(versions)
+	if err != nil {
+		t.log.Error("cannot marshalling template versions")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(versionsBytes)
+}
+
+// Rollback makes an older version of the template current.
+func (t Template) Rollback(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.log.Error("cannot reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	id := chi.URLParam(r, "id")
+
+	var body templateRollback
+	if err = json.Unmarshal(b, &body); err != nil {
+		t.log.Error("cannot unmarshalling body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rollback := &models.TemplateRollback{
+		ID:      id,
+		Version: body.Version,
+		Author:  body.Author,
+	}
+
+	ctx := context.Background()
+	template, err := t.templateService.Rollback(ctx, rollback)
+	if assertError(err, w) {
+		t.log.Error("rolling back template", err)
+		return
+	}
+
+	templateBytes, err := json.Marshal(template)
+	if err != nil {
+		t.log.Error("cannot marshalling template")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(templateBytes)
+}
+
+// Preview renders the template with sample variables for every channel.
+func (t Template) Preview(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.log.Error("cannot reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	id := chi.URLParam(r, "id")
+
+	var sample templatePreview
+	if err = json.Unmarshal(b, &sample); err != nil {
+		t.log.Error("cannot unmarshalling body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	preview := &models.TemplatePreview{
+		ID:       id,
+		City:     sample.City,
+		Strength: sample.Strength,
+		Receiver: models.Receiver{
+			FirstName: sample.Receiver.FirstName,
+			LastName:  sample.Receiver.LastName,
+			City:      sample.Receiver.City,
+		},
+		Params: sample.Params,
+	}
+
+	ctx := context.Background()
+	result, err := t.templateService.Preview(ctx, preview)
+	if assertError(err, w) {
+		t.log.Error("previewing template", err)
+		return
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		t.log.Error("cannot marshalling template preview")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(resultBytes)
+}
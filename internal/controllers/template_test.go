@@ -42,7 +42,7 @@ func TestTemplate_Create(t *testing.T) {
 
 		service.EXPECT().
 			Create(ctx, newTemplate).
-			Return(nil)
+			Return(&models.Template{Subject: body.Subject, Text: body.Text}, nil)
 
 		tmpl := NewTemplate(service, log)
 
@@ -63,6 +63,10 @@ func TestTemplate_Create(t *testing.T) {
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var created models.Template
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, body.Subject, created.Subject)
 	})
 
 	t.Run("when body is invalid then error", func(t *testing.T) {
@@ -102,7 +106,7 @@ func TestTemplate_Create(t *testing.T) {
 
 		service.EXPECT().
 			Create(ctx, newTemplate).
-			Return(errorx.ErrValidation)
+			Return(nil, errorx.ErrValidation)
 
 		tmpl := NewTemplate(service, log)
 
@@ -139,7 +143,7 @@ func TestTemplate_Create(t *testing.T) {
 
 		service.EXPECT().
 			Create(ctx, newTemplate).
-			Return(errorx.ErrInternal)
+			Return(nil, errorx.ErrInternal)
 
 		tmpl := NewTemplate(service, log)
 
@@ -308,6 +312,194 @@ func TestTemplate_Update(t *testing.T) {
 	})
 }
 
+func TestTemplate_GetByID(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	r := chi.NewRouter()
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	service := mock_controllers.NewMockTemplateService(controller)
+
+	ctx := context.Background()
+	tmpl := NewTemplate(service, log)
+	r.Get("/templates/{id}", tmpl.GetByID)
+
+	t.Run("when template exists then template", func(t *testing.T) {
+		id := "7d603549-b079-4016-b81e-9e4386c1de21"
+
+		service.EXPECT().
+			GetByID(ctx, id).
+			Return(&models.Template{Subject: "Flood", Text: "{{.City}}"}, nil)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/templates/%s", id), nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var template models.Template
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &template))
+		assert.Equal(t, "Flood", template.Subject)
+	})
+
+	t.Run("when get not found then error", func(t *testing.T) {
+		id := "7d603549-b079-4016-b81e-9e4386c1de21"
+
+		service.EXPECT().
+			GetByID(ctx, id).
+			Return(nil, errorx.ErrNotFound)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/templates/%s", id), nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestTemplate_List(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	r := chi.NewRouter()
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	service := mock_controllers.NewMockTemplateService(controller)
+
+	ctx := context.Background()
+	tmpl := NewTemplate(service, log)
+	r.Get("/templates", tmpl.List)
+
+	t.Run("when query is valid then page of templates", func(t *testing.T) {
+		filter := models.TemplateFilter{Subject: "flood", Limit: 10, Offset: 20}
+
+		service.EXPECT().
+			List(ctx, filter).
+			Return(&models.TemplateList{Templates: []models.Template{{Subject: "Flood"}}, Total: 21}, nil)
+
+		req, err := http.NewRequest(http.MethodGet, "/templates?subject=flood&limit=10&offset=20", nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var list models.TemplateList
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		assert.Equal(t, 21, list.Total)
+		assert.Len(t, list.Templates, 1)
+	})
+
+	t.Run("when limit is not a number then error", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/templates?limit=ten", nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("when service return validation error then error", func(t *testing.T) {
+		service.EXPECT().
+			List(ctx, models.TemplateFilter{Limit: 1000}).
+			Return(nil, errorx.ErrValidation)
+
+		req, err := http.NewRequest(http.MethodGet, "/templates?limit=1000", nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestTemplate_Versions(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	r := chi.NewRouter()
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	service := mock_controllers.NewMockTemplateService(controller)
+
+	ctx := context.Background()
+	tmpl := NewTemplate(service, log)
+	r.Get("/templates/{id}/versions", tmpl.Versions)
+
+	t.Run("when template exists then versions", func(t *testing.T) {
+		id := "7d603549-b079-4016-b81e-9e4386c1de21"
+
+		service.EXPECT().
+			Versions(ctx, id).
+			Return([]models.TemplateVersion{{Version: 2, Current: true}, {Version: 1}}, nil)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/templates/%s/versions", id), nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var versions []models.TemplateVersion
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &versions))
+		assert.Len(t, versions, 2)
+		assert.True(t, versions[0].Current)
+	})
+}
+
+func TestTemplate_Rollback(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	r := chi.NewRouter()
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	service := mock_controllers.NewMockTemplateService(controller)
+
+	ctx := context.Background()
+	tmpl := NewTemplate(service, log)
+	r.Post("/templates/{id}/rollback", tmpl.Rollback)
+
+	id := "7d603549-b079-4016-b81e-9e4386c1de21"
+
+	t.Run("when version exists then template", func(t *testing.T) {
+		service.EXPECT().
+			Rollback(ctx, &models.TemplateRollback{ID: id, Version: 1, Author: "operator"}).
+			Return(&models.Template{Subject: "Flood", Version: 3}, nil)
+
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/templates/%s/rollback", id), bytes.NewBufferString(`{"version":1,"author":"operator"}`))
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var template models.Template
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &template))
+		assert.Equal(t, 3, template.Version)
+	})
+
+	t.Run("when version is not found then error", func(t *testing.T) {
+		service.EXPECT().
+			Rollback(ctx, &models.TemplateRollback{ID: id, Version: 9}).
+			Return(nil, errorx.ErrNotFound)
+
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/templates/%s/rollback", id), bytes.NewBufferString(`{"version":9}`))
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
 func TestTemplate_Delete(t *testing.T) {
 	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	r := chi.NewRouter()
@@ -390,3 +582,63 @@ func TestTemplate_Delete(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 	})
 }
+
+func TestTemplate_Preview(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	r := chi.NewRouter()
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockTemplateService(controller)
+	tmpl := NewTemplate(service, log)
+	r.Post("/templates/{id}/preview", tmpl.Preview)
+
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	t.Run("when sample is valid then rendered channels", func(t *testing.T) {
+		preview := &models.TemplatePreview{
+			ID:       "abc",
+			City:     "Kazan",
+			Strength: "strong",
+			Receiver: models.Receiver{FirstName: "Ivan"},
+			Params:   map[string]string{"Level": "3"},
+		}
+		result := &models.TemplatePreviewResult{
+			Channels: []models.ChannelPreview{{Type: models.ContactTypeSMS, Text: "Kazan"}},
+			Errors:   []models.TemplateVariableError{},
+		}
+
+		service.EXPECT().
+			Preview(ctx, preview).
+			Return(result, nil)
+
+		body := `{"city":"Kazan","strength":"strong","receiver":{"first_name":"Ivan"},"params":{"Level":"3"}}`
+		resp, err := http.Post(ts.URL+"/templates/abc/preview", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var got models.TemplatePreviewResult
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, *result, got)
+	})
+
+	t.Run("when template is not found then error", func(t *testing.T) {
+		service.EXPECT().
+			Preview(ctx, gomock.Any()).
+			Return(nil, errorx.ErrNotFound)
+
+		resp, err := http.Post(ts.URL+"/templates/abc/preview", "application/json", bytes.NewBufferString(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"projects/emergency-messages/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ContactVerificationService interface {
+	Verify(ctx context.Context, id string, key models.ContactKey) (*models.ContactVerification, error)
+	Confirm(ctx context.Context, id string, confirm models.ContactConfirm) (*models.Receiver, error)
+}
+
+type ContactVerification struct {
+	verificationService ContactVerificationService
+	log                 *slog.Logger
+}
+
+func NewContactVerification(verificationService ContactVerificationService, log *slog.Logger) *ContactVerification {
+	return &ContactVerification{
+		verificationService: verificationService,
+		log:                 log,
+	}
+}
+
+// Verify sends a one-time code to the contact of the receiver with the type and the value of the body.
+// It responds with the time the code expires.
+func (c *ContactVerification) Verify(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		c.log.Error("cannot reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var key models.ContactKey
+	if err = json.Unmarshal(b, &key); err != nil {
+		c.log.Error("cannot unmarshalling body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	verification, err := c.verificationService.Verify(ctx, chi.URLParam(r, "id"), key)
+	if assertError(err, w) {
+		c.log.Error("verifying receiver contact", err)
+		return
+	}
+
+	verificationBytes, err := json.Marshal(verification)
+	if err != nil {
+		c.log.Error("cannot marshalling contact verification")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(verificationBytes)
+}
+
+// Confirm checks the code of the body sent to the contact of the receiver.
+// It responds with the receiver whose contact is verified.
+func (c *ContactVerification) Confirm(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		c.log.Error("cannot reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var confirm models.ContactConfirm
+	if err = json.Unmarshal(b, &confirm); err != nil {
+		c.log.Error("cannot unmarshalling body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	receiver, err := c.verificationService.Confirm(ctx, chi.URLParam(r, "id"), confirm)
+	if assertError(err, w) {
+		c.log.Error("confirming receiver contact", err)
+		return
+	}
+
+	receiverBytes, err := json.Marshal(receiver)
+	if err != nil {
+		c.log.Error("cannot marshalling receiver")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(receiverBytes)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	mock_controllers "projects/emergency-messages/internal/controllers/mocks"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newContactVerificationServer(service ContactVerificationService) *httptest.Server {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	c := NewContactVerification(service, log)
+
+	r := chi.NewRouter()
+	r.Post("/receivers/{id}/contacts/verify", c.Verify)
+	r.Post("/receivers/{id}/contacts/confirm", c.Confirm)
+	return httptest.NewServer(r)
+}
+
+func TestContactVerification(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	service := mock_controllers.NewMockContactVerificationService(controller)
+	ts := newContactVerificationServer(service)
+	defer ts.Close()
+
+	id := "7d603549-b079-4016-b81e-9e4386c1de21"
+	key := models.ContactKey{Type: models.ContactTypeSMS, Value: "+79001234567"}
+
+	t.Run("when code is sent then accepted with expiration", func(t *testing.T) {
+		expiresAt := time.Date(2024, 7, 8, 9, 55, 20, 0, time.UTC)
+		service.EXPECT().
+			Verify(ctx, id, key).
+			Return(&models.ContactVerification{ContactKey: key, ExpiresAt: expiresAt, MaxAttempts: 5}, nil)
+
+		body := `{"type":"sms","value":"+79001234567"}`
+		resp, err := http.Post(ts.URL+"/receivers/"+id+"/contacts/verify", "application/json", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		var verification models.ContactVerification
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&verification))
+		assert.Equal(t, expiresAt, verification.ExpiresAt)
+	})
+	t.Run("when code is confirmed then receiver", func(t *testing.T) {
+		verifiedAt := time.Date(2024, 7, 8, 9, 47, 0, 0, time.UTC)
+		service.EXPECT().
+			Confirm(ctx, id, models.ContactConfirm{ContactKey: key, Code: "123456"}).
+			Return(&models.Receiver{Contacts: []models.Contact{{Type: key.Type, Value: key.Value, IsActive: true, VerifiedAt: &verifiedAt}}}, nil)
+
+		body := `{"type":"sms","value":"+79001234567","code":"123456"}`
+		resp, err := http.Post(ts.URL+"/receivers/"+id+"/contacts/confirm", "application/json", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var receiver models.Receiver
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&receiver))
+		assert.Equal(t, verifiedAt, *receiver.Contacts[0].VerifiedAt)
+	})
+	t.Run("when code is wrong then bad request", func(t *testing.T) {
+		service.EXPECT().
+			Confirm(ctx, id, models.ContactConfirm{ContactKey: key, Code: "000000"}).
+			Return(nil, errorx.ErrValidation)
+
+		body := `{"type":"sms","value":"+79001234567","code":"000000"}`
+		resp, err := http.Post(ts.URL+"/receivers/"+id+"/contacts/confirm", "application/json", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS messages_broadcast_id_idx;
+
+ALTER TABLE public.messages
+    DROP CONSTRAINT IF EXISTS fk_broadcast_id,
+    DROP COLUMN IF EXISTS broadcast_id;
+
+DROP TABLE IF EXISTS public.broadcasts;
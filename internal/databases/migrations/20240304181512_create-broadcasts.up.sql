@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS public.broadcasts
+(
+    id          UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    template_id UUID         NOT NULL,
+    subject     VARCHAR(100) NOT NULL,
+    text        text         NOT NULL,
+    city        VARCHAR(100) NOT NULL,
+    strength    VARCHAR(50)  NOT NULL,
+    created_at  timestamp,
+
+    CONSTRAINT fk_template_id FOREIGN KEY (template_id) REFERENCES templates (id)
+);
+
+ALTER TABLE public.messages
+    ADD COLUMN IF NOT EXISTS broadcast_id UUID,
+    ADD CONSTRAINT fk_broadcast_id FOREIGN KEY (broadcast_id) REFERENCES broadcasts (id);
+
+CREATE INDEX IF NOT EXISTS messages_broadcast_id_idx ON public.messages (broadcast_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS messages_status_next_attempt_at_idx;
+
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS last_error;
@@ -0,0 +1,6 @@
+ALTER TABLE public.messages
+    ADD COLUMN IF NOT EXISTS attempts        integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_attempt_at timestamp,
+    ADD COLUMN IF NOT EXISTS last_error      text;
+
+CREATE INDEX IF NOT EXISTS messages_status_next_attempt_at_idx ON public.messages (status, next_attempt_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS messages_status_lease_expires_at_idx;
+
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS lease_owner,
+    DROP COLUMN IF EXISTS lease_expires_at;
@@ -0,0 +1,5 @@
+ALTER TABLE public.messages
+    ADD COLUMN IF NOT EXISTS lease_owner      text,
+    ADD COLUMN IF NOT EXISTS lease_expires_at timestamp;
+
+CREATE INDEX IF NOT EXISTS messages_status_lease_expires_at_idx ON public.messages (status, lease_expires_at);
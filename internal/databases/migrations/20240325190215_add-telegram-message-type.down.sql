@@ -0,0 +1,10 @@
+DELETE FROM public.messages
+WHERE type = 'telegram';
+
+ALTER TYPE public.message_type RENAME TO message_type_old;
+CREATE TYPE public.message_type AS ENUM ('sms', 'email');
+
+ALTER TABLE public.messages
+    ALTER COLUMN type TYPE public.message_type USING type::TEXT::public.message_type;
+
+DROP TYPE public.message_type_old;
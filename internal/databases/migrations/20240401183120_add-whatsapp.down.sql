@@ -0,0 +1,6 @@
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS whatsapp_template;
+
+ALTER TABLE public.templates
+    DROP COLUMN IF EXISTS whatsapp_name,
+    DROP COLUMN IF EXISTS whatsapp_language;
@@ -0,0 +1,6 @@
+ALTER TABLE public.templates
+    ADD COLUMN IF NOT EXISTS whatsapp_name     VARCHAR(512),
+    ADD COLUMN IF NOT EXISTS whatsapp_language VARCHAR(15);
+
+ALTER TABLE public.messages
+    ADD COLUMN IF NOT EXISTS whatsapp_template jsonb;
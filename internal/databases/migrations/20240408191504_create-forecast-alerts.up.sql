@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS public.forecast_alerts
+(
+    id           UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    city         VARCHAR(100)     NOT NULL,
+    metric       VARCHAR(50)      NOT NULL,
+    strength     VARCHAR(50)      NOT NULL,
+    template_id  UUID             NOT NULL,
+    value        double precision NOT NULL,
+    valid_from   timestamp        NOT NULL,
+    valid_to     timestamp        NOT NULL,
+    broadcast_id UUID,
+    created_at   timestamp,
+
+    CONSTRAINT fk_template_id FOREIGN KEY (template_id) REFERENCES templates (id),
+    CONSTRAINT fk_broadcast_id FOREIGN KEY (broadcast_id) REFERENCES broadcasts (id)
+);
+
+CREATE INDEX IF NOT EXISTS forecast_alerts_city_metric_strength_idx ON public.forecast_alerts (city, metric, strength, valid_to);
@@ -0,0 +1,2 @@
+-- ADD VALUE cannot run in a transaction block, so it is the only statement of the migration
+ALTER TYPE public.message_status ADD VALUE IF NOT EXISTS 'cancelled';
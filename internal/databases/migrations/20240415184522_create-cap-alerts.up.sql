@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS public.cap_alerts
+(
+    id           UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    identifier   text         NOT NULL,
+    sender       text         NOT NULL,
+    sent         timestamptz  NOT NULL,
+    status       VARCHAR(20)  NOT NULL,
+    msg_type     VARCHAR(20)  NOT NULL,
+    city         VARCHAR(100),
+    broadcast_id UUID,
+    created_at   timestamp,
+
+    CONSTRAINT fk_broadcast_id FOREIGN KEY (broadcast_id) REFERENCES broadcasts (id)
+);
+
+CREATE INDEX IF NOT EXISTS cap_alerts_sender_identifier_idx ON public.cap_alerts (sender, identifier);
@@ -0,0 +1,6 @@
+UPDATE public.templates
+SET text = replace(replace(replace(text, '%', '%%'), '{{.City}}', '%s'), '{{.Strength}}', '%s')
+WHERE text LIKE '%{{.City}}%' OR text LIKE '%{{.Strength}}%';
+
+ALTER TABLE public.templates
+    DROP COLUMN IF EXISTS variables;
@@ -0,0 +1,7 @@
+ALTER TABLE public.templates
+    ADD COLUMN IF NOT EXISTS variables jsonb;
+
+-- positional placeholders were formatted with the city and the strength
+UPDATE public.templates
+SET text = replace(regexp_replace(regexp_replace(text, '(^|[^%])%s', '\1{{.City}}'), '(^|[^%])%s', '\1{{.Strength}}'), '%%', '%')
+WHERE text LIKE '%\%s%';
@@ -0,0 +1,14 @@
+ALTER TABLE public.messages
+    DROP CONSTRAINT IF EXISTS fk_template_version,
+    DROP COLUMN IF EXISTS template_version,
+    DROP COLUMN IF EXISTS template_id;
+
+ALTER TABLE public.broadcasts
+    DROP CONSTRAINT IF EXISTS fk_template_version,
+    DROP COLUMN IF EXISTS template_version;
+
+DROP TABLE IF EXISTS public.template_versions;
+
+ALTER TABLE public.templates
+    DROP COLUMN IF EXISTS updated_by,
+    DROP COLUMN IF EXISTS version;
@@ -0,0 +1,36 @@
+ALTER TABLE public.templates
+    ADD COLUMN IF NOT EXISTS version    INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS updated_by VARCHAR(100);
+
+CREATE TABLE IF NOT EXISTS public.template_versions
+(
+    id                UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    template_id       UUID         NOT NULL,
+    version           INTEGER      NOT NULL,
+    subject           VARCHAR(100) NOT NULL,
+    text              text         NOT NULL,
+    variables         jsonb,
+    whatsapp_name     VARCHAR(512),
+    whatsapp_language VARCHAR(15),
+    author            VARCHAR(100),
+    restored_from     INTEGER,
+    created_at        timestamp,
+
+    CONSTRAINT fk_template_id FOREIGN KEY (template_id) REFERENCES templates (id),
+    CONSTRAINT template_versions_template_id_version_key UNIQUE (template_id, version)
+);
+
+-- the current wording of every template is its first version
+INSERT INTO public.template_versions (template_id, version, subject, text, variables, whatsapp_name, whatsapp_language, created_at)
+SELECT id, version, subject, text, variables, whatsapp_name, whatsapp_language, COALESCE(updated_at, created_at)
+FROM public.templates
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE public.broadcasts
+    ADD COLUMN IF NOT EXISTS template_version INTEGER,
+    ADD CONSTRAINT fk_template_version FOREIGN KEY (template_id, template_version) REFERENCES template_versions (template_id, version);
+
+ALTER TABLE public.messages
+    ADD COLUMN IF NOT EXISTS template_id      UUID,
+    ADD COLUMN IF NOT EXISTS template_version INTEGER,
+    ADD CONSTRAINT fk_template_version FOREIGN KEY (template_id, template_version) REFERENCES template_versions (template_id, version);
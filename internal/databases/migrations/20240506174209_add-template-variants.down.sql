@@ -0,0 +1,9 @@
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS format,
+    DROP COLUMN IF EXISTS html;
+
+ALTER TABLE public.template_versions
+    DROP COLUMN IF EXISTS variants;
+
+ALTER TABLE public.templates
+    DROP COLUMN IF EXISTS variants;
@@ -0,0 +1,9 @@
+ALTER TABLE public.templates
+    ADD COLUMN IF NOT EXISTS variants jsonb;
+
+ALTER TABLE public.template_versions
+    ADD COLUMN IF NOT EXISTS variants jsonb;
+
+ALTER TABLE public.messages
+    ADD COLUMN IF NOT EXISTS html   text,
+    ADD COLUMN IF NOT EXISTS format VARCHAR(20);
@@ -0,0 +1,14 @@
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS language_fallback,
+    DROP COLUMN IF EXISTS language;
+
+ALTER TABLE public.template_versions
+    DROP COLUMN IF EXISTS translations,
+    DROP COLUMN IF EXISTS language;
+
+ALTER TABLE public.templates
+    DROP COLUMN IF EXISTS translations,
+    DROP COLUMN IF EXISTS language;
+
+ALTER TABLE public.receivers
+    DROP COLUMN IF EXISTS language;
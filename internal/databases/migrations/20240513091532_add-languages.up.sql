@@ -0,0 +1,14 @@
+ALTER TABLE public.receivers
+    ADD COLUMN IF NOT EXISTS language VARCHAR(35);
+
+ALTER TABLE public.templates
+    ADD COLUMN IF NOT EXISTS language     VARCHAR(35),
+    ADD COLUMN IF NOT EXISTS translations jsonb;
+
+ALTER TABLE public.template_versions
+    ADD COLUMN IF NOT EXISTS language     VARCHAR(35),
+    ADD COLUMN IF NOT EXISTS translations jsonb;
+
+ALTER TABLE public.messages
+    ADD COLUMN IF NOT EXISTS language          VARCHAR(35),
+    ADD COLUMN IF NOT EXISTS language_fallback boolean NOT NULL DEFAULT false;
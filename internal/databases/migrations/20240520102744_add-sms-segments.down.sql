@@ -0,0 +1,11 @@
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS sms_encoding,
+    DROP COLUMN IF EXISTS sms_segments;
+
+ALTER TABLE public.template_versions
+    DROP COLUMN IF EXISTS segment_policy,
+    DROP COLUMN IF EXISTS max_segments;
+
+ALTER TABLE public.templates
+    DROP COLUMN IF EXISTS segment_policy,
+    DROP COLUMN IF EXISTS max_segments;
@@ -0,0 +1,11 @@
+ALTER TABLE public.templates
+    ADD COLUMN IF NOT EXISTS max_segments   INTEGER,
+    ADD COLUMN IF NOT EXISTS segment_policy VARCHAR(20);
+
+ALTER TABLE public.template_versions
+    ADD COLUMN IF NOT EXISTS max_segments   INTEGER,
+    ADD COLUMN IF NOT EXISTS segment_policy VARCHAR(20);
+
+ALTER TABLE public.messages
+    ADD COLUMN IF NOT EXISTS sms_segments INTEGER,
+    ADD COLUMN IF NOT EXISTS sms_encoding VARCHAR(10);
@@ -0,0 +1,3 @@
+ALTER TABLE public.receivers
+    ADD COLUMN IF NOT EXISTS created_at timestamp,
+    ADD COLUMN IF NOT EXISTS updated_at timestamp;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS receivers_contacts_idx;
+DROP INDEX IF EXISTS receivers_tags_idx;
+DROP INDEX IF EXISTS receivers_city_id_idx;
+
+ALTER TABLE public.receivers
+    DROP COLUMN IF EXISTS tags;
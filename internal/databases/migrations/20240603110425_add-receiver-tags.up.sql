@@ -0,0 +1,6 @@
+ALTER TABLE public.receivers
+    ADD COLUMN IF NOT EXISTS tags text[];
+
+CREATE INDEX IF NOT EXISTS receivers_city_id_idx ON public.receivers (city, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS receivers_tags_idx ON public.receivers USING gin (tags);
+CREATE INDEX IF NOT EXISTS receivers_contacts_idx ON public.receivers USING gin (contacts jsonb_path_ops);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS receivers_natural_key_idx;
+
+ALTER TABLE public.receivers
+    DROP COLUMN IF EXISTS natural_key;
@@ -0,0 +1,9 @@
+ALTER TABLE public.receivers
+    ADD COLUMN IF NOT EXISTS natural_key text;
+
+UPDATE public.receivers
+SET natural_key = lower(trim(first_name)) || '|' || lower(trim(last_name)) || '|' || lower(trim(city)) || '|' ||
+                  coalesce(jsonb_path_query_first(contacts, '$[*] ? (@.type == "sms").value') #>> '{}', '')
+WHERE natural_key IS NULL;
+
+CREATE INDEX IF NOT EXISTS receivers_natural_key_idx ON public.receivers (natural_key) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.import_errors;
+DROP TABLE IF EXISTS public.import_chunks;
+DROP TABLE IF EXISTS public.imports;
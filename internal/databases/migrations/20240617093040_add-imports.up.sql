@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS public.imports
+(
+    id               UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    status           VARCHAR(20) NOT NULL,
+    file_name        text,
+    dry_run          boolean     NOT NULL DEFAULT false,
+    total_rows       integer,
+    processed_rows   integer     NOT NULL DEFAULT 0,
+    created_rows     integer     NOT NULL DEFAULT 0,
+    updated_rows     integer     NOT NULL DEFAULT 0,
+    skipped_rows     integer     NOT NULL DEFAULT 0,
+    error            text,
+    lease_owner      text,
+    lease_expires_at timestamp,
+    created_at       timestamp,
+    started_at       timestamp,
+    finished_at      timestamp
+);
+
+CREATE INDEX IF NOT EXISTS imports_status_created_at_idx ON public.imports (status, created_at);
+
+CREATE TABLE IF NOT EXISTS public.import_chunks
+(
+    import_id UUID    NOT NULL,
+    seq       integer NOT NULL,
+    data      bytea   NOT NULL,
+
+    PRIMARY KEY (import_id, seq),
+    CONSTRAINT fk_import_id FOREIGN KEY (import_id) REFERENCES imports (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS public.import_errors
+(
+    import_id UUID    NOT NULL,
+    line      integer NOT NULL,
+    reason    text    NOT NULL,
+    record    text[],
+
+    CONSTRAINT fk_import_id FOREIGN KEY (import_id) REFERENCES imports (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS import_errors_import_id_line_idx ON public.import_errors (import_id, line);
@@ -0,0 +1,3 @@
+ALTER TABLE public.imports
+    DROP COLUMN IF EXISTS columns,
+    DROP COLUMN IF EXISTS format;
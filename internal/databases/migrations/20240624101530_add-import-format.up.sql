@@ -0,0 +1,3 @@
+ALTER TABLE public.imports
+    ADD COLUMN IF NOT EXISTS format  VARCHAR(20) NOT NULL DEFAULT 'csv',
+    ADD COLUMN IF NOT EXISTS columns jsonb;
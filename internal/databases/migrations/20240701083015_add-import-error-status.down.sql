@@ -0,0 +1,2 @@
+ALTER TABLE public.import_errors
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,2 @@
+ALTER TABLE public.import_errors
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'skipped';
@@ -0,0 +1,2 @@
+ALTER TABLE public.broadcasts
+    DROP COLUMN IF EXISTS critical;
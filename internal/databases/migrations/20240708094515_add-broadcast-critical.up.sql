@@ -0,0 +1,2 @@
+ALTER TABLE public.broadcasts
+    ADD COLUMN IF NOT EXISTS critical boolean NOT NULL DEFAULT false;
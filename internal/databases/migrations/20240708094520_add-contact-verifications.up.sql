@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS public.contact_verifications
+(
+    id           UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    receiver_id  UUID        NOT NULL,
+    type         VARCHAR(20) NOT NULL,
+    value        text        NOT NULL,
+    code_hash    text        NOT NULL,
+    attempts     integer     NOT NULL DEFAULT 0,
+    max_attempts integer     NOT NULL,
+    expires_at   timestamp   NOT NULL,
+    confirmed_at timestamp,
+    created_at   timestamp,
+
+    CONSTRAINT fk_receiver_id FOREIGN KEY (receiver_id) REFERENCES receivers (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS contact_verifications_contact_idx ON public.contact_verifications (receiver_id, type, value, created_at);
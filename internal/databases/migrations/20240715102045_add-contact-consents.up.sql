@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS public.contact_consents
+(
+    id          UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+    receiver_id UUID,
+    type        VARCHAR(20) NOT NULL,
+    value       text        NOT NULL,
+    action      VARCHAR(20) NOT NULL,
+    keyword     text,
+    message_sid text,
+    created_at  timestamp   NOT NULL,
+
+    CONSTRAINT fk_receiver_id FOREIGN KEY (receiver_id) REFERENCES receivers (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS contact_consents_receiver_id_created_at_idx ON public.contact_consents (receiver_id, created_at);
+CREATE INDEX IF NOT EXISTS contact_consents_contact_idx ON public.contact_consents (type, value, created_at);
@@ -0,0 +1,11 @@
+UPDATE public.messages
+SET status = 'delivered'
+WHERE status = 'sent';
+
+ALTER TYPE public.message_status RENAME TO message_status_old;
+CREATE TYPE public.message_status AS ENUM ('created', 'sending', 'delivered', 'failed', 'cancelled', 'accepted');
+
+ALTER TABLE public.messages
+    ALTER COLUMN status TYPE public.message_status USING status::TEXT::public.message_status;
+
+DROP TYPE public.message_status_old;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS messages_type_provider_message_id_idx;
+
+ALTER TABLE public.messages
+    DROP COLUMN IF EXISTS provider_message_id,
+    DROP COLUMN IF EXISTS provider_status;
@@ -0,0 +1,5 @@
+ALTER TABLE public.messages
+    ADD COLUMN IF NOT EXISTS provider_message_id text,
+    ADD COLUMN IF NOT EXISTS provider_status     text;
+
+CREATE INDEX IF NOT EXISTS messages_type_provider_message_id_idx ON public.messages (type, provider_message_id);
@@ -0,0 +1,5 @@
+ALTER TABLE public.template_versions
+    DROP COLUMN IF EXISTS whatsapp_parameters;
+
+ALTER TABLE public.templates
+    DROP COLUMN IF EXISTS whatsapp_parameters;
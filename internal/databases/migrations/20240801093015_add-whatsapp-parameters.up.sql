@@ -0,0 +1,14 @@
+ALTER TABLE public.templates
+    ADD COLUMN IF NOT EXISTS whatsapp_parameters jsonb;
+
+ALTER TABLE public.template_versions
+    ADD COLUMN IF NOT EXISTS whatsapp_parameters jsonb;
+
+-- the approved WhatsApp templates took the city and the strength before the parameters were configurable
+UPDATE public.templates
+SET whatsapp_parameters = '["City", "Strength"]'
+WHERE whatsapp_name IS NOT NULL;
+
+UPDATE public.template_versions
+SET whatsapp_parameters = '["City", "Strength"]'
+WHERE whatsapp_name IS NOT NULL;
@@ -0,0 +1,24 @@
+ALTER TABLE public.cap_alerts
+    DROP CONSTRAINT IF EXISTS cap_alerts_sender_identifier_key;
+
+ALTER TABLE public.cap_alerts
+    ADD COLUMN IF NOT EXISTS city         VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS broadcast_id UUID,
+    ADD CONSTRAINT fk_broadcast_id FOREIGN KEY (broadcast_id) REFERENCES broadcasts (id);
+
+INSERT INTO public.cap_alerts (identifier, sender, sent, status, msg_type, city, broadcast_id, created_at)
+SELECT ca.identifier, ca.sender, ca.sent, ca.status, ca.msg_type, b.city, b.id, ca.created_at
+FROM public.cap_alert_broadcasts cab
+JOIN public.cap_alerts ca ON ca.id = cab.cap_alert_id
+JOIN public.broadcasts b ON b.id = cab.broadcast_id;
+
+DELETE FROM public.cap_alerts ca
+WHERE ca.broadcast_id IS NULL
+  AND EXISTS (SELECT 1 FROM public.cap_alert_broadcasts cab WHERE cab.cap_alert_id = ca.id);
+
+DROP TABLE IF EXISTS public.cap_alert_broadcasts;
+
+CREATE INDEX IF NOT EXISTS cap_alerts_sender_identifier_idx ON public.cap_alerts (sender, identifier);
+
+ALTER TABLE public.broadcasts
+    DROP COLUMN IF EXISTS cancelled_at;
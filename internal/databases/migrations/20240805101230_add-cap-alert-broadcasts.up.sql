@@ -0,0 +1,37 @@
+ALTER TABLE public.broadcasts
+    ADD COLUMN IF NOT EXISTS cancelled_at timestamp;
+
+CREATE TABLE IF NOT EXISTS public.cap_alert_broadcasts
+(
+    cap_alert_id UUID NOT NULL,
+    broadcast_id UUID NOT NULL,
+
+    PRIMARY KEY (cap_alert_id, broadcast_id),
+    CONSTRAINT fk_cap_alert_id FOREIGN KEY (cap_alert_id) REFERENCES cap_alerts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_broadcast_id FOREIGN KEY (broadcast_id) REFERENCES broadcasts (id)
+);
+
+-- an alert sent to several cities was stored once per broadcast, the first row of every alert is kept
+INSERT INTO public.cap_alert_broadcasts (cap_alert_id, broadcast_id)
+SELECT kept.id, ca.broadcast_id
+FROM public.cap_alerts ca
+JOIN (SELECT DISTINCT ON (sender, identifier) id, sender, identifier
+      FROM public.cap_alerts
+      ORDER BY sender, identifier, created_at, id) kept
+    ON kept.sender = ca.sender AND kept.identifier = ca.identifier
+WHERE ca.broadcast_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+DELETE FROM public.cap_alerts
+WHERE id NOT IN (SELECT DISTINCT ON (sender, identifier) id
+                 FROM public.cap_alerts
+                 ORDER BY sender, identifier, created_at, id);
+
+ALTER TABLE public.cap_alerts
+    DROP COLUMN IF EXISTS city,
+    DROP COLUMN IF EXISTS broadcast_id;
+
+DROP INDEX IF EXISTS public.cap_alerts_sender_identifier_idx;
+
+ALTER TABLE public.cap_alerts
+    ADD CONSTRAINT cap_alerts_sender_identifier_key UNIQUE (sender, identifier);
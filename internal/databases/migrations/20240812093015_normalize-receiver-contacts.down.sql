@@ -0,0 +1 @@
+-- the normalized contacts are valid in the previous version, the values written before are not kept
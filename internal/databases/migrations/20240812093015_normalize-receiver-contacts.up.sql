@@ -0,0 +1,33 @@
+-- the contacts stored before phones and emails were normalized are written as the service writes them now,
+-- so a receiver imported again matches its natural key: phones in E.164, national numbers read in RU
+-- (the default PHONE_DEFAULT_COUNTRY) and lower case emails. A value which cannot be normalized is kept.
+UPDATE public.receivers r
+SET contacts = normalized.contacts
+FROM (SELECT r.id,
+             jsonb_agg(
+                 CASE
+                     WHEN c.contact ->> 'type' IN ('sms', 'whatsapp') AND p.phone ~ '^\+[1-9][0-9]{6,14}$'
+                         THEN jsonb_set(c.contact, '{value}', to_jsonb(p.phone))
+                     WHEN c.contact ->> 'type' = 'email' AND c.contact ->> 'value' LIKE '%@%'
+                         THEN jsonb_set(c.contact, '{value}', to_jsonb(lower(trim(c.contact ->> 'value'))))
+                     ELSE c.contact
+                 END ORDER BY c.position) AS contacts
+      FROM public.receivers r
+      CROSS JOIN LATERAL jsonb_array_elements(r.contacts) WITH ORDINALITY AS c(contact, position)
+      CROSS JOIN LATERAL (SELECT regexp_replace(trim(c.contact ->> 'value'), '[[:space:]./()' || chr(160) || '-]', '', 'g') AS digits) d
+      CROSS JOIN LATERAL (SELECT CASE
+                                     WHEN d.digits LIKE '+%' THEN d.digits
+                                     WHEN d.digits LIKE '00%' THEN '+' || substr(d.digits, 3)
+                                     WHEN d.digits ~ '^8[0-9]{10}$' THEN '+7' || substr(d.digits, 2)
+                                     WHEN d.digits ~ '^7[0-9]{10}$' THEN '+' || d.digits
+                                     ELSE '+7' || d.digits
+                                     END AS phone) p
+      WHERE jsonb_typeof(r.contacts) = 'array'
+      GROUP BY r.id) normalized
+WHERE r.id = normalized.id
+  AND r.contacts IS DISTINCT FROM normalized.contacts;
+
+UPDATE public.receivers
+SET natural_key = lower(trim(first_name)) || '|' || lower(trim(last_name)) || '|' || lower(trim(city)) || '|' ||
+                  coalesce(jsonb_path_query_first(contacts, '$[*] ? (@.type == "sms").value') #>> '{}', '')
+WHERE deleted_at IS NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS contact_verifications_sent_idx;
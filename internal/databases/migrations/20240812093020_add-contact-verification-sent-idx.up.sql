@@ -0,0 +1,2 @@
+-- the codes sent to a contact are counted across the receivers sharing it
+CREATE INDEX IF NOT EXISTS contact_verifications_sent_idx ON public.contact_verifications (type, value, created_at);
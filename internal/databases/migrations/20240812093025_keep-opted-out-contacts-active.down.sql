@@ -0,0 +1,9 @@
+UPDATE public.receivers r
+SET contacts = (SELECT jsonb_agg(
+                           CASE
+                               WHEN c.contact ? 'opted_out_at' THEN jsonb_set(c.contact, '{is_active}', 'false')
+                               ELSE c.contact
+                               END ORDER BY c.position)
+                FROM jsonb_array_elements(r.contacts) WITH ORDINALITY AS c(contact, position))
+WHERE jsonb_typeof(r.contacts) = 'array'
+  AND jsonb_path_exists(r.contacts, '$[*] ? (exists(@.opted_out_at))');
@@ -0,0 +1,12 @@
+-- an opt out deactivated the contact before, it only sets opted_out_at now and an opt in keeps the active flag,
+-- so the valid contacts deactivated by an opt out are active again and still get critical alerts only
+UPDATE public.receivers r
+SET contacts = (SELECT jsonb_agg(
+                           CASE
+                               WHEN c.contact ? 'opted_out_at' AND coalesce(c.contact ->> 'inactive_reason', '') = ''
+                                   THEN jsonb_set(c.contact, '{is_active}', 'true')
+                               ELSE c.contact
+                               END ORDER BY c.position)
+                FROM jsonb_array_elements(r.contacts) WITH ORDINALITY AS c(contact, position))
+WHERE jsonb_typeof(r.contacts) = 'array'
+  AND jsonb_path_exists(r.contacts, '$[*] ? (exists(@.opted_out_at) && @.is_active == false)');
@@ -0,0 +1,3 @@
+-- email and mobile_phone are moved to contacts, only the constraint on first_name is restored
+ALTER TABLE public.receivers
+    ADD CONSTRAINT users_first_name_key UNIQUE (first_name);
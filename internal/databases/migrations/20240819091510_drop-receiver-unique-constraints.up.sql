@@ -0,0 +1,5 @@
+-- receivers are told apart by their natural key, people may share a name
+ALTER TABLE public.receivers
+    DROP CONSTRAINT IF EXISTS users_first_name_key,
+    DROP CONSTRAINT IF EXISTS users_email_key,
+    DROP CONSTRAINT IF EXISTS users_mobile_phone_key;
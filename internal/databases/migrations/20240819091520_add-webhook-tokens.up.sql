@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS public.webhook_tokens
+(
+    token      text PRIMARY KEY,
+    expires_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_tokens_expires_at_idx ON public.webhook_tokens (expires_at);
@@ -0,0 +1,116 @@
+// Package gismeteo
+// Implements the forecast provider reading the Gismeteo API
+package gismeteo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBaseURL = "https://api.gismeteo.net"
+	forecastDays   = 3
+	requestTimeout = 30 * time.Second
+	dateLayout     = "2006-01-02 15:04:05"
+)
+
+// ClientGismeteo reads forecasts of locations from the Gismeteo API.
+type ClientGismeteo struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	log     *slog.Logger
+}
+
+type forecastResponse struct {
+	Meta struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	} `json:"meta"`
+	Response []forecastItem `json:"response"`
+}
+
+type forecastItem struct {
+	Date struct {
+		UTC string `json:"UTC"`
+	} `json:"date"`
+	Temperature struct {
+		Air struct {
+			C float64 `json:"C"`
+		} `json:"air"`
+	} `json:"temperature"`
+	Wind struct {
+		Speed struct {
+			MS float64 `json:"m_s"`
+		} `json:"speed"`
+	} `json:"wind"`
+	Precipitation struct {
+		Amount float64 `json:"amount"`
+	} `json:"precipitation"`
+}
+
+func NewGismeteoClient(log *slog.Logger) *ClientGismeteo {
+	baseURL := os.Getenv("GISMETEO_API_URL")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &ClientGismeteo{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   os.Getenv("GISMETEO_TOKEN"),
+		client:  &http.Client{Timeout: requestTimeout},
+		log:     log,
+	}
+}
+
+// Fetch returns the forecasts of the location for the next days.
+func (c *ClientGismeteo) Fetch(ctx context.Context, locationID string) ([]models.Forecast, error) {
+	url := fmt.Sprintf("%s/v2/weather/forecast/%s/?days=%d", c.baseURL, locationID, forecastDays)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Gismeteo-Token", c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching gismeteo forecast of location %s: %w", locationID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching gismeteo forecast of location %s: unexpected status %d", locationID, resp.StatusCode)
+	}
+
+	var result forecastResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding gismeteo forecast of location %s: %w", locationID, err)
+	}
+
+	return parse(result)
+}
+
+// parse transforms the items of the response to forecasts.
+func parse(result forecastResponse) ([]models.Forecast, error) {
+	forecasts := make([]models.Forecast, 0, len(result.Response))
+	for _, item := range result.Response {
+		t, err := time.Parse(dateLayout, item.Date.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("parsing gismeteo forecast date %q: %w", item.Date.UTC, err)
+		}
+		forecasts = append(forecasts, models.Forecast{
+			Time:          t,
+			Temperature:   item.Temperature.Air.C,
+			WindSpeed:     item.Wind.Speed.MS,
+			Precipitation: item.Precipitation.Amount,
+		})
+	}
+	return forecasts, nil
+}
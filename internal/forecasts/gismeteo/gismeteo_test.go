@@ -0,0 +1,82 @@
+package gismeteo
+
+import (
+	"compress/gzip"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientGismeteo_Fetch(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	t.Run("when api returns forecast then it is parsed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v2/weather/forecast/4364/", r.URL.Path)
+			assert.Equal(t, "token", r.Header.Get("X-Gismeteo-Token"))
+			w.Write([]byte(`{
+				"meta": {"message": "", "code": "200"},
+				"response": [{
+					"date": {"UTC": "2024-03-01 12:00:00"},
+					"temperature": {"air": {"C": -3.5}},
+					"wind": {"speed": {"m_s": 17}},
+					"precipitation": {"amount": 2.4}
+				}]
+			}`))
+		}))
+		defer server.Close()
+
+		t.Setenv("GISMETEO_API_URL", server.URL)
+		t.Setenv("GISMETEO_TOKEN", "token")
+		client := NewGismeteoClient(log)
+
+		forecasts, err := client.Fetch(ctx, "4364")
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Forecast{{
+			Time:          time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			Temperature:   -3.5,
+			WindSpeed:     17,
+			Precipitation: 2.4,
+		}}, forecasts)
+	})
+
+	t.Run("when api compresses forecast then it is decompressed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Contains(t, r.Header.Get("Accept-Encoding"), "gzip")
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte(`{"response": [{"date": {"UTC": "2024-03-01 12:00:00"}, "wind": {"speed": {"m_s": 17}}}]}`))
+			gz.Close()
+		}))
+		defer server.Close()
+
+		t.Setenv("GISMETEO_API_URL", server.URL)
+		client := NewGismeteoClient(log)
+
+		forecasts, err := client.Fetch(ctx, "4364")
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Forecast{{Time: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), WindSpeed: 17}}, forecasts)
+	})
+
+	t.Run("when api returns error status then error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		t.Setenv("GISMETEO_API_URL", server.URL)
+		client := NewGismeteoClient(log)
+
+		forecasts, err := client.Fetch(ctx, "4364")
+		assert.Error(t, err)
+		assert.Nil(t, forecasts)
+	})
+}
@@ -0,0 +1,111 @@
+// Package forecasts
+// Polls a forecast provider and sends alerts when forecasts exceed thresholds of cities
+package forecasts
+
+import (
+	"context"
+	"log/slog"
+	"projects/emergency-messages/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type Ingester struct {
+	provider       Provider
+	alertStore     AlertStore
+	messageService MessageSender
+	rules          map[string][]Rule
+	log            *slog.Logger
+}
+
+type Provider interface {
+	Fetch(ctx context.Context, locationID string) ([]models.Forecast, error)
+}
+
+type AlertStore interface {
+	CreateUnlessOverlaps(ctx context.Context, a *models.ForecastAlertEntity) (bool, error)
+	SetBroadcast(ctx context.Context, id, broadcastID uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type MessageSender interface {
+	Send(ctx context.Context, message models.MessageRequest) (*models.Broadcast, error)
+}
+
+func NewIngester(provider Provider, alertStore AlertStore, messageService MessageSender, rules []Rule, log *slog.Logger) *Ingester {
+	byLocation := make(map[string][]Rule)
+	for _, rule := range rules {
+		byLocation[rule.LocationID] = append(byLocation[rule.LocationID], rule)
+	}
+
+	return &Ingester{
+		provider:       provider,
+		alertStore:     alertStore,
+		messageService: messageService,
+		rules:          byLocation,
+		log:            log,
+	}
+}
+
+// Poll fetches the forecasts of every location with rules and sends alerts about new warnings.
+func (i *Ingester) Poll() {
+	ctx := context.Background()
+	for locationID, rules := range i.rules {
+		forecasts, err := i.provider.Fetch(ctx, locationID)
+		if err != nil {
+			i.log.With(slog.String("location", locationID)).
+				Error("fetching forecast", err)
+			continue
+		}
+
+		for _, warning := range Evaluate(rules, forecasts) {
+			i.alert(ctx, warning)
+		}
+	}
+}
+
+// alert sends the warning unless the same weather event has already been alerted.
+func (i *Ingester) alert(ctx context.Context, warning models.ForecastWarning) {
+	log := i.log.With(
+		slog.String("city", warning.City),
+		slog.Any("metric", warning.Metric),
+		slog.String("strength", warning.Strength),
+	)
+
+	alert := transformWarningToStoreModel(warning)
+	created, err := i.alertStore.CreateUnlessOverlaps(ctx, alert)
+	if err != nil {
+		log.Error("creating forecast alert", err)
+		return
+	}
+	if !created {
+		log.Debug("forecast warning is already alerted")
+		return
+	}
+
+	broadcast, err := i.messageService.Send(ctx, warning.MessageRequest())
+	if err != nil {
+		log.Error("sending forecast alert", err)
+		// forget the alert to send it on the next poll
+		if err = i.alertStore.Delete(ctx, alert.ID); err != nil {
+			log.Error("deleting forecast alert", err)
+		}
+		return
+	}
+
+	if err = i.alertStore.SetBroadcast(ctx, alert.ID, broadcast.ID); err != nil {
+		log.Error("linking forecast alert to broadcast", err)
+	}
+}
+
+func transformWarningToStoreModel(w models.ForecastWarning) *models.ForecastAlertEntity {
+	return &models.ForecastAlertEntity{
+		City:       w.City,
+		Metric:     w.Metric,
+		Strength:   w.Strength,
+		TemplateID: w.TemplateID,
+		Value:      w.Value,
+		ValidFrom:  w.ValidFrom,
+		ValidTo:    w.ValidTo,
+	}
+}
@@ -0,0 +1,97 @@
+package forecasts
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProvider struct {
+	forecasts []models.Forecast
+}
+
+func (p *fakeProvider) Fetch(_ context.Context, _ string) ([]models.Forecast, error) {
+	return p.forecasts, nil
+}
+
+type fakeAlertStore struct {
+	alerted    bool
+	created    []*models.ForecastAlertEntity
+	deleted    []uuid.UUID
+	broadcasts map[uuid.UUID]uuid.UUID
+}
+
+func (s *fakeAlertStore) CreateUnlessOverlaps(_ context.Context, a *models.ForecastAlertEntity) (bool, error) {
+	if s.alerted {
+		return false, nil
+	}
+	a.ID = uuid.New()
+	s.created = append(s.created, a)
+	return true, nil
+}
+
+func (s *fakeAlertStore) SetBroadcast(_ context.Context, id, broadcastID uuid.UUID) error {
+	s.broadcasts[id] = broadcastID
+	return nil
+}
+
+func (s *fakeAlertStore) Delete(_ context.Context, id uuid.UUID) error {
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+type fakeMessageSender struct {
+	requests []models.MessageRequest
+	err      error
+}
+
+func (s *fakeMessageSender) Send(_ context.Context, message models.MessageRequest) (*models.Broadcast, error) {
+	s.requests = append(s.requests, message)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.Broadcast{ID: uuid.New()}, nil
+}
+
+func TestIngester_Poll(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	rule := Rule{City: "Kazan", LocationID: "4364", TemplateID: uuid.New(), Metric: models.ForecastMetricWindSpeed, Threshold: 15, Strength: "strong"}
+	provider := &fakeProvider{forecasts: []models.Forecast{{Time: time.Now(), WindSpeed: 20}}}
+
+	t.Run("when warning is new then alert is sent", func(t *testing.T) {
+		store := &fakeAlertStore{broadcasts: make(map[uuid.UUID]uuid.UUID)}
+		sender := &fakeMessageSender{}
+
+		NewIngester(provider, store, sender, []Rule{rule}, log).Poll()
+
+		assert.Equal(t, []models.MessageRequest{{TemplateID: rule.TemplateID, City: "Kazan", Strength: "strong"}}, sender.requests)
+		assert.Len(t, store.created, 1)
+		assert.Contains(t, store.broadcasts, store.created[0].ID)
+	})
+
+	t.Run("when warning is already alerted then alert is not sent", func(t *testing.T) {
+		store := &fakeAlertStore{alerted: true, broadcasts: make(map[uuid.UUID]uuid.UUID)}
+		sender := &fakeMessageSender{}
+
+		NewIngester(provider, store, sender, []Rule{rule}, log).Poll()
+
+		assert.Empty(t, sender.requests)
+	})
+
+	t.Run("when sending fails then alert is forgotten", func(t *testing.T) {
+		store := &fakeAlertStore{broadcasts: make(map[uuid.UUID]uuid.UUID)}
+		sender := &fakeMessageSender{err: errors.New("")}
+
+		NewIngester(provider, store, sender, []Rule{rule}, log).Poll()
+
+		assert.Equal(t, []uuid.UUID{store.created[0].ID}, store.deleted)
+		assert.Empty(t, store.broadcasts)
+	})
+}
@@ -0,0 +1,161 @@
+package forecasts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Rule is a threshold of a forecast metric for a city.
+// When the forecast of the location exceeds the threshold, an alert is sent
+// to the receivers of the city with the template and the strength of the rule.
+// A critical rule alerts the contacts which are not verified too.
+type Rule struct {
+	City       string                `json:"city"`
+	LocationID string                `json:"location_id"`
+	TemplateID uuid.UUID             `json:"template_id"`
+	Metric     models.ForecastMetric `json:"metric"`
+	Threshold  float64               `json:"threshold"`
+	Strength   string                `json:"strength"`
+	Critical   bool                  `json:"critical,omitempty"`
+}
+
+// Validate validates the Rule.
+func (r Rule) Validate() error {
+	if r.City == "" {
+		return errors.New("city is empty")
+	}
+	if r.LocationID == "" {
+		return errors.New("location id is empty")
+	}
+	if r.TemplateID == uuid.Nil {
+		return errors.New("template id is empty")
+	}
+	if !r.Metric.Valid() {
+		return fmt.Errorf("unknown metric: %s", r.Metric)
+	}
+	if r.Strength == "" {
+		return errors.New("strength is empty")
+	}
+	return nil
+}
+
+// Exceeded reports whether the forecast crosses the threshold of the rule.
+func (r Rule) Exceeded(f models.Forecast) bool {
+	if r.Metric == models.ForecastMetricFrost {
+		return f.Value(r.Metric) <= r.Threshold
+	}
+	return f.Value(r.Metric) >= r.Threshold
+}
+
+// severerThan reports whether the threshold of the rule is more dangerous than the other one.
+func (r Rule) severerThan(other Rule) bool {
+	if r.Metric == models.ForecastMetricFrost {
+		return r.Threshold < other.Threshold
+	}
+	return r.Threshold > other.Threshold
+}
+
+// LoadRules reads the rules from a JSON file.
+func LoadRules(path string) ([]Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading forecast rules: %w", err)
+	}
+
+	var rules []Rule
+	if err = json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("unmarshalling forecast rules: %w", err)
+	}
+
+	for i, rule := range rules {
+		if err = rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid forecast rule %d: %w", i, err)
+		}
+	}
+	return rules, nil
+}
+
+// Evaluate returns warnings for the rules exceeded by the forecasts of one location.
+// A warning covers the first continuous period when the threshold is exceeded.
+// When several rules of the same city, metric and template are exceeded, only the severest one is warned.
+func Evaluate(rules []Rule, forecasts []models.Forecast) []models.ForecastWarning {
+	sorted := make([]models.Forecast, len(forecasts))
+	copy(sorted, forecasts)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	type key struct {
+		city       string
+		metric     models.ForecastMetric
+		templateID uuid.UUID
+	}
+	severest := make(map[key]Rule)
+	warnings := make(map[key]models.ForecastWarning)
+	order := make([]key, 0)
+
+	for _, rule := range rules {
+		warning, ok := evaluateRule(rule, sorted)
+		if !ok {
+			continue
+		}
+
+		k := key{city: rule.City, metric: rule.Metric, templateID: rule.TemplateID}
+		current, exists := severest[k]
+		if !exists {
+			order = append(order, k)
+		}
+		if !exists || rule.severerThan(current) {
+			severest[k] = rule
+			warnings[k] = warning
+		}
+	}
+
+	result := make([]models.ForecastWarning, 0, len(order))
+	for _, k := range order {
+		result = append(result, warnings[k])
+	}
+	return result
+}
+
+// evaluateRule finds the first continuous period of sorted forecasts exceeding the rule.
+func evaluateRule(rule Rule, forecasts []models.Forecast) (models.ForecastWarning, bool) {
+	var (
+		warning models.ForecastWarning
+		found   bool
+	)
+	for _, f := range forecasts {
+		if !rule.Exceeded(f) {
+			if found {
+				break
+			}
+			continue
+		}
+
+		value := f.Value(rule.Metric)
+		if !found {
+			found = true
+			warning = models.ForecastWarning{
+				City:       rule.City,
+				TemplateID: rule.TemplateID,
+				Metric:     rule.Metric,
+				Strength:   rule.Strength,
+				Critical:   rule.Critical,
+				Value:      value,
+				ValidFrom:  f.Time,
+			}
+		}
+		warning.ValidTo = f.Time
+		if rule.Metric == models.ForecastMetricFrost && value < warning.Value ||
+			rule.Metric != models.ForecastMetricFrost && value > warning.Value {
+			warning.Value = value
+		}
+	}
+	return warning, found
+}
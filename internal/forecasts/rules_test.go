@@ -0,0 +1,73 @@
+package forecasts
+
+import (
+	"projects/emergency-messages/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	templateID := uuid.New()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	forecasts := []models.Forecast{
+		{Time: start.Add(9 * time.Hour), WindSpeed: 12, Temperature: -20},
+		{Time: start, WindSpeed: 5, Temperature: -5},
+		{Time: start.Add(3 * time.Hour), WindSpeed: 16, Temperature: -12},
+		{Time: start.Add(6 * time.Hour), WindSpeed: 27, Temperature: -18},
+		{Time: start.Add(12 * time.Hour), WindSpeed: 30, Temperature: -25},
+	}
+
+	strongWind := Rule{City: "Kazan", LocationID: "4364", TemplateID: templateID, Metric: models.ForecastMetricWindSpeed, Threshold: 15, Strength: "strong"}
+	hurricane := Rule{City: "Kazan", LocationID: "4364", TemplateID: templateID, Metric: models.ForecastMetricWindSpeed, Threshold: 25, Strength: "hurricane"}
+	frost := Rule{City: "Kazan", LocationID: "4364", TemplateID: templateID, Metric: models.ForecastMetricFrost, Threshold: -15, Strength: "frost"}
+	heat := Rule{City: "Kazan", LocationID: "4364", TemplateID: templateID, Metric: models.ForecastMetricHeat, Threshold: 35, Strength: "heat"}
+
+	t.Run("when threshold is exceeded then warning covers first continuous period", func(t *testing.T) {
+		warnings := Evaluate([]Rule{strongWind}, forecasts)
+		assert.Equal(t, []models.ForecastWarning{{
+			City:       "Kazan",
+			TemplateID: templateID,
+			Metric:     models.ForecastMetricWindSpeed,
+			Strength:   "strong",
+			Value:      27,
+			ValidFrom:  start.Add(3 * time.Hour),
+			ValidTo:    start.Add(6 * time.Hour),
+		}}, warnings)
+	})
+
+	t.Run("when several thresholds are exceeded then severest is warned", func(t *testing.T) {
+		warnings := Evaluate([]Rule{strongWind, hurricane}, forecasts)
+		assert.Len(t, warnings, 1)
+		assert.Equal(t, "hurricane", warnings[0].Strength)
+		assert.Equal(t, start.Add(6*time.Hour), warnings[0].ValidFrom)
+	})
+
+	t.Run("when frost threshold is exceeded then lowest temperature is warned", func(t *testing.T) {
+		warnings := Evaluate([]Rule{frost}, forecasts)
+		assert.Len(t, warnings, 1)
+		assert.Equal(t, float64(-25), warnings[0].Value)
+		assert.Equal(t, start.Add(6*time.Hour), warnings[0].ValidFrom)
+		assert.Equal(t, start.Add(12*time.Hour), warnings[0].ValidTo)
+	})
+
+	t.Run("when threshold is not exceeded then no warnings", func(t *testing.T) {
+		warnings := Evaluate([]Rule{heat}, forecasts)
+		assert.Empty(t, warnings)
+	})
+}
+
+func TestRule_Validate(t *testing.T) {
+	valid := Rule{City: "Kazan", LocationID: "4364", TemplateID: uuid.New(), Metric: models.ForecastMetricWindSpeed, Threshold: 15, Strength: "strong"}
+	assert.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.Metric = "snow"
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.TemplateID = uuid.Nil
+	assert.Error(t, invalid.Validate())
+}
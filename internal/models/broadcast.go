@@ -0,0 +1,95 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// Broadcast is a type representing an alert sent to all receivers of a city.
+// Every message created for a receiver references the broadcast it belongs to.
+// CancelledAt is set when a later alert updates or cancels the broadcast, its messages are not sent anymore.
+type Broadcast struct {
+	ID              uuid.UUID  `json:"id"`
+	TemplateID      uuid.UUID  `json:"template_id"`
+	TemplateVersion int        `json:"template_version,omitempty"`
+	Subject         string     `json:"subject"`
+	Text            string     `json:"text"`
+	City            string     `json:"city"`
+	Strength        string     `json:"strength"`
+	Critical        bool       `json:"critical,omitempty"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// BroadcastSummary is a type representing a broadcast with delivery counters per channel.
+// FallbackReceivers is the number of receivers who got the broadcast in another language than theirs.
+type BroadcastSummary struct {
+	Broadcast
+	Channels          []BroadcastChannel `json:"channels"`
+	FallbackReceivers int                `json:"fallback_receivers"`
+}
+
+// BroadcastChannel is a type representing delivery counters of a broadcast for one contact type.
+// Sent is the number of messages accepted or sent by the provider and not reported as delivered yet,
+// Undelivered is the number of messages the provider could not deliver, bounced ones included.
+// Segments is the number of SMS segments which reached the provider, they are billed whether delivered or not.
+type BroadcastChannel struct {
+	Type        ContactType `json:"type"`
+	Created     int         `json:"created"`
+	Sent        int         `json:"sent"`
+	Delivered   int         `json:"delivered"`
+	Undelivered int         `json:"undelivered"`
+	Failed      int         `json:"failed"`
+	Cancelled   int         `json:"cancelled"`
+	Segments    int         `json:"segments,omitempty"`
+}
+
+// Add adds the number of messages with the status and their SMS segments to the channel counters,
+// the segments of the messages which did not reach the provider are not counted.
+func (c *BroadcastChannel) Add(status MessageStatus, count, segments int) {
+	switch status {
+	case Accepted, Sent:
+		c.Sent += count
+		c.Segments += segments
+	case Delivered:
+		c.Delivered += count
+		c.Segments += segments
+	case Undelivered, Bounced:
+		c.Undelivered += count
+		c.Segments += segments
+	case Failed:
+		c.Failed += count
+	case Cancelled:
+		c.Cancelled += count
+	default:
+		c.Created += count
+	}
+}
+
+// BroadcastEntity is a type representing a broadcast entity.
+// TemplateVersion is the version of the template the broadcast is rendered from,
+// Critical is set for a life-safety alert.
+// It is used to interact with the database.
+type BroadcastEntity struct {
+	bun.BaseModel   `bun:"table:broadcasts,alias:b"`
+	ID              uuid.UUID  `bun:"type:uuid,default:uuid_generate_v4()"`
+	TemplateID      uuid.UUID  `bun:"template_id,type:uuid,notnull"`
+	TemplateVersion int        `bun:"template_version,nullzero"`
+	Subject         string     `bun:"subject,notnull"`
+	Text            string     `bun:"text,notnull"`
+	City            string     `bun:"city,notnull"`
+	Strength        string     `bun:"strength,notnull"`
+	Critical        bool       `bun:"critical,notnull"`
+	CancelledAt     *time.Time `bun:"cancelled_at,nullzero"`
+	CreatedAt       *time.Time `bun:"created_at,nullzero"`
+}
+
+// BroadcastStatusCount is a type representing the number of messages
+// of a broadcast grouped by contact type and status.
+type BroadcastStatusCount struct {
+	Type     ContactType   `bun:"type"`
+	Status   MessageStatus `bun:"status"`
+	Count    int           `bun:"count"`
+	Segments int           `bun:"segments"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// CapAlert is a type representing the result of ingesting a CAP document.
+// Broadcasts are sent to the receivers of every city of the alert areas,
+// Cancelled is the number of not yet sent messages of the alerts referenced by an Update or a Cancel.
+type CapAlert struct {
+	Identifier string      `json:"identifier"`
+	Sender     string      `json:"sender"`
+	Status     string      `json:"status"`
+	MsgType    string      `json:"msg_type"`
+	Broadcasts []Broadcast `json:"broadcasts"`
+	Cancelled  int         `json:"cancelled"`
+}
+
+// CapAlertEntity is a type representing a received CAP alert, the sender and the identifier are unique.
+// It is used to interact with the database and to find the alerts referenced by later ones.
+type CapAlertEntity struct {
+	bun.BaseModel `bun:"table:cap_alerts,alias:ca"`
+	ID            uuid.UUID  `bun:"type:uuid,default:uuid_generate_v4()"`
+	Identifier    string     `bun:"identifier,notnull"`
+	Sender        string     `bun:"sender,notnull"`
+	Sent          time.Time  `bun:"sent,notnull"`
+	Status        string     `bun:"status,notnull"`
+	MsgType       string     `bun:"msg_type,notnull"`
+	CreatedAt     *time.Time `bun:"created_at,nullzero"`
+}
+
+// CapAlertBroadcastEntity is a type representing a broadcast sent for a CAP alert to a city of its areas.
+type CapAlertBroadcastEntity struct {
+	bun.BaseModel `bun:"table:cap_alert_broadcasts,alias:cab"`
+	CapAlertID    uuid.UUID `bun:"cap_alert_id,type:uuid,notnull"`
+	BroadcastID   uuid.UUID `bun:"broadcast_id,type:uuid,notnull"`
+}
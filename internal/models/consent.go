@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// ConsentAction is a type representing a change of the consent of a receiver to get messages.
+type ConsentAction string
+
+const (
+	// ConsentOptOut is a receiver asking to stop the messages
+	ConsentOptOut ConsentAction = "opt_out"
+	// ConsentOptIn is a receiver asking to get the messages again
+	ConsentOptIn ConsentAction = "opt_in"
+	// ConsentHelp is a receiver asking how to stop or get the messages, the consent is not changed
+	ConsentHelp ConsentAction = "help"
+)
+
+// ConsentKeyword is a type representing a keyword of an inbound SMS with its action and its language.
+type ConsentKeyword struct {
+	Keyword  string
+	Action   ConsentAction
+	Language string
+}
+
+// consentKeywords are the keywords by action and language, a keyword shared by languages belongs to the first one
+var consentKeywords = []struct {
+	language string
+	action   ConsentAction
+	keywords []string
+}{
+	{language: "en", action: ConsentOptOut, keywords: []string{"STOP", "STOPALL", "UNSUBSCRIBE", "CANCEL", "END", "QUIT", "OPTOUT"}},
+	{language: "en", action: ConsentOptIn, keywords: []string{"START", "YES", "UNSTOP", "SUBSCRIBE"}},
+	{language: "en", action: ConsentHelp, keywords: []string{"HELP", "INFO"}},
+	{language: "ru", action: ConsentOptOut, keywords: []string{"СТОП", "ОТПИСАТЬСЯ", "ОТПИСКА"}},
+	{language: "ru", action: ConsentOptIn, keywords: []string{"СТАРТ", "ПОДПИСАТЬСЯ", "ПОДПИСКА"}},
+	{language: "ru", action: ConsentHelp, keywords: []string{"ПОМОЩЬ", "СПРАВКА"}},
+	{language: "es", action: ConsentOptOut, keywords: []string{"BAJA", "PARAR", "ALTO"}},
+	{language: "es", action: ConsentOptIn, keywords: []string{"ALTA", "INICIAR"}},
+	{language: "es", action: ConsentHelp, keywords: []string{"AYUDA"}},
+	{language: "fr", action: ConsentOptOut, keywords: []string{"ARRET", "ARRÊT", "DESABONNER"}},
+	{language: "fr", action: ConsentOptIn, keywords: []string{"COMMENCER", "ABONNER"}},
+	{language: "fr", action: ConsentHelp, keywords: []string{"AIDE"}},
+	{language: "de", action: ConsentOptOut, keywords: []string{"STOPP", "ABMELDEN"}},
+	{language: "de", action: ConsentOptIn, keywords: []string{"ANMELDEN"}},
+	{language: "de", action: ConsentHelp, keywords: []string{"HILFE"}},
+}
+
+// ParseConsentKeyword returns the keyword of the body of an inbound SMS, e.g. "Stop" or "СТОП!".
+// The body must be the keyword alone, compared without case and the punctuation around it.
+// It returns false if the body is not a keyword.
+func ParseConsentKeyword(body string) (ConsentKeyword, bool) {
+	word := strings.ToUpper(strings.Trim(body, " \t\r\n.,!?;:\"'()"))
+	if word == "" || strings.ContainsAny(word, " \t\r\n") {
+		return ConsentKeyword{}, false
+	}
+	for _, k := range consentKeywords {
+		for _, keyword := range k.keywords {
+			if word == keyword {
+				return ConsentKeyword{Keyword: keyword, Action: k.action, Language: k.language}, true
+			}
+		}
+	}
+	return ConsentKeyword{}, false
+}
+
+// InboundSMS is a type representing an SMS sent by a receiver to the number of the service.
+// MessageSID is the ID of the SMS given by the provider.
+type InboundSMS struct {
+	MessageSID string
+	From       string
+	To         string
+	Body       string
+}
+
+// ContactConsent is a type representing a change of the consent of a receiver to get messages to a contact.
+type ContactConsent struct {
+	ContactKey
+	Action     ConsentAction `json:"action"`
+	Keyword    string        `json:"keyword,omitempty"`
+	MessageSID string        `json:"message_sid,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// ContactConsentEntity is a type representing a change of the consent to get messages to a contact.
+// ReceiverID is empty when no receiver has the contact, the change is logged anyway.
+// It is used to interact with the database.
+type ContactConsentEntity struct {
+	bun.BaseModel `bun:"table:contact_consents,alias:cc"`
+	ID            uuid.UUID     `bun:"type:uuid,default:uuid_generate_v4()"`
+	ReceiverID    uuid.UUID     `bun:"receiver_id,type:uuid,nullzero"`
+	Type          ContactType   `bun:"type,notnull"`
+	Value         string        `bun:"value,notnull"`
+	Action        ConsentAction `bun:"action,notnull"`
+	Keyword       string        `bun:"keyword,nullzero"`
+	MessageSID    string        `bun:"message_sid,nullzero"`
+	CreatedAt     *time.Time    `bun:"created_at,nullzero"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConsentKeyword(t *testing.T) {
+	tests := []struct {
+		body string
+		want ConsentKeyword
+		ok   bool
+	}{
+		{body: "STOP", want: ConsentKeyword{Keyword: "STOP", Action: ConsentOptOut, Language: "en"}, ok: true},
+		{body: " stop! ", want: ConsentKeyword{Keyword: "STOP", Action: ConsentOptOut, Language: "en"}, ok: true},
+		{body: "Стоп", want: ConsentKeyword{Keyword: "СТОП", Action: ConsentOptOut, Language: "ru"}, ok: true},
+		{body: "старт.", want: ConsentKeyword{Keyword: "СТАРТ", Action: ConsentOptIn, Language: "ru"}, ok: true},
+		{body: "Arrêt", want: ConsentKeyword{Keyword: "ARRÊT", Action: ConsentOptOut, Language: "fr"}, ok: true},
+		{body: "baja", want: ConsentKeyword{Keyword: "BAJA", Action: ConsentOptOut, Language: "es"}, ok: true},
+		{body: "Hilfe?", want: ConsentKeyword{Keyword: "HILFE", Action: ConsentHelp, Language: "de"}, ok: true},
+		{body: "start", want: ConsentKeyword{Keyword: "START", Action: ConsentOptIn, Language: "en"}, ok: true},
+		{body: "please stop", ok: false},
+		{body: "", ok: false},
+		{body: "Thanks", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			got, ok := ParseConsentKeyword(tt.body)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
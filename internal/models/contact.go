@@ -0,0 +1,159 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+const (
+	minPhoneDigits = 7
+	// maxPhoneDigits is the longest E.164 number without the plus
+	maxPhoneDigits = 15
+)
+
+// phoneCountry is the calling code of a country, the trunk prefix its national numbers are dialed with
+// and the number of digits of a national number without the trunk prefix, 0 if it varies.
+type phoneCountry struct {
+	code   string
+	trunk  string
+	digits int
+}
+
+// phoneCountries are the countries national phone numbers can be written for, by ISO 3166-1 alpha-2 codes.
+var phoneCountries = map[string]phoneCountry{
+	"AM": {code: "374", trunk: "0"},
+	"AZ": {code: "994", trunk: "0"},
+	"BY": {code: "375", trunk: "8"},
+	"CA": {code: "1", trunk: "1", digits: 10},
+	"CN": {code: "86", trunk: "0"},
+	"DE": {code: "49", trunk: "0"},
+	"ES": {code: "34"},
+	"FR": {code: "33", trunk: "0"},
+	"GB": {code: "44", trunk: "0"},
+	"GE": {code: "995", trunk: "0"},
+	"IN": {code: "91", trunk: "0"},
+	"IT": {code: "39"},
+	"KG": {code: "996", trunk: "0"},
+	"KZ": {code: "7", trunk: "8", digits: 10},
+	"PL": {code: "48"},
+	"RU": {code: "7", trunk: "8", digits: 10},
+	"TR": {code: "90", trunk: "0"},
+	"UA": {code: "380", trunk: "0"},
+	"US": {code: "1", trunk: "1", digits: 10},
+	"UZ": {code: "998"},
+}
+
+// ParsePhoneCountry returns the upper case code of the country national phone numbers are written for,
+// an empty code means national numbers are invalid.
+func ParsePhoneCountry(country string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return "", nil
+	}
+	if _, ok := phoneCountries[country]; !ok {
+		return "", fmt.Errorf("unknown phone country %q", country)
+	}
+	return country, nil
+}
+
+// NormalizePhone returns the phone number in the E.164 format, e.g. +79001234567 for 8 (900) 123-45-67 in RU.
+// Spaces, dashes, dots, slashes and parentheses are removed, 00 starts an international number,
+// a number without + or 00 is a national number of the default country whose trunk prefix is replaced by the calling code.
+// The number must have 7 to 15 digits, the length of the numbers of a country is not checked.
+func NormalizePhone(phone, defaultCountry string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '/', '(', ')', ' ':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		digits = digits[1:]
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	default:
+		country, ok := phoneCountries[defaultCountry]
+		if !ok {
+			return "", fmt.Errorf("invalid phone number %q: country code is missing", phone)
+		}
+		digits = country.national(digits)
+	}
+
+	if strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("invalid phone number %q: only digits are allowed", phone)
+	}
+	if len(digits) < minPhoneDigits || len(digits) > maxPhoneDigits {
+		return "", fmt.Errorf("invalid phone number %q: expected %d to %d digits", phone, minPhoneDigits, maxPhoneDigits)
+	}
+	if digits[0] == '0' {
+		return "", fmt.Errorf("invalid phone number %q: country code cannot start with 0", phone)
+	}
+	return "+" + digits, nil
+}
+
+// national returns the international digits of a national number.
+// When the length of the national numbers is known, a number of the trunk prefix and the national number
+// or of the calling code and the national number, e.g. 89001234567 or 79001234567 in RU, is recognized.
+func (c phoneCountry) national(digits string) string {
+	switch {
+	case c.digits == 0:
+		if c.trunk != "" {
+			digits = strings.TrimPrefix(digits, c.trunk)
+		}
+	case c.trunk != "" && strings.HasPrefix(digits, c.trunk) && len(digits) == len(c.trunk)+c.digits:
+		digits = digits[len(c.trunk):]
+	case strings.HasPrefix(digits, c.code) && len(digits) == len(c.code)+c.digits:
+		return digits
+	}
+	return c.code + digits
+}
+
+// NormalizeEmail returns the lower case email after checking it is an RFC 5322 address without a display name,
+// the domain must have a dot, e.g. example.com.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return "", fmt.Errorf("invalid email %q: %w", email, unwrapMailError(err))
+	}
+	if address.Name != "" || address.Address != email {
+		return "", fmt.Errorf("invalid email %q: expected an address without a name", email)
+	}
+	at := strings.LastIndex(email, "@")
+	domain := email[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", fmt.Errorf("invalid email %q: invalid domain %q", email, domain)
+	}
+	return strings.ToLower(email), nil
+}
+
+// unwrapMailError removes the prefix net/mail adds to its errors.
+func unwrapMailError(err error) error {
+	return errors.New(strings.TrimPrefix(err.Error(), "mail: "))
+}
+
+// Normalize validates the value of the contact by its type and replaces it by the normalized value,
+// phones and WhatsApp numbers are E.164 numbers, emails are lower case.
+// It returns the reason the value is invalid, the contact is not changed then.
+func (c *Contact) Normalize(defaultCountry string) error {
+	var value string
+	var err error
+	switch c.Type {
+	case ContactTypeSMS, ContactTypeWhatsApp:
+		value, err = NormalizePhone(c.Value, defaultCountry)
+	case ContactTypeEmail:
+		value, err = NormalizeEmail(c.Value)
+	default:
+		value = strings.TrimSpace(c.Value)
+	}
+	if err != nil {
+		return err
+	}
+	c.Value = value
+	return nil
+}
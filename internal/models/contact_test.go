@@ -0,0 +1,96 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone   string
+		country string
+		want    string
+		wantErr bool
+	}{
+		{phone: "+7 (900) 123-45-67", want: "+79001234567"},
+		{phone: "0049 30 1234567", want: "+49301234567"},
+		{phone: "8 900 123 45 67", country: "RU", want: "+79001234567"},
+		{phone: "79001234567", country: "RU", want: "+79001234567"},
+		{phone: "9001234567", country: "RU", want: "+79001234567"},
+		{phone: "(202) 555-0123", country: "US", want: "+12025550123"},
+		{phone: "1-202-555-0123", country: "US", want: "+12025550123"},
+		{phone: "030 1234567", country: "DE", want: "+49301234567"},
+		{phone: "9001234567", wantErr: true},
+		{phone: "+7 900 CALL-ME", wantErr: true},
+		{phone: "+12345", wantErr: true},
+		{phone: "+1234567890123456", wantErr: true},
+		{phone: "+0123456789", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			got, err := NormalizePhone(tt.phone, tt.country)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		want    string
+		wantErr bool
+	}{
+		{email: " Ivan.Petrov@Example.COM ", want: "ivan.petrov@example.com"},
+		{email: "ivan+alerts@mail.example.ru", want: "ivan+alerts@mail.example.ru"},
+		{email: "ivan@example", wantErr: true},
+		{email: "ivan@.example.com", wantErr: true},
+		{email: "ivan.example.com", wantErr: true},
+		{email: "Ivan <ivan@example.com>", wantErr: true},
+		{email: "ivan@@example.com", wantErr: true},
+		{email: "ivan petrov@example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got, err := NormalizeEmail(tt.email)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParsePhoneCountry(t *testing.T) {
+	country, err := ParsePhoneCountry(" ru ")
+	assert.NoError(t, err)
+	assert.Equal(t, "RU", country)
+
+	country, err = ParsePhoneCountry("")
+	assert.NoError(t, err)
+	assert.Empty(t, country)
+
+	_, err = ParsePhoneCountry("XX")
+	assert.Error(t, err)
+}
+
+func TestContact_Normalize(t *testing.T) {
+	telegram := Contact{Type: ContactTypeTelegram, Value: " 123456789 "}
+	assert.NoError(t, telegram.Normalize("RU"))
+	assert.Equal(t, "123456789", telegram.Value)
+
+	whatsapp := Contact{Type: ContactTypeWhatsApp, Value: "8 900 123 45 67"}
+	assert.NoError(t, whatsapp.Normalize("RU"))
+	assert.Equal(t, "+79001234567", whatsapp.Value)
+
+	email := Contact{Type: ContactTypeEmail, Value: "ivan@"}
+	assert.Error(t, email.Normalize("RU"))
+	assert.Equal(t, "ivan@", email.Value)
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// deliveryStages orders the statuses of a message at its provider, a delivery report never moves a message back
+var deliveryStages = map[MessageStatus]int{
+	Accepted:    1,
+	Sent:        2,
+	Delivered:   3,
+	Undelivered: 3,
+	Bounced:     3,
+}
+
+// DeliveryReport is a type representing the status of a message reported by its provider,
+// e.g. a Twilio status callback or a Mailgun event.
+// Type and ProviderMessageID find the message, the ID is the one returned by the provider at send time.
+// Status is empty when the report does not change the status, e.g. a complaint about a delivered email.
+// ProviderStatus is the status or the event name of the provider, Reason is the error of an undelivered message.
+type DeliveryReport struct {
+	Type              ContactType
+	ProviderMessageID string
+	Status            MessageStatus
+	ProviderStatus    string
+	Reason            string
+}
+
+// Apply changes the message by the report.
+// The status moves forward only, so reports coming out of order, e.g. sent after delivered, are ignored
+// and a final status is kept.
+// It returns false if the message is not changed.
+func (r DeliveryReport) Apply(m *MessageEntity) bool {
+	if r.Status == "" {
+		changed := false
+		if r.ProviderStatus != "" && r.ProviderStatus != m.ProviderStatus {
+			m.ProviderStatus = r.ProviderStatus
+			changed = true
+		}
+		// a temporary failure keeps the status with its error
+		if r.Reason != "" && r.Reason != m.LastError {
+			m.LastError = r.Reason
+			changed = true
+		}
+		return changed
+	}
+	if deliveryStages[r.Status] <= deliveryStages[m.Status] {
+		return false
+	}
+
+	m.Status = r.Status
+	m.ProviderStatus = r.ProviderStatus
+	if r.Reason != "" {
+		m.LastError = r.Reason
+	}
+	return true
+}
+
+// WebhookTokenEntity is a type representing the token of a processed webhook request,
+// a request with the same token is not processed again until the token expires.
+// It is used to interact with the database.
+type WebhookTokenEntity struct {
+	bun.BaseModel `bun:"table:webhook_tokens,alias:wt"`
+	Token         string    `bun:"token,pk"`
+	ExpiresAt     time.Time `bun:"expires_at,notnull"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryReport_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		message MessageEntity
+		report  DeliveryReport
+		want    MessageEntity
+		changed bool
+	}{
+		{
+			name:    "when accepted message is sent then sent",
+			message: MessageEntity{Status: Accepted},
+			report:  DeliveryReport{Status: Sent, ProviderStatus: "sent"},
+			want:    MessageEntity{Status: Sent, ProviderStatus: "sent"},
+			changed: true,
+		},
+		{
+			name:    "when sent message is undelivered then reason is stored",
+			message: MessageEntity{Status: Sent, ProviderStatus: "sent"},
+			report:  DeliveryReport{Status: Undelivered, ProviderStatus: "undelivered", Reason: "30003"},
+			want:    MessageEntity{Status: Undelivered, ProviderStatus: "undelivered", LastError: "30003"},
+			changed: true,
+		},
+		{
+			name:    "when accepted message is delivered then sent is skipped",
+			message: MessageEntity{Status: Accepted},
+			report:  DeliveryReport{Status: Delivered, ProviderStatus: "delivered"},
+			want:    MessageEntity{Status: Delivered, ProviderStatus: "delivered"},
+			changed: true,
+		},
+		{
+			name:    "when sent comes after delivered then ignored",
+			message: MessageEntity{Status: Delivered, ProviderStatus: "delivered"},
+			report:  DeliveryReport{Status: Sent, ProviderStatus: "sent"},
+			want:    MessageEntity{Status: Delivered, ProviderStatus: "delivered"},
+		},
+		{
+			name:    "when bounced message is delivered then final status is kept",
+			message: MessageEntity{Status: Bounced, ProviderStatus: "failed"},
+			report:  DeliveryReport{Status: Delivered, ProviderStatus: "delivered"},
+			want:    MessageEntity{Status: Bounced, ProviderStatus: "failed"},
+		},
+		{
+			name:    "when delivered email is complained then provider status only",
+			message: MessageEntity{Status: Delivered, ProviderStatus: "delivered"},
+			report:  DeliveryReport{ProviderStatus: "complained"},
+			want:    MessageEntity{Status: Delivered, ProviderStatus: "complained"},
+			changed: true,
+		},
+		{
+			name:    "when email fails temporarily then status is kept with reason",
+			message: MessageEntity{Status: Accepted, ProviderStatus: "accepted"},
+			report:  DeliveryReport{ProviderStatus: "temporary_failed", Reason: "mailbox is full"},
+			want:    MessageEntity{Status: Accepted, ProviderStatus: "temporary_failed", LastError: "mailbox is full"},
+			changed: true,
+		},
+		{
+			name:    "when report repeats provider status then not changed",
+			message: MessageEntity{Status: Delivered, ProviderStatus: "complained"},
+			report:  DeliveryReport{ProviderStatus: "complained"},
+			want:    MessageEntity{Status: Delivered, ProviderStatus: "complained"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := tt.message
+			assert.Equal(t, tt.changed, tt.report.Apply(&message))
+			assert.Equal(t, tt.want, message)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import "fmt"
+
+// ReceiverExport is a type representing the receivers exported in the layout of imported files.
+// City and ContactType filter the receivers as ReceiverFilter does, Mask hides the middle of the contact values.
+// Format is csv or jsonl, csv by default.
+type ReceiverExport struct {
+	City        string
+	ContactType ContactType
+	Format      ImportFormat
+	Mask        bool
+}
+
+// Validate validates the ReceiverExport and sets the default format.
+func (e *ReceiverExport) Validate() error {
+	if e.Format == "" {
+		e.Format = ImportFormatCSV
+	}
+	if e.Format != ImportFormatCSV && e.Format != ImportFormatJSONLines {
+		return fmt.Errorf("unknown export format %q, expected %s or %s", e.Format, ImportFormatCSV, ImportFormatJSONLines)
+	}
+	if e.ContactType != "" && !e.ContactType.IsValid() {
+		return fmt.Errorf("unknown contact type %q", e.ContactType)
+	}
+	return nil
+}
+
+// Filter returns the filter of the exported receivers.
+func (e ReceiverExport) Filter() ReceiverFilter {
+	return ReceiverFilter{
+		City:         e.City,
+		ContactType:  e.ContactType,
+		WithoutTotal: true,
+	}
+}
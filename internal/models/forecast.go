@@ -0,0 +1,91 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// Forecast is a type representing the weather forecast for a period of time.
+type Forecast struct {
+	Time time.Time
+	// Temperature is the air temperature in degrees Celsius
+	Temperature float64
+	// WindSpeed is the wind speed in meters per second
+	WindSpeed float64
+	// Precipitation is the amount of precipitation in millimeters
+	Precipitation float64
+}
+
+// ForecastMetric is a type representing a forecast value which is compared with thresholds.
+type ForecastMetric string
+
+const (
+	// ForecastMetricWindSpeed warns when the wind speed is at least the threshold
+	ForecastMetricWindSpeed ForecastMetric = "wind_speed"
+	// ForecastMetricPrecipitation warns when the precipitation is at least the threshold
+	ForecastMetricPrecipitation ForecastMetric = "precipitation"
+	// ForecastMetricHeat warns when the temperature is at least the threshold
+	ForecastMetricHeat ForecastMetric = "heat"
+	// ForecastMetricFrost warns when the temperature is at most the threshold
+	ForecastMetricFrost ForecastMetric = "frost"
+)
+
+// Valid reports whether the metric is known.
+func (m ForecastMetric) Valid() bool {
+	switch m {
+	case ForecastMetricWindSpeed, ForecastMetricPrecipitation, ForecastMetricHeat, ForecastMetricFrost:
+		return true
+	}
+	return false
+}
+
+// Value returns the value of the metric in the forecast.
+func (f Forecast) Value(metric ForecastMetric) float64 {
+	switch metric {
+	case ForecastMetricWindSpeed:
+		return f.WindSpeed
+	case ForecastMetricPrecipitation:
+		return f.Precipitation
+	default:
+		return f.Temperature
+	}
+}
+
+// ForecastWarning is a type representing a period when a forecast exceeds a threshold of a city.
+type ForecastWarning struct {
+	City       string
+	TemplateID uuid.UUID
+	Metric     ForecastMetric
+	Strength   string
+	Critical   bool
+	Value      float64
+	ValidFrom  time.Time
+	ValidTo    time.Time
+}
+
+// MessageRequest returns the request to send an alert about the warning.
+func (w ForecastWarning) MessageRequest() MessageRequest {
+	return MessageRequest{
+		TemplateID: w.TemplateID,
+		City:       w.City,
+		Strength:   w.Strength,
+		Critical:   w.Critical,
+	}
+}
+
+// ForecastAlertEntity is a type representing an alert sent about a forecast warning.
+// It is used to interact with the database and to not send the same warning twice.
+type ForecastAlertEntity struct {
+	bun.BaseModel `bun:"table:forecast_alerts,alias:fa"`
+	ID            uuid.UUID      `bun:"type:uuid,default:uuid_generate_v4()"`
+	City          string         `bun:"city,notnull"`
+	Metric        ForecastMetric `bun:"metric,notnull"`
+	Strength      string         `bun:"strength,notnull"`
+	TemplateID    uuid.UUID      `bun:"template_id,type:uuid,notnull"`
+	Value         float64        `bun:"value,notnull"`
+	ValidFrom     time.Time      `bun:"valid_from,notnull"`
+	ValidTo       time.Time      `bun:"valid_to,notnull"`
+	BroadcastID   uuid.UUID      `bun:"broadcast_id,type:uuid,nullzero"`
+	CreatedAt     *time.Time     `bun:"created_at,nullzero"`
+}
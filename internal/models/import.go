@@ -0,0 +1,262 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// ImportStatus is a type representing the result of an imported row.
+type ImportStatus string
+
+const (
+	// ImportCreated is a row which created a receiver
+	ImportCreated ImportStatus = "created"
+	// ImportUpdated is a row which updated the receiver with the same natural key
+	ImportUpdated ImportStatus = "updated"
+	// ImportSkipped is a row which is invalid, repeated or does not change the receiver
+	ImportSkipped ImportStatus = "skipped"
+)
+
+// ImportFormat is a type representing the format of an imported file.
+type ImportFormat string
+
+const (
+	// ImportFormatCSV is a CSV file separated by semicolons with a header row
+	ImportFormatCSV ImportFormat = "csv"
+	// ImportFormatJSONLines is a file of JSON objects, one object per line
+	ImportFormatJSONLines ImportFormat = "jsonl"
+	// ImportFormatXLSX is an Excel workbook, the first sheet is imported, its first row is the header
+	ImportFormatXLSX ImportFormat = "xlsx"
+	// ImportFormatVCard is a file of vCards
+	ImportFormatVCard ImportFormat = "vcard"
+)
+
+// ImportOptions is a type representing the options of an import.
+// A dry run validates the rows and reports what would be done without saving the receivers.
+// Format is detected by the content type or the extension of the file when it is empty.
+// Columns maps the columns of the receivers layout to the columns of the file,
+// the other columns are found by their names.
+type ImportOptions struct {
+	DryRun  bool
+	Format  ImportFormat
+	Columns map[string]string
+}
+
+// Validate checks the columns and replaces their names by the names of the receivers layout.
+func (o *ImportOptions) Validate() error {
+	if len(o.Columns) == 0 {
+		o.Columns = nil
+		return nil
+	}
+	columns := make(map[string]string, len(o.Columns))
+	for name, column := range o.Columns {
+		header := ""
+		for _, h := range ReceiverCSVHeader {
+			if strings.EqualFold(h, strings.TrimSpace(name)) {
+				header = h
+				break
+			}
+		}
+		if header == "" {
+			return fmt.Errorf("unknown column %q, expected one of %s", name, strings.Join(ReceiverCSVHeader, ", "))
+		}
+		if column = strings.TrimSpace(column); column == "" {
+			return fmt.Errorf("column of %s is empty", header)
+		}
+		columns[header] = column
+	}
+	o.Columns = columns
+	return nil
+}
+
+// ImportReasonNoChanges is the reason of a row which does not change the receiver
+const ImportReasonNoChanges = "no changes"
+
+// ImportRowError is the error of an imported receiver the database rejects, e.g. a too long name.
+// Reason is the message of the database.
+type ImportRowError struct {
+	Reason string
+}
+
+func (e *ImportRowError) Error() string {
+	return e.Reason
+}
+
+// ImportRow is a type representing the result of a row of an imported file, lines start at 1.
+// Warnings are the invalid contacts of an imported row, they are saved inactive.
+// Record is the row of the file when it is not imported or has warnings.
+type ImportRow struct {
+	Line       int          `json:"line"`
+	Status     ImportStatus `json:"status"`
+	ReceiverID *uuid.UUID   `json:"receiver_id,omitempty"`
+	Reason     string       `json:"reason,omitempty"`
+	Warnings   []string     `json:"warnings,omitempty"`
+	Record     []string     `json:"-"`
+}
+
+// IsError reports whether the row is skipped because it cannot be imported.
+func (r ImportRow) IsError() bool {
+	return r.Status == ImportSkipped && r.Reason != ImportReasonNoChanges
+}
+
+// Problem returns the reason the row is skipped or its warnings, false if the row has no problem.
+func (r ImportRow) Problem() (string, bool) {
+	if r.IsError() {
+		return r.Reason, true
+	}
+	if len(r.Warnings) > 0 {
+		return strings.Join(r.Warnings, "; "), true
+	}
+	return "", false
+}
+
+// ImportJobStatus is a type representing the state of an import job.
+type ImportJobStatus string
+
+const (
+	// ImportJobPending is a job waiting for a worker
+	ImportJobPending ImportJobStatus = "pending"
+	// ImportJobRunning is a job imported by a worker
+	ImportJobRunning ImportJobStatus = "running"
+	// ImportJobCompleted is a job whose every row is processed
+	ImportJobCompleted ImportJobStatus = "completed"
+	// ImportJobFailed is a job whose file cannot be read
+	ImportJobFailed ImportJobStatus = "failed"
+	// ImportJobCancelled is a job stopped by a user, the rows processed before are kept
+	ImportJobCancelled ImportJobStatus = "cancelled"
+)
+
+// IsFinished reports whether the job is not processed anymore.
+func (s ImportJobStatus) IsFinished() bool {
+	return s == ImportJobCompleted || s == ImportJobFailed || s == ImportJobCancelled
+}
+
+// ImportProgress is a type representing the numbers of processed rows of an import job.
+type ImportProgress struct {
+	Processed int `json:"processed_rows"`
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Skipped   int `json:"skipped"`
+}
+
+// Add counts the rows.
+func (p *ImportProgress) Add(rows []ImportRow) {
+	for _, row := range rows {
+		p.Processed++
+		switch row.Status {
+		case ImportCreated:
+			p.Created++
+		case ImportUpdated:
+			p.Updated++
+		default:
+			p.Skipped++
+		}
+	}
+}
+
+// ImportJob is a type representing a file of receivers imported in the background.
+// TotalRows is known when the job is started.
+type ImportJob struct {
+	ID         uuid.UUID         `json:"id"`
+	Status     ImportJobStatus   `json:"status"`
+	FileName   string            `json:"file_name,omitempty"`
+	Format     ImportFormat      `json:"format"`
+	Columns    map[string]string `json:"columns,omitempty"`
+	DryRun     bool              `json:"dry_run"`
+	TotalRows  *int              `json:"total_rows"`
+	Error      string            `json:"error,omitempty"`
+	CreatedAt  *time.Time        `json:"created_at,omitempty"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	ImportProgress
+}
+
+type ImportJobEntity struct {
+	bun.BaseModel  `bun:"table:imports,alias:i"`
+	ID             uuid.UUID         `bun:"type:uuid,default:uuid_generate_v4()"`
+	Status         ImportJobStatus   `bun:"status,notnull"`
+	FileName       string            `bun:"file_name,nullzero"`
+	Format         ImportFormat      `bun:"format,notnull"`
+	Columns        map[string]string `bun:"columns,type:jsonb,nullzero"`
+	DryRun         bool              `bun:"dry_run,notnull"`
+	TotalRows      *int              `bun:"total_rows"`
+	ProcessedRows  int               `bun:"processed_rows,notnull"`
+	CreatedRows    int               `bun:"created_rows,notnull"`
+	UpdatedRows    int               `bun:"updated_rows,notnull"`
+	SkippedRows    int               `bun:"skipped_rows,notnull"`
+	Error          string            `bun:"error,nullzero"`
+	LeaseOwner     string            `bun:"lease_owner,nullzero"`
+	LeaseExpiresAt *time.Time        `bun:"lease_expires_at,nullzero"`
+	CreatedAt      *time.Time        `bun:"created_at,nullzero"`
+	StartedAt      *time.Time        `bun:"started_at,nullzero"`
+	FinishedAt     *time.Time        `bun:"finished_at,nullzero"`
+}
+
+// ImportChunkEntity is a part of the file of an import job, the parts are read in the order of Seq.
+type ImportChunkEntity struct {
+	bun.BaseModel `bun:"table:import_chunks,alias:ic"`
+	ImportID      uuid.UUID `bun:"import_id,type:uuid,pk"`
+	Seq           int       `bun:"seq,pk"`
+	Data          []byte    `bun:"data,notnull"`
+}
+
+// ImportErrorEntity is a row of an import job which cannot be imported, or which is imported with invalid contacts.
+type ImportErrorEntity struct {
+	bun.BaseModel `bun:"table:import_errors,alias:ie"`
+	ImportID      uuid.UUID    `bun:"import_id,type:uuid"`
+	Line          int          `bun:"line,notnull"`
+	Status        ImportStatus `bun:"status,notnull"`
+	Reason        string       `bun:"reason,notnull"`
+	Record        []string     `bun:"record,array"`
+}
+
+// The columns of the receivers layout of imported and exported files.
+const (
+	ReceiverColumnFirstName        = "firstName"
+	ReceiverColumnLastName         = "secondName"
+	ReceiverColumnMobilePhone      = "MobilePhone"
+	ReceiverColumnIsMobileActive   = "IsMobileActive"
+	ReceiverColumnEmail            = "Email"
+	ReceiverColumnIsEmailActive    = "IsEmailActive"
+	ReceiverColumnCity             = "City"
+	ReceiverColumnTelegram         = "Telegram"
+	ReceiverColumnIsTelegramActive = "IsTelegramActive"
+	ReceiverColumnLanguage         = "Language"
+	ReceiverColumnWhatsApp         = "WhatsApp"
+	ReceiverColumnIsWhatsAppActive = "IsWhatsAppActive"
+	ReceiverColumnTags             = "Tags"
+)
+
+// ReceiverValueSeparator separates the contacts of a type, their Is...Active values and the tags in a cell.
+const ReceiverValueSeparator = ","
+
+// ReceiverCSVHeader is the header of the CSV files of receivers.
+var ReceiverCSVHeader = []string{
+	ReceiverColumnFirstName, ReceiverColumnLastName, ReceiverColumnMobilePhone, ReceiverColumnIsMobileActive,
+	ReceiverColumnEmail, ReceiverColumnIsEmailActive, ReceiverColumnCity,
+	ReceiverColumnTelegram, ReceiverColumnIsTelegramActive, ReceiverColumnLanguage,
+	ReceiverColumnWhatsApp, ReceiverColumnIsWhatsAppActive, ReceiverColumnTags,
+}
+
+// ReceiverNaturalKey returns the key identifying a receiver in imported files:
+// the names and the city compared case-insensitively and the phone of the first SMS contact.
+// Receivers having the same names and city without a phone share the key.
+func ReceiverNaturalKey(firstName, lastName, city string, contacts []Contact) string {
+	var phone string
+	for _, c := range contacts {
+		if c.Type == ContactTypeSMS {
+			phone = c.Value
+			break
+		}
+	}
+	return strings.Join([]string{
+		strings.ToLower(strings.TrimSpace(firstName)),
+		strings.ToLower(strings.TrimSpace(lastName)),
+		strings.ToLower(strings.TrimSpace(city)),
+		phone,
+	}, "|")
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// maxLanguageLength is the length of the language columns
+const maxLanguageLength = 35
+
+// ParseLanguage returns the canonical form of a BCP 47 language tag, e.g. tt-ru becomes tt-RU.
+// An empty tag is not set and stays empty.
+func ParseLanguage(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", nil
+	}
+	if len(tag) > maxLanguageLength {
+		return "", fmt.Errorf("language %q is longer than %d characters", tag, maxLanguageLength)
+	}
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "", fmt.Errorf("invalid language %q: %w", tag, err)
+	}
+	return parsed.String(), nil
+}
+
+// BaseLanguage returns the primary language of a canonical tag, e.g. tt for tt-RU.
+func BaseLanguage(tag string) string {
+	base, _, _ := strings.Cut(tag, "-")
+	return base
+}
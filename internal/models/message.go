@@ -5,6 +5,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
 	"projects/emergency-messages/internal/errorx"
+	"time"
 )
 
 // Message is a type representing a message
@@ -22,10 +23,32 @@ type MessageStatus string
 const (
 	// Created is a message status when it is created
 	Created MessageStatus = "created"
+	// Sending is a message status when it is claimed by a worker to be sent
+	Sending MessageStatus = "sending"
+	// Accepted is a message status when the provider accepts it to be sent
+	Accepted MessageStatus = "accepted"
+	// Sent is a message status when the provider reports it is sent to the carrier or the mail server
+	Sent MessageStatus = "sent"
 	// Delivered is a message status when it is delivered
 	Delivered MessageStatus = "delivered"
+	// Undelivered is a message status when the provider reports it could not be delivered
+	Undelivered MessageStatus = "undelivered"
+	// Bounced is a message status when the mail server of the receiver rejects it permanently
+	Bounced MessageStatus = "bounced"
 	// Failed is a message status when it is failed
 	Failed MessageStatus = "failed"
+	// Cancelled is a message status when its alert is updated or cancelled before it is sent
+	Cancelled MessageStatus = "cancelled"
+)
+
+// TextFormat is a type representing the markup of a message text
+type TextFormat string
+
+const (
+	// TextFormatPlain is a text without markup
+	TextFormatPlain TextFormat = "plain"
+	// TextFormatMarkdown is a text with Telegram MarkdownV2 markup
+	TextFormatMarkdown TextFormat = "markdown"
 )
 
 // MessageRequest is a type representing a message request.
@@ -34,6 +57,10 @@ type MessageRequest struct {
 	TemplateID uuid.UUID `json:"template_id"`
 	City       string    `json:"city"`
 	Strength   string    `json:"strength"`
+	// Params are the values of the variables declared by the template
+	Params map[string]string `json:"params,omitempty"`
+	// Critical marks a life-safety alert, it is sent to the contacts which are not verified too
+	Critical bool `json:"critical,omitempty"`
 }
 
 // Validate validates the MessageRequest.
@@ -52,33 +79,80 @@ func (m *MessageRequest) Validate() error {
 
 // MessageConsumer is a type representing a message consumer.
 // It is used to consume messages from the queue broker.
+// Subject and Text are templates rendered for every receiver with the city, the strength and the params,
+// they are taken from the TemplateVersion of the template with its channel Variants.
+// Language is the language of the Subject and the Text, Translations are picked by the language of the receiver.
+// WhatsAppParameters are the variables filling the parameters of the WhatsApp template for every receiver.
+// Critical is set for a life-safety alert.
 type MessageConsumer struct {
-	Subject string        `json:"subject"`
-	Text    string        `json:"text"`
-	Status  MessageStatus `json:"status"`
-	City    string        `json:"city"`
+	BroadcastID        uuid.UUID         `json:"broadcast_id"`
+	TemplateID         uuid.UUID         `json:"template_id"`
+	TemplateVersion    int               `json:"template_version,omitempty"`
+	Subject            string            `json:"subject"`
+	Text               string            `json:"text"`
+	Variants           *TemplateVariants `json:"variants,omitempty"`
+	Language           string            `json:"language,omitempty"`
+	Translations       Translations      `json:"translations,omitempty"`
+	MaxSegments        int               `json:"max_segments,omitempty"`
+	SegmentPolicy      SegmentPolicy     `json:"segment_policy,omitempty"`
+	Status             MessageStatus     `json:"status"`
+	City               string            `json:"city"`
+	Strength           string            `json:"strength"`
+	Params             map[string]string `json:"params,omitempty"`
+	WhatsApp           *WhatsAppTemplate `json:"whatsapp,omitempty"`
+	WhatsAppParameters []string          `json:"whatsapp_parameters,omitempty"`
+	Critical           bool              `json:"critical,omitempty"`
 }
 
 // MessageEntity is a type representing a message entity.
 // It is used to interact with the database.
+// Language is the language the message is rendered in,
+// LanguageFallback is set when the message is not in the language of the receiver.
+// Segments and Encoding are the number of parts and the encoding of an SMS.
+// ProviderMessageID is the ID given by the provider at send time, the delivery reports of the provider refer to it,
+// ProviderStatus is the last status reported by the provider.
 type MessageEntity struct {
-	bun.BaseModel `bun:"table:messages,alias:m"`
-	ID            uuid.UUID     `bun:"type:uuid,default:uuid_generate_v4()"`
-	Subject       string        `bun:"subject,notnull"`
-	Text          string        `bun:"text,notnull"`
-	Status        MessageStatus `bun:"status,notnull"`
-	ReceiverID    uuid.UUID     `bun:"receiver_id,notnull"`
-	Type          ContactType   `bun:"type,notnull"`
-	Value         string        `bun:"value,notnull"`
+	bun.BaseModel     `bun:"table:messages,alias:m"`
+	ID                uuid.UUID         `bun:"type:uuid,default:uuid_generate_v4()"`
+	BroadcastID       uuid.UUID         `bun:"broadcast_id,type:uuid,nullzero"`
+	TemplateID        uuid.UUID         `bun:"template_id,type:uuid,nullzero"`
+	TemplateVersion   int               `bun:"template_version,nullzero"`
+	Subject           string            `bun:"subject,notnull"`
+	Text              string            `bun:"text,notnull"`
+	HTML              string            `bun:"html,nullzero"`
+	Format            TextFormat        `bun:"format,nullzero"`
+	Language          string            `bun:"language,nullzero"`
+	LanguageFallback  bool              `bun:"language_fallback,notnull"`
+	Segments          int               `bun:"sms_segments,nullzero"`
+	Encoding          string            `bun:"sms_encoding,nullzero"`
+	Status            MessageStatus     `bun:"status,notnull"`
+	ReceiverID        uuid.UUID         `bun:"receiver_id,notnull"`
+	Type              ContactType       `bun:"type,notnull"`
+	Value             string            `bun:"value,notnull"`
+	Attempts          int               `bun:"attempts,notnull"`
+	NextAttemptAt     *time.Time        `bun:"next_attempt_at,nullzero"`
+	LastError         string            `bun:"last_error,nullzero"`
+	LeaseOwner        string            `bun:"lease_owner,nullzero"`
+	LeaseExpiresAt    *time.Time        `bun:"lease_expires_at,nullzero"`
+	WhatsApp          *WhatsAppTemplate `bun:"whatsapp_template,type:jsonb,nullzero"`
+	ProviderMessageID string            `bun:"provider_message_id,nullzero"`
+	ProviderStatus    string            `bun:"provider_status,nullzero"`
 }
 
 // MessageSend is a type representing a message send.
+// HTML is the HTML body of an email, Format is the markup of the text.
+// Untracked is set for a message that is not stored, e.g. a verification code, its provider does not report its statuses.
 type MessageSend struct {
-	ID         uuid.UUID     `bun:"type:uuid,default:uuid_generate_v4()"`
-	Subject    string        `bun:"subject,notnull"`
-	Text       string        `bun:"text,notnull"`
-	Status     MessageStatus `bun:"status,notnull"`
-	ReceiverID uuid.UUID     `bun:"receiver_id,notnull"`
-	Type       ContactType   `bun:"type,notnull"`
-	Value      string        `bun:"value,notnull"`
+	ID         uuid.UUID         `bun:"type:uuid,default:uuid_generate_v4()"`
+	Subject    string            `bun:"subject,notnull"`
+	Text       string            `bun:"text,notnull"`
+	HTML       string            `bun:"html,nullzero"`
+	Format     TextFormat        `bun:"format,nullzero"`
+	Status     MessageStatus     `bun:"status,notnull"`
+	ReceiverID uuid.UUID         `bun:"receiver_id,notnull"`
+	Type       ContactType       `bun:"type,notnull"`
+	Value      string            `bun:"value,notnull"`
+	Attempts   int               `bun:"attempts,notnull"`
+	WhatsApp   *WhatsAppTemplate `bun:"whatsapp_template,type:jsonb,nullzero"`
+	Untracked  bool              `bun:"-"`
 }
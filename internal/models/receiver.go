@@ -1,22 +1,53 @@
 package models
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
 )
 
+const (
+	maxReceiverNameLength = 50
+	maxReceiverCityLength = 100
+	maxContactValueLength = 255
+	maxReceiverTags       = 20
+	maxReceiverTagLength  = 50
+
+	// DefaultReceiverLimit is the size of a page of receivers when it is not set
+	DefaultReceiverLimit = 50
+	// MaxReceiverLimit is the greatest size of a page of receivers
+	MaxReceiverLimit = 500
+)
+
 type Receiver struct {
-	ID        uuid.UUID `json:"id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	City      string    `json:"city"`
-	Contacts  []Contact `json:"contacts"`
+	ID        uuid.UUID  `json:"id"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	City      string     `json:"city"`
+	Language  string     `json:"language,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Contacts  []Contact  `json:"contacts"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 }
 
+// Contact is a type representing a way to reach a receiver.
+// InactiveReason is the reason an invalid contact is inactive, e.g. an invalid phone number.
+// VerifiedAt is the time the receiver confirmed the code sent to the contact,
+// OptedOutAt is the time the receiver opted out of the messages, e.g. by replying STOP to an SMS.
 type Contact struct {
-	Value    string      `json:"value"`
-	Type     ContactType `json:"type"`
-	IsActive bool        `json:"is_active"`
+	Value          string      `json:"value"`
+	Type           ContactType `json:"type"`
+	IsActive       bool        `json:"is_active"`
+	InactiveReason string      `json:"inactive_reason,omitempty"`
+	VerifiedAt     *time.Time  `json:"verified_at,omitempty"`
+	OptedOutAt     *time.Time  `json:"opted_out_at,omitempty"`
 }
 
 func (c *Contact) IsActiveMobilePhone() bool {
@@ -27,28 +58,295 @@ func (c *Contact) IsActiveEmail() bool {
 	return c.IsActive && c.Type == ContactTypeEmail
 }
 
+// IsVerified reports whether the receiver confirmed the contact.
+func (c *Contact) IsVerified() bool {
+	return c.VerifiedAt != nil
+}
+
+// IsOptedOut reports whether the receiver opted out of the messages sent to the contact.
+func (c *Contact) IsOptedOut() bool {
+	return c.OptedOutAt != nil
+}
+
+// Key returns the type and the value identifying the contact of a receiver.
+func (c *Contact) Key() ContactKey {
+	return ContactKey{Type: c.Type, Value: c.Value}
+}
+
+// Validate validates the Contact.
+func (c *Contact) Validate() error {
+	return c.Key().Validate()
+}
+
+// MaskedValue returns the value with its middle hidden, e.g. +7*******67,
+// an email keeps the first letter and the domain, e.g. i***@example.com.
+func (c *Contact) MaskedValue() string {
+	if c.Type == ContactTypeEmail {
+		if at := strings.LastIndex(c.Value, "@"); at > 0 {
+			first, _ := utf8.DecodeRuneInString(c.Value)
+			return string(first) + "***" + c.Value[at:]
+		}
+	}
+	runes := []rune(c.Value)
+	if len(runes) <= 6 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:2]) + strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-2:])
+}
+
+// IsMaskedValue reports whether the value is hidden by MaskedValue, e.g. a contact of an export with mask=true.
+func IsMaskedValue(value string) bool {
+	return strings.Contains(value, "***")
+}
+
+// ContactKey is a type representing the type and the value identifying a contact of a receiver.
+type ContactKey struct {
+	Type  ContactType `json:"type"`
+	Value string      `json:"value"`
+}
+
+// Validate validates the ContactKey.
+func (k ContactKey) Validate() error {
+	if !k.Type.IsValid() {
+		return fmt.Errorf("unknown contact type %q", k.Type)
+	}
+	if k.Value == "" {
+		return errors.New("contact value is empty")
+	}
+	if utf8.RuneCountInString(k.Value) > maxContactValueLength {
+		return fmt.Errorf("contact value is longer than %d characters", maxContactValueLength)
+	}
+	return nil
+}
+
 type ContactType string
 
 const (
-	ContactTypeEmail ContactType = "email"
-	ContactTypeSMS   ContactType = "sms"
+	ContactTypeEmail    ContactType = "email"
+	ContactTypeSMS      ContactType = "sms"
+	ContactTypeTelegram ContactType = "telegram"
+	ContactTypeWhatsApp ContactType = "whatsapp"
 )
 
+// IsValid reports whether the contact type is known.
+func (t ContactType) IsValid() bool {
+	switch t {
+	case ContactTypeEmail, ContactTypeSMS, ContactTypeTelegram, ContactTypeWhatsApp:
+		return true
+	default:
+		return false
+	}
+}
+
 type ReceiverCreate struct {
 	ID        uuid.UUID `json:"id"`
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 	Contacts  []Contact `json:"contacts"`
 	City      string    `json:"city"`
+	Language  string    `json:"language,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+}
+
+// Validate validates the ReceiverCreate.
+func (r *ReceiverCreate) Validate() error {
+	if err := validateReceiverName("first name", r.FirstName); err != nil {
+		return err
+	}
+	if err := validateReceiverName("last name", r.LastName); err != nil {
+		return err
+	}
+	if err := validateReceiverCity(r.City); err != nil {
+		return err
+	}
+	if _, err := ParseLanguage(r.Language); err != nil {
+		return err
+	}
+	if err := ValidateTags(r.Tags); err != nil {
+		return err
+	}
+	return ValidateContacts(r.Contacts)
+}
+
+// ReceiverUpdate is a type representing a partial update of a receiver, fields which are nil are not changed.
+type ReceiverUpdate struct {
+	ID        string
+	FirstName *string
+	LastName  *string
+	City      *string
+	Language  *string
+	Tags      *[]string
+}
+
+// Validate validates the ReceiverUpdate.
+func (r *ReceiverUpdate) Validate() error {
+	if r.ID == "" {
+		return errors.New("id is empty")
+	}
+	if r.FirstName != nil {
+		if err := validateReceiverName("first name", *r.FirstName); err != nil {
+			return err
+		}
+	}
+	if r.LastName != nil {
+		if err := validateReceiverName("last name", *r.LastName); err != nil {
+			return err
+		}
+	}
+	if r.City != nil {
+		if err := validateReceiverCity(*r.City); err != nil {
+			return err
+		}
+	}
+	if r.Language != nil {
+		if _, err := ParseLanguage(*r.Language); err != nil {
+			return err
+		}
+	}
+	if r.Tags != nil {
+		if err := ValidateTags(*r.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTags validates the tags of a receiver, e.g. volunteer or school-12.
+func ValidateTags(tags []string) error {
+	if len(tags) > maxReceiverTags {
+		return fmt.Errorf("receiver has more than %d tags", maxReceiverTags)
+	}
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return errors.New("tag is empty")
+		}
+		// the tags are separated by commas in the files of receivers
+		if strings.Contains(tag, ReceiverValueSeparator) {
+			return fmt.Errorf("tag %q contains %q", tag, ReceiverValueSeparator)
+		}
+		if utf8.RuneCountInString(tag) > maxReceiverTagLength {
+			return fmt.Errorf("tag is longer than %d characters", maxReceiverTagLength)
+		}
+	}
+	return nil
+}
+
+// ReceiverFilter is a type representing a page of the receiver list.
+// Name filters the receivers whose first or last name starts with the text, case-insensitively,
+// ContactType and Active filter the receivers having a contact of the type which is active or inactive,
+// Tags filter the receivers having all the tags.
+// Receivers are ordered by ID, Cursor is the position after the last receiver of the previous page.
+type ReceiverFilter struct {
+	City        string
+	Name        string
+	ContactType ContactType
+	Active      *bool
+	Language    string
+	Tags        []string
+	Cursor      string
+	Limit       int
+	// WithoutTotal skips counting the matching receivers, e.g. when every page is read
+	WithoutTotal bool
+	// After is the ID of the last receiver of the previous page, it is decoded from the Cursor
+	After uuid.UUID
+}
+
+// Validate validates the ReceiverFilter, sets the default page size and decodes the cursor.
+func (f *ReceiverFilter) Validate() error {
+	if f.Limit == 0 {
+		f.Limit = DefaultReceiverLimit
+	}
+	if f.Limit < 0 || f.Limit > MaxReceiverLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxReceiverLimit)
+	}
+	if f.ContactType != "" && !f.ContactType.IsValid() {
+		return fmt.Errorf("unknown contact type %q", f.ContactType)
+	}
+	language, err := ParseLanguage(f.Language)
+	if err != nil {
+		return err
+	}
+	f.Language = language
+
+	f.After = uuid.Nil
+	if f.Cursor != "" {
+		b, err := base64.RawURLEncoding.DecodeString(f.Cursor)
+		if err != nil {
+			return fmt.Errorf("invalid cursor: %w", err)
+		}
+		if f.After, err = uuid.FromBytes(b); err != nil {
+			return fmt.Errorf("invalid cursor: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReceiverCursor returns the cursor of the page after the receiver.
+func ReceiverCursor(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+// ReceiverList is a type representing a page of receivers with the number of all matching receivers.
+// NextCursor is empty on the last page.
+type ReceiverList struct {
+	Receivers  []Receiver `json:"receivers"`
+	Total      int        `json:"total"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// ValidateContacts validates the contacts of a receiver, a contact must not be repeated.
+func ValidateContacts(contacts []Contact) error {
+	keys := make(map[ContactKey]bool, len(contacts))
+	for _, c := range contacts {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+		if keys[c.Key()] {
+			return fmt.Errorf("%s contact %s is repeated", c.Type, c.Value)
+		}
+		keys[c.Key()] = true
+	}
+	return nil
+}
+
+func validateReceiverName(field, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s is empty", field)
+	}
+	if utf8.RuneCountInString(name) > maxReceiverNameLength {
+		return fmt.Errorf("%s is longer than %d characters", field, maxReceiverNameLength)
+	}
+	return nil
+}
+
+func validateReceiverCity(city string) error {
+	if city == "" {
+		return errors.New("city is empty")
+	}
+	if utf8.RuneCountInString(city) > maxReceiverCityLength {
+		return fmt.Errorf("city is longer than %d characters", maxReceiverCityLength)
+	}
+	return nil
 }
 
 type ReceiverEntity struct {
 	bun.BaseModel `bun:"table:receivers,alias:u"`
-	ID            uuid.UUID `bun:"type:uuid,default:uuid_generate_v4()"`
-	FirstName     string    `bun:"first_name,notnull"`
-	LastName      string    `bun:"last_name,notnull"`
-	Contacts      []Contact `bun:"contacts,notnull"`
-	City          string    `bun:"city,notnull"`
+	ID            uuid.UUID  `bun:"type:uuid,default:uuid_generate_v4()"`
+	FirstName     string     `bun:"first_name,notnull"`
+	LastName      string     `bun:"last_name,notnull"`
+	Contacts      []Contact  `bun:"contacts,notnull"`
+	City          string     `bun:"city,notnull"`
+	Language      string     `bun:"language,nullzero"`
+	Tags          []string   `bun:"tags,array"`
+	NaturalKey    string     `bun:"natural_key,nullzero"`
+	CreatedAt     *time.Time `bun:"created_at,nullzero"`
+	UpdatedAt     *time.Time `bun:"updated_at,nullzero"`
+	DeletedAt     *time.Time `bun:"deleted_at,nullzero"`
+}
+
+// SetNaturalKey sets the natural key of the receiver from its names, city and contacts.
+func (e *ReceiverEntity) SetNaturalKey() {
+	e.NaturalKey = ReceiverNaturalKey(e.FirstName, e.LastName, e.City, e.Contacts)
 }
 
 type ReceiverSend struct {
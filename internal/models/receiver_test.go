@@ -0,0 +1,84 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiverFilter_Validate(t *testing.T) {
+	t.Run("when limit is not set then default", func(t *testing.T) {
+		filter := ReceiverFilter{}
+		assert.NoError(t, filter.Validate())
+		assert.Equal(t, DefaultReceiverLimit, filter.Limit)
+		assert.Equal(t, uuid.Nil, filter.After)
+	})
+	t.Run("when cursor is set then it is decoded", func(t *testing.T) {
+		id := uuid.New()
+		filter := ReceiverFilter{Cursor: ReceiverCursor(id), Language: "TT-ru"}
+		assert.NoError(t, filter.Validate())
+		assert.Equal(t, id, filter.After)
+		assert.Equal(t, "tt-RU", filter.Language)
+	})
+	t.Run("when cursor is invalid then error", func(t *testing.T) {
+		filter := ReceiverFilter{Cursor: "abc"}
+		assert.Error(t, filter.Validate())
+	})
+	t.Run("when limit is too big then error", func(t *testing.T) {
+		filter := ReceiverFilter{Limit: MaxReceiverLimit + 1}
+		assert.Error(t, filter.Validate())
+	})
+	t.Run("when contact type is unknown then error", func(t *testing.T) {
+		filter := ReceiverFilter{ContactType: "fax"}
+		assert.Error(t, filter.Validate())
+	})
+}
+
+func TestValidateTags(t *testing.T) {
+	t.Run("when tags are valid then no error", func(t *testing.T) {
+		assert.NoError(t, ValidateTags([]string{"volunteer", "school-12"}))
+	})
+	t.Run("when tag is empty then error", func(t *testing.T) {
+		assert.Error(t, ValidateTags([]string{" "}))
+	})
+	t.Run("when tag is too long then error", func(t *testing.T) {
+		assert.Error(t, ValidateTags([]string{strings.Repeat("a", maxReceiverTagLength+1)}))
+	})
+	t.Run("when tag contains comma then error", func(t *testing.T) {
+		assert.Error(t, ValidateTags([]string{"school,12"}))
+	})
+}
+
+func TestContact_MaskedValue(t *testing.T) {
+	tests := []struct {
+		contact Contact
+		want    string
+	}{
+		{contact: Contact{Type: ContactTypeSMS, Value: "+79001234567"}, want: "+7********67"},
+		{contact: Contact{Type: ContactTypeEmail, Value: "ildar@example.com"}, want: "i***@example.com"},
+		{contact: Contact{Type: ContactTypeEmail, Value: "ильдар@пример.рф"}, want: "и***@пример.рф"},
+		{contact: Contact{Type: ContactTypeTelegram, Value: "12345"}, want: "*****"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.contact.MaskedValue(), tt.contact.Value)
+	}
+}
+
+func TestReceiverExport_Validate(t *testing.T) {
+	t.Run("when format is not set then csv", func(t *testing.T) {
+		export := ReceiverExport{City: "Kazan", ContactType: ContactTypeSMS}
+		assert.NoError(t, export.Validate())
+		assert.Equal(t, ImportFormatCSV, export.Format)
+		assert.Equal(t, ReceiverFilter{City: "Kazan", ContactType: ContactTypeSMS, WithoutTotal: true}, export.Filter())
+	})
+	t.Run("when format cannot be exported then error", func(t *testing.T) {
+		export := ReceiverExport{Format: ImportFormatXLSX}
+		assert.Error(t, export.Validate())
+	})
+	t.Run("when contact type is unknown then error", func(t *testing.T) {
+		export := ReceiverExport{ContactType: "fax"}
+		assert.Error(t, export.Validate())
+	})
+}
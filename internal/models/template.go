@@ -2,21 +2,265 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
+	"projects/emergency-messages/internal/render"
 	"time"
+	"unicode/utf8"
 )
 
+// Template is a type representing a template returned by the API.
 type Template struct {
-	ID      string `json:"id"`
-	Subject string `json:"subject"`
-	Text    string `json:"text"`
+	ID                 uuid.UUID          `json:"id"`
+	Subject            string             `json:"subject"`
+	Text               string             `json:"text"`
+	Variables          []TemplateVariable `json:"variables"`
+	Variants           *TemplateVariants  `json:"variants,omitempty"`
+	Language           string             `json:"language,omitempty"`
+	Translations       Translations       `json:"translations,omitempty"`
+	MaxSegments        int                `json:"max_segments,omitempty"`
+	SegmentPolicy      SegmentPolicy      `json:"segment_policy,omitempty"`
+	WhatsAppName       string             `json:"whatsapp_name,omitempty"`
+	WhatsAppLanguage   string             `json:"whatsapp_language,omitempty"`
+	WhatsAppParameters []string           `json:"whatsapp_parameters,omitempty"`
+	Version            int                `json:"version"`
+	UpdatedBy          string             `json:"updated_by,omitempty"`
+	CreatedAt          *time.Time         `json:"created_at,omitempty"`
+	UpdatedAt          *time.Time         `json:"updated_at,omitempty"`
 }
 
-type TemplateUpdate struct {
+// TemplateVersion is a type representing an immutable revision of a template.
+// RestoredFrom is the version whose wording a rollback copied into this one.
+type TemplateVersion struct {
+	Version            int                `json:"version"`
+	Subject            string             `json:"subject"`
+	Text               string             `json:"text"`
+	Variables          []TemplateVariable `json:"variables"`
+	Variants           *TemplateVariants  `json:"variants,omitempty"`
+	Language           string             `json:"language,omitempty"`
+	Translations       Translations       `json:"translations,omitempty"`
+	MaxSegments        int                `json:"max_segments,omitempty"`
+	SegmentPolicy      SegmentPolicy      `json:"segment_policy,omitempty"`
+	WhatsAppName       string             `json:"whatsapp_name,omitempty"`
+	WhatsAppLanguage   string             `json:"whatsapp_language,omitempty"`
+	WhatsAppParameters []string           `json:"whatsapp_parameters,omitempty"`
+	Author             string             `json:"author,omitempty"`
+	RestoredFrom       int                `json:"restored_from,omitempty"`
+	Current            bool               `json:"current"`
+	CreatedAt          *time.Time         `json:"created_at,omitempty"`
+}
+
+// TemplateRollback is a type representing a request to make an older version of a template current.
+type TemplateRollback struct {
 	ID      string
+	Version int
+	Author  string
+}
+
+func (t TemplateRollback) Validate() error {
+	if t.ID == "" {
+		return errors.New("id is empty")
+	}
+	if t.Version < 1 {
+		return errors.New("version must be positive")
+	}
+	return validateAuthor(t.Author)
+}
+
+const (
+	// DefaultTemplateLimit is the page size of the template list when it is not set
+	DefaultTemplateLimit = 20
+	// MaxTemplateLimit is the largest page size of the template list
+	MaxTemplateLimit = 100
+
+	maxTemplateAuthorLength = 100
+)
+
+// TemplateFilter is a type representing a page of the template list.
+// Subject filters the templates whose subject contains the text, case-insensitively.
+type TemplateFilter struct {
 	Subject string
-	Text    string
+	Limit   int
+	Offset  int
+}
+
+// Validate validates the TemplateFilter and sets the default page size.
+func (f *TemplateFilter) Validate() error {
+	if f.Limit == 0 {
+		f.Limit = DefaultTemplateLimit
+	}
+	if f.Limit < 0 || f.Limit > MaxTemplateLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxTemplateLimit)
+	}
+	if f.Offset < 0 {
+		return errors.New("offset is negative")
+	}
+	return nil
+}
+
+// TemplateList is a type representing a page of templates with the number of all matching templates.
+type TemplateList struct {
+	Templates []Template `json:"templates"`
+	Total     int        `json:"total"`
+}
+
+// TemplateVariable is a type representing a variable declared by a template, e.g. {{.WindSpeed}}.
+// Its value is passed in the params of a message request.
+// The variables City, Strength and Receiver are built-in and are not declared.
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// TemplateVariants is a type representing the bodies of a template written for specific channels.
+// An empty variant falls back to the text of the template, an email without the HTML variant is plain text.
+// The SMS variant is at most MaxSMSVariantLength characters before rendering,
+// the Markdown variant is sent to messengers as Telegram MarkdownV2.
+type TemplateVariants struct {
+	SMS       string `json:"sms,omitempty"`
+	Email     string `json:"email,omitempty"`
+	EmailHTML string `json:"email_html,omitempty"`
+	Markdown  string `json:"markdown,omitempty"`
+}
+
+// Render returns the channel variants to be rendered, nil variants have none.
+func (v *TemplateVariants) Render() render.Variants {
+	if v == nil {
+		return render.Variants{}
+	}
+	return render.Variants{
+		SMS:       v.SMS,
+		Email:     v.Email,
+		EmailHTML: v.EmailHTML,
+		Markdown:  v.Markdown,
+	}
+}
+
+// MaxSMSVariantLength is the length of the SMS variant of a template, a single GSM-7 SMS
+const MaxSMSVariantLength = 160
+
+// Validate validates the TemplateVariants, no variants are valid.
+func (v *TemplateVariants) Validate() error {
+	if v == nil {
+		return nil
+	}
+	if utf8.RuneCountInString(v.SMS) > MaxSMSVariantLength {
+		return fmt.Errorf("sms variant is longer than %d characters", MaxSMSVariantLength)
+	}
+	return nil
+}
+
+// IsEmpty reports whether no variant is set.
+func (v *TemplateVariants) IsEmpty() bool {
+	return v == nil || *v == TemplateVariants{}
+}
+
+// SegmentPolicy is a type representing what is done with an SMS longer than the maximum number of segments of its template.
+type SegmentPolicy string
+
+const (
+	// SegmentPolicyReject fails the SMS, a template whose text alone is too long is rejected
+	SegmentPolicyReject SegmentPolicy = "reject"
+	// SegmentPolicyTruncate cuts the SMS and ends it with an ellipsis, the default policy
+	SegmentPolicyTruncate SegmentPolicy = "truncate"
+	// SegmentPolicyTransliterate writes the SMS in the GSM-7 alphabet and truncates it if it is still too long
+	SegmentPolicyTransliterate SegmentPolicy = "transliterate"
+)
+
+// MaxSMSSegments is the greatest maximum number of segments of a template
+const MaxSMSSegments = 10
+
+// validateSegments checks the maximum number of SMS segments of a template and its policy,
+// zero segments are not limited.
+func validateSegments(segments int, policy SegmentPolicy) error {
+	if segments < 0 || segments > MaxSMSSegments {
+		return fmt.Errorf("max segments must be between 0 and %d", MaxSMSSegments)
+	}
+	switch policy {
+	case "", SegmentPolicyReject, SegmentPolicyTruncate, SegmentPolicyTransliterate:
+		return nil
+	default:
+		return fmt.Errorf("unknown segment policy %q", policy)
+	}
+}
+
+// TemplateTranslation is a type representing the subject, the text and the channel variants of a template in a language.
+type TemplateTranslation struct {
+	Subject  string            `json:"subject"`
+	Text     string            `json:"text"`
+	Variants *TemplateVariants `json:"variants,omitempty"`
+}
+
+// Translations is a type representing the translations of a template by BCP 47 language tag.
+// The subject and the text of the template are in the language of the template.
+type Translations map[string]TemplateTranslation
+
+// Validate validates the Translations.
+func (t Translations) Validate() error {
+	languages := make(map[string]bool, len(t))
+	for tag, translation := range t {
+		language, err := ParseLanguage(tag)
+		if err != nil {
+			return err
+		}
+		if language == "" {
+			return errors.New("language of translation is empty")
+		}
+		if languages[language] {
+			return fmt.Errorf("language %s is translated twice", language)
+		}
+		languages[language] = true
+		if translation.Subject == "" {
+			return fmt.Errorf("subject of %s translation is empty", tag)
+		}
+		if translation.Text == "" {
+			return fmt.Errorf("text of %s translation is empty", tag)
+		}
+		if err = translation.Variants.Validate(); err != nil {
+			return fmt.Errorf("%s translation: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// Canonical returns the translations keyed by the canonical language tags.
+// The translations must be valid.
+func (t Translations) Canonical() Translations {
+	if len(t) == 0 {
+		return nil
+	}
+	result := make(Translations, len(t))
+	for tag, translation := range t {
+		language, _ := ParseLanguage(tag)
+		result[language] = translation
+	}
+	return result
+}
+
+// WhatsAppTemplate is a type representing a pre-approved WhatsApp message template.
+// Parameters fill the positional placeholders {{1}}, {{2}}, ... of the template body.
+type WhatsAppTemplate struct {
+	Name       string   `json:"name"`
+	Language   string   `json:"language"`
+	Parameters []string `json:"parameters,omitempty"`
+}
+
+type TemplateUpdate struct {
+	ID                 string
+	Subject            string
+	Text               string
+	Variables          []TemplateVariable
+	Variants           *TemplateVariants
+	Language           string
+	Translations       Translations
+	MaxSegments        int
+	SegmentPolicy      SegmentPolicy
+	WhatsAppName       string
+	WhatsAppLanguage   string
+	WhatsAppParameters []string
+	Author             string
 }
 
 func (t TemplateUpdate) Validate() error {
@@ -29,13 +273,38 @@ func (t TemplateUpdate) Validate() error {
 	if t.Text == "" {
 		return errors.New("text is empty")
 	}
-	return nil
+	if err := validateAuthor(t.Author); err != nil {
+		return err
+	}
+	if err := t.Variants.Validate(); err != nil {
+		return err
+	}
+	if _, err := ParseLanguage(t.Language); err != nil {
+		return err
+	}
+	if err := validateSegments(t.MaxSegments, t.SegmentPolicy); err != nil {
+		return err
+	}
+	if err := t.Translations.Validate(); err != nil {
+		return err
+	}
+	return validateWhatsApp(t.WhatsAppName, t.WhatsAppLanguage, t.WhatsAppParameters)
 }
 
 type TemplateCreate struct {
-	ID      string
-	Subject string
-	Text    string
+	ID                 string
+	Subject            string
+	Text               string
+	Variables          []TemplateVariable
+	Variants           *TemplateVariants
+	Language           string
+	Translations       Translations
+	MaxSegments        int
+	SegmentPolicy      SegmentPolicy
+	WhatsAppName       string
+	WhatsAppLanguage   string
+	WhatsAppParameters []string
+	Author             string
 }
 
 func (t *TemplateCreate) Validate() error {
@@ -45,15 +314,125 @@ func (t *TemplateCreate) Validate() error {
 	if t.Text == "" {
 		return errors.New("text is empty")
 	}
+	if err := validateAuthor(t.Author); err != nil {
+		return err
+	}
+	if err := t.Variants.Validate(); err != nil {
+		return err
+	}
+	if _, err := ParseLanguage(t.Language); err != nil {
+		return err
+	}
+	if err := validateSegments(t.MaxSegments, t.SegmentPolicy); err != nil {
+		return err
+	}
+	if err := t.Translations.Validate(); err != nil {
+		return err
+	}
+	return validateWhatsApp(t.WhatsAppName, t.WhatsAppLanguage, t.WhatsAppParameters)
+}
+
+// validateAuthor checks that the author of a template version fits its column.
+func validateAuthor(author string) error {
+	if utf8.RuneCountInString(author) > maxTemplateAuthorLength {
+		return fmt.Errorf("author is longer than %d characters", maxTemplateAuthorLength)
+	}
+	return nil
+}
+
+// validateWhatsApp checks that a WhatsApp template name comes with its language
+// and the parameters belong to a WhatsApp template.
+func validateWhatsApp(name, language string, parameters []string) error {
+	if name != "" && language == "" {
+		return errors.New("whatsapp language is empty")
+	}
+	if name == "" && (language != "" || len(parameters) > 0) {
+		return errors.New("whatsapp name is empty")
+	}
 	return nil
 }
 
 type TemplateEntity struct {
-	bun.BaseModel `bun:"table:templates,alias:t"`
-	ID            uuid.UUID  `bun:"type:uuid,default:uuid_generate_v4()"`
-	Subject       string     `bun:"subject,notnull"`
-	Text          string     `bun:"text,notnull"`
-	DeletedAt     *time.Time `bun:"deleted_at,nullzero"`
-	CreatedAt     *time.Time `bun:"created_at,nullzero"`
-	UpdatedAt     *time.Time `bun:"updated_at,nullzero"`
+	bun.BaseModel      `bun:"table:templates,alias:t"`
+	ID                 uuid.UUID          `bun:"type:uuid,default:uuid_generate_v4()"`
+	Subject            string             `bun:"subject,notnull"`
+	Text               string             `bun:"text,notnull"`
+	Variables          []TemplateVariable `bun:"variables,type:jsonb,nullzero"`
+	Variants           *TemplateVariants  `bun:"variants,type:jsonb,nullzero"`
+	Language           string             `bun:"language,nullzero"`
+	Translations       Translations       `bun:"translations,type:jsonb,nullzero"`
+	MaxSegments        int                `bun:"max_segments,nullzero"`
+	SegmentPolicy      SegmentPolicy      `bun:"segment_policy,nullzero"`
+	WhatsAppName       string             `bun:"whatsapp_name,nullzero"`
+	WhatsAppLanguage   string             `bun:"whatsapp_language,nullzero"`
+	WhatsAppParameters []string           `bun:"whatsapp_parameters,type:jsonb,nullzero"`
+	Version            int                `bun:"version,notnull"`
+	UpdatedBy          string             `bun:"updated_by,nullzero"`
+	DeletedAt          *time.Time         `bun:"deleted_at,nullzero"`
+	CreatedAt          *time.Time         `bun:"created_at,nullzero"`
+	UpdatedAt          *time.Time         `bun:"updated_at,nullzero"`
+}
+
+// TemplateVersionEntity is a type representing a revision of a template.
+// Every edit of a template adds a version, versions are never updated,
+// so the messages referencing a version keep the exact wording they were sent with.
+type TemplateVersionEntity struct {
+	bun.BaseModel      `bun:"table:template_versions,alias:tv"`
+	ID                 uuid.UUID          `bun:"type:uuid,default:uuid_generate_v4()"`
+	TemplateID         uuid.UUID          `bun:"template_id,type:uuid,notnull"`
+	Version            int                `bun:"version,notnull"`
+	Subject            string             `bun:"subject,notnull"`
+	Text               string             `bun:"text,notnull"`
+	Variables          []TemplateVariable `bun:"variables,type:jsonb,nullzero"`
+	Variants           *TemplateVariants  `bun:"variants,type:jsonb,nullzero"`
+	Language           string             `bun:"language,nullzero"`
+	Translations       Translations       `bun:"translations,type:jsonb,nullzero"`
+	MaxSegments        int                `bun:"max_segments,nullzero"`
+	SegmentPolicy      SegmentPolicy      `bun:"segment_policy,nullzero"`
+	WhatsAppName       string             `bun:"whatsapp_name,nullzero"`
+	WhatsAppLanguage   string             `bun:"whatsapp_language,nullzero"`
+	WhatsAppParameters []string           `bun:"whatsapp_parameters,type:jsonb,nullzero"`
+	Author             string             `bun:"author,nullzero"`
+	RestoredFrom       int                `bun:"restored_from,nullzero"`
+	CreatedAt          *time.Time         `bun:"created_at,nullzero"`
+}
+
+// TemplatePreview is a type representing sample variables a template is rendered with to be checked.
+type TemplatePreview struct {
+	ID       string
+	City     string
+	Strength string
+	Receiver Receiver
+	Params   map[string]string
+}
+
+// TemplatePreviewResult is a type representing a template rendered for every channel.
+// Errors list the variables which are missing, unknown or not declared by the template.
+type TemplatePreviewResult struct {
+	Channels []ChannelPreview        `json:"channels"`
+	Errors   []TemplateVariableError `json:"errors"`
+}
+
+// ChannelPreview is a type representing a template rendered as it is sent by a channel.
+type ChannelPreview struct {
+	Type     ContactType       `json:"type"`
+	Subject  string            `json:"subject,omitempty"`
+	Text     string            `json:"text,omitempty"`
+	HTML     string            `json:"html,omitempty"`
+	Format   TextFormat        `json:"format,omitempty"`
+	SMS      *SMSSegments      `json:"sms,omitempty"`
+	WhatsApp *WhatsAppTemplate `json:"whatsapp,omitempty"`
+}
+
+// SMSSegments is a type representing the encoding and the number of parts of an SMS.
+type SMSSegments struct {
+	Encoding   string `json:"encoding"`
+	Characters int    `json:"characters"`
+	Segments   int    `json:"segments"`
+}
+
+// TemplateVariableError is a type representing a problem with a variable of a template.
+type TemplateVariableError struct {
+	Variable string `json:"variable,omitempty"`
+	Message  string `json:"message"`
 }
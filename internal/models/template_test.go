@@ -61,3 +61,26 @@ func TestTemplateUpdate_Validate(t1 *testing.T) {
 		})
 	}
 }
+
+func Test_validateWhatsApp(t *testing.T) {
+	tests := []struct {
+		name       string
+		waName     string
+		language   string
+		parameters []string
+		wantErr    bool
+	}{
+		{name: "when whatsapp is not mapped then no error"},
+		{name: "when name and language are set then no error", waName: "flood_warning", language: "ru"},
+		{name: "when language is empty then error", waName: "flood_warning", wantErr: true},
+		{name: "when name is empty then error", language: "ru", wantErr: true},
+		{name: "when parameters are set without name then error", parameters: []string{"City"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWhatsApp(tt.waName, tt.language, tt.parameters); (err != nil) != tt.wantErr {
+				t.Errorf("validateWhatsApp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
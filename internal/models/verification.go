@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// ContactConfirm is a type representing the code sent to a contact to verify it.
+type ContactConfirm struct {
+	ContactKey
+	Code string `json:"code"`
+}
+
+// Validate validates the ContactConfirm.
+func (c ContactConfirm) Validate() error {
+	if err := c.ContactKey.Validate(); err != nil {
+		return err
+	}
+	if c.Code == "" {
+		return errors.New("code is empty")
+	}
+	return nil
+}
+
+// ContactVerification is a type representing a code sent to a contact,
+// the code is accepted until ExpiresAt.
+type ContactVerification struct {
+	ContactKey
+	ExpiresAt   time.Time `json:"expires_at"`
+	MaxAttempts int       `json:"max_attempts"`
+}
+
+// ContactVerificationEntity is a type representing a code sent to a contact of a receiver.
+// CodeHash is the hash of the code, Attempts is the number of codes checked against it.
+// It is used to interact with the database.
+type ContactVerificationEntity struct {
+	bun.BaseModel `bun:"table:contact_verifications,alias:cv"`
+	ID            uuid.UUID   `bun:"type:uuid,default:uuid_generate_v4()"`
+	ReceiverID    uuid.UUID   `bun:"receiver_id,type:uuid,notnull"`
+	Type          ContactType `bun:"type,notnull"`
+	Value         string      `bun:"value,notnull"`
+	CodeHash      string      `bun:"code_hash,notnull"`
+	Attempts      int         `bun:"attempts,notnull"`
+	MaxAttempts   int         `bun:"max_attempts,notnull"`
+	ExpiresAt     time.Time   `bun:"expires_at,notnull"`
+	ConfirmedAt   *time.Time  `bun:"confirmed_at,nullzero"`
+	CreatedAt     *time.Time  `bun:"created_at,nullzero"`
+}
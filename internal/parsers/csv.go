@@ -0,0 +1,86 @@
+package parsers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const semicolon = ';'
+
+// byteOrderMark starts the CSV files saved by Excel
+const byteOrderMark = "\ufeff"
+
+// formulaChars start a formula in a spreadsheet, a cell starting with them is escaped with formulaEscape
+const (
+	formulaChars  = "=+-@\t\r"
+	formulaEscape = "'"
+)
+
+type csvReader struct {
+	reader *csv.Reader
+	header []string
+}
+
+// NewCSV returns the reader of a CSV file separated by semicolons, the first row is the header.
+// A row may have fewer cells than the header, the missing cells are empty.
+func NewCSV(r io.Reader) (Reader, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = semicolon
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &csvReader{reader: reader}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	header[0] = strings.TrimPrefix(header[0], byteOrderMark)
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	return &csvReader{reader: reader, header: header}, nil
+}
+
+func (r *csvReader) Read() (Row, error) {
+	record, err := r.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Row{}, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+	}
+	if err != nil {
+		return Row{}, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	if len(record) > len(r.header) {
+		return Row{}, &RowError{Line: line, Err: fmt.Errorf("expected %d cells, have %d cells", len(r.header), len(record))}
+	}
+	values := make(map[string]string, len(record))
+	for i, v := range record {
+		if _, ok := values[r.header[i]]; !ok {
+			values[r.header[i]] = UnescapeFormula(v)
+		}
+	}
+	return Row{Line: line, Values: values}, nil
+}
+
+// EscapeFormula returns the value of a CSV cell which a spreadsheet does not run as a formula,
+// a value starting with =, +, -, @, a tab or a carriage return is prefixed with a quote, e.g. '+79001234567.
+func EscapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaChars, rune(value[0])) {
+		return formulaEscape + value
+	}
+	return value
+}
+
+// UnescapeFormula returns the value of a CSV cell escaped by EscapeFormula.
+func UnescapeFormula(value string) string {
+	if len(value) > 1 && strings.HasPrefix(value, formulaEscape) && strings.ContainsRune(formulaChars, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
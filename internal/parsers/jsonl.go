@@ -0,0 +1,76 @@
+package parsers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type jsonLinesReader struct {
+	reader *bufio.Reader
+	line   int
+}
+
+// NewJSONLines returns the reader of a file of JSON objects, one object per line, the keys of an object are its columns.
+// Strings, numbers and booleans are values, nulls are empty, arrays and objects are kept as JSON.
+// Empty lines are skipped.
+func NewJSONLines(r io.Reader) (Reader, error) {
+	return &jsonLinesReader{reader: bufio.NewReader(r)}, nil
+}
+
+func (r *jsonLinesReader) Read() (Row, error) {
+	for {
+		data, err := r.reader.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			return Row{}, err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Row{}, err
+		}
+		r.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		values, err := parseJSONObject(data)
+		if err != nil {
+			return Row{}, &RowError{Line: r.line, Err: err}
+		}
+		return Row{Line: r.line, Values: values}, nil
+	}
+}
+
+func parseJSONObject(data []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("invalid JSON object: %w", err)
+	}
+	if object == nil {
+		return nil, errors.New("invalid JSON object: null")
+	}
+
+	values := make(map[string]string, len(object))
+	for key, value := range object {
+		switch v := value.(type) {
+		case nil:
+			values[key] = ""
+		case string:
+			values[key] = v
+		case json.Number:
+			values[key] = v.String()
+		case bool:
+			values[key] = strconv.FormatBool(v)
+		default:
+			raw, _ := json.Marshal(v)
+			values[key] = string(raw)
+		}
+	}
+	return values, nil
+}
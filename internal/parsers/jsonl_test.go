@@ -0,0 +1,25 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLines(t *testing.T) {
+	data := `{"name":"Ildar","phone":79001234567,"active":true,"email":null,"tags":["a"]}` + "\r\n\n" +
+		`["Boris"]` + "\n" +
+		`null` + "\n" +
+		`{"name":"Albert"}`
+	r, err := NewJSONLines(strings.NewReader(data))
+	assert.NoError(t, err)
+
+	rows, rowErrors := readAll(t, r)
+	assert.Equal(t, []Row{
+		{Line: 1, Values: map[string]string{"name": "Ildar", "phone": "79001234567", "active": "true", "email": "", "tags": `["a"]`}},
+		{Line: 5, Values: map[string]string{"name": "Albert"}},
+	}, rows)
+	assert.Contains(t, rowErrors[3], "invalid JSON object")
+	assert.Contains(t, rowErrors[4], "invalid JSON object")
+}
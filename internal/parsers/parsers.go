@@ -0,0 +1,116 @@
+package parsers
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"projects/emergency-messages/internal/models"
+	"strings"
+)
+
+// Row is a row of an imported file, the values are keyed by the columns of the file. Lines start at 1.
+type Row struct {
+	Line   int
+	Values map[string]string
+}
+
+// RowError is a row which cannot be parsed, the rows after it are read.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads the rows of an imported file one by one.
+// Read returns a *RowError for a row which cannot be parsed and io.EOF at the end of the file,
+// the other errors mean the file cannot be read.
+type Reader interface {
+	Read() (Row, error)
+}
+
+// Parser returns the reader of a file of its format.
+type Parser func(r io.Reader) (Reader, error)
+
+type format struct {
+	parser       Parser
+	contentTypes []string
+	extensions   []string
+}
+
+var formats = map[models.ImportFormat]format{}
+
+// order is the order the formats are registered in, the formats are detected in this order
+var order []models.ImportFormat
+
+func init() {
+	Register(models.ImportFormatCSV, NewCSV,
+		[]string{"text/csv", "application/csv"}, []string{".csv", ".txt"})
+	Register(models.ImportFormatJSONLines, NewJSONLines,
+		[]string{"application/x-ndjson", "application/jsonl", "application/x-jsonlines"}, []string{".jsonl", ".ndjson"})
+	Register(models.ImportFormatXLSX, NewXLSX,
+		[]string{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}, []string{".xlsx"})
+	Register(models.ImportFormatVCard, NewVCard,
+		[]string{"text/vcard", "text/x-vcard", "text/directory"}, []string{".vcf", ".vcard"})
+}
+
+// Register adds the parser of the format, the format is detected by its content types and extensions.
+// A format registered again replaces the previous parser.
+func Register(f models.ImportFormat, parser Parser, contentTypes, extensions []string) {
+	if _, ok := formats[f]; !ok {
+		order = append(order, f)
+	}
+	formats[f] = format{
+		parser:       parser,
+		contentTypes: contentTypes,
+		extensions:   extensions,
+	}
+}
+
+// Supports reports whether the format has a parser.
+func Supports(f models.ImportFormat) bool {
+	_, ok := formats[f]
+	return ok
+}
+
+// Detect returns the format of the file by its extension or, when the extension is unknown, by its content type,
+// clients often send a generic content type, e.g. text/plain for any text file.
+// The file is CSV when neither is known.
+func Detect(contentType, fileName string) models.ImportFormat {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	if extension != "" {
+		for _, name := range order {
+			for _, e := range formats[name].extensions {
+				if e == extension {
+					return name
+				}
+			}
+		}
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		for _, name := range order {
+			for _, t := range formats[name].contentTypes {
+				if t == mediaType {
+					return name
+				}
+			}
+		}
+	}
+	return models.ImportFormatCSV
+}
+
+// New returns the reader of the file of the format.
+func New(f models.ImportFormat, r io.Reader) (Reader, error) {
+	format, ok := formats[f]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", f)
+	}
+	return format.parser(r)
+}
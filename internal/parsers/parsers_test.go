@@ -0,0 +1,99 @@
+package parsers
+
+import (
+	"errors"
+	"io"
+	"projects/emergency-messages/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readAll returns the rows of the reader and the lines of the rows which cannot be parsed.
+func readAll(t *testing.T, r Reader) ([]Row, map[int]string) {
+	var rows []Row
+	rowErrors := make(map[int]string)
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, rowErrors
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			rowErrors[rowErr.Line] = rowErr.Error()
+			continue
+		}
+		if !assert.NoError(t, err) {
+			return rows, rowErrors
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		fileName    string
+		want        models.ImportFormat
+	}{
+		{name: "when extension is known then it wins", contentType: "application/x-ndjson", fileName: "receivers.csv", want: models.ImportFormatCSV},
+		{name: "when content type is text/plain then extension", contentType: "text/plain", fileName: "receivers.jsonl", want: models.ImportFormatJSONLines},
+		{name: "when extension is missing then content type", contentType: "application/x-ndjson", fileName: "receivers", want: models.ImportFormatJSONLines},
+		{name: "when content type has parameters then they are ignored", contentType: "text/vcard; charset=utf-8", want: models.ImportFormatVCard},
+		{name: "when content type is unknown then extension", contentType: "application/octet-stream", fileName: "Registry.XLSX", want: models.ImportFormatXLSX},
+		{name: "when extension is vcf then vcard", fileName: "contacts.vcf", want: models.ImportFormatVCard},
+		{name: "when nothing is known then csv", fileName: "receivers", want: models.ImportFormatCSV},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Detect(tt.contentType, tt.fileName))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("when format is unknown then error", func(t *testing.T) {
+		_, err := New("pdf", strings.NewReader(""))
+		assert.Error(t, err)
+		assert.False(t, Supports("pdf"))
+	})
+	t.Run("when format is registered then it is supported", func(t *testing.T) {
+		assert.True(t, Supports(models.ImportFormatCSV))
+		assert.True(t, Supports(models.ImportFormatJSONLines))
+		assert.True(t, Supports(models.ImportFormatXLSX))
+		assert.True(t, Supports(models.ImportFormatVCard))
+	})
+}
+
+func TestCSV(t *testing.T) {
+	t.Run("when rows are read then values are keyed by header", func(t *testing.T) {
+		data := "\ufeff firstName ;City;City\nIldar;Kazan;Moscow\nBoris\n\"Albert;Kazan\nRobert;Kazan;Moscow;Ufa\n"
+		r, err := NewCSV(strings.NewReader(data))
+		assert.NoError(t, err)
+
+		rows, rowErrors := readAll(t, r)
+		assert.Equal(t, []Row{
+			{Line: 2, Values: map[string]string{"firstName": "Ildar", "City": "Kazan"}},
+			{Line: 3, Values: map[string]string{"firstName": "Boris"}},
+		}, rows)
+		assert.Len(t, rowErrors, 1)
+		assert.Contains(t, rowErrors[4], "quote")
+	})
+	t.Run("when cell is escaped formula then quote is removed", func(t *testing.T) {
+		r, err := NewCSV(strings.NewReader("MobilePhone;Note\n'+79001234567;'quoted\n"))
+		assert.NoError(t, err)
+
+		rows, _ := readAll(t, r)
+		assert.Equal(t, []Row{{Line: 2, Values: map[string]string{"MobilePhone": "+79001234567", "Note": "'quoted"}}}, rows)
+	})
+	t.Run("when file is empty then no rows", func(t *testing.T) {
+		r, err := NewCSV(strings.NewReader(""))
+		assert.NoError(t, err)
+
+		rows, rowErrors := readAll(t, r)
+		assert.Empty(t, rows)
+		assert.Empty(t, rowErrors)
+	})
+}
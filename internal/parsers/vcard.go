@@ -0,0 +1,289 @@
+package parsers
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"mime/quotedprintable"
+	"projects/emergency-messages/internal/models"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+type vcardReader struct {
+	scanner *bufio.Scanner
+	line    int
+	// next is the line read ahead to unfold the lines
+	next     string
+	nextLine int
+	hasNext  bool
+}
+
+// vcardProperty is a content line of a vCard, e.g. TEL;TYPE=cell:+79001234567
+type vcardProperty struct {
+	name   string
+	params []string
+	value  string
+}
+
+// NewVCard returns the reader of a file of vCards 2.1, 3.0 or 4.0, a vCard is a row in the receivers layout:
+// N or FN are the names, the first mobile TEL or the first TEL is the phone, the first EMAIL is the email,
+// the locality of the first ADR is the city, LANG is the language and X-TELEGRAM is the telegram chat.
+// The values encoded as ENCODING=QUOTED-PRINTABLE are decoded and the values in another CHARSET are converted to UTF-8.
+// The line of a row is the line of its BEGIN:VCARD.
+func NewVCard(r io.Reader) (Reader, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	return &vcardReader{scanner: scanner}, nil
+}
+
+func (r *vcardReader) Read() (Row, error) {
+	begin := 0
+	var properties []vcardProperty
+	for {
+		text, line, err := r.readLine()
+		if errors.Is(err, io.EOF) && begin > 0 {
+			return Row{}, &RowError{Line: begin, Err: errors.New("vCard has no END:VCARD")}
+		}
+		if err != nil {
+			return Row{}, err
+		}
+
+		property, ok := parseVCardProperty(text)
+		if !ok {
+			continue
+		}
+		switch {
+		case property.name == "BEGIN" && strings.EqualFold(property.value, "VCARD"):
+			begin, properties = line, nil
+		case begin == 0:
+			// the lines out of vCards are skipped
+		case property.name == "END" && strings.EqualFold(property.value, "VCARD"):
+			return Row{Line: begin, Values: vcardValues(properties)}, nil
+		default:
+			properties = append(properties, property)
+		}
+	}
+}
+
+// readLine returns the next unfolded line, a line starting with a space or a tab continues the previous one,
+// a quoted-printable value ending with = continues on the next line.
+func (r *vcardReader) readLine() (string, int, error) {
+	if !r.hasNext {
+		if !r.scan() {
+			return "", 0, r.err()
+		}
+	}
+	text, line := r.next, r.nextLine
+	r.hasNext = false
+	for r.scan() {
+		if !strings.HasPrefix(r.next, " ") && !strings.HasPrefix(r.next, "\t") {
+			break
+		}
+		text += r.next[1:]
+		r.hasNext = false
+	}
+	if property, ok := parseVCardProperty(text); ok && property.isQuotedPrintable() {
+		for strings.HasSuffix(text, "=") && r.hasNext {
+			text = text[:len(text)-1] + r.next
+			r.hasNext = false
+			r.scan()
+		}
+	}
+	return text, line, nil
+}
+
+func (r *vcardReader) scan() bool {
+	if !r.scanner.Scan() {
+		return false
+	}
+	r.line++
+	r.next, r.nextLine, r.hasNext = strings.TrimRight(r.scanner.Text(), "\r"), r.line, true
+	return true
+}
+
+func (r *vcardReader) err() error {
+	if err := r.scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// parseVCardProperty splits a content line into the name without its group, the parameters and the value.
+func parseVCardProperty(text string) (vcardProperty, bool) {
+	head, value, ok := strings.Cut(text, ":")
+	if !ok {
+		return vcardProperty{}, false
+	}
+	parts := strings.Split(head, ";")
+	name := parts[0]
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return vcardProperty{
+		name:   strings.ToUpper(strings.TrimSpace(name)),
+		params: parts[1:],
+		value:  value,
+	}, true
+}
+
+// param returns the value of the parameter, e.g. cell of TYPE=cell.
+func (p vcardProperty) param(name string) string {
+	for _, param := range p.params {
+		key, value, ok := strings.Cut(param, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), name) {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return ""
+}
+
+// isQuotedPrintable reports whether the value is quoted-printable, vCard 2.1 allows the encoding without ENCODING=.
+func (p vcardProperty) isQuotedPrintable() bool {
+	if strings.EqualFold(p.param("ENCODING"), "QUOTED-PRINTABLE") {
+		return true
+	}
+	for _, param := range p.params {
+		if strings.EqualFold(strings.TrimSpace(param), "QUOTED-PRINTABLE") {
+			return true
+		}
+	}
+	return false
+}
+
+// decode returns the value decoded from quoted-printable and converted from its charset to UTF-8,
+// a value which cannot be decoded is kept as it is.
+func (p vcardProperty) decode() string {
+	value := p.value
+	if p.isQuotedPrintable() {
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(value)))
+		if err != nil {
+			return value
+		}
+		value = string(decoded)
+	}
+	if charset := p.param("CHARSET"); charset != "" && !strings.EqualFold(charset, "UTF-8") {
+		encoding, err := htmlindex.Get(charset)
+		if err != nil {
+			return value
+		}
+		if converted, err := encoding.NewDecoder().String(value); err == nil {
+			value = converted
+		}
+	}
+	return value
+}
+
+// isMobile reports whether the property has the type cell, e.g. TYPE=cell,voice or CELL of vCard 2.1.
+func (p vcardProperty) isMobile() bool {
+	for _, param := range p.params {
+		param = strings.ToUpper(param)
+		param = strings.TrimPrefix(param, "TYPE=")
+		for _, t := range strings.Split(strings.Trim(param, `"`), ",") {
+			if t == "CELL" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func vcardValues(properties []vcardProperty) map[string]string {
+	values := make(map[string]string)
+	set := func(column, value string) {
+		if _, ok := values[column]; !ok && value != "" {
+			values[column] = value
+		}
+	}
+
+	var fullName string
+	for i := range properties {
+		properties[i].value = properties[i].decode()
+	}
+	for _, p := range properties {
+		switch p.name {
+		case "N":
+			components := splitVCardValue(p.value)
+			if len(components) > 1 {
+				set(models.ReceiverColumnFirstName, components[1])
+			}
+			set(models.ReceiverColumnLastName, components[0])
+		case "FN":
+			fullName = unescapeVCardValue(p.value)
+		case "TEL":
+			if p.isMobile() {
+				set(models.ReceiverColumnMobilePhone, vcardPhone(p.value))
+			}
+		case "EMAIL":
+			set(models.ReceiverColumnEmail, unescapeVCardValue(p.value))
+		case "ADR":
+			if components := splitVCardValue(p.value); len(components) > 3 {
+				set(models.ReceiverColumnCity, components[3])
+			}
+		case "LANG":
+			set(models.ReceiverColumnLanguage, unescapeVCardValue(p.value))
+		case "X-TELEGRAM":
+			set(models.ReceiverColumnTelegram, unescapeVCardValue(p.value))
+		}
+	}
+	// a mobile phone is preferred
+	for _, p := range properties {
+		if p.name == "TEL" {
+			set(models.ReceiverColumnMobilePhone, vcardPhone(p.value))
+		}
+	}
+	if fullName != "" && values[models.ReceiverColumnFirstName] == "" && values[models.ReceiverColumnLastName] == "" {
+		first, last, _ := strings.Cut(strings.TrimSpace(fullName), " ")
+		set(models.ReceiverColumnFirstName, first)
+		set(models.ReceiverColumnLastName, strings.TrimSpace(last))
+	}
+	return values
+}
+
+// vcardPhone returns the number of a TEL, vCard 4.0 writes it as a URI, e.g. tel:+79001234567.
+func vcardPhone(value string) string {
+	value = unescapeVCardValue(value)
+	if len(value) > 4 && strings.EqualFold(value[:4], "tel:") {
+		value = value[4:]
+	}
+	return value
+}
+
+// splitVCardValue splits a structured value by the semicolons which are not escaped and unescapes its components.
+func splitVCardValue(value string) []string {
+	var components []string
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			b.WriteByte(value[i])
+			b.WriteByte(value[i+1])
+			i++
+		case value[i] == ';':
+			components = append(components, unescapeVCardValue(b.String()))
+			b.Reset()
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return append(components, unescapeVCardValue(b.String()))
+}
+
+func unescapeVCardValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
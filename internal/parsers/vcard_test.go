@@ -0,0 +1,90 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+
+	"projects/emergency-messages/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVCard(t *testing.T) {
+	t.Run("when vcards are read then values are in receivers layout", func(t *testing.T) {
+		data := strings.Join([]string{
+			"BEGIN:VCARD",
+			"VERSION:3.0",
+			"N:Sabirov;Ildar;;;",
+			"FN:Ildar Sabirov",
+			"TEL;TYPE=work,voice:+78432000000",
+			"item1.TEL;TYPE=cell:+7900123",
+			" 4567",
+			"EMAIL;TYPE=internet:ildar@example.com",
+			"ADR;TYPE=home:;;Baumana 1;Kazan;Tatarstan;420000;Russia",
+			"LANG:tt",
+			"X-TELEGRAM:123456789",
+			"END:VCARD",
+			"",
+			"BEGIN:VCARD",
+			"VERSION:4.0",
+			"FN:Boris Ivanov",
+			"TEL;VALUE=uri:tel:+7312787312",
+			"ADR:;;;Saint\\, Petersburg;;;",
+			"END:VCARD",
+		}, "\r\n")
+		r, err := NewVCard(strings.NewReader(data))
+		assert.NoError(t, err)
+
+		rows, rowErrors := readAll(t, r)
+		assert.Empty(t, rowErrors)
+		assert.Equal(t, []Row{
+			{Line: 1, Values: map[string]string{
+				models.ReceiverColumnFirstName:   "Ildar",
+				models.ReceiverColumnLastName:    "Sabirov",
+				models.ReceiverColumnMobilePhone: "+79001234567",
+				models.ReceiverColumnEmail:       "ildar@example.com",
+				models.ReceiverColumnCity:        "Kazan",
+				models.ReceiverColumnLanguage:    "tt",
+				models.ReceiverColumnTelegram:    "123456789",
+			}},
+			{Line: 14, Values: map[string]string{
+				models.ReceiverColumnFirstName:   "Boris",
+				models.ReceiverColumnLastName:    "Ivanov",
+				models.ReceiverColumnMobilePhone: "+7312787312",
+				models.ReceiverColumnCity:        "Saint, Petersburg",
+			}},
+		}, rows)
+	})
+	t.Run("when values are quoted-printable then they are decoded from their charset", func(t *testing.T) {
+		data := strings.Join([]string{
+			"BEGIN:VCARD",
+			"VERSION:2.1",
+			"N;CHARSET=WINDOWS-1251;ENCODING=QUOTED-PRINTABLE:=D1=E0=E1=E8=F0=EE=E2;=C8=EB=FC=E4=E0=F0;;;",
+			"TEL;CELL:+79001234567",
+			"ADR;HOME;CHARSET=UTF-8;QUOTED-PRINTABLE:;;;=D0=9A=D0=B0=D0=B7=",
+			"=D0=B0=D0=BD=D1=8C;;;",
+			"END:VCARD",
+		}, "\r\n")
+		r, err := NewVCard(strings.NewReader(data))
+		assert.NoError(t, err)
+
+		rows, rowErrors := readAll(t, r)
+		assert.Empty(t, rowErrors)
+		assert.Equal(t, []Row{
+			{Line: 1, Values: map[string]string{
+				models.ReceiverColumnFirstName:   "Ильдар",
+				models.ReceiverColumnLastName:    "Сабиров",
+				models.ReceiverColumnMobilePhone: "+79001234567",
+				models.ReceiverColumnCity:        "Казань",
+			}},
+		}, rows)
+	})
+	t.Run("when vcard has no end then error", func(t *testing.T) {
+		r, err := NewVCard(strings.NewReader("BEGIN:VCARD\nFN:Ildar Sabirov\n"))
+		assert.NoError(t, err)
+
+		rows, rowErrors := readAll(t, r)
+		assert.Empty(t, rows)
+		assert.Equal(t, map[int]string{1: "vCard has no END:VCARD"}, rowErrors)
+	})
+}
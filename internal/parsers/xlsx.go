@@ -0,0 +1,279 @@
+package parsers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxXLSXSize is the largest workbook which is read, a workbook is read in memory as a zip archive
+const maxXLSXSize = 64 << 20
+
+// maxXLSXPartSize is the largest decompressed part which is read in memory, e.g. the shared strings,
+// a small archive may decompress into a huge part
+const maxXLSXPartSize = 128 << 20
+
+const (
+	xlsxWorkbookPart      = "xl/workbook.xml"
+	xlsxWorkbookRelsPart  = "xl/_rels/workbook.xml.rels"
+	xlsxSharedStringsPart = "xl/sharedStrings.xml"
+	xlsxFirstSheetPart    = "xl/worksheets/sheet1.xml"
+)
+
+type xlsxReader struct {
+	decoder *xml.Decoder
+	strings []string
+	header  []string
+	line    int
+}
+
+// xlsxText is a text of a shared string or an inline string, a rich text is split into runs
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxRow struct {
+	R     int `xml:"r,attr"`
+	Cells []struct {
+		R      string    `xml:"r,attr"`
+		T      string    `xml:"t,attr"`
+		V      string    `xml:"v"`
+		Inline *xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+// NewXLSX returns the reader of the first sheet of an Excel workbook, the first row of the sheet is the header.
+// Empty rows are skipped.
+func NewXLSX(r io.Reader) (Reader, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxXLSXSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxXLSXSize {
+		return nil, fmt.Errorf("workbook is larger than %d bytes", maxXLSXSize)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("reading workbook: %w", err)
+	}
+
+	reader := &xlsxReader{}
+	if reader.strings, err = xlsxSharedStrings(archive); err != nil {
+		return nil, err
+	}
+	sheet, err := archive.Open(xlsxSheet(archive))
+	if err != nil {
+		return nil, fmt.Errorf("opening sheet: %w", err)
+	}
+	// the sheet is read from the memory, it is not closed
+	reader.decoder = xml.NewDecoder(sheet)
+
+	row, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return reader, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	reader.header = make([]string, 0, len(row.Values))
+	for i := 0; i < len(row.Values); i++ {
+		reader.header = append(reader.header, strings.TrimSpace(row.Values[strconv.Itoa(i)]))
+	}
+	return reader, nil
+}
+
+// Read returns the next row, the values are keyed by the positions of the cells while the header is read.
+func (r *xlsxReader) Read() (Row, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return Row{}, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err = r.decoder.DecodeElement(&row, &start); err != nil {
+			return Row{}, err
+		}
+		// the number of a row is optional
+		if row.R == 0 {
+			row.R = r.line + 1
+		}
+		r.line = row.R
+		cells := make(map[int]string, len(row.Cells))
+		last := -1
+		for i, c := range row.Cells {
+			column := i
+			if c.R != "" {
+				if column, err = xlsxColumn(c.R); err != nil {
+					return Row{}, &RowError{Line: row.R, Err: err}
+				}
+			}
+			value, err := r.value(c.T, c.V, c.Inline)
+			if err != nil {
+				return Row{}, &RowError{Line: row.R, Err: fmt.Errorf("cell %s: %w", c.R, err)}
+			}
+			if value != "" {
+				cells[column] = value
+				if column > last {
+					last = column
+				}
+			}
+		}
+		if len(cells) == 0 {
+			continue
+		}
+
+		values := make(map[string]string, last+1)
+		if r.header == nil {
+			for i := 0; i <= last; i++ {
+				values[strconv.Itoa(i)] = cells[i]
+			}
+			return Row{Line: row.R, Values: values}, nil
+		}
+		for i, h := range r.header {
+			if _, ok := values[h]; !ok {
+				values[h] = cells[i]
+			}
+		}
+		if last >= len(r.header) {
+			return Row{}, &RowError{Line: row.R, Err: fmt.Errorf("expected %d cells, have %d cells", len(r.header), last+1)}
+		}
+		return Row{Line: row.R, Values: values}, nil
+	}
+}
+
+// value returns the text of a cell by its type.
+func (r *xlsxReader) value(cellType, value string, inline *xlsxText) (string, error) {
+	switch cellType {
+	case "s":
+		i, err := strconv.Atoi(value)
+		if err != nil || i < 0 || i >= len(r.strings) {
+			return "", fmt.Errorf("invalid shared string %q", value)
+		}
+		return r.strings[i], nil
+	case "inlineStr":
+		if inline == nil {
+			return "", nil
+		}
+		return inline.String(), nil
+	case "b":
+		return strconv.FormatBool(value == "1"), nil
+	default:
+		return value, nil
+	}
+}
+
+// xlsxSharedStrings returns the strings the cells refer to, a workbook without strings has no such part.
+func xlsxSharedStrings(archive *zip.Reader) ([]string, error) {
+	file, err := archive.Open(xlsxSharedStringsPart)
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	var sst struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if err = xml.NewDecoder(newXLSXPartReader(file)).Decode(&sst); err != nil {
+		return nil, fmt.Errorf("reading shared strings: %w", err)
+	}
+	result := make([]string, 0, len(sst.Items))
+	for _, item := range sst.Items {
+		result = append(result, item.String())
+	}
+	return result, nil
+}
+
+// xlsxSheet returns the path of the first sheet of the workbook.
+func xlsxSheet(archive *zip.Reader) string {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if xlsxDecode(archive, xlsxWorkbookPart, &workbook) != nil || len(workbook.Sheets) == 0 ||
+		xlsxDecode(archive, xlsxWorkbookRelsPart, &rels) != nil {
+		return xlsxFirstSheetPart
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join(path.Dir(xlsxWorkbookPart), rel.Target)
+	}
+	return xlsxFirstSheetPart
+}
+
+func xlsxDecode(archive *zip.Reader, name string, v any) error {
+	file, err := archive.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return xml.NewDecoder(newXLSXPartReader(file)).Decode(v)
+}
+
+// xlsxPartReader reads a part of the workbook up to maxXLSXPartSize bytes and fails after it.
+type xlsxPartReader struct {
+	r *io.LimitedReader
+}
+
+func newXLSXPartReader(r io.Reader) io.Reader {
+	return &xlsxPartReader{r: &io.LimitedReader{R: r, N: maxXLSXPartSize + 1}}
+}
+
+func (r *xlsxPartReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if r.r.N == 0 {
+		return n, fmt.Errorf("part is larger than %d bytes", maxXLSXPartSize)
+	}
+	return n, err
+}
+
+// xlsxColumn returns the position of the column of a cell reference, e.g. 27 for AB12.
+func xlsxColumn(reference string) (int, error) {
+	column := 0
+	for _, c := range reference {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		column = column*26 + int(c-'A'+1)
+	}
+	if column == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", reference)
+	}
+	return column - 1, nil
+}
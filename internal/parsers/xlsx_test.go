@@ -0,0 +1,80 @@
+package parsers
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newXLSX returns a workbook of the parts.
+func newXLSX(t *testing.T, parts map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, archive.Close())
+	return &buf
+}
+
+func TestXLSX(t *testing.T) {
+	t.Run("when sheet is read then cells are keyed by header", func(t *testing.T) {
+		data := newXLSX(t, map[string]string{
+			"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Receivers" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="styles" Target="styles.xml"/>
+<Relationship Id="rId3" Type="worksheet" Target="worksheets/receivers.xml"/></Relationships>`,
+			"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Name</t></si><si><t>City</t></si><si><t>Phone</t></si><si><r><t>Ild</t></r><r><t>ar</t></r></si><si><t>Kazan</t></si></sst>`,
+			"xl/worksheets/receivers.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="inlineStr"><is><t>Active</t></is></c></row>
+<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2" t="s"><v>4</v></c><c r="C2"><v>79001234567</v></c><c r="D2" t="b"><v>1</v></c></row>
+<row r="3"/>
+<row r="4"><c r="C4"><v>79007654321</v></c></row>
+<row r="5"><c r="F5"><v>1</v></c></row>
+</sheetData></worksheet>`,
+		})
+		r, err := NewXLSX(data)
+		assert.NoError(t, err)
+
+		rows, rowErrors := readAll(t, r)
+		assert.Equal(t, []Row{
+			{Line: 2, Values: map[string]string{"Name": "Ildar", "City": "Kazan", "Phone": "79001234567", "Active": "true"}},
+			{Line: 4, Values: map[string]string{"Name": "", "City": "", "Phone": "79007654321", "Active": ""}},
+		}, rows)
+		assert.Equal(t, map[int]string{5: "expected 4 cells, have 6 cells"}, rowErrors)
+	})
+	t.Run("when workbook has no relationships then first sheet", func(t *testing.T) {
+		data := newXLSX(t, map[string]string{
+			"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+<row><c t="inlineStr"><is><t>Name</t></is></c></row>
+<row><c t="inlineStr"><is><t>Ildar</t></is></c></row>
+</sheetData></worksheet>`,
+		})
+		r, err := NewXLSX(data)
+		assert.NoError(t, err)
+
+		rows, _ := readAll(t, r)
+		assert.Equal(t, []Row{{Line: 2, Values: map[string]string{"Name": "Ildar"}}}, rows)
+	})
+	t.Run("when file is not a workbook then error", func(t *testing.T) {
+		_, err := NewXLSX(bytes.NewBufferString("firstName;secondName"))
+		assert.Error(t, err)
+	})
+}
+
+func Test_xlsxColumn(t *testing.T) {
+	for reference, want := range map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "AB12": 27} {
+		column, err := xlsxColumn(reference)
+		assert.NoError(t, err)
+		assert.Equal(t, want, column, reference)
+	}
+	_, err := xlsxColumn("12")
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"os"
 	"projects/emergency-messages/internal/models"
+	"strings"
 
 	"github.com/mailgun/mailgun-go"
 )
@@ -25,21 +26,34 @@ func NewEmailMailgClient(log *slog.Logger) *ClientMailg {
 	}
 }
 
+// ReportsDelivery reports whether the events of Mailgun are received,
+// every event is rejected without MAILGUN_WEBHOOK_SIGNING_KEY.
+func (c ClientMailg) ReportsDelivery() bool {
+	return os.Getenv("MAILGUN_WEBHOOK_SIGNING_KEY") != ""
+}
+
+// message composes the email, the HTML body is sent as an alternative to the plain text.
 func (c ClientMailg) message(newMessage models.MessageSend) *mailgun.Message {
-	return c.mg.NewMessage(
+	message := c.mg.NewMessage(
 		os.Getenv("MAILGUN_FROM"),
 		newMessage.Subject,
 		newMessage.Text,
 		newMessage.Value,
 	)
+	if newMessage.HTML != "" {
+		message.SetHtml(newMessage.HTML)
+	}
+	return message
 }
 
-func (c ClientMailg) Send(newMessage models.MessageSend) error {
+// Send sends the email, it returns its Message-Id without the angle brackets,
+// the same as the one in the events of Mailgun.
+func (c ClientMailg) Send(newMessage models.MessageSend) (string, error) {
 	message := c.message(newMessage)
-	_, _, err := c.mg.Send(message)
+	_, id, err := c.mg.Send(message)
 	if err != nil {
 		c.log.Error("cannot sending message:", err)
-		return err
+		return "", err
 	}
-	return err
+	return strings.Trim(id, "<>"), nil
 }
@@ -0,0 +1,49 @@
+package mail_gun
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// maxWebhookAge is the greatest difference between the timestamp of a webhook request and the current time,
+// an older request is rejected, so a captured request cannot be replayed later
+const maxWebhookAge = 5 * time.Minute
+
+// WebhookValidator checks the signature of the webhooks called by Mailgun,
+// the signature is the HMAC-SHA256 of the timestamp and the token with the webhook signing key.
+// Without the signing key every request is rejected, so a webhook is not opened by a missing configuration.
+// A replay within the age of a request is stopped by its token, the delivery service processes a token once.
+type WebhookValidator struct {
+	signingKey []byte
+}
+
+func NewWebhookValidator(signingKey string) *WebhookValidator {
+	return &WebhookValidator{
+		signingKey: []byte(signingKey),
+	}
+}
+
+// Validate reports whether the signature is the signature of the timestamp and the token of the request
+// and the timestamp is recent.
+func (v *WebhookValidator) Validate(timestamp, token, signature string) bool {
+	if len(v.signingKey) == 0 || signature == "" {
+		return false
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := time.Since(time.Unix(seconds, 0))
+	if age > maxWebhookAge || age < -maxWebhookAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, v.signingKey)
+	mac.Write([]byte(timestamp + token))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
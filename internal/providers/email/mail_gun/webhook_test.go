@@ -0,0 +1,37 @@
+package mail_gun
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookValidator_Validate(t *testing.T) {
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	sign := func(timestamp, token string) string {
+		mac := hmac.New(sha256.New, []byte("key"))
+		mac.Write([]byte(timestamp + token))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	t.Run("when signature matches then valid", func(t *testing.T) {
+		assert.True(t, NewWebhookValidator("key").Validate(timestamp, "token", sign(timestamp, "token")))
+	})
+	t.Run("when token is changed then invalid", func(t *testing.T) {
+		assert.False(t, NewWebhookValidator("key").Validate(timestamp, "other", sign(timestamp, "token")))
+	})
+	t.Run("when timestamp is old then invalid", func(t *testing.T) {
+		old := strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)
+		assert.False(t, NewWebhookValidator("key").Validate(old, "token", sign(old, "token")))
+	})
+	t.Run("when signing key is not set then invalid", func(t *testing.T) {
+		assert.False(t, NewWebhookValidator("").Validate(timestamp, "token", sign(timestamp, "token")))
+	})
+}
@@ -0,0 +1,122 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/render"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBaseURL = "https://api.telegram.org"
+	requestTimeout = 10 * time.Second
+	parseModeV2    = "MarkdownV2"
+)
+
+// ClientTelegram sends messages by the Telegram Bot API.
+// The value of a telegram contact is the chat id of the receiver.
+type ClientTelegram struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	log     *slog.Logger
+}
+
+type sendMessageRequest struct {
+	ChatID    string `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+type sendMessageResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Result      struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"result"`
+}
+
+func NewTelegramClient(log *slog.Logger) *ClientTelegram {
+	baseURL := os.Getenv("TELEGRAM_API_URL")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &ClientTelegram{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   os.Getenv("TELEGRAM_BOT_TOKEN"),
+		client:  &http.Client{Timeout: requestTimeout},
+		log:     log,
+	}
+}
+
+// Send sends the message to the chat.
+// It returns the chat id and the message id joined by a colon, the message id is unique in its chat only.
+func (c *ClientTelegram) Send(newMessage models.MessageSend) (string, error) {
+	request := sendMessageRequest{
+		ChatID: newMessage.Value,
+		Text:   c.text(newMessage),
+	}
+	if newMessage.Format == models.TextFormatMarkdown {
+		request.ParseMode = parseModeV2
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", c.baseURL, c.token)
+	resp, err := c.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		err = redactURL(err)
+		c.log.With(slog.Any("message", newMessage.ID)).
+			Error("sending telegram message", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result sendMessageResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decoding telegram response with status %d: %w", resp.StatusCode, err)
+	}
+	if !result.OK {
+		err = fmt.Errorf("telegram api error %d: %s", result.ErrorCode, result.Description)
+		c.log.With(
+			slog.Any("message", newMessage.ID),
+			slog.String("chat", newMessage.Value)).
+			Error("sending telegram message", err)
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d", newMessage.Value, result.Result.MessageID), nil
+}
+
+// redactURL removes the URL from a transport error, the URL holds the bot token
+// and the error is logged and stored with the message.
+func redactURL(err error) error {
+	var urlErr *neturl.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	return fmt.Errorf("%s telegram bot api: %w", urlErr.Op, urlErr.Err)
+}
+
+// text joins the subject and the text, because a telegram message has no subject.
+// The subject of a Markdown text is escaped and bold.
+func (c *ClientTelegram) text(newMessage models.MessageSend) string {
+	if newMessage.Subject == "" {
+		return newMessage.Text
+	}
+	if newMessage.Format == models.TextFormatMarkdown {
+		return "*" + render.EscapeMarkdown(newMessage.Subject) + "*\n\n" + newMessage.Text
+	}
+	return newMessage.Subject + "\n\n" + newMessage.Text
+}
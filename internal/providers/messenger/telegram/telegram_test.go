@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientTelegram_Send(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("when bot api accepts message then no error", func(t *testing.T) {
+		var got sendMessageRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/bottoken/sendMessage", r.URL.Path)
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+		}))
+		defer server.Close()
+
+		t.Setenv("TELEGRAM_API_URL", server.URL)
+		t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+		client := NewTelegramClient(log)
+
+		id, err := client.Send(models.MessageSend{
+			Subject: "Flood",
+			Text:    "Flood in Kazan",
+			Type:    models.ContactTypeTelegram,
+			Value:   "12345",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "12345:1", id)
+		assert.Equal(t, sendMessageRequest{ChatID: "12345", Text: "Flood\n\nFlood in Kazan"}, got)
+	})
+
+	t.Run("when text is markdown then subject is escaped and parse mode is set", func(t *testing.T) {
+		var got sendMessageRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+		}))
+		defer server.Close()
+
+		t.Setenv("TELEGRAM_API_URL", server.URL)
+		t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+		client := NewTelegramClient(log)
+
+		_, err := client.Send(models.MessageSend{
+			Subject: "Flood!",
+			Text:    "_Kazan_",
+			Format:  models.TextFormatMarkdown,
+			Value:   "12345",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, sendMessageRequest{ChatID: "12345", Text: "*Flood\\!*\n\n_Kazan_", ParseMode: "MarkdownV2"}, got)
+	})
+
+	t.Run("when bot api rejects message then error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+		}))
+		defer server.Close()
+
+		t.Setenv("TELEGRAM_API_URL", server.URL)
+		t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+		client := NewTelegramClient(log)
+
+		_, err := client.Send(models.MessageSend{Text: "Flood in Kazan", Value: "12345"})
+		assert.ErrorContains(t, err, "chat not found")
+	})
+
+	t.Run("when bot api is unreachable then error has no token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		t.Setenv("TELEGRAM_API_URL", server.URL)
+		t.Setenv("TELEGRAM_BOT_TOKEN", "110201543:secret")
+		client := NewTelegramClient(log)
+
+		_, err := client.Send(models.MessageSend{Text: "Flood in Kazan", Value: "12345"})
+		assert.Error(t, err)
+		assert.NotContains(t, err.Error(), "secret")
+		assert.Contains(t, err.Error(), "telegram bot api")
+	})
+}
@@ -0,0 +1,160 @@
+package whatsapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBaseURL = "https://graph.facebook.com/v18.0"
+	requestTimeout = 10 * time.Second
+)
+
+// ErrNoTemplate is returned when a message has no approved WhatsApp template.
+// Business initiated conversations can be opened by template messages only.
+var ErrNoTemplate = errors.New("message has no whatsapp template")
+
+// ClientWhatsApp sends template messages by the WhatsApp Cloud API.
+// The value of a whatsapp contact is the phone number of the receiver.
+type ClientWhatsApp struct {
+	baseURL       string
+	phoneNumberID string
+	token         string
+	client        *http.Client
+	log           *slog.Logger
+}
+
+type messageRequest struct {
+	MessagingProduct string          `json:"messaging_product"`
+	To               string          `json:"to"`
+	Type             string          `json:"type"`
+	Template         templateRequest `json:"template"`
+}
+
+type templateRequest struct {
+	Name       string      `json:"name"`
+	Language   language    `json:"language"`
+	Components []component `json:"components,omitempty"`
+}
+
+type language struct {
+	Code string `json:"code"`
+}
+
+type component struct {
+	Type       string      `json:"type"`
+	Parameters []parameter `json:"parameters"`
+}
+
+type parameter struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type messageResponse struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+func NewWhatsAppClient(log *slog.Logger) *ClientWhatsApp {
+	baseURL := os.Getenv("WHATSAPP_API_URL")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &ClientWhatsApp{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		phoneNumberID: os.Getenv("WHATSAPP_PHONE_NUMBER_ID"),
+		token:         os.Getenv("WHATSAPP_ACCESS_TOKEN"),
+		client:        &http.Client{Timeout: requestTimeout},
+		log:           log,
+	}
+}
+
+// Send sends the template message, it returns the WhatsApp message id.
+func (c *ClientWhatsApp) Send(newMessage models.MessageSend) (string, error) {
+	if newMessage.WhatsApp == nil {
+		c.log.With(slog.Any("message", newMessage.ID)).
+			Error("sending whatsapp message", ErrNoTemplate)
+		return "", ErrNoTemplate
+	}
+
+	body, err := json.Marshal(c.message(newMessage))
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/%s/messages", c.baseURL, c.phoneNumberID)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.log.With(slog.Any("message", newMessage.ID)).
+			Error("sending whatsapp message", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var result errorResponse
+		if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return "", fmt.Errorf("whatsapp api error with status %d", resp.StatusCode)
+		}
+		err = fmt.Errorf("whatsapp api error %d: %s", result.Error.Code, result.Error.Message)
+		c.log.With(
+			slog.Any("message", newMessage.ID),
+			slog.String("phone", newMessage.Value)).
+			Error("sending whatsapp message", err)
+		return "", err
+	}
+
+	// the message is accepted, a response without the id is not an error
+	var result messageResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil || len(result.Messages) == 0 {
+		return "", nil
+	}
+	return result.Messages[0].ID, nil
+}
+
+// message builds a template message filling the body placeholders with the template parameters.
+func (c *ClientWhatsApp) message(newMessage models.MessageSend) messageRequest {
+	template := templateRequest{
+		Name:     newMessage.WhatsApp.Name,
+		Language: language{Code: newMessage.WhatsApp.Language},
+	}
+	if len(newMessage.WhatsApp.Parameters) > 0 {
+		parameters := make([]parameter, 0, len(newMessage.WhatsApp.Parameters))
+		for _, p := range newMessage.WhatsApp.Parameters {
+			parameters = append(parameters, parameter{Type: "text", Text: p})
+		}
+		template.Components = []component{{Type: "body", Parameters: parameters}}
+	}
+
+	return messageRequest{
+		MessagingProduct: "whatsapp",
+		To:               strings.TrimPrefix(newMessage.Value, "+"),
+		Type:             "template",
+		Template:         template,
+	}
+}
@@ -0,0 +1,79 @@
+package whatsapp
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientWhatsApp_Send(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	message := models.MessageSend{
+		Text:  "Flood in Kazan",
+		Type:  models.ContactTypeWhatsApp,
+		Value: "+79001234567",
+		WhatsApp: &models.WhatsAppTemplate{
+			Name:       "flood_warning",
+			Language:   "ru",
+			Parameters: []string{"Kazan", "strong"},
+		},
+	}
+
+	t.Run("when cloud api accepts message then no error", func(t *testing.T) {
+		var got messageRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/1055/messages", r.URL.Path)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.Write([]byte(`{"messaging_product":"whatsapp","messages":[{"id":"wamid.1"}]}`))
+		}))
+		defer server.Close()
+
+		t.Setenv("WHATSAPP_API_URL", server.URL)
+		t.Setenv("WHATSAPP_PHONE_NUMBER_ID", "1055")
+		t.Setenv("WHATSAPP_ACCESS_TOKEN", "token")
+		client := NewWhatsAppClient(log)
+
+		id, err := client.Send(message)
+		assert.NoError(t, err)
+		assert.Equal(t, "wamid.1", id)
+		assert.Equal(t, "79001234567", got.To)
+		assert.Equal(t, "template", got.Type)
+		assert.Equal(t, "flood_warning", got.Template.Name)
+		assert.Equal(t, "ru", got.Template.Language.Code)
+		assert.Equal(t, []component{{
+			Type: "body",
+			Parameters: []parameter{
+				{Type: "text", Text: "Kazan"},
+				{Type: "text", Text: "strong"},
+			},
+		}}, got.Template.Components)
+	})
+
+	t.Run("when cloud api rejects message then error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"Template name does not exist in the translation","code":132001}}`))
+		}))
+		defer server.Close()
+
+		t.Setenv("WHATSAPP_API_URL", server.URL)
+		client := NewWhatsAppClient(log)
+
+		_, err := client.Send(message)
+		assert.ErrorContains(t, err, "132001")
+	})
+
+	t.Run("when message has no template then error", func(t *testing.T) {
+		client := NewWhatsAppClient(log)
+
+		_, err := client.Send(models.MessageSend{Text: "Flood in Kazan", Value: "+79001234567"})
+		assert.ErrorIs(t, err, ErrNoTemplate)
+	})
+}
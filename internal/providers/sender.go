@@ -5,11 +5,19 @@ import (
 	"log/slog"
 	"projects/emergency-messages/internal/models"
 	"projects/emergency-messages/internal/providers/email/mail_gun"
+	"projects/emergency-messages/internal/providers/messenger/telegram"
+	"projects/emergency-messages/internal/providers/messenger/whatsapp"
 	"projects/emergency-messages/internal/providers/sms/twil"
 )
 
+// Sender sends a message by a provider, it returns the ID the provider gives the message.
 type Sender interface {
-	Send(message models.MessageSend) error
+	Send(message models.MessageSend) (string, error)
+}
+
+// DeliveryReporter is a provider calling back with the delivery statuses of the sent messages.
+type DeliveryReporter interface {
+	ReportsDelivery() bool
 }
 
 type SendManager struct {
@@ -19,10 +27,14 @@ type SendManager struct {
 func New(l *slog.Logger) *SendManager {
 	mailg := mail_gun.NewEmailMailgClient(l)
 	twilSMS := twil.NewMobileTwilClient(l)
+	telegramBot := telegram.NewTelegramClient(l)
+	whatsappCloud := whatsapp.NewWhatsAppClient(l)
 
 	suppliers := map[models.ContactType]Sender{
-		models.ContactTypeEmail: mailg,
-		models.ContactTypeSMS:   twilSMS,
+		models.ContactTypeEmail:    mailg,
+		models.ContactTypeSMS:      twilSMS,
+		models.ContactTypeTelegram: telegramBot,
+		models.ContactTypeWhatsApp: whatsappCloud,
 	}
 
 	return &SendManager{
@@ -34,11 +46,18 @@ func (sm *SendManager) addProvider(provider Sender, cType models.ContactType) {
 	sm.providers[cType] = provider
 }
 
-func (sm *SendManager) Send(message models.MessageSend) error {
+func (sm *SendManager) Send(message models.MessageSend) (string, error) {
 	provider := sm.providers[message.Type]
 	if provider == nil {
-		return fmt.Errorf("couldn't find provider by type: %s", message.Type)
+		return "", fmt.Errorf("couldn't find provider by type: %s", message.Type)
 	}
 
 	return provider.Send(message)
 }
+
+// ReportsDelivery reports whether the provider of the contact type is configured to call back
+// with the delivery statuses of the sent messages.
+func (sm *SendManager) ReportsDelivery(contactType models.ContactType) bool {
+	reporter, ok := sm.providers[contactType].(DeliveryReporter)
+	return ok && reporter.ReportsDelivery()
+}
@@ -4,16 +4,23 @@ import (
 	"log/slog"
 	"os"
 	"projects/emergency-messages/internal/models"
+	"strings"
 
 	"github.com/twilio/twilio-go"
 	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
 )
 
+// statusCallbackPath is the path of the webhook Twilio reports the statuses of the sent messages to
+const statusCallbackPath = "/api/v1/webhooks/twilio/status"
+
 type ClientTwilSMS struct {
 	log *slog.Logger
 }
 
-func (c *ClientTwilSMS) Send(newMessage models.MessageSend) error {
+// Send sends the SMS, Twilio reports its statuses to the webhook of the service when WEBHOOK_BASE_URL is set
+// and the message is tracked.
+// It returns the SID of the message.
+func (c *ClientTwilSMS) Send(newMessage models.MessageSend) (string, error) {
 	client := twilio.NewRestClientWithParams(twilio.ClientParams{
 		Username: os.Getenv("MOBILE_TWIL_ACCOUNT_SID"),
 		Password: os.Getenv("MOBILE_TWIL_AUTH_TOKEN"),
@@ -23,16 +30,28 @@ func (c *ClientTwilSMS) Send(newMessage models.MessageSend) error {
 	params.SetTo(newMessage.Value)
 	params.SetFrom(os.Getenv("MOBILE_PHONE_EMERGENCY_SERVICE"))
 	params.SetBody(newMessage.Text)
+	if baseURL := os.Getenv("WEBHOOK_BASE_URL"); baseURL != "" && !newMessage.Untracked {
+		params.SetStatusCallback(strings.TrimRight(baseURL, "/") + statusCallbackPath)
+	}
 
-	_, err := client.Api.CreateMessage(params)
+	resp, err := client.Api.CreateMessage(params)
 	if err != nil {
 		c.log.With(
 			slog.Any("message", newMessage),
 			slog.String("phone", newMessage.Value)).
 			Error("sending twil message", err)
-		return err
+		return "", err
+	}
+	if resp.Sid == nil {
+		return "", nil
 	}
-	return nil
+	return *resp.Sid, nil
+}
+
+// ReportsDelivery reports whether Twilio calls back with the statuses of the sent messages,
+// the callback URL is set when WEBHOOK_BASE_URL is set.
+func (c *ClientTwilSMS) ReportsDelivery() bool {
+	return os.Getenv("WEBHOOK_BASE_URL") != ""
 }
 
 func NewMobileTwilClient(log *slog.Logger) *ClientTwilSMS {
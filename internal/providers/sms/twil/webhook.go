@@ -0,0 +1,29 @@
+package twil
+
+import (
+	"github.com/twilio/twilio-go/client"
+)
+
+// RequestValidator checks the X-Twilio-Signature of the webhooks called by Twilio.
+// Without the auth token every request is rejected, so a webhook is not opened by a missing configuration.
+type RequestValidator struct {
+	validator *client.RequestValidator
+}
+
+func NewRequestValidator(authToken string) *RequestValidator {
+	if authToken == "" {
+		return &RequestValidator{}
+	}
+	validator := client.NewRequestValidator(authToken)
+	return &RequestValidator{
+		validator: &validator,
+	}
+}
+
+// Validate reports whether the signature is the signature of the URL and the form params of the request.
+func (v *RequestValidator) Validate(url string, params map[string]string, signature string) bool {
+	if v.validator == nil || signature == "" {
+		return false
+	}
+	return v.validator.Validate(url, params, signature)
+}
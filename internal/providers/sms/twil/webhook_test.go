@@ -0,0 +1,30 @@
+package twil
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestValidator_Validate(t *testing.T) {
+	url := "https://alerts.example.com/api/v1/sms/inbound"
+	params := map[string]string{"From": "+79001234567", "Body": "STOP", "MessageSid": "SM1"}
+
+	mac := hmac.New(sha1.New, []byte("token"))
+	mac.Write([]byte(url + "BodySTOPFrom+79001234567MessageSidSM1"))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	t.Run("when signature matches then valid", func(t *testing.T) {
+		assert.True(t, NewRequestValidator("token").Validate(url, params, signature))
+	})
+	t.Run("when params are changed then invalid", func(t *testing.T) {
+		changed := map[string]string{"From": "+79007654321", "Body": "STOP", "MessageSid": "SM1"}
+		assert.False(t, NewRequestValidator("token").Validate(url, changed, signature))
+	})
+	t.Run("when auth token is not set then invalid", func(t *testing.T) {
+		assert.False(t, NewRequestValidator("").Validate(url, params, signature))
+	})
+}
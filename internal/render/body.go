@@ -0,0 +1,122 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template/parse"
+)
+
+// Format is a type representing the markup of a message body.
+type Format string
+
+const (
+	// FormatPlain is a plain text body, variables are rendered as is
+	FormatPlain Format = "plain"
+	// FormatHTML is an HTML body, variables are escaped by the context they are rendered in
+	FormatHTML Format = "html"
+	// FormatMarkdown is a Telegram MarkdownV2 body, variables are escaped not to be taken for markup
+	FormatMarkdown Format = "markdown"
+)
+
+// markdownSpecial are the characters which must be escaped in Telegram MarkdownV2
+const markdownSpecial = "_*[]()~`>#+-=|{}.!\\"
+
+// Body is a parsed message body of a channel, e.g. the SMS or the HTML email variant of a template.
+type Body struct {
+	name    string
+	format  Format
+	tree    *parse.Tree
+	execute func(w io.Writer, data any) error
+}
+
+// ParseBody parses a message body written in the format.
+func ParseBody(name, text string, format Format) (*Body, error) {
+	if format == FormatHTML {
+		t, err := htmltemplate.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, wrapParseError(name, err)
+		}
+		return &Body{name: name, format: format, tree: t.Tree, execute: t.Execute}, nil
+	}
+
+	t, err := parseText(name, text)
+	if err != nil {
+		return nil, err
+	}
+	return &Body{name: name, format: format, tree: t.Tree, execute: t.Execute}, nil
+}
+
+// CheckBody validates that the body references only the built-in variables and the declared ones.
+// The literal text of a Markdown body must be valid Telegram MarkdownV2.
+func CheckBody(name, text string, format Format, declared []string) error {
+	b, err := ParseBody(name, text, format)
+	if err != nil {
+		return err
+	}
+	if format == FormatMarkdown {
+		if err = checkMarkdown(b.tree.Root); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if unknown := b.Undeclared(declared); len(unknown) > 0 {
+		return unknownVariables(unknown)
+	}
+	return nil
+}
+
+// Format returns the markup of the body.
+func (b *Body) Format() Format {
+	return b.format
+}
+
+// Undeclared returns the variables referenced by the body which are neither built-in nor declared.
+func (b *Body) Undeclared(declared []string) []string {
+	fields := make(map[string]bool)
+	collectFields(b.tree.Root, fields)
+	return undeclared(fields, declared)
+}
+
+// Render renders the body.
+// It returns an error wrapping ErrMissingVariable when a referenced variable has no value.
+func (b *Body) Render(v Variables) (string, error) {
+	data := v.data()
+	if b.format == FormatMarkdown {
+		data = escapeData(data, EscapeMarkdown)
+	}
+
+	var buf bytes.Buffer
+	if err := b.execute(&buf, data); err != nil {
+		return "", executeError(b.name, err)
+	}
+	return buf.String(), nil
+}
+
+// EscapeMarkdown escapes the Telegram MarkdownV2 special characters of a text.
+func EscapeMarkdown(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(markdownSpecial, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func escapeData(data map[string]any, escape func(string) string) map[string]any {
+	escaped := make(map[string]any, len(data))
+	for k, v := range data {
+		switch value := v.(type) {
+		case string:
+			escaped[k] = escape(value)
+		case map[string]any:
+			escaped[k] = escapeData(value, escape)
+		default:
+			escaped[k] = v
+		}
+	}
+	return escaped
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBody_Render(t *testing.T) {
+	variables := Variables{
+		City:     "Kazan",
+		Strength: "high",
+		Receiver: Receiver{FirstName: "<b>Ivan</b>"},
+		Params:   map[string]string{"Level": "3.5"},
+	}
+
+	t.Run("when body is plain then variables are rendered as is", func(t *testing.T) {
+		body, err := ParseBody("sms", "{{.City}}: {{.Receiver.FirstName}}", FormatPlain)
+		assert.NoError(t, err)
+
+		text, err := body.Render(variables)
+		assert.NoError(t, err)
+		assert.Equal(t, "Kazan: <b>Ivan</b>", text)
+	})
+	t.Run("when body is html then variables are escaped", func(t *testing.T) {
+		body, err := ParseBody("email_html", "<p>{{.Receiver.FirstName}}, water level is {{.Level}} m</p>", FormatHTML)
+		assert.NoError(t, err)
+
+		text, err := body.Render(variables)
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>&lt;b&gt;Ivan&lt;/b&gt;, water level is 3.5 m</p>", text)
+	})
+	t.Run("when body is markdown then variables are escaped", func(t *testing.T) {
+		body, err := ParseBody("markdown", "*{{.City}}*: water level is {{.Level}} m", FormatMarkdown)
+		assert.NoError(t, err)
+
+		text, err := body.Render(variables)
+		assert.NoError(t, err)
+		assert.Equal(t, `*Kazan*: water level is 3\.5 m`, text)
+	})
+	t.Run("when param is missing then error", func(t *testing.T) {
+		body, err := ParseBody("sms", "{{.Wind}}", FormatPlain)
+		assert.NoError(t, err)
+
+		_, err = body.Render(variables)
+		assert.ErrorIs(t, err, ErrMissingVariable)
+	})
+}
+
+func TestCheckBody(t *testing.T) {
+	t.Run("when variables are declared then no error", func(t *testing.T) {
+		err := CheckBody("email_html", "<p>{{.City}} {{.Level}}</p>", FormatHTML, []string{"Level"})
+		assert.NoError(t, err)
+	})
+	t.Run("when variable is not declared then error", func(t *testing.T) {
+		err := CheckBody("sms", "{{.City}} {{.Level}}", FormatPlain, nil)
+		assert.ErrorIs(t, err, ErrUnknownVariable)
+	})
+	t.Run("when body is malformed then error", func(t *testing.T) {
+		err := CheckBody("markdown", "{{.City", FormatMarkdown, nil)
+		assert.Error(t, err)
+	})
+}
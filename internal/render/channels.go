@@ -0,0 +1,122 @@
+package render
+
+// Variants is a type representing the bodies of a template written for specific channels.
+// The SMS and the email variants are plain text, the HTML email is HTML and the messenger one is Markdown.
+type Variants struct {
+	SMS       string
+	Email     string
+	EmailHTML string
+	Markdown  string
+}
+
+// Channels is a parsed template with its channel variants.
+type Channels struct {
+	template  *Template
+	sms       *Body
+	email     *Body
+	emailHTML *Body
+	markdown  *Body
+}
+
+// Rendered is a type representing a template rendered for every channel.
+// SMS and Email fall back to the text when their variants are not set,
+// EmailHTML and Markdown are empty when their variants are not set.
+type Rendered struct {
+	Subject   string
+	Text      string
+	SMS       string
+	Email     string
+	EmailHTML string
+	Markdown  string
+}
+
+// ParseChannels parses the subject and the text of a template with its channel variants.
+func ParseChannels(subject, text string, variants Variants) (*Channels, error) {
+	t, err := Parse(subject, text)
+	if err != nil {
+		return nil, err
+	}
+	c := &Channels{template: t}
+	for _, v := range c.variants(variants) {
+		if v.text == "" {
+			continue
+		}
+		if *v.body, err = ParseBody(v.name, v.text, v.format); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// CheckChannels validates that the template and its channel variants reference
+// only the built-in variables and the declared ones.
+func CheckChannels(subject, text string, variants Variants, declared []string) error {
+	if err := Check(subject, text, declared); err != nil {
+		return err
+	}
+	c := &Channels{}
+	for _, v := range c.variants(variants) {
+		if v.text == "" {
+			continue
+		}
+		if err := CheckBody(v.name, v.text, v.format, declared); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type variant struct {
+	name   string
+	text   string
+	format Format
+	body   **Body
+}
+
+func (c *Channels) variants(v Variants) []variant {
+	return []variant{
+		{name: "sms", text: v.SMS, format: FormatPlain, body: &c.sms},
+		{name: "email", text: v.Email, format: FormatPlain, body: &c.email},
+		{name: "email_html", text: v.EmailHTML, format: FormatHTML, body: &c.emailHTML},
+		{name: "markdown", text: v.Markdown, format: FormatMarkdown, body: &c.markdown},
+	}
+}
+
+// Undeclared returns the variables referenced by the template or its variants which are neither built-in nor declared.
+func (c *Channels) Undeclared(declared []string) []string {
+	fields := c.template.fields()
+	for _, b := range []*Body{c.sms, c.email, c.emailHTML, c.markdown} {
+		if b != nil {
+			collectFields(b.tree.Root, fields)
+		}
+	}
+	return undeclared(fields, declared)
+}
+
+// Render renders the template for every channel.
+// It returns an error wrapping ErrMissingVariable when a referenced variable has no value.
+func (c *Channels) Render(v Variables) (*Rendered, error) {
+	subject, text, err := c.template.Render(v)
+	if err != nil {
+		return nil, err
+	}
+	r := &Rendered{Subject: subject, Text: text, SMS: text, Email: text}
+	bodies := []struct {
+		body   *Body
+		result *string
+	}{
+		{body: c.sms, result: &r.SMS},
+		{body: c.email, result: &r.Email},
+		{body: c.emailHTML, result: &r.EmailHTML},
+		{body: c.markdown, result: &r.Markdown},
+	}
+	for _, b := range bodies {
+		if b.body == nil {
+			continue
+		}
+		if *b.result, err = b.body.Render(v); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannels_Render(t *testing.T) {
+	variables := Variables{City: "Kazan", Strength: "high", Receiver: Receiver{FirstName: "Ivan"}}
+
+	t.Run("when variants are not set then text is used", func(t *testing.T) {
+		channels, err := ParseChannels("Flood in {{.City}}", "{{.Receiver.FirstName}}, flood in {{.City}}", Variants{})
+		assert.NoError(t, err)
+
+		rendered, err := channels.Render(variables)
+		assert.NoError(t, err)
+		assert.Equal(t, &Rendered{
+			Subject: "Flood in Kazan",
+			Text:    "Ivan, flood in Kazan",
+			SMS:     "Ivan, flood in Kazan",
+			Email:   "Ivan, flood in Kazan",
+		}, rendered)
+	})
+	t.Run("when variants are set then they are rendered", func(t *testing.T) {
+		channels, err := ParseChannels("Flood", "flood in {{.City}}", Variants{
+			SMS:       "{{.City}}: {{.Strength}}",
+			Email:     "Dear {{.Receiver.FirstName}}, flood in {{.City}}",
+			EmailHTML: "<b>{{.City}}</b>",
+			Markdown:  "*{{.City}}*",
+		})
+		assert.NoError(t, err)
+
+		rendered, err := channels.Render(variables)
+		assert.NoError(t, err)
+		assert.Equal(t, "Kazan: high", rendered.SMS)
+		assert.Equal(t, "Dear Ivan, flood in Kazan", rendered.Email)
+		assert.Equal(t, "<b>Kazan</b>", rendered.EmailHTML)
+		assert.Equal(t, "*Kazan*", rendered.Markdown)
+	})
+	t.Run("when variant references an undeclared variable then it is reported", func(t *testing.T) {
+		channels, err := ParseChannels("Flood", "flood", Variants{SMS: "{{.Level}}"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Level"}, channels.Undeclared(nil))
+		assert.ErrorIs(t, CheckChannels("Flood", "flood", Variants{SMS: "{{.Level}}"}, nil), ErrUnknownVariable)
+	})
+}
@@ -0,0 +1,157 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template/parse"
+)
+
+// markdownPlaceholder stands for a rendered variable when the markup of a body is checked,
+// the variables are escaped when they are rendered
+const markdownPlaceholder = "x"
+
+// markdownEntities are the characters opening and closing a Telegram MarkdownV2 entity, e.g. *bold* or ~strike~
+const markdownEntities = "*_~"
+
+// markdownReserved are the characters which are not markup and must always be escaped in Telegram MarkdownV2
+const markdownReserved = "#+-=.!{}"
+
+// checkMarkdown validates the literal text of a Telegram MarkdownV2 body, so Telegram does not reject the message.
+// The reserved characters must be escaped, entities, links and code must be closed.
+func checkMarkdown(root *parse.ListNode) error {
+	var b strings.Builder
+	writeMarkdownSkeleton(root, &b)
+	return validateMarkdown(b.String())
+}
+
+// writeMarkdownSkeleton writes the literal text of the body with a placeholder for every action.
+func writeMarkdownSkeleton(node parse.Node, b *strings.Builder) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			writeMarkdownSkeleton(child, b)
+		}
+	case *parse.TextNode:
+		b.Write(n.Text)
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			b.WriteString(markdownPlaceholder)
+		}
+	case *parse.IfNode:
+		writeMarkdownBranch(&n.BranchNode, b)
+	case *parse.RangeNode:
+		writeMarkdownBranch(&n.BranchNode, b)
+	case *parse.WithNode:
+		writeMarkdownBranch(&n.BranchNode, b)
+	}
+}
+
+// writeMarkdownBranch writes both lists of a branch, an entity opened in a branch must be closed in it.
+func writeMarkdownBranch(n *parse.BranchNode, b *strings.Builder) {
+	writeMarkdownSkeleton(n.List, b)
+	writeMarkdownSkeleton(n.ElseList, b)
+}
+
+// validateMarkdown validates the text as Telegram MarkdownV2.
+func validateMarkdown(text string) error {
+	runes := []rune(text)
+	open := make(map[rune]bool)
+	link := false
+	lineStart := true
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		atLineStart := lineStart
+		lineStart = r == '\n'
+
+		switch {
+		case r == '\\':
+			i++
+		case r == '`':
+			end, err := markdownCodeEnd(runes, i)
+			if err != nil {
+				return err
+			}
+			i = end
+		case strings.ContainsRune(markdownEntities, r):
+			open[r] = !open[r]
+		case r == '|':
+			if i+1 >= len(runes) || runes[i+1] != '|' {
+				return markdownError(r)
+			}
+			open[r] = !open[r]
+			i++
+		case r == '[':
+			if link {
+				return markdownError(r)
+			}
+			link = true
+		case r == ']':
+			if !link {
+				return markdownError(r)
+			}
+			link = false
+			if i+1 < len(runes) && runes[i+1] == '(' {
+				end, err := markdownURLEnd(runes, i+1)
+				if err != nil {
+					return err
+				}
+				i = end
+			}
+		case r == '>':
+			if !atLineStart {
+				return markdownError(r)
+			}
+		case r == '(' || r == ')' || strings.ContainsRune(markdownReserved, r):
+			return markdownError(r)
+		}
+	}
+
+	for _, r := range markdownEntities + "|" {
+		if open[r] {
+			return fmt.Errorf("%q is not closed", r)
+		}
+	}
+	if link {
+		return errors.New("link is not closed")
+	}
+	return nil
+}
+
+// markdownCodeEnd returns the position of the end of the inline code or the code block starting at the position.
+func markdownCodeEnd(runes []rune, start int) (int, error) {
+	fence := "`"
+	if start+2 < len(runes) && string(runes[start:start+3]) == "```" {
+		fence = "```"
+	}
+	for i := start + len(fence); i < len(runes); i++ {
+		if runes[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(string(runes[i:]), fence) {
+			return i + len(fence) - 1, nil
+		}
+	}
+	return 0, errors.New("code is not closed")
+}
+
+// markdownURLEnd returns the position of the closing parenthesis of the link URL starting at the position.
+func markdownURLEnd(runes []rune, start int) (int, error) {
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case ')':
+			return i, nil
+		}
+	}
+	return 0, errors.New("link URL is not closed")
+}
+
+func markdownError(r rune) error {
+	return fmt.Errorf("%q must be escaped as \\%c", r, r)
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBody_markdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{name: "when markup is valid then no error", text: "*Flood* in {{.City}}\\! _Level_ {{.Strength}}"},
+		{name: "when link and code are closed then no error", text: "[map](https://example.com/map?city=kazan) `a.b-c`\n>quote"},
+		{name: "when variable is in an entity then no error", text: "*{{.City}}* ||{{.Strength}}||"},
+		{name: "when dot is not escaped then error", text: "Flood in {{.City}}.", wantErr: `'.' must be escaped`},
+		{name: "when parenthesis is not escaped then error", text: "Level (m): {{.Strength}}", wantErr: `'(' must be escaped`},
+		{name: "when dash is not escaped then error", text: "{{.City}} - flood", wantErr: `'-' must be escaped`},
+		{name: "when entity is not closed then error", text: "*Flood in {{.City}}", wantErr: `'*' is not closed`},
+		{name: "when code is not closed then error", text: "`{{.City}}", wantErr: "code is not closed"},
+		{name: "when quote is not at line start then error", text: "a > b", wantErr: `'>' must be escaped`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckBody("markdown", tt.text, FormatMarkdown, nil)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("when body is plain then markup is not checked", func(t *testing.T) {
+		assert.NoError(t, CheckBody("sms", "Flood (m).", FormatPlain, nil))
+	})
+}
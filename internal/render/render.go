@@ -0,0 +1,381 @@
+// Package render
+// Implements rendering of message templates with named variables, e.g. {{.City}} or {{.Receiver.FirstName}}
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const (
+	// VariableCity is the city of the alert
+	VariableCity = "City"
+	// VariableStrength is the strength of the alert
+	VariableStrength = "Strength"
+	// VariableReceiver is the receiver of the message with the fields FirstName, LastName and City
+	VariableReceiver = "Receiver"
+)
+
+var (
+	ErrUnknownVariable = errors.New("unknown variable")
+	ErrMissingVariable = errors.New("missing variable")
+
+	receiverFields = []string{"FirstName", "LastName", "City"}
+	variableName   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+)
+
+// Receiver is a type representing the receiver fields available in templates.
+type Receiver struct {
+	FirstName string
+	LastName  string
+	City      string
+}
+
+// Variables is a type representing the values a template is rendered with.
+// Params are the variables declared by the template and passed with the message request.
+type Variables struct {
+	City     string
+	Strength string
+	Receiver Receiver
+	Params   map[string]string
+}
+
+// Template is a parsed message template with a subject and a text.
+type Template struct {
+	subject *template.Template
+	text    *template.Template
+}
+
+// Parse parses the subject and the text of a template.
+func Parse(subject, text string) (*Template, error) {
+	s, err := parseText("subject", subject)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseText("text", text)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{subject: s, text: t}, nil
+}
+
+func parseText(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, wrapParseError(name, err)
+	}
+	return t, nil
+}
+
+func wrapParseError(name string, err error) error {
+	return fmt.Errorf("parsing %s: %w", name, err)
+}
+
+// Check validates that the template references only the built-in variables and the declared ones.
+// Declared names must be identifiers and must not shadow the built-in variables.
+func Check(subject, text string, declared []string) error {
+	known := make(map[string]bool, len(declared))
+	for _, name := range declared {
+		if !variableName.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+		if IsBuiltin(name) {
+			return fmt.Errorf("variable %q is built-in", name)
+		}
+		if known[name] {
+			return fmt.Errorf("variable %q is declared twice", name)
+		}
+		known[name] = true
+	}
+
+	t, err := Parse(subject, text)
+	if err != nil {
+		return err
+	}
+
+	if unknown := t.Undeclared(declared); len(unknown) > 0 {
+		return unknownVariables(unknown)
+	}
+	return nil
+}
+
+// CheckFields validates that the fields, e.g. City or Receiver.FirstName, are built-in or declared variables.
+func CheckFields(fields, declared []string) error {
+	referenced := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		referenced[field] = true
+	}
+	if unknown := undeclared(referenced, declared); len(unknown) > 0 {
+		return unknownVariables(unknown)
+	}
+	return nil
+}
+
+func unknownVariables(unknown []string) error {
+	return fmt.Errorf("%w: %s", ErrUnknownVariable, strings.Join(unknown, ", "))
+}
+
+// IsBuiltin reports whether the variable is always available in templates.
+func IsBuiltin(name string) bool {
+	return name == VariableCity || name == VariableStrength || name == VariableReceiver
+}
+
+func isBuiltinField(field string) bool {
+	if IsBuiltin(field) {
+		return true
+	}
+	name, sub, ok := strings.Cut(field, ".")
+	if !ok || name != VariableReceiver {
+		return false
+	}
+	for _, f := range receiverFields {
+		if f == sub {
+			return true
+		}
+	}
+	return false
+}
+
+// Fields returns the variables referenced by the template, e.g. City or Receiver.FirstName, sorted by name.
+// Fields are resolved against the root of the variables.
+func (t *Template) Fields() []string {
+	return sortedFields(t.fields())
+}
+
+func (t *Template) fields() map[string]bool {
+	fields := make(map[string]bool)
+	collectFields(t.subject.Tree.Root, fields)
+	collectFields(t.text.Tree.Root, fields)
+	return fields
+}
+
+// Undeclared returns the variables referenced by the template which are neither built-in nor declared.
+func (t *Template) Undeclared(declared []string) []string {
+	return undeclared(t.fields(), declared)
+}
+
+func sortedFields(fields map[string]bool) []string {
+	result := make([]string, 0, len(fields))
+	for f := range fields {
+		result = append(result, f)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func undeclared(fields map[string]bool, declared []string) []string {
+	known := make(map[string]bool, len(declared))
+	for _, name := range declared {
+		known[name] = true
+	}
+
+	unknown := make([]string, 0)
+	for _, field := range sortedFields(fields) {
+		if !known[field] && !isBuiltinField(field) {
+			unknown = append(unknown, field)
+		}
+	}
+	return unknown
+}
+
+// scope is the value the dot refers to in a template, the path of a field from the root of the variables.
+// The dot of a range is an element of the ranged value, its fields are not resolved.
+type scope struct {
+	path  []string
+	known bool
+}
+
+var root = scope{known: true}
+
+// resolve returns the path of the fields from the root, or false if the dot is not known.
+func (s scope) resolve(fields []string) ([]string, bool) {
+	if !s.known {
+		return nil, false
+	}
+	path := make([]string, 0, len(s.path)+len(fields))
+	path = append(path, s.path...)
+	return append(path, fields...), true
+}
+
+func collectFields(node parse.Node, fields map[string]bool) {
+	collectScoped(node, root, fields)
+}
+
+func collectScoped(node parse.Node, dot scope, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectScoped(child, dot, fields)
+		}
+	case *parse.ActionNode:
+		collectScoped(n.Pipe, dot, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectScoped(cmd, dot, fields)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectScoped(arg, dot, fields)
+		}
+	case *parse.FieldNode, *parse.VariableNode, *parse.ChainNode:
+		if path, ok := fieldPath(n, dot); ok {
+			addField(path, fields)
+			return
+		}
+		if chain, ok := n.(*parse.ChainNode); ok {
+			collectScoped(chain.Node, dot, fields)
+		}
+	case *parse.IfNode:
+		collectScoped(n.Pipe, dot, fields)
+		collectScoped(n.List, dot, fields)
+		collectScoped(n.ElseList, dot, fields)
+	case *parse.RangeNode:
+		collectScoped(n.Pipe, dot, fields)
+		collectScoped(n.List, scope{}, fields)
+		collectScoped(n.ElseList, dot, fields)
+	case *parse.WithNode:
+		collectScoped(n.Pipe, dot, fields)
+		inner := scope{}
+		if path, ok := pipePath(n.Pipe, dot); ok {
+			inner = scope{path: path, known: true}
+		}
+		collectScoped(n.List, inner, fields)
+		collectScoped(n.ElseList, dot, fields)
+	}
+}
+
+// fieldPath returns the path from the root of a field, e.g. {{.City}}, {{$.City}} or {{(.Receiver).FirstName}}.
+// It returns false if the node does not refer to the variables, e.g. a variable declared in the template.
+func fieldPath(node parse.Node, dot scope) ([]string, bool) {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		return dot.resolve(n.Ident)
+	case *parse.VariableNode:
+		if n.Ident[0] != "$" || len(n.Ident) < 2 {
+			return nil, false
+		}
+		return root.resolve(n.Ident[1:])
+	case *parse.ChainNode:
+		var base []string
+		var ok bool
+		if pipe, isPipe := n.Node.(*parse.PipeNode); isPipe {
+			base, ok = pipePath(pipe, dot)
+		} else {
+			base, ok = fieldPath(n.Node, dot)
+		}
+		if !ok {
+			return nil, false
+		}
+		return scope{path: base, known: true}.resolve(n.Field)
+	}
+	return nil, false
+}
+
+// pipePath returns the path from the root of a pipeline made of a single field, e.g. the pipeline of {{with .Receiver}}.
+func pipePath(pipe *parse.PipeNode, dot scope) ([]string, bool) {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil, false
+	}
+	return fieldPath(pipe.Cmds[0].Args[0], dot)
+}
+
+// addField adds the variable of the path, a receiver field is kept with its parent, e.g. Receiver.FirstName.
+func addField(path []string, fields map[string]bool) {
+	if len(path) == 0 {
+		return
+	}
+	if path[0] == VariableReceiver && len(path) > 1 {
+		fields[path[0]+"."+path[1]] = true
+		return
+	}
+	fields[path[0]] = true
+}
+
+// Render renders the subject and the text of the template.
+// It returns an error wrapping ErrMissingVariable when a referenced variable has no value.
+func (t *Template) Render(v Variables) (string, string, error) {
+	data := v.data()
+	subject, err := execute(t.subject, data)
+	if err != nil {
+		return "", "", err
+	}
+	text, err := execute(t.text, data)
+	if err != nil {
+		return "", "", err
+	}
+	return subject, text, nil
+}
+
+func execute(t *template.Template, data map[string]any) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", executeError(t.Name(), err)
+	}
+	return b.String(), nil
+}
+
+func executeError(name string, err error) error {
+	if strings.Contains(err.Error(), "map has no entry for key") {
+		return fmt.Errorf("%w: %s", ErrMissingVariable, err)
+	}
+	return fmt.Errorf("rendering %s: %w", name, err)
+}
+
+// Value returns the value of a field, e.g. City or Receiver.FirstName, the built-in variables win over the params.
+// It returns an empty string when the field has no value.
+func (v Variables) Value(field string) string {
+	switch field {
+	case VariableCity:
+		return v.City
+	case VariableStrength:
+		return v.Strength
+	case VariableReceiver + ".FirstName":
+		return v.Receiver.FirstName
+	case VariableReceiver + ".LastName":
+		return v.Receiver.LastName
+	case VariableReceiver + ".City":
+		return v.Receiver.City
+	}
+	return v.Params[field]
+}
+
+// Values returns the values of the fields in order, e.g. the parameters of a pre-approved WhatsApp template.
+func (v Variables) Values(fields []string) []string {
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		values = append(values, v.Value(field))
+	}
+	return values
+}
+
+func (v Variables) data() map[string]any {
+	data := make(map[string]any, len(v.Params)+3)
+	for name, value := range v.Params {
+		data[name] = value
+	}
+	data[VariableCity] = v.City
+	data[VariableStrength] = v.Strength
+	data[VariableReceiver] = map[string]any{
+		"FirstName": v.Receiver.FirstName,
+		"LastName":  v.Receiver.LastName,
+		"City":      v.Receiver.City,
+	}
+	return data
+}
+
+// Escape escapes the template delimiters of a text, so it is rendered as is.
+func Escape(text string) string {
+	return strings.ReplaceAll(text, "{{", `{{"{{"}}`)
+}
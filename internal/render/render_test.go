@@ -0,0 +1,119 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	t.Run("when built-in and declared variables are used then no error", func(t *testing.T) {
+		err := Check(
+			"{{.City}}: {{.Strength}}",
+			"{{.Receiver.FirstName}}, water level {{if .Level}}{{.Level}}{{else}}unknown{{end}}",
+			[]string{"Level"},
+		)
+		assert.NoError(t, err)
+	})
+	t.Run("when variable is not declared then error", func(t *testing.T) {
+		err := Check("{{.City}}", "{{.Level}} {{.Receiver.Phone}}", nil)
+		assert.ErrorIs(t, err, ErrUnknownVariable)
+		assert.ErrorContains(t, err, "Level, Receiver.Phone")
+	})
+	t.Run("when template is malformed then error", func(t *testing.T) {
+		err := Check("{{.City}", "text", nil)
+		assert.Error(t, err)
+	})
+	t.Run("when declared variable is built-in then error", func(t *testing.T) {
+		err := Check("subject", "text", []string{"City"})
+		assert.Error(t, err)
+	})
+	t.Run("when declared variable is not an identifier then error", func(t *testing.T) {
+		err := Check("subject", "text", []string{"water level"})
+		assert.Error(t, err)
+	})
+	t.Run("when fields are reached by the root variable or a chain then they are checked", func(t *testing.T) {
+		err := Check("{{$.City}}", "{{(.Receiver).FirstName}} {{$.Level}} {{(.Receiver).Phone}}", nil)
+		assert.ErrorIs(t, err, ErrUnknownVariable)
+		assert.ErrorContains(t, err, "Level, Receiver.Phone")
+	})
+	t.Run("when with changes the dot then fields are checked in its scope", func(t *testing.T) {
+		err := Check("subject", "{{with .Receiver}}{{.FirstName}}{{else}}{{.City}}{{end}}", nil)
+		assert.NoError(t, err)
+
+		err = Check("subject", "{{with .Receiver}}{{.Phone}}{{end}}", nil)
+		assert.ErrorIs(t, err, ErrUnknownVariable)
+		assert.ErrorContains(t, err, "Receiver.Phone")
+	})
+	t.Run("when variable is declared in the template then it is not a field", func(t *testing.T) {
+		err := Check("subject", "{{$level := .Level}}{{$level}}", []string{"Level"})
+		assert.NoError(t, err)
+	})
+}
+
+func TestTemplate_Render(t *testing.T) {
+	template, err := Parse("{{.City}}: {{.Strength}}", "Dear {{.Receiver.FirstName}}, water level is {{.Level}} m")
+	assert.NoError(t, err)
+
+	t.Run("when all variables are set then rendered", func(t *testing.T) {
+		subject, text, err := template.Render(Variables{
+			City:     "Kazan",
+			Strength: "high",
+			Receiver: Receiver{FirstName: "Ivan"},
+			Params:   map[string]string{"Level": "3.5"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "Kazan: high", subject)
+		assert.Equal(t, "Dear Ivan, water level is 3.5 m", text)
+	})
+	t.Run("when param is missing then error", func(t *testing.T) {
+		_, _, err := template.Render(Variables{City: "Kazan", Strength: "high"})
+		assert.ErrorIs(t, err, ErrMissingVariable)
+	})
+	t.Run("when param shadows a built-in variable then built-in wins", func(t *testing.T) {
+		subject, _, err := template.Render(Variables{
+			City:     "Kazan",
+			Strength: "high",
+			Params:   map[string]string{"City": "Moscow", "Level": "1"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "Kazan: high", subject)
+	})
+	t.Run("when text is escaped then delimiters are kept", func(t *testing.T) {
+		escaped, err := Parse(Escape("{{.City}}"), Escape("a {{ b }} c"))
+		assert.NoError(t, err)
+		subject, text, err := escaped.Render(Variables{})
+		assert.NoError(t, err)
+		assert.Equal(t, "{{.City}}", subject)
+		assert.Equal(t, "a {{ b }} c", text)
+	})
+}
+
+func TestCheckFields(t *testing.T) {
+	t.Run("when fields are built-in or declared then no error", func(t *testing.T) {
+		err := CheckFields([]string{"City", "Receiver.FirstName", "Level"}, []string{"Level"})
+		assert.NoError(t, err)
+	})
+	t.Run("when field is not declared then error", func(t *testing.T) {
+		err := CheckFields([]string{"City", "Level"}, nil)
+		assert.ErrorIs(t, err, ErrUnknownVariable)
+		assert.ErrorContains(t, err, "Level")
+	})
+}
+
+func TestVariables_Value(t *testing.T) {
+	v := Variables{
+		City:     "Kazan",
+		Strength: "high",
+		Receiver: Receiver{FirstName: "Ivan", City: "Moscow"},
+		Params:   map[string]string{"City": "Samara", "Level": "3.5"},
+	}
+
+	assert.Equal(t, "Kazan", v.Value("City"))
+	assert.Equal(t, "high", v.Value("Strength"))
+	assert.Equal(t, "Ivan", v.Value("Receiver.FirstName"))
+	assert.Equal(t, "Moscow", v.Value("Receiver.City"))
+	assert.Equal(t, "3.5", v.Value("Level"))
+	assert.Equal(t, "", v.Value("Unknown"))
+	assert.Equal(t, []string{"Ivan", "Kazan", "3.5"}, v.Values([]string{"Receiver.FirstName", "City", "Level"}))
+}
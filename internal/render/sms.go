@@ -0,0 +1,195 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Encoding is a type representing the character encoding of an SMS.
+type Encoding string
+
+const (
+	// EncodingGSM7 is the 7-bit default alphabet, 160 characters in a single SMS
+	EncodingGSM7 Encoding = "GSM-7"
+	// EncodingUCS2 is used when a character is not in the GSM-7 alphabet, 70 characters in a single SMS
+	EncodingUCS2 Encoding = "UCS-2"
+
+	gsm7SingleSegment = 160
+	gsm7MultiSegment  = 153
+	ucs2SingleSegment = 70
+	ucs2MultiSegment  = 67
+)
+
+// gsm7Basic is the GSM 03.38 basic character set
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension is the GSM 03.38 extension table, every character takes two septets
+const gsm7Extension = "\f^{}\\[~]|€"
+
+var gsm7Septets = func() map[rune]int {
+	septets := make(map[rune]int)
+	for _, r := range gsm7Basic {
+		septets[r] = 1
+	}
+	for _, r := range gsm7Extension {
+		septets[r] = 2
+	}
+	return septets
+}()
+
+// Segments is a type representing the number of parts an SMS is split into.
+// Characters is the number of septets for GSM-7 and the number of UTF-16 code units for UCS-2.
+type Segments struct {
+	Encoding   Encoding `json:"encoding"`
+	Characters int      `json:"characters"`
+	Count      int      `json:"segments"`
+}
+
+// CountSegments calculates the encoding and the number of segments of an SMS text.
+// A long text is sent as a concatenated SMS, every part loses room for the concatenation header,
+// and an escaped GSM-7 character or a UTF-16 surrogate pair is never split between parts.
+func CountSegments(text string) Segments {
+	if IsGSM7(text) {
+		return count(EncodingGSM7, gsm7Units(text), gsm7SingleSegment, gsm7MultiSegment)
+	}
+	return count(EncodingUCS2, ucs2Units(text), ucs2SingleSegment, ucs2MultiSegment)
+}
+
+// IsGSM7 reports whether every character of the text is in the GSM-7 alphabet.
+func IsGSM7(text string) bool {
+	for _, r := range text {
+		if _, ok := gsm7Septets[r]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// gsm7Units returns the number of septets of every character.
+func gsm7Units(text string) []int {
+	units := make([]int, 0, len(text))
+	for _, r := range text {
+		units = append(units, gsm7Septets[r])
+	}
+	return units
+}
+
+// ucs2Units returns the number of UTF-16 code units of every character.
+func ucs2Units(text string) []int {
+	units := make([]int, 0, len(text))
+	for _, r := range text {
+		if utf16.IsSurrogate(r) || r > 0xFFFF {
+			units = append(units, 2)
+			continue
+		}
+		units = append(units, 1)
+	}
+	return units
+}
+
+func count(encoding Encoding, units []int, single, multi int) Segments {
+	total := 0
+	for _, u := range units {
+		total += u
+	}
+
+	segments := Segments{Encoding: encoding, Characters: total}
+	switch {
+	case total == 0:
+		return segments
+	case total <= single:
+		segments.Count = 1
+		return segments
+	}
+
+	segments.Count = 1
+	used := 0
+	for _, u := range units {
+		if used+u > multi {
+			segments.Count++
+			used = 0
+		}
+		used += u
+	}
+	return segments
+}
+
+// Truncate shortens an SMS text to at most the number of segments and ends it with an ellipsis.
+// A GSM-7 text ends with three dots to stay in its encoding.
+func Truncate(text string, segments int) string {
+	if segments < 1 || CountSegments(text).Count <= segments {
+		return text
+	}
+	ellipsis := "…"
+	if IsGSM7(text) {
+		ellipsis = "..."
+	}
+
+	runes := []rune(text)
+	// binary search of the longest prefix which fits with the ellipsis
+	low, high := 0, len(runes)
+	for low < high {
+		mid := (low + high + 1) / 2
+		if CountSegments(string(runes[:mid])+ellipsis).Count <= segments {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:low]), unicode.IsSpace) + ellipsis
+}
+
+// transliterations are the replacements of the characters out of the GSM-7 alphabet,
+// Cyrillic letters are written in Latin ones
+var transliterations = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'ә': "a", 'ө': "o", 'ү': "u", 'җ': "zh", 'ң': "n", 'һ': "h",
+	'«': "\"", '»': "\"", '“': "\"", '”': "\"", '„': "\"", '‘': "'", '’': "'",
+	'–': "-", '—': "-", '…': "...", '№': "N", '\u00a0': " ", '\t': " ",
+}
+
+// Transliterate replaces the characters of a text out of the GSM-7 alphabet, so the text is sent in GSM-7.
+// Cyrillic letters are written in Latin ones, accents are removed and other characters become a question mark.
+func Transliterate(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if _, ok := gsm7Septets[r]; ok {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(transliterate(r))
+	}
+	return b.String()
+}
+
+func transliterate(r rune) string {
+	lower := unicode.ToLower(r)
+	if t, ok := transliterations[lower]; ok {
+		if lower != r && t != "" {
+			return strings.ToUpper(t[:1]) + t[1:]
+		}
+		return t
+	}
+
+	var b strings.Builder
+	for _, d := range norm.NFD.String(string(r)) {
+		if unicode.Is(unicode.Mn, d) {
+			continue
+		}
+		if _, ok := gsm7Septets[d]; !ok {
+			return "?"
+		}
+		b.WriteRune(d)
+	}
+	if b.Len() == 0 {
+		return "?"
+	}
+	return b.String()
+}
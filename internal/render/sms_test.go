@@ -0,0 +1,72 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountSegments(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Segments
+	}{
+		{"when text is empty then no segments", "", Segments{Encoding: EncodingGSM7}},
+		{"when gsm text fits then one segment", strings.Repeat("a", 160), Segments{Encoding: EncodingGSM7, Characters: 160, Count: 1}},
+		{"when gsm text is longer then concatenated", strings.Repeat("a", 161), Segments{Encoding: EncodingGSM7, Characters: 161, Count: 2}},
+		{"when extension characters then two septets", strings.Repeat("€", 80), Segments{Encoding: EncodingGSM7, Characters: 160, Count: 1}},
+		{"when escape does not fit then moved to the next segment", strings.Repeat("a", 152) + "€" + strings.Repeat("a", 10), Segments{Encoding: EncodingGSM7, Characters: 164, Count: 2}},
+		{"when cyrillic text then ucs-2", strings.Repeat("ш", 70), Segments{Encoding: EncodingUCS2, Characters: 70, Count: 1}},
+		{"when ucs-2 text is longer then concatenated", strings.Repeat("ш", 135), Segments{Encoding: EncodingUCS2, Characters: 135, Count: 3}},
+		{"when emoji then surrogate pair", "🌊" + strings.Repeat("a", 68), Segments{Encoding: EncodingUCS2, Characters: 70, Count: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CountSegments(tt.text))
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	t.Run("when text fits then as is", func(t *testing.T) {
+		assert.Equal(t, "Flood in Kazan", Truncate("Flood in Kazan", 1))
+	})
+	t.Run("when gsm text is longer then three dots", func(t *testing.T) {
+		text := Truncate(strings.Repeat("a", 200), 1)
+		assert.Equal(t, strings.Repeat("a", 157)+"...", text)
+		assert.Equal(t, 1, CountSegments(text).Count)
+	})
+	t.Run("when ucs-2 text is longer then ellipsis", func(t *testing.T) {
+		text := Truncate(strings.Repeat("ш", 200), 2)
+		assert.Equal(t, strings.Repeat("ш", 133)+"…", text)
+		assert.Equal(t, 2, CountSegments(text).Count)
+	})
+	t.Run("when cut at a space then space is trimmed", func(t *testing.T) {
+		text := Truncate(strings.Repeat("a", 156)+" "+strings.Repeat("b", 10), 1)
+		assert.Equal(t, strings.Repeat("a", 156)+"...", text)
+	})
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"when gsm text then as is", "Flood in Kazan, 3.5 m", "Flood in Kazan, 3.5 m"},
+		{"when cyrillic text then latin", "Наводнение в Казани", "Navodnenie v Kazani"},
+		{"when tatar text then latin", "Су басу, Җил", "Su basu, Zhil"},
+		{"when typography then ascii", "«Шторм» — №3", "\"Shtorm\" - N3"},
+		{"when accents then removed", "Kraków", "Krakow"},
+		{"when emoji then question mark", "🌊", "?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := Transliterate(tt.text)
+			assert.Equal(t, tt.want, text)
+			assert.True(t, IsGSM7(text))
+		})
+	}
+}
@@ -11,18 +11,32 @@ const (
 )
 
 type Router struct {
-	router   *chi.Mux
-	message  *controllers.Message
-	receiver *controllers.Receiver
-	template *controllers.Template
+	router    *chi.Mux
+	message   *controllers.Message
+	receiver  *controllers.Receiver
+	template  *controllers.Template
+	broadcast *controllers.Broadcast
+	cap       *controllers.Cap
+	capFeed   *controllers.CapFeed
+	imports   *controllers.Import
+	verify    *controllers.ContactVerification
+	consent   *controllers.Consent
+	delivery  *controllers.Delivery
 }
 
-func New(router *chi.Mux, message *controllers.Message, receiver *controllers.Receiver, template *controllers.Template) Router {
+func New(router *chi.Mux, message *controllers.Message, receiver *controllers.Receiver, template *controllers.Template, broadcast *controllers.Broadcast, cap *controllers.Cap, capFeed *controllers.CapFeed, imports *controllers.Import, verify *controllers.ContactVerification, consent *controllers.Consent, delivery *controllers.Delivery) Router {
 	return Router{
-		router:   router,
-		message:  message,
-		receiver: receiver,
-		template: template,
+		router:    router,
+		message:   message,
+		receiver:  receiver,
+		template:  template,
+		broadcast: broadcast,
+		cap:       cap,
+		capFeed:   capFeed,
+		imports:   imports,
+		verify:    verify,
+		consent:   consent,
+		delivery:  delivery,
 	}
 }
 
@@ -31,17 +45,58 @@ func (r Router) Load() {
 		router.Route("/messages", func(router chi.Router) {
 			router.Post("/", r.message.Send)
 		})
+		router.Route("/cap", func(router chi.Router) {
+			router.Post("/", r.cap.Ingest)
+			router.Get("/feed", r.capFeed.Feed)
+			router.Get("/alerts/{id}", r.capFeed.GetAlert)
+		})
+		router.Route("/broadcasts", func(router chi.Router) {
+			router.Get("/{id}", r.broadcast.GetByID)
+		})
 		router.Route("/templates", func(router chi.Router) {
+			router.Get("/", r.template.List)
 			router.Post("/", r.template.Create)
 
 			router.Route("/{id}", func(router chi.Router) {
+				router.Get("/", r.template.GetByID)
 				router.Delete("/", r.template.Delete)
 				router.Patch("/", r.template.Update)
+				router.Post("/preview", r.template.Preview)
+				router.Get("/versions", r.template.Versions)
+				router.Post("/rollback", r.template.Rollback)
 			})
 		})
 		router.Route("/receivers", func(router chi.Router) {
-			router.Get("/city/:city", r.receiver.GetByCity)
-			router.Post("/upload", r.receiver.Upload)
+			router.Get("/", r.receiver.List)
+			router.Post("/", r.receiver.Create)
+			router.Get("/city/{city}", r.receiver.GetByCity)
+			router.Get("/export", r.receiver.Export)
+			router.Post("/upload", r.imports.Upload)
+
+			router.Route("/{id}", func(router chi.Router) {
+				router.Get("/", r.receiver.GetByID)
+				router.Patch("/", r.receiver.Update)
+				router.Delete("/", r.receiver.Delete)
+				router.Post("/contacts", r.receiver.AddContact)
+				router.Post("/contacts/verify", r.verify.Verify)
+				router.Post("/contacts/confirm", r.verify.Confirm)
+				router.Get("/consents", r.consent.List)
+				router.Delete("/contacts/{type}/{value}", r.receiver.RemoveContact)
+				router.Post("/contacts/{type}/{value}/activate", r.receiver.ActivateContact)
+				router.Post("/contacts/{type}/{value}/deactivate", r.receiver.DeactivateContact)
+			})
+		})
+		router.Route("/sms", func(router chi.Router) {
+			router.Post("/inbound", r.consent.InboundSMS)
+		})
+		router.Route("/webhooks", func(router chi.Router) {
+			router.Post("/twilio/status", r.delivery.TwilioStatus)
+			router.Post("/mailgun/events", r.delivery.MailgunEvent)
+		})
+		router.Route("/imports/{id}", func(router chi.Router) {
+			router.Get("/", r.imports.GetByID)
+			router.Get("/errors", r.imports.Errors)
+			router.Post("/cancel", r.imports.Cancel)
 		})
 	})
 }
@@ -0,0 +1,79 @@
+package senders
+
+import (
+	"fmt"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/render"
+	"sort"
+)
+
+// localized is a template parsed in its language and in every language it is translated into.
+type localized struct {
+	language     string
+	template     *render.Channels
+	translations map[string]*render.Channels
+	languages    []string
+}
+
+// parseLocalized parses the template of the message and its translations.
+func parseLocalized(m models.MessageConsumer) (*localized, error) {
+	template, err := render.ParseChannels(m.Subject, m.Text, m.Variants.Render())
+	if err != nil {
+		return nil, err
+	}
+	l := &localized{
+		language:     m.Language,
+		template:     template,
+		translations: make(map[string]*render.Channels, len(m.Translations)),
+		languages:    make([]string, 0, len(m.Translations)),
+	}
+	for language, t := range m.Translations {
+		if l.translations[language], err = render.ParseChannels(t.Subject, t.Text, t.Variants.Render()); err != nil {
+			return nil, fmt.Errorf("%s translation: %w", language, err)
+		}
+		l.languages = append(l.languages, language)
+	}
+	sort.Strings(l.languages)
+	return l, nil
+}
+
+// choose picks the template for the language of a receiver.
+// It tries the language, its base language and any translation of the base language,
+// then the languages of the fallback chain and then the template itself.
+// It returns the template, its language and whether the receiver fell back to another language.
+// A receiver without a language gets the template itself and never falls back.
+func (l *localized) choose(language string, fallback []string) (*render.Channels, string, bool) {
+	if language == "" {
+		return l.template, l.language, false
+	}
+	if t, ok := l.lookup(language); ok {
+		return t, language, false
+	}
+	base := models.BaseLanguage(language)
+	if t, ok := l.lookup(base); ok {
+		return t, base, false
+	}
+	if l.language != "" && models.BaseLanguage(l.language) == base {
+		return l.template, l.language, false
+	}
+	for _, tag := range l.languages {
+		if models.BaseLanguage(tag) == base {
+			return l.translations[tag], tag, false
+		}
+	}
+
+	for _, tag := range fallback {
+		if t, ok := l.lookup(tag); ok {
+			return t, tag, true
+		}
+	}
+	return l.template, l.language, true
+}
+
+func (l *localized) lookup(language string) (*render.Channels, bool) {
+	if language == l.language {
+		return l.template, true
+	}
+	t, ok := l.translations[language]
+	return t, ok
+}
@@ -0,0 +1,52 @@
+package senders
+
+import (
+	"projects/emergency-messages/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalized_choose(t *testing.T) {
+	message := models.MessageConsumer{
+		Subject:  "Наводнение",
+		Text:     "Наводнение в {{.City}}",
+		Language: "ru",
+		City:     "Kazan",
+		Translations: models.Translations{
+			"tt": {Subject: "Су басу", Text: "{{.City}} шәһәрендә су басу"},
+			"en": {Subject: "Flood", Text: "Flood in {{.City}}"},
+		},
+	}
+	template, err := parseLocalized(message)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		language string
+		fallback []string
+		want     string
+		fell     bool
+	}{
+		{name: "when receiver has no language then template", language: "", want: "ru"},
+		{name: "when template is translated then translation", language: "tt", want: "tt"},
+		{name: "when language is the template one then template", language: "ru", want: "ru"},
+		{name: "when region is not translated then base language", language: "en-GB", want: "en"},
+		{name: "when language is not translated then fallback chain", language: "de", fallback: []string{"fr", "en"}, want: "en", fell: true},
+		{name: "when chain is not translated then template", language: "de", fallback: []string{"fr"}, want: "ru", fell: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, language, fell := template.choose(tt.language, tt.fallback)
+			assert.Equal(t, tt.want, language)
+			assert.Equal(t, tt.fell, fell)
+		})
+	}
+
+	t.Run("when translation is malformed then error", func(t *testing.T) {
+		broken := message
+		broken.Translations = models.Translations{"en": {Subject: "Flood", Text: "{{.City"}}
+		_, err := parseLocalized(broken)
+		assert.Error(t, err)
+	})
+}
@@ -7,16 +7,25 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"log/slog"
-	"projects/emergency-messages/internal/errorx"
 	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/render"
 	"runtime"
 	"sync"
 )
 
+// Sender creates the messages of a broadcast for every receiver of the city.
+// Messages are rendered in the language of the receiver when the template is translated into it,
+// in the first language of the fallback chain the template is translated into otherwise.
+// With verifiedOnly the contacts which are not verified get critical messages only,
+// the contacts whose receivers opted out get critical messages only whatever verifiedOnly is.
+// A broadcast cancelled by a later alert is not sent anymore.
 type Sender struct {
-	messageStore  MessageCreator
-	receiverStore ReceiverFinder
-	log           *slog.Logger
+	messageStore   MessageCreator
+	receiverStore  ReceiverFinder
+	broadcastStore BroadcastFinder
+	fallback       []string
+	verifiedOnly   bool
+	log            *slog.Logger
 }
 
 type MessageCreator interface {
@@ -24,73 +33,140 @@ type MessageCreator interface {
 }
 
 type ReceiverFinder interface {
-	FindByCity(ctx context.Context, city string) ([]models.ReceiverEntity, error)
+	List(ctx context.Context, filter models.ReceiverFilter) ([]models.ReceiverEntity, int, error)
 }
 
-func New(messageStore MessageCreator, receiverStore ReceiverFinder, log *slog.Logger) *Sender {
+type BroadcastFinder interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BroadcastEntity, error)
+}
+
+// errBroadcastCancelled stops the sending of a broadcast cancelled while its messages are created
+var errBroadcastCancelled = errors.New("broadcast is cancelled")
+
+// receiverBatchSize is the number of receivers of the city read from the store at once
+const receiverBatchSize = models.MaxReceiverLimit
+
+func New(messageStore MessageCreator, receiverStore ReceiverFinder, broadcastStore BroadcastFinder, fallback []string, verifiedOnly bool, log *slog.Logger) *Sender {
 	return &Sender{
-		messageStore:  messageStore,
-		receiverStore: receiverStore,
-		log:           log,
+		messageStore:   messageStore,
+		receiverStore:  receiverStore,
+		broadcastStore: broadcastStore,
+		fallback:       fallback,
+		verifiedOnly:   verifiedOnly,
+		log:            log,
 	}
 
 }
 
 func (s *Sender) Send(message models.MessageConsumer) error {
-	receiverStore, err := s.receiverStore.FindByCity(context.Background(), message.City)
-	if err != nil {
-		// if we don't find any receivers, we don't return an error
-		if errors.Is(err, errorx.ErrNotFound) {
-			return nil
-		}
-		s.log.With(slog.Any("city", message.City)).
-			Error("finding receivers by city", err)
-		return err
-	}
-	receivers, err := s.transformReceiversStoreToReceivers(receiverStore)
+	template, err := parseLocalized(message)
 	if err != nil {
-		s.log.Error("transforming receivers store to receivers", err)
+		s.log.With(slog.Any("broadcastID", message.BroadcastID)).
+			Error("parsing template", err)
 		return err
 	}
 
-	receiversCh := make(chan *models.Receiver, len(receivers))
+	receiversCh := make(chan *models.Receiver, receiverBatchSize)
 	var wg sync.WaitGroup
 	for i := 0; i < runtime.NumCPU(); i++ {
 		wg.Add(1)
-		go s.send(context.Background(), receiversCh, message, &wg)
+		go s.send(context.Background(), receiversCh, message, template, &wg)
 	}
 
-	go writeReceiversToChannel(receivers, receiversCh)
+	err = s.writeReceiversToChannel(context.Background(), message.BroadcastID, message.City, receiversCh)
 	wg.Wait()
-
+	if errors.Is(err, errBroadcastCancelled) {
+		s.log.With(slog.Any("broadcastID", message.BroadcastID)).
+			Info("broadcast is cancelled")
+		return nil
+	}
+	if err != nil {
+		s.log.With(slog.Any("city", message.City)).
+			Error("finding receivers by city", err)
+		return err
+	}
 	return nil
 }
 
-// writeReceiversToChannel writes receivers to the channel
-func writeReceiversToChannel(receivers []*models.Receiver, receiversCh chan<- *models.Receiver) {
-	for _, u := range receivers {
-		receiversCh <- u
+// writeReceiversToChannel reads the receivers of the city page by page and writes them to the channel,
+// so the receivers of a big city are not kept in memory at once.
+// It stops with errBroadcastCancelled when the broadcast is cancelled before a page is read.
+func (s *Sender) writeReceiversToChannel(ctx context.Context, broadcastID uuid.UUID, city string, receiversCh chan<- *models.Receiver) error {
+	defer close(receiversCh)
+
+	filter := models.ReceiverFilter{City: city, Limit: receiverBatchSize, WithoutTotal: true}
+	for {
+		broadcast, err := s.broadcastStore.GetByID(ctx, broadcastID)
+		if err != nil {
+			return err
+		}
+		if broadcast.CancelledAt != nil {
+			return errBroadcastCancelled
+		}
+
+		receiverStore, _, err := s.receiverStore.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+		receivers, err := s.transformReceiversStoreToReceivers(receiverStore)
+		if err != nil {
+			return err
+		}
+		for _, u := range receivers {
+			receiversCh <- u
+		}
+		if len(receiverStore) < filter.Limit {
+			return nil
+		}
+		filter.After = receiverStore[len(receiverStore)-1].ID
 	}
-	close(receiversCh)
 }
 
-// send sends the message to the receivers
-func (s *Sender) send(ctx context.Context, receiversCh <-chan *models.Receiver, message models.MessageConsumer, wg *sync.WaitGroup) {
+// send renders the message for every receiver in their language and creates it for every active contact
+// with the variant of the contact channel
+func (s *Sender) send(ctx context.Context, receiversCh <-chan *models.Receiver, message models.MessageConsumer, template *localized, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for receiver := range receiversCh {
+		translation, language, fallback := template.choose(receiver.Language, s.fallback)
+		variables := render.Variables{
+			City:     message.City,
+			Strength: message.Strength,
+			Receiver: render.Receiver{
+				FirstName: receiver.FirstName,
+				LastName:  receiver.LastName,
+				City:      receiver.City,
+			},
+			Params: message.Params,
+		}
+		rendered, renderErr := translation.Render(variables)
+		if renderErr != nil {
+			s.log.With(slog.Any("broadcastID", message.BroadcastID), slog.Any("receiver_id", receiver.ID)).
+				Error("rendering message", renderErr)
+		}
+
 		for _, contact := range receiver.Contacts {
-			if !contact.IsActive {
+			if !s.reaches(message, contact) {
 				continue
 			}
-			newMessage, err := s.transformMessageToStoreModel(message, receiver.ID, contact)
-			newMessage.ID = uuid.New()
-			if err != nil {
-				s.log.With(slog.Any("message", message), slog.Any("receiver_id", receiver.ID)).
-					Error("transforming message to store model", err)
-				continue
+
+			var newMessage *models.MessageEntity
+			if renderErr != nil {
+				// a message which cannot be rendered is stored failed to be reported with the broadcast
+				newMessage = transformFailedMessageToStoreModel(message, renderErr, receiver.ID, contact)
+			} else {
+				var err error
+				newMessage, err = s.transformMessageToStoreModel(message, rendered, variables, receiver.ID, contact)
+				if err != nil {
+					s.log.With(slog.Any("message", message), slog.Any("receiver_id", receiver.ID)).
+						Error("transforming message to store model", err)
+					continue
+				}
 			}
+			newMessage.ID = uuid.New()
+			newMessage.Language = language
+			newMessage.LanguageFallback = fallback
 
-			if err = s.messageStore.Create(ctx, newMessage); err != nil {
+			if err := s.messageStore.Create(ctx, newMessage); err != nil {
 				s.log.With(slog.Any("message", newMessage)).
 					Error("creating message", err)
 				continue
@@ -99,18 +175,85 @@ func (s *Sender) send(ctx context.Context, receiversCh <-chan *models.Receiver,
 	}
 }
 
-func (s *Sender) transformMessageToStoreModel(m models.MessageConsumer, receiverID uuid.UUID, contact models.Contact) (*models.MessageEntity, error) {
+// reaches reports whether the message is sent to the contact, an inactive contact is not sent messages.
+// A contact whose receiver opted out gets critical messages only.
+func (s *Sender) reaches(message models.MessageConsumer, contact models.Contact) bool {
+	if !contact.IsActive {
+		return false
+	}
+	if contact.IsOptedOut() {
+		return message.Critical
+	}
+	return !s.verifiedOnly || message.Critical || contact.IsVerified()
+}
+
+func (s *Sender) transformMessageToStoreModel(m models.MessageConsumer, rendered *render.Rendered, variables render.Variables, receiverID uuid.UUID, contact models.Contact) (*models.MessageEntity, error) {
 	storeModel := &models.MessageEntity{
-		Subject:    m.Subject,
-		Text:       m.Text,
-		Status:     m.Status,
-		ReceiverID: receiverID,
-		Type:       contact.Type,
-		Value:      contact.Value,
+		BroadcastID:     m.BroadcastID,
+		TemplateID:      m.TemplateID,
+		TemplateVersion: m.TemplateVersion,
+		Subject:         rendered.Subject,
+		Text:            rendered.Text,
+		Status:          m.Status,
+		ReceiverID:      receiverID,
+		Type:            contact.Type,
+		Value:           contact.Value,
+	}
+	switch contact.Type {
+	case models.ContactTypeSMS:
+		text, segments, err := fitSMS(rendered.SMS, m.MaxSegments, m.SegmentPolicy)
+		storeModel.Text = text
+		storeModel.Segments = segments.Count
+		storeModel.Encoding = string(segments.Encoding)
+		// a rejected sms is stored failed to be reported with the broadcast
+		if err != nil {
+			storeModel.Status = models.Failed
+			storeModel.LastError = err.Error()
+		}
+	case models.ContactTypeEmail:
+		storeModel.Text = rendered.Email
+		storeModel.HTML = rendered.EmailHTML
+	case models.ContactTypeTelegram:
+		if rendered.Markdown != "" {
+			storeModel.Text = rendered.Markdown
+			storeModel.Format = models.TextFormatMarkdown
+		}
+	case models.ContactTypeWhatsApp:
+		storeModel.WhatsApp = whatsAppTemplate(m, variables)
 	}
 	return storeModel, nil
 }
 
+// transformFailedMessageToStoreModel returns the failed message of a contact whose template could not be rendered,
+// it keeps the template text.
+func transformFailedMessageToStoreModel(m models.MessageConsumer, renderErr error, receiverID uuid.UUID, contact models.Contact) *models.MessageEntity {
+	return &models.MessageEntity{
+		BroadcastID:     m.BroadcastID,
+		TemplateID:      m.TemplateID,
+		TemplateVersion: m.TemplateVersion,
+		Subject:         m.Subject,
+		Text:            m.Text,
+		Status:          models.Failed,
+		LastError:       renderErr.Error(),
+		ReceiverID:      receiverID,
+		Type:            contact.Type,
+		Value:           contact.Value,
+	}
+}
+
+// whatsAppTemplate returns the WhatsApp template of the message with the parameters of the receiver.
+// A message queued before the parameters were configurable has them filled already.
+func whatsAppTemplate(m models.MessageConsumer, variables render.Variables) *models.WhatsAppTemplate {
+	if m.WhatsApp == nil || len(m.WhatsAppParameters) == 0 {
+		return m.WhatsApp
+	}
+	return &models.WhatsAppTemplate{
+		Name:       m.WhatsApp.Name,
+		Language:   m.WhatsApp.Language,
+		Parameters: variables.Values(m.WhatsAppParameters),
+	}
+}
+
 func (s *Sender) transformReceiversStoreToReceivers(receiversStore []models.ReceiverEntity) ([]*models.Receiver, error) {
 	results := make([]*models.Receiver, 0, len(receiversStore))
 	for _, u := range receiversStore {
@@ -120,6 +263,8 @@ func (s *Sender) transformReceiversStoreToReceivers(receiversStore []models.Rece
 			LastName:  u.LastName,
 			Contacts:  u.Contacts,
 			City:      u.City,
+			Language:  u.Language,
+			Tags:      u.Tags,
 		}
 		results = append(results, receiver)
 	}
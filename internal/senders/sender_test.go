@@ -0,0 +1,225 @@
+package senders
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/render"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSender_transformMessageToStoreModel(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	sender := New(nil, nil, nil, nil, false, log)
+
+	message := models.MessageConsumer{
+		BroadcastID:     uuid.New(),
+		TemplateID:      uuid.New(),
+		TemplateVersion: 2,
+		Status:          models.Created,
+		WhatsApp:        &models.WhatsAppTemplate{Name: "flood", Language: "ru"},
+	}
+	rendered := &render.Rendered{
+		Subject:   "Flood",
+		Text:      "Flood in Kazan, the water level is rising",
+		SMS:       "Flood in Kazan",
+		Email:     "Flood in Kazan, the water level is rising",
+		EmailHTML: "<p>Flood in Kazan</p>",
+		Markdown:  "*Flood* in Kazan",
+	}
+	variables := render.Variables{City: "Kazan", Strength: "high", Receiver: render.Receiver{FirstName: "Ivan"}}
+	receiverID := uuid.New()
+
+	t.Run("when contact is sms then sms variant", func(t *testing.T) {
+		m, err := sender.transformMessageToStoreModel(message, rendered, variables, receiverID, models.Contact{Type: models.ContactTypeSMS, Value: "+79001234567"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Flood in Kazan", m.Text)
+		assert.Empty(t, m.HTML)
+		assert.Equal(t, message.TemplateVersion, m.TemplateVersion)
+		assert.Equal(t, 1, m.Segments)
+		assert.Equal(t, string(render.EncodingGSM7), m.Encoding)
+	})
+	t.Run("when sms exceeds max segments then failed", func(t *testing.T) {
+		limited := message
+		limited.MaxSegments = 1
+		limited.SegmentPolicy = models.SegmentPolicyReject
+		long := *rendered
+		long.SMS = strings.Repeat("Наводнение ", 10)
+		m, err := sender.transformMessageToStoreModel(limited, &long, variables, receiverID, models.Contact{Type: models.ContactTypeSMS, Value: "+79001234567"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Failed, m.Status)
+		assert.NotEmpty(t, m.LastError)
+		assert.Equal(t, 2, m.Segments)
+		assert.Equal(t, string(render.EncodingUCS2), m.Encoding)
+	})
+	t.Run("when contact is email then plain and html variants", func(t *testing.T) {
+		m, err := sender.transformMessageToStoreModel(message, rendered, variables, receiverID, models.Contact{Type: models.ContactTypeEmail, Value: "ivan@example.com"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Flood", m.Subject)
+		assert.Equal(t, rendered.Email, m.Text)
+		assert.Equal(t, rendered.EmailHTML, m.HTML)
+	})
+	t.Run("when contact is telegram then markdown variant", func(t *testing.T) {
+		m, err := sender.transformMessageToStoreModel(message, rendered, variables, receiverID, models.Contact{Type: models.ContactTypeTelegram, Value: "12345"})
+		assert.NoError(t, err)
+		assert.Equal(t, rendered.Markdown, m.Text)
+		assert.Equal(t, models.TextFormatMarkdown, m.Format)
+	})
+	t.Run("when telegram variant is not set then text", func(t *testing.T) {
+		plain := *rendered
+		plain.Markdown = ""
+		m, err := sender.transformMessageToStoreModel(message, &plain, variables, receiverID, models.Contact{Type: models.ContactTypeTelegram, Value: "12345"})
+		assert.NoError(t, err)
+		assert.Equal(t, rendered.Text, m.Text)
+		assert.Empty(t, m.Format)
+	})
+	t.Run("when contact is whatsapp then whatsapp template", func(t *testing.T) {
+		m, err := sender.transformMessageToStoreModel(message, rendered, variables, receiverID, models.Contact{Type: models.ContactTypeWhatsApp, Value: "+79001234567"})
+		assert.NoError(t, err)
+		assert.Equal(t, message.WhatsApp, m.WhatsApp)
+	})
+	t.Run("when whatsapp template has parameters then filled for the receiver", func(t *testing.T) {
+		parameterized := message
+		parameterized.WhatsAppParameters = []string{"Receiver.FirstName", "City"}
+		m, err := sender.transformMessageToStoreModel(parameterized, rendered, variables, receiverID, models.Contact{Type: models.ContactTypeWhatsApp, Value: "+79001234567"})
+		assert.NoError(t, err)
+		assert.Equal(t, &models.WhatsAppTemplate{Name: "flood", Language: "ru", Parameters: []string{"Ivan", "Kazan"}}, m.WhatsApp)
+	})
+}
+
+type createdMessages struct {
+	messages []*models.MessageEntity
+}
+
+func (c *createdMessages) Create(_ context.Context, m *models.MessageEntity) error {
+	c.messages = append(c.messages, m)
+	return nil
+}
+
+func TestSender_send(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("when message cannot be rendered then it is stored failed", func(t *testing.T) {
+		store := &createdMessages{}
+		sender := New(store, nil, nil, nil, false, log)
+		message := models.MessageConsumer{
+			BroadcastID: uuid.New(),
+			Subject:     "Flood",
+			Text:        "Water level {{.Level}} m",
+			Status:      models.Created,
+		}
+		template, err := parseLocalized(message)
+		assert.NoError(t, err)
+
+		receiversCh := make(chan *models.Receiver, 1)
+		receiversCh <- &models.Receiver{ID: uuid.New(), Contacts: []models.Contact{{Type: models.ContactTypeSMS, Value: "+79001234567", IsActive: true}}}
+		close(receiversCh)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		sender.send(context.Background(), receiversCh, message, template, &wg)
+
+		assert.Len(t, store.messages, 1)
+		assert.Equal(t, models.Failed, store.messages[0].Status)
+		assert.Contains(t, store.messages[0].LastError, "Level")
+		assert.Equal(t, message.BroadcastID, store.messages[0].BroadcastID)
+	})
+}
+
+func TestSender_reaches(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	now := time.Now()
+	verified := models.Contact{Type: models.ContactTypeSMS, Value: "+79001234567", IsActive: true, VerifiedAt: &now}
+	unverified := models.Contact{Type: models.ContactTypeSMS, Value: "+79007654321", IsActive: true}
+	inactive := models.Contact{Type: models.ContactTypeSMS, Value: "+79000000000", VerifiedAt: &now}
+	optedOut := models.Contact{Type: models.ContactTypeSMS, Value: "+79001111111", IsActive: true, VerifiedAt: &now, OptedOutAt: &now}
+	critical := models.MessageConsumer{Critical: true}
+
+	t.Run("when every contact is sent messages then verified ones are not required", func(t *testing.T) {
+		sender := New(nil, nil, nil, nil, false, log)
+		assert.True(t, sender.reaches(models.MessageConsumer{}, unverified))
+		assert.False(t, sender.reaches(critical, inactive))
+	})
+	t.Run("when verified contacts only then unverified ones get critical messages only", func(t *testing.T) {
+		sender := New(nil, nil, nil, nil, true, log)
+		assert.True(t, sender.reaches(models.MessageConsumer{}, verified))
+		assert.False(t, sender.reaches(models.MessageConsumer{}, unverified))
+		assert.True(t, sender.reaches(critical, unverified))
+		assert.False(t, sender.reaches(critical, inactive))
+	})
+	t.Run("when contact opted out then critical messages only", func(t *testing.T) {
+		sender := New(nil, nil, nil, nil, false, log)
+		assert.False(t, sender.reaches(models.MessageConsumer{}, optedOut))
+		assert.True(t, sender.reaches(critical, optedOut))
+		optedOut.IsActive = false
+		assert.False(t, sender.reaches(critical, optedOut))
+	})
+}
+
+type receiverPages struct {
+	receivers []models.ReceiverEntity
+	filters   []models.ReceiverFilter
+}
+
+func (p *receiverPages) List(_ context.Context, filter models.ReceiverFilter) ([]models.ReceiverEntity, int, error) {
+	p.filters = append(p.filters, filter)
+	start := 0
+	for i, r := range p.receivers {
+		if r.ID == filter.After {
+			start = i + 1
+		}
+	}
+	end := start + filter.Limit
+	if end > len(p.receivers) {
+		end = len(p.receivers)
+	}
+	return p.receivers[start:end], 0, nil
+}
+
+type broadcasts struct {
+	cancelledAt *time.Time
+}
+
+func (b *broadcasts) GetByID(_ context.Context, id uuid.UUID) (*models.BroadcastEntity, error) {
+	return &models.BroadcastEntity{ID: id, CancelledAt: b.cancelledAt}, nil
+}
+
+func TestSender_writeReceiversToChannel(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	pages := &receiverPages{}
+	for i := 0; i < receiverBatchSize+1; i++ {
+		pages.receivers = append(pages.receivers, models.ReceiverEntity{ID: uuid.New(), City: "Kazan"})
+	}
+	sender := New(nil, pages, &broadcasts{}, nil, false, log)
+
+	receiversCh := make(chan *models.Receiver, len(pages.receivers))
+	err := sender.writeReceiversToChannel(context.Background(), uuid.New(), "Kazan", receiversCh)
+	assert.NoError(t, err)
+
+	count := 0
+	for range receiversCh {
+		count++
+	}
+	assert.Equal(t, len(pages.receivers), count)
+	assert.Len(t, pages.filters, 2)
+	assert.Equal(t, pages.receivers[receiverBatchSize-1].ID, pages.filters[1].After)
+	assert.True(t, pages.filters[0].WithoutTotal)
+}
+
+func TestSender_writeReceiversToChannel_cancelled(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	pages := &receiverPages{receivers: []models.ReceiverEntity{{ID: uuid.New(), City: "Kazan"}}}
+	now := time.Now()
+	sender := New(nil, pages, &broadcasts{cancelledAt: &now}, nil, false, log)
+
+	receiversCh := make(chan *models.Receiver, len(pages.receivers))
+	err := sender.writeReceiversToChannel(context.Background(), uuid.New(), "Kazan", receiversCh)
+	assert.ErrorIs(t, err, errBroadcastCancelled)
+	assert.Empty(t, pages.filters)
+}
@@ -0,0 +1,26 @@
+package senders
+
+import (
+	"fmt"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/render"
+)
+
+// fitSMS applies the segment policy of the template to an SMS longer than its maximum number of segments.
+// It returns the text to be sent, its segments and an error if the policy rejects the SMS.
+func fitSMS(text string, maxSegments int, policy models.SegmentPolicy) (string, render.Segments, error) {
+	segments := render.CountSegments(text)
+	if maxSegments == 0 || segments.Count <= maxSegments {
+		return text, segments, nil
+	}
+
+	switch policy {
+	case "", models.SegmentPolicyTruncate:
+		text = render.Truncate(text, maxSegments)
+	case models.SegmentPolicyTransliterate:
+		text = render.Truncate(render.Transliterate(text), maxSegments)
+	default:
+		return text, segments, fmt.Errorf("sms takes %d %s segments, max is %d", segments.Count, segments.Encoding, maxSegments)
+	}
+	return text, render.CountSegments(text), nil
+}
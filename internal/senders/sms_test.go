@@ -0,0 +1,51 @@
+package senders
+
+import (
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/render"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFitSMS(t *testing.T) {
+	long := strings.Repeat("Наводнение в Казани. ", 5)
+
+	t.Run("when segments are not limited then as is", func(t *testing.T) {
+		text, segments, err := fitSMS(long, 0, "")
+		assert.NoError(t, err)
+		assert.Equal(t, long, text)
+		assert.Equal(t, render.EncodingUCS2, segments.Encoding)
+		assert.Equal(t, 2, segments.Count)
+	})
+	t.Run("when sms fits then as is", func(t *testing.T) {
+		text, segments, err := fitSMS(long, 2, models.SegmentPolicyReject)
+		assert.NoError(t, err)
+		assert.Equal(t, long, text)
+		assert.Equal(t, 2, segments.Count)
+	})
+	t.Run("when policy is reject then error", func(t *testing.T) {
+		_, segments, err := fitSMS(long, 1, models.SegmentPolicyReject)
+		assert.Error(t, err)
+		assert.Equal(t, 2, segments.Count)
+	})
+	t.Run("when policy is truncate then one segment with ellipsis", func(t *testing.T) {
+		text, segments, err := fitSMS(long, 1, models.SegmentPolicyTruncate)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasSuffix(text, "…"))
+		assert.Equal(t, 1, segments.Count)
+	})
+	t.Run("when policy is not set then truncated", func(t *testing.T) {
+		_, segments, err := fitSMS(long, 1, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, segments.Count)
+	})
+	t.Run("when policy is transliterate then gsm-7", func(t *testing.T) {
+		text, segments, err := fitSMS(long, 1, models.SegmentPolicyTransliterate)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(text, "Navodnenie v Kazani."))
+		assert.Equal(t, render.EncodingGSM7, segments.Encoding)
+		assert.Equal(t, 1, segments.Count)
+	})
+}
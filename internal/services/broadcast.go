@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"log/slog"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"sort"
+)
+
+type BroadcastService struct {
+	broadcastStore BroadcastStore
+	log            *slog.Logger
+}
+
+type BroadcastStore interface {
+	Create(ctx context.Context, b *models.BroadcastEntity) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BroadcastEntity, error)
+	CountMessages(ctx context.Context, id uuid.UUID) ([]models.BroadcastStatusCount, error)
+	CountFallbacks(ctx context.Context, id uuid.UUID) (int, error)
+	FindLatest(ctx context.Context, limit int) ([]models.BroadcastEntity, error)
+}
+
+func NewBroadcast(broadcastStore BroadcastStore, log *slog.Logger) *BroadcastService {
+	return &BroadcastService{
+		broadcastStore: broadcastStore,
+		log:            log,
+	}
+}
+
+// GetByID returns the broadcast with the number of created, delivered and failed messages per channel.
+func (s *BroadcastService) GetByID(ctx context.Context, id string) (*models.BroadcastSummary, error) {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
+
+	broadcast, err := s.broadcastStore.GetByID(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("broadcastID", id)).
+			Error("getting broadcast", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errorx.ErrNotFound
+		}
+		return nil, errorx.ErrInternal
+	}
+
+	counts, err := s.broadcastStore.CountMessages(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("broadcastID", id)).
+			Error("counting broadcast messages", err)
+		return nil, errorx.ErrInternal
+	}
+
+	fallbacks, err := s.broadcastStore.CountFallbacks(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("broadcastID", id)).
+			Error("counting broadcast fallbacks", err)
+		return nil, errorx.ErrInternal
+	}
+
+	summary := &models.BroadcastSummary{
+		Broadcast:         transformBroadcastStoreModelToBroadcast(broadcast),
+		Channels:          s.countChannels(counts),
+		FallbackReceivers: fallbacks,
+	}
+	return summary, nil
+}
+
+func (s *BroadcastService) countChannels(counts []models.BroadcastStatusCount) []models.BroadcastChannel {
+	channels := make(map[models.ContactType]*models.BroadcastChannel)
+	for _, c := range counts {
+		channel, ok := channels[c.Type]
+		if !ok {
+			channel = &models.BroadcastChannel{Type: c.Type}
+			channels[c.Type] = channel
+		}
+		channel.Add(c.Status, c.Count, c.Segments)
+	}
+
+	result := make([]models.BroadcastChannel, 0, len(channels))
+	for _, channel := range channels {
+		result = append(result, *channel)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Type < result[j].Type
+	})
+	return result
+}
+
+func transformBroadcastStoreModelToBroadcast(b *models.BroadcastEntity) models.Broadcast {
+	broadcast := models.Broadcast{
+		ID:              b.ID,
+		TemplateID:      b.TemplateID,
+		TemplateVersion: b.TemplateVersion,
+		Subject:         b.Subject,
+		Text:            b.Text,
+		City:            b.City,
+		Strength:        b.Strength,
+		Critical:        b.Critical,
+		CancelledAt:     b.CancelledAt,
+	}
+	if b.CreatedAt != nil {
+		broadcast.CreatedAt = *b.CreatedAt
+	}
+	return broadcast
+}
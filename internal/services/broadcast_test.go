@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/services/mocks"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBroadcastService_GetByID(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	store := mock_services.NewMockBroadcastStore(controller)
+	service := NewBroadcast(store, log)
+
+	t.Run("when broadcast exists then counters are grouped by channel", func(t *testing.T) {
+		id := uuid.New()
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.BroadcastEntity{ID: id, City: "Kazan"}, nil)
+		store.
+			EXPECT().
+			CountMessages(ctx, id).
+			Return([]models.BroadcastStatusCount{
+				{Type: models.ContactTypeSMS, Status: models.Created, Count: 3, Segments: 3},
+				{Type: models.ContactTypeSMS, Status: models.Delivered, Count: 5, Segments: 10},
+				{Type: models.ContactTypeSMS, Status: models.Undelivered, Count: 1, Segments: 2},
+				{Type: models.ContactTypeEmail, Status: models.Failed, Count: 1},
+				{Type: models.ContactTypeEmail, Status: models.Delivered, Count: 2},
+				{Type: models.ContactTypeEmail, Status: models.Bounced, Count: 1},
+				{Type: models.ContactTypeSMS, Status: models.Accepted, Count: 2, Segments: 2},
+				{Type: models.ContactTypeSMS, Status: models.Sent, Count: 1, Segments: 3},
+			}, nil)
+		store.
+			EXPECT().
+			CountFallbacks(ctx, id).
+			Return(4, nil)
+
+		summary, err := service.GetByID(ctx, id.String())
+		assert.NoError(t, err)
+		assert.Equal(t, id, summary.ID)
+		assert.Equal(t, []models.BroadcastChannel{
+			{Type: models.ContactTypeEmail, Delivered: 2, Undelivered: 1, Failed: 1},
+			{Type: models.ContactTypeSMS, Created: 3, Sent: 3, Delivered: 5, Undelivered: 1, Segments: 17},
+		}, summary.Channels)
+		assert.Equal(t, 4, summary.FallbackReceivers)
+	})
+	t.Run("when id is invalid then error", func(t *testing.T) {
+		summary, err := service.GetByID(ctx, "abc")
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+		assert.Nil(t, summary)
+	})
+	t.Run("when broadcast is not found then error", func(t *testing.T) {
+		id := uuid.New()
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(nil, sql.ErrNoRows)
+
+		summary, err := service.GetByID(ctx, id.String())
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+		assert.Nil(t, summary)
+	})
+	t.Run("when counting returns error then error", func(t *testing.T) {
+		id := uuid.New()
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.BroadcastEntity{ID: id}, nil)
+		store.
+			EXPECT().
+			CountMessages(ctx, id).
+			Return(nil, errors.New(""))
+
+		summary, err := service.GetByID(ctx, id.String())
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+		assert.Nil(t, summary)
+	})
+	t.Run("when counting fallbacks returns error then error", func(t *testing.T) {
+		id := uuid.New()
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.BroadcastEntity{ID: id}, nil)
+		store.
+			EXPECT().
+			CountMessages(ctx, id).
+			Return(nil, nil)
+		store.
+			EXPECT().
+			CountFallbacks(ctx, id).
+			Return(0, errors.New(""))
+
+		summary, err := service.GetByID(ctx, id.String())
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+		assert.Nil(t, summary)
+	})
+}
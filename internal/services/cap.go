@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"projects/emergency-messages/internal/cap"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/render"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxCapSubjectLength is the length of the message subject column
+	maxCapSubjectLength = 50
+	// maxCapAuthorLength is the length of the template author column
+	maxCapAuthorLength = 100
+	// capStrengthSeparator separates the severity and the urgency in the strength of a CAP message
+	capStrengthSeparator = " / "
+)
+
+type CapService struct {
+	templateStore   TemplateStore
+	capAlertStore   CapAlertStore
+	messageStore    MessageCanceller
+	messageSender   MessageSender
+	defaultLanguage string
+	log             *slog.Logger
+}
+
+type CapAlertStore interface {
+	Create(ctx context.Context, a *models.CapAlertEntity) (bool, error)
+	AddBroadcasts(ctx context.Context, id uuid.UUID, broadcastIDs []uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindBroadcastIDs(ctx context.Context, references []cap.Reference) ([]uuid.UUID, error)
+}
+
+type MessageCanceller interface {
+	CancelByBroadcasts(ctx context.Context, broadcastIDs []uuid.UUID) (int, error)
+}
+
+type MessageSender interface {
+	Send(ctx context.Context, message models.MessageRequest) (*models.Broadcast, error)
+}
+
+// NewCap creates the service ingesting CAP 1.2 alerts.
+// When an alert has infos in several languages, only the infos of the default language are sent,
+// or the infos of the first language if the alert has none in the default one.
+func NewCap(templateStore TemplateStore, capAlertStore CapAlertStore, messageStore MessageCanceller, messageSender MessageSender, defaultLanguage string, log *slog.Logger) *CapService {
+	return &CapService{
+		templateStore:   templateStore,
+		capAlertStore:   capAlertStore,
+		messageStore:    messageStore,
+		messageSender:   messageSender,
+		defaultLanguage: defaultLanguage,
+		log:             log,
+	}
+}
+
+// Ingest parses a CAP document and sends its infos to the receivers of the area cities.
+// The severity and the urgency of an info become the strength of the message,
+// the headline and the description become the subject and the text of a new template.
+// An Update or a Cancel cancels the broadcasts of the alerts it references with their not yet sent messages.
+// It returns a *cap.ValidationError with every problem of a malformed document.
+func (s *CapService) Ingest(ctx context.Context, r io.Reader) (*models.CapAlert, error) {
+	alert, err := cap.Parse(r)
+	if err != nil {
+		s.log.Error("parsing cap alert", err)
+		return nil, err
+	}
+
+	log := s.log.With(slog.String("identifier", alert.Identifier), slog.String("sender", alert.Sender))
+
+	// the alert is recorded first, so an alert received twice at once is delivered once
+	entity := transformCapAlertToStoreModel(alert)
+	created, err := s.capAlertStore.Create(ctx, entity)
+	if err != nil {
+		log.Error("creating cap alert", err)
+		return nil, errorx.ErrInternal
+	}
+	if !created {
+		return nil, &cap.ValidationError{Problems: []cap.Problem{{Field: "alert.identifier", Message: "alert is already received"}}}
+	}
+
+	result := &models.CapAlert{
+		Identifier: alert.Identifier,
+		Sender:     alert.Sender,
+		Status:     alert.Status,
+		MsgType:    alert.MsgType,
+		Broadcasts: make([]models.Broadcast, 0),
+	}
+
+	if alert.Supersedes() {
+		result.Cancelled, err = s.cancelReferences(ctx, alert)
+		if err != nil {
+			log.Error("cancelling referenced cap alerts", err)
+			s.forget(ctx, entity.ID, log)
+			return nil, errorx.ErrInternal
+		}
+	}
+
+	if alert.Deliverable() {
+		for _, info := range s.selectInfos(alert.Info) {
+			broadcasts, err := s.deliver(ctx, alert, info)
+			result.Broadcasts = append(result.Broadcasts, broadcasts...)
+			if err != nil {
+				if len(result.Broadcasts) == 0 {
+					// the alert is not kept when it is not delivered, so its sender may send it again
+					s.forget(ctx, entity.ID, log)
+					return nil, err
+				}
+				// the alert is kept with the broadcasts sent before, so a retry does not send them again
+				// and an Update or a Cancel referencing the alert still cancels them
+				if addErr := s.addBroadcasts(ctx, entity.ID, result.Broadcasts); addErr != nil {
+					log.Error("creating cap alert broadcasts", addErr)
+				}
+				return nil, err
+			}
+		}
+	}
+
+	if err = s.addBroadcasts(ctx, entity.ID, result.Broadcasts); err != nil {
+		log.Error("creating cap alert broadcasts", err)
+		return nil, errorx.ErrInternal
+	}
+
+	return result, nil
+}
+
+// addBroadcasts records the broadcasts sent for the alert.
+func (s *CapService) addBroadcasts(ctx context.Context, id uuid.UUID, broadcasts []models.Broadcast) error {
+	broadcastIDs := make([]uuid.UUID, 0, len(broadcasts))
+	for _, b := range broadcasts {
+		broadcastIDs = append(broadcastIDs, b.ID)
+	}
+	return s.capAlertStore.AddBroadcasts(ctx, id, broadcastIDs)
+}
+
+// forget deletes the alert which is not delivered.
+func (s *CapService) forget(ctx context.Context, id uuid.UUID, log *slog.Logger) {
+	if err := s.capAlertStore.Delete(ctx, id); err != nil {
+		log.Error("deleting cap alert", err)
+	}
+}
+
+func (s *CapService) cancelReferences(ctx context.Context, alert *cap.Alert) (int, error) {
+	references, err := alert.ParseReferences()
+	if err != nil {
+		return 0, err
+	}
+
+	broadcastIDs, err := s.capAlertStore.FindBroadcastIDs(ctx, references)
+	if err != nil {
+		return 0, err
+	}
+	if len(broadcastIDs) == 0 {
+		return 0, nil
+	}
+	return s.messageStore.CancelByBroadcasts(ctx, broadcastIDs)
+}
+
+// deliver creates the template of the info and sends it to every city of its areas.
+// It returns the broadcasts sent before an error too.
+func (s *CapService) deliver(ctx context.Context, alert *cap.Alert, info cap.Info) ([]models.Broadcast, error) {
+	template := transformCapInfoToTemplateStoreModel(alert.Sender, info)
+	if err := s.templateStore.Create(ctx, template); err != nil {
+		s.log.With(slog.Any("template", template)).
+			Error("creating cap template", err)
+		return nil, errorx.ErrInternal
+	}
+
+	cities := info.Cities()
+	broadcasts := make([]models.Broadcast, 0, len(cities))
+	for _, city := range cities {
+		request := models.MessageRequest{
+			TemplateID: template.ID,
+			City:       city,
+			Strength:   capStrength(info),
+			// an extraordinary threat to life or property is a life-safety alert
+			Critical: info.Severity == cap.SeverityExtreme,
+		}
+		broadcast, err := s.messageSender.Send(ctx, request)
+		if err != nil {
+			s.log.With(slog.Any("message", request)).
+				Error("sending cap alert", err)
+			return broadcasts, errorx.ErrInternal
+		}
+		broadcasts = append(broadcasts, *broadcast)
+	}
+	return broadcasts, nil
+}
+
+// selectInfos returns the infos of the default language,
+// or the infos of the language of the first info when there are none.
+func (s *CapService) selectInfos(infos []cap.Info) []cap.Info {
+	if len(infos) == 0 {
+		return nil
+	}
+
+	language := infos[0].Lang()
+	for _, info := range infos {
+		if s.defaultLanguage != "" && matchLanguage(info.Lang(), s.defaultLanguage) {
+			language = info.Lang()
+			break
+		}
+	}
+
+	selected := make([]cap.Info, 0, len(infos))
+	for _, info := range infos {
+		if info.Lang() == language {
+			selected = append(selected, info)
+		}
+	}
+	return selected
+}
+
+// matchLanguage reports whether the RFC 3066 language matches the wanted one,
+// e.g. ru-RU matches ru.
+func matchLanguage(language, want string) bool {
+	if strings.EqualFold(language, want) {
+		return true
+	}
+	primary, _, _ := strings.Cut(language, "-")
+	return strings.EqualFold(primary, want)
+}
+
+// capStrength returns the strength of the message, e.g. "Severe / Immediate".
+func capStrength(info cap.Info) string {
+	return info.Severity + capStrengthSeparator + info.Urgency
+}
+
+// transformCapInfoToTemplateStoreModel creates the template of the info authored by the sender of the alert.
+func transformCapInfoToTemplateStoreModel(sender string, info cap.Info) *models.TemplateEntity {
+	subject := info.Headline
+	if strings.TrimSpace(subject) == "" {
+		subject = info.Event
+	}
+	subject = truncate(subject, maxCapSubjectLength)
+
+	parts := []string{"{{.City}}: {{.Strength}}"}
+	if info.Description != "" {
+		parts = append(parts, render.Escape(info.Description))
+	}
+	if info.Instruction != "" {
+		parts = append(parts, render.Escape(info.Instruction))
+	}
+
+	return &models.TemplateEntity{
+		ID:        uuid.New(),
+		Subject:   render.Escape(subject),
+		Text:      strings.Join(parts, "\n\n"),
+		UpdatedBy: truncate(sender, maxCapAuthorLength),
+	}
+}
+
+// truncate cuts the text to the number of characters.
+func truncate(text string, length int) string {
+	if utf8.RuneCountInString(text) > length {
+		return string([]rune(text)[:length])
+	}
+	return text
+}
+
+func transformCapAlertToStoreModel(alert *cap.Alert) *models.CapAlertEntity {
+	return &models.CapAlertEntity{
+		Identifier: alert.Identifier,
+		Sender:     alert.Sender,
+		Sent:       alert.SentAt(),
+		Status:     alert.Status,
+		MsgType:    alert.MsgType,
+	}
+}
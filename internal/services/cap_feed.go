@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"log/slog"
+	"projects/emergency-messages/internal/cap"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"strings"
+	"time"
+)
+
+const (
+	capFeedTitle = "Emergency alerts"
+	// capCancelledTitle prefixes the title of the entry of a cancelled broadcast
+	capCancelledTitle = "Cancelled: "
+	// capCancelSuffix makes the identifier of the Cancel message of a cancelled broadcast
+	capCancelSuffix = "-cancel"
+)
+
+type CapFeedService struct {
+	broadcastStore BroadcastStore
+	sender         string
+	url            string
+	size           int
+	log            *slog.Logger
+}
+
+// NewCapFeed creates the service exporting the issued broadcasts as CAP alerts.
+// The sender identifies this service in the alerts,
+// the url is the public address of the CAP endpoints used for the links of the feed,
+// the size is the number of the latest broadcasts in the feed.
+func NewCapFeed(broadcastStore BroadcastStore, sender, url string, size int, log *slog.Logger) *CapFeedService {
+	return &CapFeedService{
+		broadcastStore: broadcastStore,
+		sender:         sender,
+		url:            strings.TrimSuffix(url, "/"),
+		size:           size,
+		log:            log,
+	}
+}
+
+// Feed returns the Atom feed of the latest broadcasts.
+// Every entry links to the CAP document of the broadcast, a cancelled broadcast is updated at its cancellation.
+func (s *CapFeedService) Feed(ctx context.Context) (*cap.Feed, error) {
+	entities, err := s.broadcastStore.FindLatest(ctx, s.size)
+	if err != nil {
+		s.log.Error("finding latest broadcasts", err)
+		return nil, errorx.ErrInternal
+	}
+
+	updated := time.Now()
+	if len(entities) > 0 && entities[0].CreatedAt != nil {
+		updated = *entities[0].CreatedAt
+	}
+	for _, e := range entities {
+		if e.CancelledAt != nil && e.CancelledAt.After(updated) {
+			updated = *e.CancelledAt
+		}
+	}
+
+	feed := &cap.Feed{
+		ID:      s.url + "/feed",
+		Title:   capFeedTitle,
+		Updated: updated.Format(time.RFC3339),
+		Author:  &cap.Author{Name: s.sender},
+		Links: []cap.Link{
+			{Rel: "self", Type: cap.FeedContentType, Href: s.url + "/feed"},
+		},
+		Entries: make([]cap.Entry, 0, len(entities)),
+	}
+	for i := range entities {
+		broadcast := transformBroadcastStoreModelToBroadcast(&entities[i])
+		feed.Entries = append(feed.Entries, s.transformBroadcastToFeedEntry(broadcast))
+	}
+	return feed, nil
+}
+
+// GetAlert returns the CAP alert of the broadcast, the Cancel message of the alert if the broadcast is cancelled.
+func (s *CapFeedService) GetAlert(ctx context.Context, id string) (*cap.Alert, error) {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
+
+	entity, err := s.broadcastStore.GetByID(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("broadcastID", id)).
+			Error("getting broadcast", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errorx.ErrNotFound
+		}
+		return nil, errorx.ErrInternal
+	}
+
+	return s.transformBroadcastToCapAlert(transformBroadcastStoreModelToBroadcast(entity)), nil
+}
+
+func (s *CapFeedService) alertURL(id uuid.UUID) string {
+	return s.url + "/alerts/" + id.String()
+}
+
+func (s *CapFeedService) transformBroadcastToFeedEntry(b models.Broadcast) cap.Entry {
+	created := b.CreatedAt.Format(time.RFC3339)
+	title, updated := b.Subject, created
+	if b.CancelledAt != nil {
+		title, updated = capCancelledTitle+b.Subject, b.CancelledAt.Format(time.RFC3339)
+	}
+	return cap.Entry{
+		ID:        "urn:uuid:" + b.ID.String(),
+		Title:     title,
+		Updated:   updated,
+		Published: created,
+		Summary:   b.Text,
+		Category:  []cap.Category{{Term: b.City}},
+		Links: []cap.Link{
+			{Rel: "alternate", Type: cap.ContentType, Href: s.alertURL(b.ID)},
+		},
+	}
+}
+
+// transformBroadcastToCapAlert maps the broadcast onto a CAP alert.
+// The severity and the urgency are known for broadcasts of ingested CAP alerts only,
+// the strength is kept as a parameter of the info.
+// A cancelled broadcast is a Cancel message sent at the cancellation and referencing the alert of the broadcast.
+func (s *CapFeedService) transformBroadcastToCapAlert(b models.Broadcast) *cap.Alert {
+	severity, urgency := cap.SeverityUnknown, cap.UrgencyUnknown
+	if sev, urg, ok := strings.Cut(b.Strength, capStrengthSeparator); ok && cap.IsSeverity(sev) && cap.IsUrgency(urg) {
+		severity, urgency = sev, urg
+	}
+
+	identifier, sent, msgType, references := b.ID.String(), cap.FormatTime(b.CreatedAt), cap.MsgTypeAlert, ""
+	if b.CancelledAt != nil {
+		references = strings.Join([]string{s.sender, identifier, sent}, ",")
+		identifier, sent, msgType = identifier+capCancelSuffix, cap.FormatTime(*b.CancelledAt), cap.MsgTypeCancel
+	}
+
+	return &cap.Alert{
+		Identifier: identifier,
+		Sender:     s.sender,
+		Sent:       sent,
+		Status:     cap.StatusActual,
+		MsgType:    msgType,
+		Scope:      cap.ScopePublic,
+		References: references,
+		Info: []cap.Info{
+			{
+				Category:    []string{cap.CategoryOther},
+				Event:       b.Subject,
+				Urgency:     urgency,
+				Severity:    severity,
+				Certainty:   cap.CertaintyUnknown,
+				Headline:    b.Subject,
+				Description: b.Text,
+				Web:         s.alertURL(b.ID),
+				Parameter: []cap.Parameter{
+					{ValueName: "strength", Value: b.Strength},
+				},
+				Area: []cap.Area{
+					{AreaDesc: b.City},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/cap"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/services/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCapFeedService_Feed(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	store := mock_services.NewMockBroadcastStore(controller)
+	service := NewCapFeed(store, "emergency-messages", "https://alerts.example.org/api/v1/cap/", 2, log)
+
+	t.Run("when broadcasts exist then entries link to alerts", func(t *testing.T) {
+		id := uuid.New()
+		created := time.Date(2024, 4, 22, 10, 0, 0, 0, time.UTC)
+		store.
+			EXPECT().
+			FindLatest(ctx, 2).
+			Return([]models.BroadcastEntity{{ID: id, Subject: "Storm", Text: "Kazan: strong", City: "Kazan", CreatedAt: &created}}, nil)
+
+		feed, err := service.Feed(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://alerts.example.org/api/v1/cap/feed", feed.ID)
+		assert.Equal(t, "2024-04-22T10:00:00Z", feed.Updated)
+		assert.Len(t, feed.Entries, 1)
+		assert.Equal(t, "urn:uuid:"+id.String(), feed.Entries[0].ID)
+		assert.Equal(t, []cap.Link{{Rel: "alternate", Type: cap.ContentType, Href: "https://alerts.example.org/api/v1/cap/alerts/" + id.String()}}, feed.Entries[0].Links)
+	})
+	t.Run("when broadcast is cancelled then entry is updated at cancellation", func(t *testing.T) {
+		created := time.Date(2024, 4, 22, 10, 0, 0, 0, time.UTC)
+		cancelled := created.Add(time.Hour)
+		store.
+			EXPECT().
+			FindLatest(ctx, 2).
+			Return([]models.BroadcastEntity{
+				{ID: uuid.New(), Subject: "Wind", City: "Kazan", CreatedAt: &created},
+				{ID: uuid.New(), Subject: "Storm", City: "Kazan", CreatedAt: &created, CancelledAt: &cancelled},
+			}, nil)
+
+		feed, err := service.Feed(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-04-22T11:00:00Z", feed.Updated)
+		assert.Equal(t, "Cancelled: Storm", feed.Entries[1].Title)
+		assert.Equal(t, "2024-04-22T11:00:00Z", feed.Entries[1].Updated)
+		assert.Equal(t, "2024-04-22T10:00:00Z", feed.Entries[1].Published)
+	})
+	t.Run("when finding returns error then error", func(t *testing.T) {
+		store.
+			EXPECT().
+			FindLatest(ctx, 2).
+			Return(nil, sql.ErrConnDone)
+
+		feed, err := service.Feed(ctx)
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+		assert.Nil(t, feed)
+	})
+}
+
+func TestCapFeedService_GetAlert(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	store := mock_services.NewMockBroadcastStore(controller)
+	service := NewCapFeed(store, "emergency-messages", "https://alerts.example.org/api/v1/cap", 50, log)
+
+	t.Run("when broadcast exists then alert is a valid cap document", func(t *testing.T) {
+		id := uuid.New()
+		created := time.Date(2024, 4, 22, 10, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.BroadcastEntity{
+				ID:        id,
+				Subject:   "Storm",
+				Text:      "Kazan: Severe / Immediate",
+				City:      "Kazan",
+				Strength:  "Severe / Immediate",
+				CreatedAt: &created,
+			}, nil)
+
+		alert, err := service.GetAlert(ctx, id.String())
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-04-22T10:00:00+03:00", alert.Sent)
+		assert.Equal(t, "Severe", alert.Info[0].Severity)
+		assert.Equal(t, "Immediate", alert.Info[0].Urgency)
+
+		b, err := xml.Marshal(alert)
+		assert.NoError(t, err)
+		parsed, err := cap.Parse(bytes.NewReader(b))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Kazan"}, parsed.Info[0].Cities())
+	})
+	t.Run("when broadcast is cancelled then alert is a cancel referencing it", func(t *testing.T) {
+		id := uuid.New()
+		created := time.Date(2024, 4, 22, 10, 0, 0, 0, time.UTC)
+		cancelled := created.Add(time.Hour)
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.BroadcastEntity{ID: id, Subject: "Storm", City: "Kazan", Strength: "Severe / Immediate", CreatedAt: &created, CancelledAt: &cancelled}, nil)
+
+		alert, err := service.GetAlert(ctx, id.String())
+		assert.NoError(t, err)
+		assert.Equal(t, cap.MsgTypeCancel, alert.MsgType)
+		assert.Equal(t, id.String()+"-cancel", alert.Identifier)
+		assert.Equal(t, "2024-04-22T11:00:00+00:00", alert.Sent)
+
+		b, err := xml.Marshal(alert)
+		assert.NoError(t, err)
+		parsed, err := cap.Parse(bytes.NewReader(b))
+		assert.NoError(t, err)
+		references, err := parsed.ParseReferences()
+		assert.NoError(t, err)
+		assert.Equal(t, []cap.Reference{{Sender: "emergency-messages", Identifier: id.String(), Sent: "2024-04-22T10:00:00+00:00"}}, references)
+	})
+	t.Run("when strength is not a cap one then severity is unknown", func(t *testing.T) {
+		id := uuid.New()
+		created := time.Now()
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.BroadcastEntity{ID: id, Subject: "Wind", City: "Kazan", Strength: "strong wind", CreatedAt: &created}, nil)
+
+		alert, err := service.GetAlert(ctx, id.String())
+		assert.NoError(t, err)
+		assert.Equal(t, cap.SeverityUnknown, alert.Info[0].Severity)
+		assert.Equal(t, []cap.Parameter{{ValueName: "strength", Value: "strong wind"}}, alert.Info[0].Parameter)
+	})
+	t.Run("when broadcast is not found then error", func(t *testing.T) {
+		id := uuid.New()
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(nil, sql.ErrNoRows)
+
+		alert, err := service.GetAlert(ctx, id.String())
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+		assert.Nil(t, alert)
+	})
+}
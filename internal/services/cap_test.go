@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/cap"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/services/mocks"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const capAlert = `<alert xmlns="urn:oasis:names:tc:emergency:cap:1.2">
+  <identifier>KSN-2</identifier>
+  <sender>mchs@example.org</sender>
+  <sent>2024-04-15T19:00:00+03:00</sent>
+  <status>Actual</status>
+  <msgType>Update</msgType>
+  <scope>Public</scope>
+  <references>mchs@example.org,KSN-1,2024-04-15T18:45:00+03:00</references>
+  <info>
+    <language>en-US</language>
+    <category>Met</category>
+    <event>Storm</event>
+    <urgency>Immediate</urgency>
+    <severity>Extreme</severity>
+    <certainty>Observed</certainty>
+    <headline>Storm</headline>
+    <area><areaDesc>Kazan</areaDesc></area>
+  </info>
+  <info>
+    <language>ru-RU</language>
+    <category>Met</category>
+    <event>Шторм</event>
+    <urgency>Immediate</urgency>
+    <severity>Extreme</severity>
+    <certainty>Observed</certainty>
+    <headline>Штормовое предупреждение</headline>
+    <description>Порывы ветра до 30 м/с, 100% вероятность</description>
+    <area><areaDesc>Kazan; Zelenodolsk</areaDesc></area>
+  </info>
+</alert>`
+
+func TestCapService_Ingest(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	templateStore := mock_services.NewMockTemplateStore(controller)
+	capAlertStore := mock_services.NewMockCapAlertStore(controller)
+	messageStore := mock_services.NewMockMessageCanceller(controller)
+	messageSender := mock_services.NewMockMessageSender(controller)
+	service := NewCap(templateStore, capAlertStore, messageStore, messageSender, "ru", log)
+
+	t.Run("when update is valid then references are cancelled and infos of the language are sent", func(t *testing.T) {
+		referenced := []uuid.UUID{uuid.New()}
+		capAlertStore.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+		capAlertStore.EXPECT().
+			FindBroadcastIDs(ctx, []cap.Reference{{Sender: "mchs@example.org", Identifier: "KSN-1", Sent: "2024-04-15T18:45:00+03:00"}}).
+			Return(referenced, nil)
+		messageStore.EXPECT().CancelByBroadcasts(ctx, referenced).Return(7, nil)
+
+		var template *models.TemplateEntity
+		templateStore.EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, t *models.TemplateEntity) error {
+				template = t
+				return nil
+			})
+		messageSender.EXPECT().
+			Send(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, m models.MessageRequest) (*models.Broadcast, error) {
+				return &models.Broadcast{ID: uuid.New(), TemplateID: m.TemplateID, City: m.City, Strength: m.Strength}, nil
+			}).
+			Times(2)
+		var broadcastIDs []uuid.UUID
+		capAlertStore.EXPECT().
+			AddBroadcasts(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, ids []uuid.UUID) error {
+				broadcastIDs = ids
+				return nil
+			})
+
+		alert, err := service.Ingest(ctx, strings.NewReader(capAlert))
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{alert.Broadcasts[0].ID, alert.Broadcasts[1].ID}, broadcastIDs)
+		assert.Equal(t, 7, alert.Cancelled)
+		assert.Len(t, alert.Broadcasts, 2)
+		assert.Equal(t, "Kazan", alert.Broadcasts[0].City)
+		assert.Equal(t, "Zelenodolsk", alert.Broadcasts[1].City)
+		assert.Equal(t, "Extreme / Immediate", alert.Broadcasts[0].Strength)
+		assert.Equal(t, template.ID, alert.Broadcasts[0].TemplateID)
+		assert.Equal(t, "Штормовое предупреждение", template.Subject)
+		assert.Equal(t, "{{.City}}: {{.Strength}}\n\nПорывы ветра до 30 м/с, 100% вероятность", template.Text)
+	})
+	t.Run("when alert is already received then report", func(t *testing.T) {
+		capAlertStore.EXPECT().Create(ctx, gomock.Any()).Return(false, nil)
+
+		alert, err := service.Ingest(ctx, strings.NewReader(capAlert))
+		var report *cap.ValidationError
+		assert.True(t, errors.As(err, &report))
+		assert.Nil(t, alert)
+	})
+	t.Run("when alert is a test then it is only recorded", func(t *testing.T) {
+		doc := strings.Replace(capAlert, "<status>Actual</status>", "<status>Test</status>", 1)
+		capAlertStore.EXPECT().Create(ctx, gomock.Any()).Return(true, nil)
+		capAlertStore.EXPECT().AddBroadcasts(ctx, gomock.Any(), []uuid.UUID{}).Return(nil)
+
+		alert, err := service.Ingest(ctx, strings.NewReader(doc))
+		assert.NoError(t, err)
+		assert.Empty(t, alert.Broadcasts)
+		assert.Equal(t, 0, alert.Cancelled)
+	})
+	t.Run("when sending returns error then alert is not kept", func(t *testing.T) {
+		doc := strings.Replace(capAlert, "<msgType>Update</msgType>", "<msgType>Alert</msgType>", 1)
+		id := uuid.New()
+		capAlertStore.EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, a *models.CapAlertEntity) (bool, error) {
+				a.ID = id
+				return true, nil
+			})
+		templateStore.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+		messageSender.EXPECT().Send(ctx, gomock.Any()).Return(nil, errorx.ErrNotFound)
+		capAlertStore.EXPECT().Delete(ctx, id).Return(nil)
+
+		alert, err := service.Ingest(ctx, strings.NewReader(doc))
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+		assert.Nil(t, alert)
+	})
+	t.Run("when sending to second city returns error then alert is kept with sent broadcasts", func(t *testing.T) {
+		doc := strings.Replace(capAlert, "<msgType>Update</msgType>", "<msgType>Alert</msgType>", 1)
+		id := uuid.New()
+		sent := uuid.New()
+		capAlertStore.EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, a *models.CapAlertEntity) (bool, error) {
+				a.ID = id
+				return true, nil
+			})
+		templateStore.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+		gomock.InOrder(
+			messageSender.EXPECT().Send(ctx, gomock.Any()).Return(&models.Broadcast{ID: sent, City: "Kazan"}, nil),
+			messageSender.EXPECT().Send(ctx, gomock.Any()).Return(nil, errors.New("connection refused")),
+		)
+		capAlertStore.EXPECT().AddBroadcasts(ctx, id, []uuid.UUID{sent}).Return(nil)
+
+		alert, err := service.Ingest(ctx, strings.NewReader(doc))
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+		assert.Nil(t, alert)
+	})
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// consentReply is the reply to a keyword in a language
+type consentReply struct {
+	optOut string
+	optIn  string
+	help   string
+}
+
+// consentReplies are the replies to the keywords by the language of the keyword
+var consentReplies = map[string]consentReply{
+	"en": {
+		optOut: "You are unsubscribed from emergency alerts and will get life-safety critical alerts only. Reply START to subscribe again.",
+		optIn:  "You are subscribed to emergency alerts again. Reply STOP to unsubscribe.",
+		help:   "Emergency alerts of your city. Reply STOP to unsubscribe, START to subscribe again.",
+	},
+	"ru": {
+		optOut: "Вы отписались от экстренных оповещений и будете получать только оповещения об угрозе жизни. Отправьте СТАРТ, чтобы подписаться снова.",
+		optIn:  "Вы снова подписаны на экстренные оповещения. Отправьте СТОП, чтобы отписаться.",
+		help:   "Экстренные оповещения вашего города. Отправьте СТОП, чтобы отписаться, СТАРТ, чтобы подписаться снова.",
+	},
+	"es": {
+		optOut: "Se ha dado de baja de las alertas de emergencia y solo recibirá alertas críticas para la vida. Responda ALTA para suscribirse de nuevo.",
+		optIn:  "Se ha suscrito de nuevo a las alertas de emergencia. Responda BAJA para darse de baja.",
+		help:   "Alertas de emergencia de su ciudad. Responda BAJA para darse de baja, ALTA para suscribirse de nuevo.",
+	},
+	"fr": {
+		optOut: "Vous êtes désabonné des alertes d'urgence et ne recevrez que les alertes vitales. Répondez COMMENCER pour vous réabonner.",
+		optIn:  "Vous êtes de nouveau abonné aux alertes d'urgence. Répondez ARRET pour vous désabonner.",
+		help:   "Alertes d'urgence de votre ville. Répondez ARRET pour vous désabonner, COMMENCER pour vous réabonner.",
+	},
+	"de": {
+		optOut: "Sie haben die Notfallwarnungen abbestellt und erhalten nur noch lebenswichtige Warnungen. Antworten Sie ANMELDEN, um sie wieder zu erhalten.",
+		optIn:  "Sie erhalten wieder Notfallwarnungen. Antworten Sie STOPP, um sie abzubestellen.",
+		help:   "Notfallwarnungen Ihrer Stadt. Antworten Sie STOPP zum Abbestellen, ANMELDEN zum erneuten Anmelden.",
+	},
+}
+
+// ConsentService handles the keywords of the SMS sent by receivers: STOP opts the receivers of the number out,
+// START opts them in again and HELP tells how to do it. Every change of a consent is logged.
+type ConsentService struct {
+	receiverStore ReceiverStore
+	consentStore  ContactConsentStore
+	// phoneCountry is the country of the phone numbers written without a country code
+	phoneCountry string
+	log          *slog.Logger
+}
+
+type ContactConsentStore interface {
+	Create(ctx context.Context, c *models.ContactConsentEntity) error
+	FindByReceiver(ctx context.Context, receiverID uuid.UUID) ([]models.ContactConsentEntity, error)
+	FindOptedOut(ctx context.Context, keys []models.ContactKey) (map[models.ContactKey]time.Time, error)
+}
+
+func NewConsent(receiverStore ReceiverStore, consentStore ContactConsentStore, phoneCountry string, log *slog.Logger) *ConsentService {
+	return &ConsentService{
+		receiverStore: receiverStore,
+		consentStore:  consentStore,
+		phoneCountry:  phoneCountry,
+		log:           log,
+	}
+}
+
+// Receive changes the consent of the receivers having the sms contact of the sender when the SMS is a keyword.
+// An opted out contact gets critical alerts only, an opted in contact gets every alert again if it is active.
+// It returns the reply in the language of the keyword, empty if the SMS is not a keyword.
+func (s *ConsentService) Receive(ctx context.Context, sms models.InboundSMS) (string, error) {
+	keyword, ok := models.ParseConsentKeyword(sms.Body)
+	if !ok {
+		return "", nil
+	}
+
+	contact := models.Contact{Type: models.ContactTypeSMS, Value: sms.From}
+	if err := contact.Normalize(s.phoneCountry); err != nil {
+		s.log.With(slog.String("messageSID", sms.MessageSID)).
+			Error("normalizing sender phone", err)
+		return "", errorx.ErrValidation
+	}
+	key := contact.Key()
+
+	receivers, err := s.receiverStore.FindByContact(ctx, key)
+	if err != nil {
+		s.log.With(slog.Any("contact", key)).
+			Error("finding receivers by contact", err)
+		return "", errorx.ErrInternal
+	}
+
+	now := time.Now()
+	consent := models.ContactConsentEntity{
+		Type:       key.Type,
+		Value:      key.Value,
+		Action:     keyword.Action,
+		Keyword:    keyword.Keyword,
+		MessageSID: sms.MessageSID,
+		CreatedAt:  &now,
+	}
+	if len(receivers) == 0 {
+		if err = s.consentStore.Create(ctx, &consent); err != nil {
+			s.log.With(slog.Any("contact", key)).
+				Error("logging contact consent", err)
+			return "", errorx.ErrInternal
+		}
+	}
+	for _, receiver := range receivers {
+		if keyword.Action != models.ConsentHelp {
+			_, err = s.receiverStore.UpdateContacts(ctx, receiver.ID, func(contacts []models.Contact) ([]models.Contact, error) {
+				if i := findContact(contacts, key); i >= 0 {
+					setConsent(&contacts[i], keyword.Action, now)
+				}
+				return contacts, nil
+			})
+			if err != nil {
+				s.log.With(slog.Any("receiverID", receiver.ID), slog.Any("contact", key)).
+					Error("changing contact consent", err)
+				return "", errorx.ErrInternal
+			}
+		}
+
+		consent.ID, consent.ReceiverID = uuid.Nil, receiver.ID
+		if err = s.consentStore.Create(ctx, &consent); err != nil {
+			s.log.With(slog.Any("receiverID", receiver.ID), slog.Any("contact", key)).
+				Error("logging contact consent", err)
+			return "", errorx.ErrInternal
+		}
+	}
+
+	return consentReplyText(keyword), nil
+}
+
+// Consents returns the changes of the consents of the receiver, the latest first.
+func (s *ConsentService) Consents(ctx context.Context, id string) ([]models.ContactConsent, error) {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
+
+	consents, err := s.consentStore.FindByReceiver(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("receiverID", id)).
+			Error("finding contact consents", err)
+		return nil, errorx.ErrInternal
+	}
+
+	result := make([]models.ContactConsent, 0, len(consents))
+	for _, c := range consents {
+		consent := models.ContactConsent{
+			ContactKey: models.ContactKey{Type: c.Type, Value: c.Value},
+			Action:     c.Action,
+			Keyword:    c.Keyword,
+			MessageSID: c.MessageSID,
+		}
+		if c.CreatedAt != nil {
+			consent.CreatedAt = *c.CreatedAt
+		}
+		result = append(result, consent)
+	}
+	return result, nil
+}
+
+// setConsent opts the contact out or in, the contact keeps its active flag,
+// so an opt in does not activate a contact deactivated by hand.
+func setConsent(contact *models.Contact, action models.ConsentAction, now time.Time) {
+	switch action {
+	case models.ConsentOptOut:
+		if !contact.IsOptedOut() {
+			contact.OptedOutAt = &now
+		}
+	case models.ConsentOptIn:
+		contact.OptedOutAt = nil
+	}
+}
+
+// consentReplyText returns the reply to the keyword in its language.
+func consentReplyText(keyword models.ConsentKeyword) string {
+	reply, ok := consentReplies[keyword.Language]
+	if !ok {
+		reply = consentReplies["en"]
+	}
+	switch keyword.Action {
+	case models.ConsentOptOut:
+		return reply.optOut
+	case models.ConsentOptIn:
+		return reply.optIn
+	default:
+		return reply.help
+	}
+}
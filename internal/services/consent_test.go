@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/services/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestConsentService_Receive(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	consentStore := mock_services.NewMockContactConsentStore(ctrl)
+	consentService := NewConsent(receiverStore, consentStore, "RU", log)
+
+	key := models.ContactKey{Type: models.ContactTypeSMS, Value: "+79001234567"}
+	first, second := uuid.New(), uuid.New()
+	optedOutAt := time.Now().Add(-time.Hour)
+
+	changeContacts := func(contacts []models.Contact, changed *[]models.Contact) func(context.Context, uuid.UUID, func([]models.Contact) ([]models.Contact, error)) (*models.ReceiverEntity, error) {
+		return func(_ context.Context, id uuid.UUID, change func([]models.Contact) ([]models.Contact, error)) (*models.ReceiverEntity, error) {
+			result, err := change(contacts)
+			if err != nil {
+				return nil, err
+			}
+			*changed = append(*changed, result...)
+			return &models.ReceiverEntity{ID: id, Contacts: result}, nil
+		}
+	}
+	logged := func(consents *[]models.ContactConsentEntity) func(context.Context, *models.ContactConsentEntity) error {
+		return func(_ context.Context, c *models.ContactConsentEntity) error {
+			*consents = append(*consents, *c)
+			return nil
+		}
+	}
+
+	t.Run("when stop is sent then contacts of every receiver are opted out", func(t *testing.T) {
+		var (
+			changed  []models.Contact
+			consents []models.ContactConsentEntity
+		)
+		email := models.Contact{Type: models.ContactTypeEmail, Value: "ivan@example.com", IsActive: true}
+		receiverStore.
+			EXPECT().
+			FindByContact(ctx, key).
+			Return([]models.ReceiverEntity{{ID: first}, {ID: second}}, nil)
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, first, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{{Type: key.Type, Value: key.Value, IsActive: true}, email}, &changed))
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, second, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{{Type: key.Type, Value: key.Value, IsActive: true}}, &changed))
+		consentStore.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(logged(&consents)).
+			Times(2)
+
+		reply, err := consentService.Receive(ctx, models.InboundSMS{MessageSID: "SM1", From: "+79001234567", Body: "Стоп"})
+		assert.NoError(t, err)
+		assert.Contains(t, reply, "СТАРТ")
+
+		assert.Len(t, changed, 3)
+		assert.True(t, changed[0].IsActive)
+		assert.True(t, changed[0].IsOptedOut())
+		assert.Equal(t, email, changed[1])
+		assert.True(t, changed[2].IsOptedOut())
+
+		assert.Len(t, consents, 2)
+		assert.Equal(t, first, consents[0].ReceiverID)
+		assert.Equal(t, second, consents[1].ReceiverID)
+		assert.Equal(t, models.ConsentOptOut, consents[1].Action)
+		assert.Equal(t, "СТОП", consents[1].Keyword)
+		assert.Equal(t, "SM1", consents[1].MessageSID)
+	})
+	t.Run("when start is sent then contact is opted in", func(t *testing.T) {
+		var (
+			changed  []models.Contact
+			consents []models.ContactConsentEntity
+		)
+		receiverStore.
+			EXPECT().
+			FindByContact(ctx, key).
+			Return([]models.ReceiverEntity{{ID: first}}, nil)
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, first, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{{Type: key.Type, Value: key.Value, IsActive: true, OptedOutAt: &optedOutAt}}, &changed))
+		consentStore.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(logged(&consents))
+
+		reply, err := consentService.Receive(ctx, models.InboundSMS{From: "89001234567", Body: "start"})
+		assert.NoError(t, err)
+		assert.Contains(t, reply, "STOP")
+		assert.Equal(t, []models.Contact{{Type: key.Type, Value: key.Value, IsActive: true}}, changed)
+		assert.Equal(t, models.ConsentOptIn, consents[0].Action)
+	})
+	t.Run("when start is sent then contact deactivated by hand stays inactive", func(t *testing.T) {
+		var changed []models.Contact
+		receiverStore.
+			EXPECT().
+			FindByContact(ctx, key).
+			Return([]models.ReceiverEntity{{ID: first}}, nil)
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, first, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{{Type: key.Type, Value: key.Value, OptedOutAt: &optedOutAt}}, &changed))
+		consentStore.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil)
+
+		_, err := consentService.Receive(ctx, models.InboundSMS{From: "+79001234567", Body: "START"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Contact{{Type: key.Type, Value: key.Value}}, changed)
+	})
+	t.Run("when help is sent then consent is not changed", func(t *testing.T) {
+		var consents []models.ContactConsentEntity
+		receiverStore.
+			EXPECT().
+			FindByContact(ctx, key).
+			Return([]models.ReceiverEntity{{ID: first}}, nil)
+		consentStore.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(logged(&consents))
+
+		reply, err := consentService.Receive(ctx, models.InboundSMS{From: "+79001234567", Body: "HELP"})
+		assert.NoError(t, err)
+		assert.Contains(t, reply, "STOP")
+		assert.Equal(t, models.ConsentHelp, consents[0].Action)
+	})
+	t.Run("when number is unknown then consent is logged without receiver", func(t *testing.T) {
+		var consents []models.ContactConsentEntity
+		receiverStore.
+			EXPECT().
+			FindByContact(ctx, key).
+			Return(nil, nil)
+		consentStore.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(logged(&consents))
+
+		_, err := consentService.Receive(ctx, models.InboundSMS{From: "+79001234567", Body: "STOP"})
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.Nil, consents[0].ReceiverID)
+		assert.Equal(t, key.Value, consents[0].Value)
+	})
+	t.Run("when sms is not a keyword then no reply", func(t *testing.T) {
+		reply, err := consentService.Receive(ctx, models.InboundSMS{From: "+79001234567", Body: "Stop sending me this"})
+		assert.NoError(t, err)
+		assert.Empty(t, reply)
+	})
+	t.Run("when sender is not a phone number then error", func(t *testing.T) {
+		_, err := consentService.Receive(ctx, models.InboundSMS{From: "alerts", Body: "STOP"})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+}
+
+func TestConsentService_Consents(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	consentStore := mock_services.NewMockContactConsentStore(ctrl)
+	consentService := NewConsent(nil, consentStore, "RU", log)
+
+	id := uuid.New()
+	createdAt := time.Now()
+	consentStore.
+		EXPECT().
+		FindByReceiver(ctx, id).
+		Return([]models.ContactConsentEntity{{ReceiverID: id, Type: models.ContactTypeSMS, Value: "+79001234567", Action: models.ConsentOptOut, Keyword: "STOP", CreatedAt: &createdAt}}, nil)
+
+	consents, err := consentService.Consents(ctx, id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ContactConsent{{
+		ContactKey: models.ContactKey{Type: models.ContactTypeSMS, Value: "+79001234567"},
+		Action:     models.ConsentOptOut,
+		Keyword:    "STOP",
+		CreatedAt:  createdAt,
+	}}, consents)
+}
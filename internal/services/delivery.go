@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"time"
+)
+
+// reportRetryDelays are the delays the message of a report is looked up again after,
+// a provider may report a message before the sender stores its ID.
+var reportRetryDelays = []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second}
+
+// webhookTokenTTL is the time the token of a webhook request is kept for,
+// a request is accepted while its timestamp is within 5 minutes of the current time.
+const webhookTokenTTL = 10 * time.Minute
+
+// DeliveryService changes the statuses of the sent messages by the delivery reports of their providers.
+type DeliveryService struct {
+	deliveryStore DeliveryStore
+	tokenStore    WebhookTokenStore
+	retryDelays   []time.Duration
+	log           *slog.Logger
+}
+
+type DeliveryStore interface {
+	UpdateDelivery(ctx context.Context, contactType models.ContactType, providerMessageID string, change func(*models.MessageEntity) bool) (*models.MessageEntity, bool, error)
+}
+
+type WebhookTokenStore interface {
+	Use(ctx context.Context, token string, expiresAt time.Time, process func(ctx context.Context) error) (bool, error)
+}
+
+func NewDelivery(deliveryStore DeliveryStore, tokenStore WebhookTokenStore, log *slog.Logger) *DeliveryService {
+	return &DeliveryService{
+		deliveryStore: deliveryStore,
+		tokenStore:    tokenStore,
+		retryDelays:   reportRetryDelays,
+		log:           log,
+	}
+}
+
+// Report changes the message the provider reports about, the status of the message moves forward only.
+// The provider may report a message before its ID is stored, so an unknown message is looked up again
+// after each of the retry delays before the report is not found.
+func (s *DeliveryService) Report(ctx context.Context, report models.DeliveryReport) error {
+	if report.ProviderMessageID == "" {
+		s.log.With(slog.Any("report", report)).
+			Error("validating delivery report", errorx.ErrValidation)
+		return errorx.ErrValidation
+	}
+
+	message, changed, err := s.deliveryStore.UpdateDelivery(ctx, report.Type, report.ProviderMessageID, report.Apply)
+	for _, delay := range s.retryDelays {
+		if !errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			s.log.With(slog.Any("report", report)).
+				Error("waiting for message of delivery report", ctx.Err())
+			return errorx.ErrInternal
+		case <-time.After(delay):
+		}
+		message, changed, err = s.deliveryStore.UpdateDelivery(ctx, report.Type, report.ProviderMessageID, report.Apply)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.log.With(slog.Any("report", report)).
+				Error("finding message of delivery report", err)
+			return errorx.ErrNotFound
+		}
+		s.log.With(slog.Any("report", report)).
+			Error("updating message delivery", err)
+		return errorx.ErrInternal
+	}
+
+	if !changed {
+		s.log.With(slog.Any("message id", message.ID), slog.Any("report", report)).
+			Debug("delivery report does not change message")
+	}
+	return nil
+}
+
+// ReportOnce changes the message like Report, a report of a webhook request is processed once by its token.
+// The token is recorded when the report is processed, so a request retried after an error is processed again,
+// a request with a processed token is ignored.
+func (s *DeliveryService) ReportOnce(ctx context.Context, report models.DeliveryReport, token string) error {
+	var reportErr error
+	processed, err := s.tokenStore.Use(ctx, token, time.Now().Add(webhookTokenTTL), func(ctx context.Context) error {
+		reportErr = s.Report(ctx, report)
+		return reportErr
+	})
+	if reportErr != nil {
+		return reportErr
+	}
+	if err != nil {
+		s.log.With(slog.Any("report", report)).
+			Error("using webhook token", err)
+		return errorx.ErrInternal
+	}
+
+	if !processed {
+		s.log.With(slog.Any("report", report)).
+			Warn("ignoring delivery report of processed webhook token")
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/services/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeliveryService_Report(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	deliveryStore := mock_services.NewMockDeliveryStore(ctrl)
+	deliveryService := NewDelivery(deliveryStore, mock_services.NewMockWebhookTokenStore(ctrl), log)
+	deliveryService.retryDelays = []time.Duration{0, 0}
+
+	applied := func(message models.MessageEntity) func(context.Context, models.ContactType, string, func(*models.MessageEntity) bool) (*models.MessageEntity, bool, error) {
+		return func(_ context.Context, _ models.ContactType, _ string, change func(*models.MessageEntity) bool) (*models.MessageEntity, bool, error) {
+			changed := change(&message)
+			return &message, changed, nil
+		}
+	}
+
+	t.Run("when message is delivered then status is changed", func(t *testing.T) {
+		var changed *models.MessageEntity
+		deliveryStore.
+			EXPECT().
+			UpdateDelivery(ctx, models.ContactTypeSMS, "SM1", gomock.Any()).
+			DoAndReturn(func(ctx context.Context, contactType models.ContactType, id string, change func(*models.MessageEntity) bool) (*models.MessageEntity, bool, error) {
+				message, ok, err := applied(models.MessageEntity{ID: uuid.New(), Status: models.Accepted})(ctx, contactType, id, change)
+				changed = message
+				return message, ok, err
+			})
+
+		err := deliveryService.Report(ctx, models.DeliveryReport{Type: models.ContactTypeSMS, ProviderMessageID: "SM1", Status: models.Delivered, ProviderStatus: "delivered"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Delivered, changed.Status)
+		assert.Equal(t, "delivered", changed.ProviderStatus)
+	})
+	t.Run("when report is out of order then no error", func(t *testing.T) {
+		deliveryStore.
+			EXPECT().
+			UpdateDelivery(ctx, models.ContactTypeSMS, "SM1", gomock.Any()).
+			DoAndReturn(applied(models.MessageEntity{ID: uuid.New(), Status: models.Delivered}))
+
+		err := deliveryService.Report(ctx, models.DeliveryReport{Type: models.ContactTypeSMS, ProviderMessageID: "SM1", Status: models.Sent, ProviderStatus: "sent"})
+		assert.NoError(t, err)
+	})
+	t.Run("when message id is stored after report then status is changed", func(t *testing.T) {
+		var changed *models.MessageEntity
+		gomock.InOrder(
+			deliveryStore.
+				EXPECT().
+				UpdateDelivery(ctx, models.ContactTypeSMS, "SM2", gomock.Any()).
+				Return(nil, false, fmt.Errorf("updating message delivery: %w", sql.ErrNoRows)),
+			deliveryStore.
+				EXPECT().
+				UpdateDelivery(ctx, models.ContactTypeSMS, "SM2", gomock.Any()).
+				DoAndReturn(func(ctx context.Context, contactType models.ContactType, id string, change func(*models.MessageEntity) bool) (*models.MessageEntity, bool, error) {
+					message, ok, err := applied(models.MessageEntity{ID: uuid.New(), Status: models.Accepted})(ctx, contactType, id, change)
+					changed = message
+					return message, ok, err
+				}),
+		)
+
+		err := deliveryService.Report(ctx, models.DeliveryReport{Type: models.ContactTypeSMS, ProviderMessageID: "SM2", Status: models.Sent, ProviderStatus: "sent"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Sent, changed.Status)
+	})
+	t.Run("when message is not found then error", func(t *testing.T) {
+		deliveryStore.
+			EXPECT().
+			UpdateDelivery(ctx, models.ContactTypeEmail, "1@example.com", gomock.Any()).
+			Return(nil, false, fmt.Errorf("updating message delivery: %w", sql.ErrNoRows)).
+			Times(3)
+
+		err := deliveryService.Report(ctx, models.DeliveryReport{Type: models.ContactTypeEmail, ProviderMessageID: "1@example.com", Status: models.Bounced})
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+	t.Run("when store fails then error", func(t *testing.T) {
+		deliveryStore.
+			EXPECT().
+			UpdateDelivery(ctx, models.ContactTypeEmail, "1@example.com", gomock.Any()).
+			Return(nil, false, errors.New("connection refused"))
+
+		err := deliveryService.Report(ctx, models.DeliveryReport{Type: models.ContactTypeEmail, ProviderMessageID: "1@example.com", Status: models.Delivered})
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+	})
+	t.Run("when provider message id is empty then error", func(t *testing.T) {
+		err := deliveryService.Report(ctx, models.DeliveryReport{Type: models.ContactTypeSMS, Status: models.Delivered})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+}
+
+func TestDeliveryService_ReportOnce(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	deliveryStore := mock_services.NewMockDeliveryStore(ctrl)
+	tokenStore := mock_services.NewMockWebhookTokenStore(ctrl)
+	deliveryService := NewDelivery(deliveryStore, tokenStore, log)
+	deliveryService.retryDelays = nil
+
+	process := func(ctx context.Context, _ string, _ time.Time, process func(context.Context) error) (bool, error) {
+		if err := process(ctx); err != nil {
+			return false, fmt.Errorf("using webhook token: %w", err)
+		}
+		return true, nil
+	}
+	report := models.DeliveryReport{Type: models.ContactTypeEmail, ProviderMessageID: "1@example.com", Status: models.Delivered}
+
+	t.Run("when token is new then report is processed", func(t *testing.T) {
+		tokenStore.
+			EXPECT().
+			Use(ctx, "token", gomock.Any(), gomock.Any()).
+			DoAndReturn(process)
+		deliveryStore.
+			EXPECT().
+			UpdateDelivery(ctx, models.ContactTypeEmail, "1@example.com", gomock.Any()).
+			Return(&models.MessageEntity{ID: uuid.New(), Status: models.Delivered}, true, nil)
+
+		err := deliveryService.ReportOnce(ctx, report, "token")
+		assert.NoError(t, err)
+	})
+	t.Run("when token is processed then report is ignored", func(t *testing.T) {
+		tokenStore.
+			EXPECT().
+			Use(ctx, "token", gomock.Any(), gomock.Any()).
+			Return(false, nil)
+
+		err := deliveryService.ReportOnce(ctx, report, "token")
+		assert.NoError(t, err)
+	})
+	t.Run("when report fails then its error is returned", func(t *testing.T) {
+		tokenStore.
+			EXPECT().
+			Use(ctx, "token", gomock.Any(), gomock.Any()).
+			DoAndReturn(process)
+		deliveryStore.
+			EXPECT().
+			UpdateDelivery(ctx, models.ContactTypeEmail, "1@example.com", gomock.Any()).
+			Return(nil, false, errors.New("connection refused"))
+
+		err := deliveryService.ReportOnce(ctx, report, "token")
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+	})
+	t.Run("when token store fails then error", func(t *testing.T) {
+		tokenStore.
+			EXPECT().
+			Use(ctx, "token", gomock.Any(), gomock.Any()).
+			Return(false, errors.New("connection refused"))
+
+		err := deliveryService.ReportOnce(ctx, report, "token")
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+	})
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log/slog"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/parsers"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportService creates the jobs importing files of receivers in the background and reports their progress.
+type ImportService struct {
+	importStore ImportStore
+	log         *slog.Logger
+}
+
+type ImportStore interface {
+	Create(ctx context.Context, job *models.ImportJobEntity, file io.Reader) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ImportJobEntity, error)
+	Cancel(ctx context.Context, id uuid.UUID, now time.Time) error
+	Errors(ctx context.Context, id uuid.UUID, fn func(models.ImportErrorEntity) error) error
+}
+
+func NewImport(importStore ImportStore, log *slog.Logger) *ImportService {
+	return &ImportService{
+		importStore: importStore,
+		log:         log,
+	}
+}
+
+// Create stores the file and creates a pending import job of it.
+// The format of the file is detected by its content type and name unless the options set it.
+func (s *ImportService) Create(ctx context.Context, fileName, contentType string, file io.Reader, options models.ImportOptions) (*models.ImportJob, error) {
+	if options.Format == "" {
+		options.Format = parsers.Detect(contentType, fileName)
+	}
+	if !parsers.Supports(options.Format) {
+		s.log.With(slog.String("file", fileName)).
+			Error("unknown import format", options.Format)
+		return nil, errorx.ErrValidation
+	}
+	if err := options.Validate(); err != nil {
+		s.log.With(slog.String("file", fileName)).
+			Error("validating import options", err)
+		return nil, errorx.ErrValidation
+	}
+
+	job := &models.ImportJobEntity{
+		Status:   models.ImportJobPending,
+		FileName: fileName,
+		Format:   options.Format,
+		Columns:  options.Columns,
+		DryRun:   options.DryRun,
+	}
+	if err := s.importStore.Create(ctx, job, file); err != nil {
+		s.log.With(slog.String("file", fileName)).
+			Error("creating import", err)
+		return nil, errorx.ErrInternal
+	}
+
+	result := transformImportStoreModelToImportJob(job)
+	return &result, nil
+}
+
+// GetByID returns the import job with its progress.
+func (s *ImportService) GetByID(ctx context.Context, id string) (*models.ImportJob, error) {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
+
+	job, err := s.importStore.GetByID(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("importID", id)).
+			Error("getting import", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errorx.ErrNotFound
+		}
+		return nil, errorx.ErrInternal
+	}
+
+	result := transformImportStoreModelToImportJob(job)
+	return &result, nil
+}
+
+// Cancel stops the pending or running import job, the rows imported before are kept.
+// It returns errorx.ErrValidation when the job is finished.
+func (s *ImportService) Cancel(ctx context.Context, id string) (*models.ImportJob, error) {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
+
+	if err = s.importStore.Cancel(ctx, uuidValue, time.Now()); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.log.With(slog.Any("importID", id)).
+				Error("cancelling import", err)
+			return nil, errorx.ErrInternal
+		}
+		// the job is not found or it is finished
+		if _, err = s.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, errorx.ErrValidation
+	}
+	return s.GetByID(ctx, id)
+}
+
+// WriteErrors writes the rows of the import job which cannot be imported, or which are imported with invalid contacts,
+// as a CSV file separated by semicolons, the line, the status and the reason are followed by the cells of the row.
+func (s *ImportService) WriteErrors(ctx context.Context, id string, w io.Writer) error {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return errorx.ErrValidation
+	}
+
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = semicolon
+	if err = csvWriter.Write(append([]string{"Line", "Status", "Reason"}, models.ReceiverCSVHeader...)); err != nil {
+		return err
+	}
+	err = s.importStore.Errors(ctx, uuidValue, func(e models.ImportErrorEntity) error {
+		return csvWriter.Write(append([]string{strconv.Itoa(e.Line), string(e.Status), e.Reason}, e.Record...))
+	})
+	if err != nil {
+		s.log.With(slog.Any("importID", id)).
+			Error("writing import errors", err)
+		return errorx.ErrInternal
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func transformImportStoreModelToImportJob(j *models.ImportJobEntity) models.ImportJob {
+	return models.ImportJob{
+		ID:         j.ID,
+		Status:     j.Status,
+		FileName:   j.FileName,
+		Format:     j.Format,
+		Columns:    j.Columns,
+		DryRun:     j.DryRun,
+		TotalRows:  j.TotalRows,
+		Error:      j.Error,
+		CreatedAt:  j.CreatedAt,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+		ImportProgress: models.ImportProgress{
+			Processed: j.ProcessedRows,
+			Created:   j.CreatedRows,
+			Updated:   j.UpdatedRows,
+			Skipped:   j.SkippedRows,
+		},
+	}
+}
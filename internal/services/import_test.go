@@ -0,0 +1,171 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/services/mocks"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestImportService_Create(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	importStore := mock_services.NewMockImportStore(ctrl)
+	importService := NewImport(importStore, log)
+	file := strings.NewReader("firstName;secondName")
+
+	t.Run("when file is stored then pending import", func(t *testing.T) {
+		id := uuid.New()
+		importStore.
+			EXPECT().
+			Create(ctx, &models.ImportJobEntity{Status: models.ImportJobPending, FileName: "receivers.csv", Format: models.ImportFormatCSV, DryRun: true}, file).
+			DoAndReturn(func(_ context.Context, job *models.ImportJobEntity, _ io.Reader) error {
+				job.ID = id
+				return nil
+			})
+
+		job, err := importService.Create(ctx, "receivers.csv", "application/octet-stream", file, models.ImportOptions{DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, id, job.ID)
+		assert.Equal(t, models.ImportJobPending, job.Status)
+		assert.Equal(t, models.ImportFormatCSV, job.Format)
+		assert.Nil(t, job.TotalRows)
+	})
+	t.Run("when file is excel then format is detected and columns are canonical", func(t *testing.T) {
+		importStore.
+			EXPECT().
+			Create(ctx, &models.ImportJobEntity{
+				Status:   models.ImportJobPending,
+				FileName: "registry.xlsx",
+				Format:   models.ImportFormatXLSX,
+				Columns:  map[string]string{"MobilePhone": "Телефон"},
+			}, file).
+			Return(nil)
+
+		job, err := importService.Create(ctx, "registry.xlsx", "", file, models.ImportOptions{Columns: map[string]string{"mobilephone": "Телефон"}})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ImportFormatXLSX, job.Format)
+	})
+	t.Run("when format is unknown then error", func(t *testing.T) {
+		_, err := importService.Create(ctx, "receivers.csv", "", file, models.ImportOptions{Format: "pdf"})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when column is unknown then error", func(t *testing.T) {
+		_, err := importService.Create(ctx, "receivers.csv", "", file, models.ImportOptions{Columns: map[string]string{"Phone": "Телефон"}})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when store fails then error", func(t *testing.T) {
+		importStore.
+			EXPECT().
+			Create(ctx, gomock.Any(), file).
+			Return(errors.New("connection refused"))
+
+		_, err := importService.Create(ctx, "receivers.csv", "text/csv", file, models.ImportOptions{})
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+	})
+}
+
+func TestImportService_Cancel(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	importStore := mock_services.NewMockImportStore(ctrl)
+	importService := NewImport(importStore, log)
+	id := uuid.New()
+
+	t.Run("when import is running then cancelled", func(t *testing.T) {
+		importStore.
+			EXPECT().
+			Cancel(ctx, id, gomock.Any()).
+			Return(nil)
+		importStore.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.ImportJobEntity{ID: id, Status: models.ImportJobCancelled, ProcessedRows: 500}, nil)
+
+		job, err := importService.Cancel(ctx, id.String())
+		assert.NoError(t, err)
+		assert.Equal(t, models.ImportJobCancelled, job.Status)
+		assert.Equal(t, 500, job.Processed)
+	})
+	t.Run("when import is finished then error", func(t *testing.T) {
+		importStore.
+			EXPECT().
+			Cancel(ctx, id, gomock.Any()).
+			Return(sql.ErrNoRows)
+		importStore.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.ImportJobEntity{ID: id, Status: models.ImportJobCompleted}, nil)
+
+		_, err := importService.Cancel(ctx, id.String())
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when import is not found then error", func(t *testing.T) {
+		importStore.
+			EXPECT().
+			Cancel(ctx, id, gomock.Any()).
+			Return(sql.ErrNoRows)
+		importStore.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(nil, sql.ErrNoRows)
+
+		_, err := importService.Cancel(ctx, id.String())
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+}
+
+func TestImportService_WriteErrors(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	importStore := mock_services.NewMockImportStore(ctrl)
+	importService := NewImport(importStore, log)
+	id := uuid.New()
+
+	t.Run("when import has errors then csv with line and reason", func(t *testing.T) {
+		importStore.
+			EXPECT().
+			Errors(ctx, id, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, fn func(models.ImportErrorEntity) error) error {
+				if err := fn(models.ImportErrorEntity{Line: 2, Status: models.ImportSkipped, Reason: "last name is empty", Record: []string{"Boris", "", "+7312787312"}}); err != nil {
+					return err
+				}
+				if err := fn(models.ImportErrorEntity{Line: 3, Status: models.ImportCreated, Reason: `Email: invalid email "ivan@"`, Record: []string{"Ivan", "Petrov", "", "", "ivan@"}}); err != nil {
+					return err
+				}
+				return fn(models.ImportErrorEntity{Line: 5, Status: models.ImportSkipped, Reason: "extraneous \" in field"})
+			})
+
+		var buf bytes.Buffer
+		assert.NoError(t, importService.WriteErrors(ctx, id.String(), &buf))
+		lines := strings.Split(buf.String(), "\n")
+		assert.Equal(t, "Line;Status;Reason;firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City;Telegram;IsTelegramActive;Language;WhatsApp;IsWhatsAppActive;Tags", lines[0])
+		assert.Equal(t, "2;skipped;last name is empty;Boris;;+7312787312", lines[1])
+		assert.Equal(t, `3;created;"Email: invalid email ""ivan@""";Ivan;Petrov;;;ivan@`, lines[2])
+		assert.Equal(t, `5;skipped;"extraneous "" in field"`, lines[3])
+	})
+	t.Run("when id is not uuid then error", func(t *testing.T) {
+		assert.ErrorIs(t, importService.WriteErrors(ctx, "abc", io.Discard), errorx.ErrValidation)
+	})
+}
@@ -4,17 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"log/slog"
 	"projects/emergency-messages/internal/errorx"
 	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/render"
 )
 
 type MessageService struct {
-	producer      Producer
-	templateStore Template
-	log           *slog.Logger
+	producer       Producer
+	templateStore  Template
+	broadcastStore Broadcast
+	log            *slog.Logger
 }
 
 type Producer interface {
@@ -25,18 +28,25 @@ type Template interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.TemplateEntity, error)
 }
 
-func NewMessage(producer Producer, templateStore Template, log *slog.Logger) *MessageService {
+type Broadcast interface {
+	Create(ctx context.Context, b *models.BroadcastEntity) error
+}
+
+func NewMessage(producer Producer, templateStore Template, broadcastStore Broadcast, log *slog.Logger) *MessageService {
 	return &MessageService{
-		producer:      producer,
-		templateStore: templateStore,
-		log:           log,
+		producer:       producer,
+		templateStore:  templateStore,
+		broadcastStore: broadcastStore,
+		log:            log,
 	}
 }
 
-func (s *MessageService) Send(ctx context.Context, message models.MessageRequest) error {
+// Send creates a broadcast for the message request and sends it to the queue.
+// It returns the created broadcast, which is referenced by every message sent to the receivers.
+func (s *MessageService) Send(ctx context.Context, message models.MessageRequest) (*models.Broadcast, error) {
 	// validate message
 	if err := message.Validate(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// get template by id
@@ -44,33 +54,123 @@ func (s *MessageService) Send(ctx context.Context, message models.MessageRequest
 	if err != nil {
 		s.log.With(slog.Any("templateID", message.TemplateID)).
 			Error("getting template", err)
-		if err == sql.ErrNoRows {
-			return errorx.ErrNotFound
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errorx.ErrNotFound
 		}
-		return errorx.ErrInternal
+		return nil, errorx.ErrInternal
+	}
+
+	params, err := templateParams(template.Variables, message.Params)
+	if err != nil {
+		s.log.With(slog.Any("templateID", message.TemplateID), slog.Any("params", message.Params)).
+			Error("checking template params", err)
+		return nil, err
+	}
+
+	parsed, err := render.Parse(template.Subject, template.Text)
+	if err != nil {
+		s.log.With(slog.Any("templateID", message.TemplateID)).
+			Error("parsing template", err)
+		return nil, errorx.ErrInternal
+	}
+
+	// the broadcast keeps the text without the receiver fields, every receiver gets its own rendering
+	subject, text, err := parsed.Render(render.Variables{
+		City:     message.City,
+		Strength: message.Strength,
+		Params:   params,
+	})
+	if err != nil {
+		s.log.With(slog.Any("templateID", message.TemplateID)).
+			Error("rendering template", err)
+		return nil, errorx.ErrValidation
+	}
+
+	broadcast := &models.BroadcastEntity{
+		TemplateID:      message.TemplateID,
+		TemplateVersion: template.Version,
+		Subject:         subject,
+		Text:            text,
+		City:            message.City,
+		Strength:        message.Strength,
+		Critical:        message.Critical,
+	}
+	if err = s.broadcastStore.Create(ctx, broadcast); err != nil {
+		s.log.With(slog.Any("broadcast", broadcast)).
+			Error("creating broadcast", err)
+		return nil, errorx.ErrInternal
 	}
 
 	newMessage := models.MessageConsumer{
-		Subject: template.Subject,
-		Text:    fmt.Sprintf(template.Text, message.City, message.Strength),
-		Status:  models.Created,
-		City:    message.City,
+		BroadcastID:     broadcast.ID,
+		TemplateID:      template.ID,
+		TemplateVersion: template.Version,
+		Subject:         template.Subject,
+		Text:            template.Text,
+		Variants:        template.Variants,
+		Language:        template.Language,
+		Translations:    template.Translations,
+		MaxSegments:     template.MaxSegments,
+		SegmentPolicy:   template.SegmentPolicy,
+		Status:          models.Created,
+		City:            broadcast.City,
+		Strength:        broadcast.Strength,
+		Params:          params,
+		Critical:        broadcast.Critical,
+	}
+	// the parameters of the whatsapp template are filled for every receiver by the sender
+	if newMessage.WhatsApp = whatsAppTemplate(template); newMessage.WhatsApp != nil {
+		newMessage.WhatsAppParameters = template.WhatsAppParameters
 	}
 
 	messageBytes, err := json.Marshal(newMessage)
 	if err != nil {
 		s.log.With(slog.Any("message", newMessage)).
 			Error("marshaling message", err)
-		return errorx.ErrInternal
+		return nil, errorx.ErrInternal
 	}
 
 	// send to queue
 	if err = s.producer.Send(messageBytes); err != nil {
 		s.log.With(slog.Any("message", newMessage)).
 			Error("sending message", err)
-		return errorx.ErrInternal
+		return nil, errorx.ErrInternal
 
 	}
 
-	return nil
+	result := transformBroadcastStoreModelToBroadcast(broadcast)
+	return &result, nil
+}
+
+// templateParams checks the params of the request against the variables declared by the template.
+// Optional variables without a value are rendered empty.
+func templateParams(variables []models.TemplateVariable, params map[string]string) (map[string]string, error) {
+	declared := make(map[string]bool, len(variables))
+	result := make(map[string]string, len(variables))
+	for _, v := range variables {
+		declared[v.Name] = true
+		value, ok := params[v.Name]
+		if !ok && v.Required {
+			return nil, fmt.Errorf("missing param %s: %w", v.Name, errorx.ErrValidation)
+		}
+		result[v.Name] = value
+	}
+	for name := range params {
+		if !declared[name] {
+			return nil, fmt.Errorf("unknown param %s: %w", name, errorx.ErrValidation)
+		}
+	}
+	return result, nil
+}
+
+// whatsAppTemplate returns the pre-approved WhatsApp template without its parameters,
+// or nil if the template has no WhatsApp counterpart.
+func whatsAppTemplate(template *models.TemplateEntity) *models.WhatsAppTemplate {
+	if template.WhatsAppName == "" {
+		return nil
+	}
+	return &models.WhatsAppTemplate{
+		Name:     template.WhatsAppName,
+		Language: template.WhatsAppLanguage,
+	}
 }
@@ -1,11 +1,18 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"log/slog"
 	"os"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
 	mock_service "projects/emergency-messages/internal/services/mocks"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -18,9 +25,160 @@ func TestNewMessage(t *testing.T) {
 
 	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	producer := mock_service.NewMockProducer(controller)
+	broadcastStore := mock_service.NewMockBroadcast(controller)
 
-	res := NewMessage(producer, templateStore, log)
+	res := NewMessage(producer, templateStore, broadcastStore, log)
 	assert.NotNil(t, res)
 	assert.Equal(t, templateStore, res.templateStore)
+	assert.Equal(t, broadcastStore, res.broadcastStore)
 	assert.Equal(t, log, res.log)
 }
+
+func TestMessageService_Send(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	templateStore := mock_service.NewMockTemplate(controller)
+	broadcastStore := mock_service.NewMockBroadcast(controller)
+	producer := mock_service.NewMockProducer(controller)
+	service := NewMessage(producer, templateStore, broadcastStore, log)
+
+	templateID := uuid.New()
+	request := models.MessageRequest{
+		TemplateID: templateID,
+		City:       "Kazan",
+		Strength:   "strong",
+	}
+
+	t.Run("when template is found then broadcast is created and queued", func(t *testing.T) {
+		broadcastID := uuid.New()
+		templateStore.
+			EXPECT().
+			GetByID(ctx, templateID).
+			Return(&models.TemplateEntity{ID: templateID, Subject: "Flood", Text: "Flood in {{.City}}, {{.Strength}}", Version: 3}, nil)
+		broadcastStore.
+			EXPECT().
+			Create(ctx, &models.BroadcastEntity{
+				TemplateID:      templateID,
+				TemplateVersion: 3,
+				Subject:         "Flood",
+				Text:            "Flood in Kazan, strong",
+				City:            "Kazan",
+				Strength:        "strong",
+			}).
+			DoAndReturn(func(_ context.Context, b *models.BroadcastEntity) error {
+				b.ID = broadcastID
+				return nil
+			})
+		producer.
+			EXPECT().
+			Send(gomock.Any()).
+			DoAndReturn(func(messageBytes []byte) error {
+				var message models.MessageConsumer
+				assert.NoError(t, json.Unmarshal(messageBytes, &message))
+				assert.Equal(t, broadcastID, message.BroadcastID)
+				assert.Equal(t, templateID, message.TemplateID)
+				assert.Equal(t, 3, message.TemplateVersion)
+				assert.Equal(t, "Flood in {{.City}}, {{.Strength}}", message.Text)
+				assert.Equal(t, "strong", message.Strength)
+				return nil
+			})
+
+		broadcast, err := service.Send(ctx, request)
+		assert.NoError(t, err)
+		assert.Equal(t, broadcastID, broadcast.ID)
+		assert.Equal(t, "Flood in Kazan, strong", broadcast.Text)
+	})
+
+	t.Run("when template is not found then error", func(t *testing.T) {
+		templateStore.
+			EXPECT().
+			GetByID(ctx, templateID).
+			Return(nil, sql.ErrNoRows)
+
+		broadcast, err := service.Send(ctx, request)
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+		assert.Nil(t, broadcast)
+	})
+
+	t.Run("when declared variables are passed then they are rendered", func(t *testing.T) {
+		templateStore.
+			EXPECT().
+			GetByID(ctx, templateID).
+			Return(&models.TemplateEntity{
+				ID:      templateID,
+				Subject: "Flood",
+				Text:    "Water level {{.Level}} m{{.Note}}",
+				Variables: []models.TemplateVariable{
+					{Name: "Level", Required: true},
+					{Name: "Note"},
+				},
+			}, nil)
+		broadcastStore.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil)
+		producer.
+			EXPECT().
+			Send(gomock.Any()).
+			DoAndReturn(func(messageBytes []byte) error {
+				var message models.MessageConsumer
+				assert.NoError(t, json.Unmarshal(messageBytes, &message))
+				assert.Equal(t, map[string]string{"Level": "3.5", "Note": ""}, message.Params)
+				return nil
+			})
+
+		withParams := request
+		withParams.Params = map[string]string{"Level": "3.5"}
+		broadcast, err := service.Send(ctx, withParams)
+		assert.NoError(t, err)
+		assert.Equal(t, "Water level 3.5 m", broadcast.Text)
+	})
+
+	t.Run("when required variable is missing then error", func(t *testing.T) {
+		templateStore.
+			EXPECT().
+			GetByID(ctx, templateID).
+			Return(&models.TemplateEntity{
+				ID:        templateID,
+				Subject:   "Flood",
+				Text:      "Water level {{.Level}} m",
+				Variables: []models.TemplateVariable{{Name: "Level", Required: true}},
+			}, nil)
+
+		broadcast, err := service.Send(ctx, request)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+		assert.Nil(t, broadcast)
+	})
+
+	t.Run("when param is not declared then error", func(t *testing.T) {
+		templateStore.
+			EXPECT().
+			GetByID(ctx, templateID).
+			Return(&models.TemplateEntity{ID: templateID, Subject: "Flood", Text: "Flood in {{.City}}"}, nil)
+
+		withParams := request
+		withParams.Params = map[string]string{"Levle": "3.5"}
+		broadcast, err := service.Send(ctx, withParams)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+		assert.Nil(t, broadcast)
+	})
+
+	t.Run("when broadcast store returns error then error", func(t *testing.T) {
+		templateStore.
+			EXPECT().
+			GetByID(ctx, templateID).
+			Return(&models.TemplateEntity{ID: templateID, Subject: "Flood", Text: "Flood in {{.City}}, {{.Strength}}"}, nil)
+		broadcastStore.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(errors.New(""))
+
+		broadcast, err := service.Send(ctx, request)
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+		assert.Nil(t, broadcast)
+	})
+}
@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/broadcast.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/services/broadcast.go -destination internal/services/mocks/broadcast_mock.go
+//
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBroadcastStore is a mock of BroadcastStore interface.
+type MockBroadcastStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockBroadcastStoreMockRecorder
+}
+
+// MockBroadcastStoreMockRecorder is the mock recorder for MockBroadcastStore.
+type MockBroadcastStoreMockRecorder struct {
+	mock *MockBroadcastStore
+}
+
+// NewMockBroadcastStore creates a new mock instance.
+func NewMockBroadcastStore(ctrl *gomock.Controller) *MockBroadcastStore {
+	mock := &MockBroadcastStore{ctrl: ctrl}
+	mock.recorder = &MockBroadcastStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBroadcastStore) EXPECT() *MockBroadcastStoreMockRecorder {
+	return m.recorder
+}
+
+// CountFallbacks mocks base method.
+func (m *MockBroadcastStore) CountFallbacks(ctx context.Context, id uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFallbacks", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFallbacks indicates an expected call of CountFallbacks.
+func (mr *MockBroadcastStoreMockRecorder) CountFallbacks(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFallbacks", reflect.TypeOf((*MockBroadcastStore)(nil).CountFallbacks), ctx, id)
+}
+
+// CountMessages mocks base method.
+func (m *MockBroadcastStore) CountMessages(ctx context.Context, id uuid.UUID) ([]models.BroadcastStatusCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountMessages", ctx, id)
+	ret0, _ := ret[0].([]models.BroadcastStatusCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountMessages indicates an expected call of CountMessages.
+func (mr *MockBroadcastStoreMockRecorder) CountMessages(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountMessages", reflect.TypeOf((*MockBroadcastStore)(nil).CountMessages), ctx, id)
+}
+
+// Create mocks base method.
+func (m *MockBroadcastStore) Create(ctx context.Context, b *models.BroadcastEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, b)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockBroadcastStoreMockRecorder) Create(ctx, b any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBroadcastStore)(nil).Create), ctx, b)
+}
+
+// FindLatest mocks base method.
+func (m *MockBroadcastStore) FindLatest(ctx context.Context, limit int) ([]models.BroadcastEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLatest", ctx, limit)
+	ret0, _ := ret[0].([]models.BroadcastEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatest indicates an expected call of FindLatest.
+func (mr *MockBroadcastStoreMockRecorder) FindLatest(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatest", reflect.TypeOf((*MockBroadcastStore)(nil).FindLatest), ctx, limit)
+}
+
+// GetByID mocks base method.
+func (m *MockBroadcastStore) GetByID(ctx context.Context, id uuid.UUID) (*models.BroadcastEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.BroadcastEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockBroadcastStoreMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockBroadcastStore)(nil).GetByID), ctx, id)
+}
@@ -0,0 +1,176 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/cap.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/services/cap.go -destination internal/services/mocks/cap_mock.go
+//
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	cap "projects/emergency-messages/internal/cap"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCapAlertStore is a mock of CapAlertStore interface.
+type MockCapAlertStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockCapAlertStoreMockRecorder
+}
+
+// MockCapAlertStoreMockRecorder is the mock recorder for MockCapAlertStore.
+type MockCapAlertStoreMockRecorder struct {
+	mock *MockCapAlertStore
+}
+
+// NewMockCapAlertStore creates a new mock instance.
+func NewMockCapAlertStore(ctrl *gomock.Controller) *MockCapAlertStore {
+	mock := &MockCapAlertStore{ctrl: ctrl}
+	mock.recorder = &MockCapAlertStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCapAlertStore) EXPECT() *MockCapAlertStoreMockRecorder {
+	return m.recorder
+}
+
+// AddBroadcasts mocks base method.
+func (m *MockCapAlertStore) AddBroadcasts(ctx context.Context, id uuid.UUID, broadcastIDs []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBroadcasts", ctx, id, broadcastIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddBroadcasts indicates an expected call of AddBroadcasts.
+func (mr *MockCapAlertStoreMockRecorder) AddBroadcasts(ctx, id, broadcastIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBroadcasts", reflect.TypeOf((*MockCapAlertStore)(nil).AddBroadcasts), ctx, id, broadcastIDs)
+}
+
+// Create mocks base method.
+func (m *MockCapAlertStore) Create(ctx context.Context, a *models.CapAlertEntity) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, a)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCapAlertStoreMockRecorder) Create(ctx, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCapAlertStore)(nil).Create), ctx, a)
+}
+
+// Delete mocks base method.
+func (m *MockCapAlertStore) Delete(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCapAlertStoreMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCapAlertStore)(nil).Delete), ctx, id)
+}
+
+// FindBroadcastIDs mocks base method.
+func (m *MockCapAlertStore) FindBroadcastIDs(ctx context.Context, references []cap.Reference) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBroadcastIDs", ctx, references)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBroadcastIDs indicates an expected call of FindBroadcastIDs.
+func (mr *MockCapAlertStoreMockRecorder) FindBroadcastIDs(ctx, references any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBroadcastIDs", reflect.TypeOf((*MockCapAlertStore)(nil).FindBroadcastIDs), ctx, references)
+}
+
+// MockMessageCanceller is a mock of MessageCanceller interface.
+type MockMessageCanceller struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageCancellerMockRecorder
+}
+
+// MockMessageCancellerMockRecorder is the mock recorder for MockMessageCanceller.
+type MockMessageCancellerMockRecorder struct {
+	mock *MockMessageCanceller
+}
+
+// NewMockMessageCanceller creates a new mock instance.
+func NewMockMessageCanceller(ctrl *gomock.Controller) *MockMessageCanceller {
+	mock := &MockMessageCanceller{ctrl: ctrl}
+	mock.recorder = &MockMessageCancellerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageCanceller) EXPECT() *MockMessageCancellerMockRecorder {
+	return m.recorder
+}
+
+// CancelByBroadcasts mocks base method.
+func (m *MockMessageCanceller) CancelByBroadcasts(ctx context.Context, broadcastIDs []uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelByBroadcasts", ctx, broadcastIDs)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelByBroadcasts indicates an expected call of CancelByBroadcasts.
+func (mr *MockMessageCancellerMockRecorder) CancelByBroadcasts(ctx, broadcastIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelByBroadcasts", reflect.TypeOf((*MockMessageCanceller)(nil).CancelByBroadcasts), ctx, broadcastIDs)
+}
+
+// MockMessageSender is a mock of MessageSender interface.
+type MockMessageSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageSenderMockRecorder
+}
+
+// MockMessageSenderMockRecorder is the mock recorder for MockMessageSender.
+type MockMessageSenderMockRecorder struct {
+	mock *MockMessageSender
+}
+
+// NewMockMessageSender creates a new mock instance.
+func NewMockMessageSender(ctrl *gomock.Controller) *MockMessageSender {
+	mock := &MockMessageSender{ctrl: ctrl}
+	mock.recorder = &MockMessageSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageSender) EXPECT() *MockMessageSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockMessageSender) Send(ctx context.Context, message models.MessageRequest) (*models.Broadcast, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, message)
+	ret0, _ := ret[0].(*models.Broadcast)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMessageSenderMockRecorder) Send(ctx, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMessageSender)(nil).Send), ctx, message)
+}
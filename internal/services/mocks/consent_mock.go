@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/consent.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/services/consent.go -destination internal/services/mocks/consent_mock.go
+//
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockContactConsentStore is a mock of ContactConsentStore interface.
+type MockContactConsentStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockContactConsentStoreMockRecorder
+}
+
+// MockContactConsentStoreMockRecorder is the mock recorder for MockContactConsentStore.
+type MockContactConsentStoreMockRecorder struct {
+	mock *MockContactConsentStore
+}
+
+// NewMockContactConsentStore creates a new mock instance.
+func NewMockContactConsentStore(ctrl *gomock.Controller) *MockContactConsentStore {
+	mock := &MockContactConsentStore{ctrl: ctrl}
+	mock.recorder = &MockContactConsentStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContactConsentStore) EXPECT() *MockContactConsentStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockContactConsentStore) Create(ctx context.Context, c *models.ContactConsentEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockContactConsentStoreMockRecorder) Create(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockContactConsentStore)(nil).Create), ctx, c)
+}
+
+// FindByReceiver mocks base method.
+func (m *MockContactConsentStore) FindByReceiver(ctx context.Context, receiverID uuid.UUID) ([]models.ContactConsentEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByReceiver", ctx, receiverID)
+	ret0, _ := ret[0].([]models.ContactConsentEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByReceiver indicates an expected call of FindByReceiver.
+func (mr *MockContactConsentStoreMockRecorder) FindByReceiver(ctx, receiverID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByReceiver", reflect.TypeOf((*MockContactConsentStore)(nil).FindByReceiver), ctx, receiverID)
+}
+
+// FindOptedOut mocks base method.
+func (m *MockContactConsentStore) FindOptedOut(ctx context.Context, keys []models.ContactKey) (map[models.ContactKey]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOptedOut", ctx, keys)
+	ret0, _ := ret[0].(map[models.ContactKey]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOptedOut indicates an expected call of FindOptedOut.
+func (mr *MockContactConsentStoreMockRecorder) FindOptedOut(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOptedOut", reflect.TypeOf((*MockContactConsentStore)(nil).FindOptedOut), ctx, keys)
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/delivery.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/services/delivery.go -destination internal/services/mocks/delivery_mock.go
+//
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeliveryStore is a mock of DeliveryStore interface.
+type MockDeliveryStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryStoreMockRecorder
+}
+
+// MockDeliveryStoreMockRecorder is the mock recorder for MockDeliveryStore.
+type MockDeliveryStoreMockRecorder struct {
+	mock *MockDeliveryStore
+}
+
+// NewMockDeliveryStore creates a new mock instance.
+func NewMockDeliveryStore(ctrl *gomock.Controller) *MockDeliveryStore {
+	mock := &MockDeliveryStore{ctrl: ctrl}
+	mock.recorder = &MockDeliveryStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryStore) EXPECT() *MockDeliveryStoreMockRecorder {
+	return m.recorder
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockDeliveryStore) UpdateDelivery(ctx context.Context, contactType models.ContactType, providerMessageID string, change func(*models.MessageEntity) bool) (*models.MessageEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", ctx, contactType, providerMessageID, change)
+	ret0, _ := ret[0].(*models.MessageEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockDeliveryStoreMockRecorder) UpdateDelivery(ctx, contactType, providerMessageID, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockDeliveryStore)(nil).UpdateDelivery), ctx, contactType, providerMessageID, change)
+}
+
+// MockWebhookTokenStore is a mock of WebhookTokenStore interface.
+type MockWebhookTokenStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookTokenStoreMockRecorder
+}
+
+// MockWebhookTokenStoreMockRecorder is the mock recorder for MockWebhookTokenStore.
+type MockWebhookTokenStoreMockRecorder struct {
+	mock *MockWebhookTokenStore
+}
+
+// NewMockWebhookTokenStore creates a new mock instance.
+func NewMockWebhookTokenStore(ctrl *gomock.Controller) *MockWebhookTokenStore {
+	mock := &MockWebhookTokenStore{ctrl: ctrl}
+	mock.recorder = &MockWebhookTokenStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookTokenStore) EXPECT() *MockWebhookTokenStoreMockRecorder {
+	return m.recorder
+}
+
+// Use mocks base method.
+func (m *MockWebhookTokenStore) Use(ctx context.Context, token string, expiresAt time.Time, process func(context.Context) error) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Use", ctx, token, expiresAt, process)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Use indicates an expected call of Use.
+func (mr *MockWebhookTokenStoreMockRecorder) Use(ctx, token, expiresAt, process any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockWebhookTokenStore)(nil).Use), ctx, token, expiresAt, process)
+}
@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/import.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/services/import.go -destination internal/services/mocks/import_mock.go
+//
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	io "io"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockImportStore is a mock of ImportStore interface.
+type MockImportStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportStoreMockRecorder
+}
+
+// MockImportStoreMockRecorder is the mock recorder for MockImportStore.
+type MockImportStoreMockRecorder struct {
+	mock *MockImportStore
+}
+
+// NewMockImportStore creates a new mock instance.
+func NewMockImportStore(ctrl *gomock.Controller) *MockImportStore {
+	mock := &MockImportStore{ctrl: ctrl}
+	mock.recorder = &MockImportStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImportStore) EXPECT() *MockImportStoreMockRecorder {
+	return m.recorder
+}
+
+// Cancel mocks base method.
+func (m *MockImportStore) Cancel(ctx context.Context, id uuid.UUID, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, id, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockImportStoreMockRecorder) Cancel(ctx, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockImportStore)(nil).Cancel), ctx, id, now)
+}
+
+// Create mocks base method.
+func (m *MockImportStore) Create(ctx context.Context, job *models.ImportJobEntity, file io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, job, file)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockImportStoreMockRecorder) Create(ctx, job, file any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockImportStore)(nil).Create), ctx, job, file)
+}
+
+// Errors mocks base method.
+func (m *MockImportStore) Errors(ctx context.Context, id uuid.UUID, fn func(models.ImportErrorEntity) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Errors", ctx, id, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Errors indicates an expected call of Errors.
+func (mr *MockImportStoreMockRecorder) Errors(ctx, id, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Errors", reflect.TypeOf((*MockImportStore)(nil).Errors), ctx, id, fn)
+}
+
+// GetByID mocks base method.
+func (m *MockImportStore) GetByID(ctx context.Context, id uuid.UUID) (*models.ImportJobEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.ImportJobEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockImportStoreMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockImportStore)(nil).GetByID), ctx, id)
+}
@@ -91,3 +91,40 @@ func (mr *MockTemplateMockRecorder) GetByID(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockTemplate)(nil).GetByID), ctx, id)
 }
+
+// MockBroadcast is a mock of Broadcast interface.
+type MockBroadcast struct {
+	ctrl     *gomock.Controller
+	recorder *MockBroadcastMockRecorder
+}
+
+// MockBroadcastMockRecorder is the mock recorder for MockBroadcast.
+type MockBroadcastMockRecorder struct {
+	mock *MockBroadcast
+}
+
+// NewMockBroadcast creates a new mock instance.
+func NewMockBroadcast(ctrl *gomock.Controller) *MockBroadcast {
+	mock := &MockBroadcast{ctrl: ctrl}
+	mock.recorder = &MockBroadcastMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBroadcast) EXPECT() *MockBroadcastMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockBroadcast) Create(ctx context.Context, b *models.BroadcastEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, b)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockBroadcastMockRecorder) Create(ctx, b any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBroadcast)(nil).Create), ctx, b)
+}
@@ -12,7 +12,9 @@ import (
 	context "context"
 	models "projects/emergency-messages/internal/models"
 	reflect "reflect"
+	time "time"
 
+	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -53,6 +55,34 @@ func (mr *MockReceiverStoreMockRecorder) Create(ctx, receiver any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReceiverStore)(nil).Create), ctx, receiver)
 }
 
+// Delete mocks base method.
+func (m *MockReceiverStore) Delete(ctx context.Context, id uuid.UUID, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockReceiverStoreMockRecorder) Delete(ctx, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReceiverStore)(nil).Delete), ctx, id, now)
+}
+
+// Export mocks base method.
+func (m *MockReceiverStore) Export(ctx context.Context, filter models.ReceiverFilter, fn func(models.ReceiverEntity) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockReceiverStoreMockRecorder) Export(ctx, filter, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockReceiverStore)(nil).Export), ctx, filter, fn)
+}
+
 // FindByCity mocks base method.
 func (m *MockReceiverStore) FindByCity(ctx context.Context, city string) ([]models.ReceiverEntity, error) {
 	m.ctrl.T.Helper()
@@ -67,3 +97,93 @@ func (mr *MockReceiverStoreMockRecorder) FindByCity(ctx, city any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCity", reflect.TypeOf((*MockReceiverStore)(nil).FindByCity), ctx, city)
 }
+
+// FindByContact mocks base method.
+func (m *MockReceiverStore) FindByContact(ctx context.Context, key models.ContactKey) ([]models.ReceiverEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByContact", ctx, key)
+	ret0, _ := ret[0].([]models.ReceiverEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByContact indicates an expected call of FindByContact.
+func (mr *MockReceiverStoreMockRecorder) FindByContact(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByContact", reflect.TypeOf((*MockReceiverStore)(nil).FindByContact), ctx, key)
+}
+
+// GetByID mocks base method.
+func (m *MockReceiverStore) GetByID(ctx context.Context, id uuid.UUID) (*models.ReceiverEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.ReceiverEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockReceiverStoreMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockReceiverStore)(nil).GetByID), ctx, id)
+}
+
+// Import mocks base method.
+func (m *MockReceiverStore) Import(ctx context.Context, receivers []*models.ReceiverEntity, merge func(*models.ReceiverEntity, *models.ReceiverEntity) bool, dryRun bool) ([]models.ImportStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, receivers, merge, dryRun)
+	ret0, _ := ret[0].([]models.ImportStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockReceiverStoreMockRecorder) Import(ctx, receivers, merge, dryRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockReceiverStore)(nil).Import), ctx, receivers, merge, dryRun)
+}
+
+// List mocks base method.
+func (m *MockReceiverStore) List(ctx context.Context, filter models.ReceiverFilter) ([]models.ReceiverEntity, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]models.ReceiverEntity)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockReceiverStoreMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReceiverStore)(nil).List), ctx, filter)
+}
+
+// Update mocks base method.
+func (m *MockReceiverStore) Update(ctx context.Context, receiver *models.ReceiverEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, receiver)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockReceiverStoreMockRecorder) Update(ctx, receiver any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockReceiverStore)(nil).Update), ctx, receiver)
+}
+
+// UpdateContacts mocks base method.
+func (m *MockReceiverStore) UpdateContacts(ctx context.Context, id uuid.UUID, change func([]models.Contact) ([]models.Contact, error)) (*models.ReceiverEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateContacts", ctx, id, change)
+	ret0, _ := ret[0].(*models.ReceiverEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateContacts indicates an expected call of UpdateContacts.
+func (mr *MockReceiverStoreMockRecorder) UpdateContacts(ctx, id, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContacts", reflect.TypeOf((*MockReceiverStore)(nil).UpdateContacts), ctx, id, change)
+}
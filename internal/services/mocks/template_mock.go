@@ -84,6 +84,37 @@ func (mr *MockTemplateStoreMockRecorder) GetByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockTemplateStore)(nil).GetByID), ctx, id)
 }
 
+// List mocks base method.
+func (m *MockTemplateStore) List(ctx context.Context, filter models.TemplateFilter) ([]models.TemplateEntity, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]models.TemplateEntity)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockTemplateStoreMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTemplateStore)(nil).List), ctx, filter)
+}
+
+// Rollback mocks base method.
+func (m *MockTemplateStore) Rollback(ctx context.Context, id uuid.UUID, version int, author string) (*models.TemplateEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", ctx, id, version, author)
+	ret0, _ := ret[0].(*models.TemplateEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockTemplateStoreMockRecorder) Rollback(ctx, id, version, author any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockTemplateStore)(nil).Rollback), ctx, id, version, author)
+}
+
 // Update mocks base method.
 func (m *MockTemplateStore) Update(ctx context.Context, t *models.TemplateEntity) error {
 	m.ctrl.T.Helper()
@@ -97,3 +128,18 @@ func (mr *MockTemplateStoreMockRecorder) Update(ctx, t any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTemplateStore)(nil).Update), ctx, t)
 }
+
+// Versions mocks base method.
+func (m *MockTemplateStore) Versions(ctx context.Context, id uuid.UUID) ([]models.TemplateVersionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Versions", ctx, id)
+	ret0, _ := ret[0].([]models.TemplateVersionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Versions indicates an expected call of Versions.
+func (mr *MockTemplateStoreMockRecorder) Versions(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Versions", reflect.TypeOf((*MockTemplateStore)(nil).Versions), ctx, id)
+}
@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/verification.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/services/verification.go -destination internal/services/mocks/verification_mock.go
+//
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "projects/emergency-messages/internal/models"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockContactVerificationStore is a mock of ContactVerificationStore interface.
+type MockContactVerificationStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockContactVerificationStoreMockRecorder
+}
+
+// MockContactVerificationStoreMockRecorder is the mock recorder for MockContactVerificationStore.
+type MockContactVerificationStoreMockRecorder struct {
+	mock *MockContactVerificationStore
+}
+
+// NewMockContactVerificationStore creates a new mock instance.
+func NewMockContactVerificationStore(ctrl *gomock.Controller) *MockContactVerificationStore {
+	mock := &MockContactVerificationStore{ctrl: ctrl}
+	mock.recorder = &MockContactVerificationStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContactVerificationStore) EXPECT() *MockContactVerificationStoreMockRecorder {
+	return m.recorder
+}
+
+// Attempt mocks base method.
+func (m *MockContactVerificationStore) Attempt(ctx context.Context, receiverID uuid.UUID, key models.ContactKey, now time.Time) (*models.ContactVerificationEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Attempt", ctx, receiverID, key, now)
+	ret0, _ := ret[0].(*models.ContactVerificationEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Attempt indicates an expected call of Attempt.
+func (mr *MockContactVerificationStoreMockRecorder) Attempt(ctx, receiverID, key, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attempt", reflect.TypeOf((*MockContactVerificationStore)(nil).Attempt), ctx, receiverID, key, now)
+}
+
+// Confirm mocks base method.
+func (m *MockContactVerificationStore) Confirm(ctx context.Context, id uuid.UUID, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, id, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockContactVerificationStoreMockRecorder) Confirm(ctx, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockContactVerificationStore)(nil).Confirm), ctx, id, now)
+}
+
+// Create mocks base method.
+func (m *MockContactVerificationStore) Create(ctx context.Context, v *models.ContactVerificationEntity, since time.Time, check func([]time.Time) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, v, since, check)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockContactVerificationStoreMockRecorder) Create(ctx, v, since, check any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockContactVerificationStore)(nil).Create), ctx, v, since, check)
+}
+
+// MockCodeSender is a mock of CodeSender interface.
+type MockCodeSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockCodeSenderMockRecorder
+}
+
+// MockCodeSenderMockRecorder is the mock recorder for MockCodeSender.
+type MockCodeSenderMockRecorder struct {
+	mock *MockCodeSender
+}
+
+// NewMockCodeSender creates a new mock instance.
+func NewMockCodeSender(ctrl *gomock.Controller) *MockCodeSender {
+	mock := &MockCodeSender{ctrl: ctrl}
+	mock.recorder = &MockCodeSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCodeSender) EXPECT() *MockCodeSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockCodeSender) Send(message models.MessageSend) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", message)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockCodeSenderMockRecorder) Send(message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeSender)(nil).Send), message)
+}
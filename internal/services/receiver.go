@@ -2,31 +2,43 @@ package services
 
 import (
 	"context"
-	"encoding/csv"
+	"database/sql"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"projects/emergency-messages/internal/errorx"
 	"projects/emergency-messages/internal/models"
-	"strconv"
-)
+	"time"
 
-const numberOfCSVCells = 7
-const semicolon = ';'
+	"github.com/google/uuid"
+)
 
 type ReceiverService struct {
 	receiverStore ReceiverStore
-	log           *slog.Logger
+	consentStore  ContactConsentStore
+	// phoneCountry is the country of the phone numbers written without a country code
+	phoneCountry string
+	log          *slog.Logger
 }
 
 type ReceiverStore interface {
 	Create(ctx context.Context, receiver *models.ReceiverEntity) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ReceiverEntity, error)
+	FindByContact(ctx context.Context, key models.ContactKey) ([]models.ReceiverEntity, error)
 	FindByCity(ctx context.Context, city string) ([]models.ReceiverEntity, error)
+	List(ctx context.Context, filter models.ReceiverFilter) ([]models.ReceiverEntity, int, error)
+	Export(ctx context.Context, filter models.ReceiverFilter, fn func(models.ReceiverEntity) error) error
+	Update(ctx context.Context, receiver *models.ReceiverEntity) error
+	UpdateContacts(ctx context.Context, id uuid.UUID, change func([]models.Contact) ([]models.Contact, error)) (*models.ReceiverEntity, error)
+	Delete(ctx context.Context, id uuid.UUID, now time.Time) error
+	Import(ctx context.Context, receivers []*models.ReceiverEntity, merge func(existing, imported *models.ReceiverEntity) bool, dryRun bool) ([]models.ImportStatus, error)
 }
 
-func NewReceiverService(receiverStore ReceiverStore, log *slog.Logger) *ReceiverService {
+func NewReceiverService(receiverStore ReceiverStore, consentStore ContactConsentStore, phoneCountry string, log *slog.Logger) *ReceiverService {
 	return &ReceiverService{
 		receiverStore: receiverStore,
+		consentStore:  consentStore,
+		phoneCountry:  phoneCountry,
 		log:           log,
 	}
 }
@@ -52,150 +64,345 @@ func (s *ReceiverService) FindByCity(ctx context.Context, city string) ([]models
 	return receivers, nil
 }
 
-func (s *ReceiverService) Upload(csvData io.Reader) ([]*models.ReceiverCreate, error) {
-	ctx := context.Background()
-	receivers, err := s.getReceiversFromCSV(csvData)
+// List returns a page of the receivers matching the filter with the number of all matching receivers.
+func (s *ReceiverService) List(ctx context.Context, filter models.ReceiverFilter) (*models.ReceiverList, error) {
+	if err := filter.Validate(); err != nil {
+		s.log.With(slog.Any("filter", filter)).
+			Error("validating receiver filter", err)
+		return nil, errorx.ErrValidation
+	}
+
+	entities, total, err := s.receiverStore.List(ctx, filter)
 	if err != nil {
-		s.log.Error("getting receivers from csv", err)
-		return nil, err
+		s.log.With(slog.Any("filter", filter)).
+			Error("listing receivers", err)
+		return nil, errorx.ErrInternal
 	}
 
-	result := make([]*models.ReceiverCreate, 0, len(receivers))
+	receivers, err := s.transformStoreModelsByCityToReceivers(entities)
+	if err != nil {
+		s.log.Error("transforming store model to ReceiverService", err)
+		return nil, errorx.ErrInternal
+	}
 
-	for _, receiver := range receivers {
-		receiverStoreModel, err := s.transformReceiverCreateToStoreModel(receiver)
-		if err != nil {
-			s.log.Error("transforming ReceiverService to store model", err)
-			return nil, err
-		}
-		if err = s.receiverStore.Create(ctx, receiverStoreModel); err != nil {
-			s.log.Error("creating ReceiverService", err)
-			return nil, err
-		}
+	list := &models.ReceiverList{
+		Receivers: receivers,
+		Total:     total,
+	}
+	if len(entities) == filter.Limit {
+		list.NextCursor = models.ReceiverCursor(entities[len(entities)-1].ID)
+	}
+	return list, nil
+}
 
-		receiverCreated, err := s.transformStoreModelToReceiver(receiverStoreModel)
-		if err != nil {
-			s.log.Error("transforming store model to ReceiverService", err)
-			return nil, err
-		}
-		result = append(result, receiverCreated)
+// Create creates the receiver and returns it with its ID and timestamps.
+// The contacts are normalized, an invalid contact is created inactive with the reason,
+// a contact whose number opted out before stays opted out.
+func (s *ReceiverService) Create(ctx context.Context, receiver *models.ReceiverCreate) (*models.Receiver, error) {
+	s.normalizeContacts(receiver.Contacts)
+	if err := receiver.Validate(); err != nil {
+		s.log.With(slog.Any("receiver", receiver)).
+			Error("validating receiver", err)
+		return nil, errorx.ErrValidation
+	}
+	if err := s.keepOptOuts(ctx, receiver.Contacts); err != nil {
+		s.log.With(slog.Any("receiver", receiver)).
+			Error("finding opted out contacts", err)
+		return nil, errorx.ErrInternal
+	}
+
+	storeModel, err := s.transformReceiverCreateToStoreModel(receiver)
+	if err != nil {
+		s.log.With(slog.Any("receiver", receiver)).
+			Error("transforming receiver to store model", err)
+		return nil, errorx.ErrValidation
+	}
+	storeModel.Language = language(receiver.Language)
+	if storeModel.Contacts == nil {
+		storeModel.Contacts = []models.Contact{}
 	}
-	return result, nil
+
+	if err = s.receiverStore.Create(ctx, storeModel); err != nil {
+		s.log.With(slog.Any("receiver", storeModel)).
+			Error("creating receiver", err)
+		return nil, errorx.ErrInternal
+	}
+
+	result := transformReceiverStoreModelToReceiver(storeModel)
+	return &result, nil
 }
 
-func (s *ReceiverService) getReceiversFromCSV(csvData io.Reader) ([]*models.ReceiverCreate, error) {
-	csvReader := csv.NewReader(csvData)
-	csvReader.Comma = semicolon
+// GetByID returns the receiver unless it is deleted.
+func (s *ReceiverService) GetByID(ctx context.Context, id string) (*models.Receiver, error) {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
 
-	records, err := csvReader.ReadAll()
+	receiver, err := s.receiverStore.GetByID(ctx, uuidValue)
 	if err != nil {
-		s.log.Error("reading csv", err)
-		return nil, err
+		s.log.With(slog.Any("receiverID", id)).
+			Error("getting receiver", err)
+		return nil, receiverStoreError(err)
 	}
 
-	receivers := make([]*models.ReceiverCreate, 0, len(records))
-	for i, v := range records {
-		if len(v) != numberOfCSVCells {
-			return nil, errors.New(fmt.Sprintf("invalid csv file, expect %d cells, have %d cells", numberOfCSVCells, len(v)))
-		}
+	result := transformReceiverStoreModelToReceiver(receiver)
+	return &result, nil
+}
 
-		// the head of the file
-		if i == 0 {
-			continue
-		}
-		// v[0] is firstName
-		// v[1] is secondName
-		// v[2] is MobilePhone
-		// v[3] is IsMobileActive
-		// v[4] is Email
-		// v[5] is IsEmailActive
-		// v[6] is City
-		firstName := v[0]
-		lastName := v[1]
-		if firstName == "" || lastName == "" {
-			continue
+// Update changes the fields of the receiver which are set and returns the receiver.
+func (s *ReceiverService) Update(ctx context.Context, update *models.ReceiverUpdate) (*models.Receiver, error) {
+	if err := update.Validate(); err != nil {
+		s.log.With(slog.Any("receiver", update)).
+			Error("validating receiver", err)
+		return nil, errorx.ErrValidation
+	}
+	uuidValue, err := uuid.Parse(update.ID)
+	if err != nil {
+		s.log.Error("parsing uuid", update.ID, err)
+		return nil, errorx.ErrValidation
+	}
+
+	receiver, err := s.receiverStore.GetByID(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("receiverID", update.ID)).
+			Error("getting receiver", err)
+		return nil, receiverStoreError(err)
+	}
+
+	if update.FirstName != nil {
+		receiver.FirstName = *update.FirstName
+	}
+	if update.LastName != nil {
+		receiver.LastName = *update.LastName
+	}
+	if update.City != nil {
+		receiver.City = *update.City
+	}
+	if update.Language != nil {
+		receiver.Language = language(*update.Language)
+	}
+	if update.Tags != nil {
+		receiver.Tags = *update.Tags
+	}
+
+	if err = s.receiverStore.Update(ctx, receiver); err != nil {
+		s.log.With(slog.Any("receiverID", update.ID)).
+			Error("updating receiver", err)
+		return nil, receiverStoreError(err)
+	}
+
+	result := transformReceiverStoreModelToReceiver(receiver)
+	return &result, nil
+}
+
+// Delete marks the receiver as deleted, it is not sent messages anymore.
+func (s *ReceiverService) Delete(ctx context.Context, id string) error {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return errorx.ErrValidation
+	}
+
+	if err = s.receiverStore.Delete(ctx, uuidValue, time.Now()); err != nil {
+		s.log.With(slog.Any("receiverID", id)).
+			Error("deleting receiver", err)
+		return receiverStoreError(err)
+	}
+	return nil
+}
+
+// AddContact adds the contact to the receiver, a contact of the same type and value must not exist.
+// The contact is normalized, an invalid contact is added inactive with the reason,
+// a contact whose number opted out before stays opted out.
+func (s *ReceiverService) AddContact(ctx context.Context, id string, contact models.Contact) (*models.Receiver, error) {
+	s.normalizeContact(&contact)
+	if err := contact.Validate(); err != nil {
+		s.log.With(slog.Any("contact", contact)).
+			Error("validating contact", err)
+		return nil, errorx.ErrValidation
+	}
+	contacts := []models.Contact{contact}
+	if err := s.keepOptOuts(ctx, contacts); err != nil {
+		s.log.With(slog.Any("contact", contact)).
+			Error("finding opted out contacts", err)
+		return nil, errorx.ErrInternal
+	}
+	contact = contacts[0]
+	return s.updateContacts(ctx, id, func(contacts []models.Contact) ([]models.Contact, error) {
+		if findContact(contacts, contact.Key()) >= 0 {
+			return nil, fmt.Errorf("%s contact %s exists: %w", contact.Type, contact.Value, errorx.ErrValidation)
 		}
+		return append(contacts, contact), nil
+	})
+}
 
-		contacts, err := getContacts(v)
-		if err != nil {
-			continue
+// RemoveContact removes the contact from the receiver.
+func (s *ReceiverService) RemoveContact(ctx context.Context, id string, key models.ContactKey) (*models.Receiver, error) {
+	if err := key.Validate(); err != nil {
+		s.log.With(slog.Any("contact", key)).
+			Error("validating contact", err)
+		return nil, errorx.ErrValidation
+	}
+	return s.updateContacts(ctx, id, func(contacts []models.Contact) ([]models.Contact, error) {
+		i := s.findContact(contacts, key)
+		if i < 0 {
+			return nil, fmt.Errorf("%s contact %s: %w", key.Type, key.Value, errorx.ErrNotFound)
 		}
+		return append(contacts[:i], contacts[i+1:]...), nil
+	})
+}
 
-		receiver := &models.ReceiverCreate{
-			FirstName: firstName,
-			LastName:  lastName,
-			Contacts:  contacts,
-			City:      v[6],
+// SetContactActive activates or deactivates the contact of the receiver, an inactive contact is not sent messages.
+// An invalid contact cannot be activated, and neither can a contact whose receiver opted out.
+func (s *ReceiverService) SetContactActive(ctx context.Context, id string, key models.ContactKey, active bool) (*models.Receiver, error) {
+	if err := key.Validate(); err != nil {
+		s.log.With(slog.Any("contact", key)).
+			Error("validating contact", err)
+		return nil, errorx.ErrValidation
+	}
+	return s.updateContacts(ctx, id, func(contacts []models.Contact) ([]models.Contact, error) {
+		i := s.findContact(contacts, key)
+		if i < 0 {
+			return nil, fmt.Errorf("%s contact %s: %w", key.Type, key.Value, errorx.ErrNotFound)
 		}
+		if active && contacts[i].InactiveReason != "" {
+			return nil, fmt.Errorf("%s contact %s is invalid: %s: %w", key.Type, key.Value, contacts[i].InactiveReason, errorx.ErrValidation)
+		}
+		if active && contacts[i].IsOptedOut() {
+			return nil, fmt.Errorf("%s contact %s opted out: %w", key.Type, key.Value, errorx.ErrValidation)
+		}
+		contacts[i].IsActive = active
+		return contacts, nil
+	})
+}
 
-		receivers = append(receivers, receiver)
+func (s *ReceiverService) updateContacts(ctx context.Context, id string, change func([]models.Contact) ([]models.Contact, error)) (*models.Receiver, error) {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
 	}
 
-	return receivers, nil
+	receiver, err := s.receiverStore.UpdateContacts(ctx, uuidValue, change)
+	if err != nil {
+		s.log.With(slog.Any("receiverID", id)).
+			Error("updating receiver contacts", err)
+		return nil, receiverStoreError(err)
+	}
+
+	result := transformReceiverStoreModelToReceiver(receiver)
+	return &result, nil
 }
 
-func getContacts(v []string) ([]models.Contact, error) {
-	// v[2] is MobilePhone
-	// v[3] is IsMobileActive
-	var contacts []models.Contact
-	if mobilePhone := v[2]; mobilePhone != "" {
-		isMobileActive, err := strconv.ParseBool(v[3])
-		if err != nil {
-			return nil, err
-		}
-		contact := models.Contact{
-			Value:    mobilePhone,
-			Type:     models.ContactTypeSMS,
-			IsActive: isMobileActive,
-		}
-		contacts = append(contacts, contact)
-	}
-	// v[4] is Email
-	// v[5] is IsEmailActive
-	if email := v[4]; email != "" {
-		isEmailActive, err := strconv.ParseBool(v[5])
-		if err != nil {
-			return nil, err
+// normalizeContacts normalizes the values of the contacts, see normalizeContact.
+func (s *ReceiverService) normalizeContacts(contacts []models.Contact) {
+	for i := range contacts {
+		s.normalizeContact(&contacts[i])
+	}
+}
+
+// normalizeContact normalizes the value of the contact, an invalid contact is deactivated with the reason.
+// A new contact is not verified until the receiver confirms the code sent to it.
+func (s *ReceiverService) normalizeContact(contact *models.Contact) {
+	contact.InactiveReason, contact.VerifiedAt, contact.OptedOutAt = "", nil, nil
+	if err := contact.Normalize(s.phoneCountry); err != nil {
+		contact.IsActive, contact.InactiveReason = false, err.Error()
+	}
+}
+
+// keepOptOuts marks the contacts whose numbers opted out as opted out, the consents are logged by the number,
+// so a number which sent STOP is not sent messages again when its contact is removed and added or imported.
+func (s *ReceiverService) keepOptOuts(ctx context.Context, contacts []models.Contact) error {
+	if len(contacts) == 0 {
+		return nil
+	}
+	keys := make([]models.ContactKey, 0, len(contacts))
+	for _, c := range contacts {
+		keys = append(keys, c.Key())
+	}
+	optedOut, err := s.consentStore.FindOptedOut(ctx, keys)
+	if err != nil {
+		return err
+	}
+	for i := range contacts {
+		if at, ok := optedOut[contacts[i].Key()]; ok {
+			contacts[i].OptedOutAt = &at
 		}
-		contact := models.Contact{
-			Value:    email,
-			Type:     models.ContactTypeEmail,
-			IsActive: isEmailActive,
+	}
+	return nil
+}
+
+// findContact returns the index of the contact with the key written as it is sent or normalized, -1 if there is none.
+func (s *ReceiverService) findContact(contacts []models.Contact, key models.ContactKey) int {
+	return findNormalizedContact(contacts, key, s.phoneCountry)
+}
+
+// findNormalizedContact returns the index of the contact with the key written as it is sent
+// or normalized with the default phone country, -1 if there is none.
+func findNormalizedContact(contacts []models.Contact, key models.ContactKey, phoneCountry string) int {
+	if i := findContact(contacts, key); i >= 0 {
+		return i
+	}
+	contact := models.Contact{Type: key.Type, Value: key.Value}
+	if err := contact.Normalize(phoneCountry); err != nil {
+		return -1
+	}
+	return findContact(contacts, contact.Key())
+}
+
+// findContact returns the index of the contact with the key, -1 if there is none.
+func findContact(contacts []models.Contact, key models.ContactKey) int {
+	for i, c := range contacts {
+		if c.Key() == key {
+			return i
 		}
-		contacts = append(contacts, contact)
 	}
-	return contacts, nil
+	return -1
+}
+
+// receiverStoreError maps an error of the receiver store to the error of the service.
+func receiverStoreError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, errorx.ErrNotFound):
+		return errorx.ErrNotFound
+	case errors.Is(err, errorx.ErrValidation):
+		return errorx.ErrValidation
+	default:
+		return errorx.ErrInternal
+	}
 }
 
 func (s *ReceiverService) transformStoreModelsByCityToReceivers(receiverStore []models.ReceiverEntity) ([]models.Receiver, error) {
-	receivers := make([]models.Receiver, len(receiverStore))
-	for _, u := range receiverStore {
-		receiver := models.Receiver{
-			FirstName: u.FirstName,
-			LastName:  u.LastName,
-			Contacts:  u.Contacts,
-			City:      u.City,
-		}
-		receivers = append(receivers, receiver)
+	receivers := make([]models.Receiver, 0, len(receiverStore))
+	for i := range receiverStore {
+		receivers = append(receivers, transformReceiverStoreModelToReceiver(&receiverStore[i]))
 	}
 	return receivers, nil
 }
 
-func (s *ReceiverService) transformReceiverCreateToStoreModel(u *models.ReceiverCreate) (*models.ReceiverEntity, error) {
-	return &models.ReceiverEntity{
+func transformReceiverStoreModelToReceiver(u *models.ReceiverEntity) models.Receiver {
+	return models.Receiver{
+		ID:        u.ID,
 		FirstName: u.FirstName,
 		LastName:  u.LastName,
-		Contacts:  u.Contacts,
 		City:      u.City,
-	}, nil
+		Language:  u.Language,
+		Tags:      u.Tags,
+		Contacts:  u.Contacts,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
 }
 
-func (s *ReceiverService) transformStoreModelToReceiver(u *models.ReceiverEntity) (*models.ReceiverCreate, error) {
-	return &models.ReceiverCreate{
-		ID:        u.ID,
+func (s *ReceiverService) transformReceiverCreateToStoreModel(u *models.ReceiverCreate) (*models.ReceiverEntity, error) {
+	return &models.ReceiverEntity{
 		FirstName: u.FirstName,
 		LastName:  u.LastName,
 		Contacts:  u.Contacts,
 		City:      u.City,
+		Language:  u.Language,
+		Tags:      u.Tags,
 	}, nil
 }
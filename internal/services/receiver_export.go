@@ -0,0 +1,167 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/parsers"
+	"strconv"
+	"strings"
+)
+
+// receiverWriter writes the records of receivers in the layout of imported files.
+type receiverWriter interface {
+	Write(record []string) error
+	Flush() error
+}
+
+// Export writes the receivers matching the export as a CSV file separated by semicolons with a header row,
+// or as JSON Lines, in the layout the import reads. The receivers are written while they are read from the store,
+// the output is buffered, so nothing is written when the receivers cannot be read at all.
+// A receiver is written with every contact and tag, the contacts of a type and the tags are separated by commas.
+// The verification and the opt out of a contact are not written, an import keeps the opt outs by the numbers.
+func (s *ReceiverService) Export(ctx context.Context, export models.ReceiverExport, w io.Writer) error {
+	if err := export.Validate(); err != nil {
+		s.log.With(slog.Any("export", export)).
+			Error("validating receiver export", err)
+		return errorx.ErrValidation
+	}
+
+	var writer receiverWriter
+	if export.Format == models.ImportFormatJSONLines {
+		writer = &jsonLinesReceiverWriter{w: bufio.NewWriter(w)}
+	} else {
+		writer = newCSVReceiverWriter(w)
+	}
+
+	err := s.receiverStore.Export(ctx, export.Filter(), func(entity models.ReceiverEntity) error {
+		return writer.Write(receiverExportRecord(entity, export.Mask))
+	})
+	if err != nil {
+		s.log.With(slog.Any("export", export)).
+			Error("exporting receivers", err)
+		return errorx.ErrInternal
+	}
+	return writer.Flush()
+}
+
+// receiverExportRecord returns the receiver in the order of the receivers layout.
+func receiverExportRecord(entity models.ReceiverEntity, mask bool) []string {
+	record := make([]string, len(models.ReceiverCSVHeader))
+	record[0] = entity.FirstName
+	record[1] = entity.LastName
+	record[6] = entity.City
+	record[9] = entity.Language
+	record[12] = strings.Join(entity.Tags, models.ReceiverValueSeparator)
+	for _, column := range receiverContactColumns {
+		var values, actives []string
+		for _, contact := range entity.Contacts {
+			if contact.Type != column.contactType {
+				continue
+			}
+			value := contact.Value
+			if mask {
+				value = contact.MaskedValue()
+			}
+			values = append(values, value)
+			actives = append(actives, strconv.FormatBool(contact.IsActive))
+		}
+		record[column.value] = strings.Join(values, models.ReceiverValueSeparator)
+		record[column.active] = strings.Join(actives, models.ReceiverValueSeparator)
+	}
+	return record
+}
+
+// csvReceiverWriter writes the header row before the first receiver,
+// the cells are escaped so a spreadsheet does not run them as formulas.
+type csvReceiverWriter struct {
+	writer *csv.Writer
+	header bool
+}
+
+func newCSVReceiverWriter(w io.Writer) *csvReceiverWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = semicolon
+	return &csvReceiverWriter{writer: writer}
+}
+
+func (w *csvReceiverWriter) Write(record []string) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	escaped := make([]string, len(record))
+	for i, v := range record {
+		escaped[i] = parsers.EscapeFormula(v)
+	}
+	return w.writer.Write(escaped)
+}
+
+// Flush writes the header row of an empty export too.
+func (w *csvReceiverWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvReceiverWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	return w.writer.Write(models.ReceiverCSVHeader)
+}
+
+// jsonLinesReceiverWriter writes a receiver as a JSON object keyed by the columns of the layout,
+// the empty columns are omitted and the Is...Active columns are booleans.
+type jsonLinesReceiverWriter struct {
+	w   *bufio.Writer
+	buf bytes.Buffer
+}
+
+func (w *jsonLinesReceiverWriter) Write(record []string) error {
+	w.buf.Reset()
+	w.buf.WriteByte('{')
+	for i, column := range models.ReceiverCSVHeader {
+		if record[i] == "" {
+			continue
+		}
+		if w.buf.Len() > 1 {
+			w.buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		w.buf.Write(key)
+		w.buf.WriteByte(':')
+
+		var value []byte
+		if active, err := strconv.ParseBool(record[i]); err == nil && isActiveColumn(i) {
+			value, _ = json.Marshal(active)
+		} else {
+			value, _ = json.Marshal(record[i])
+		}
+		w.buf.Write(value)
+	}
+	w.buf.WriteString("}\n")
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
+
+func (w *jsonLinesReceiverWriter) Flush() error {
+	return w.w.Flush()
+}
+
+func isActiveColumn(i int) bool {
+	for _, column := range receiverContactColumns {
+		if column.active == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/parsers"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const semicolon = ';'
+
+// importBatchSize is the number of rows saved in a transaction
+const importBatchSize = 500
+
+// importedReceiver is a valid row of an imported file, warnings are its invalid contacts
+type importedReceiver struct {
+	line     int
+	receiver *models.ReceiverCreate
+	warnings []string
+	record   []string
+}
+
+// receiverColumnAliases are the other names of the columns of the receivers layout in imported files,
+// the names are compared in lower case without spaces, dashes, dots and underscores.
+// A column called name is not an alias, it usually holds the full name.
+var receiverColumnAliases = map[string][]string{
+	models.ReceiverColumnFirstName:        {"firstname", "givenname"},
+	models.ReceiverColumnLastName:         {"secondname", "lastname", "surname", "familyname"},
+	models.ReceiverColumnMobilePhone:      {"mobilephone", "phone", "mobile", "tel"},
+	models.ReceiverColumnIsMobileActive:   {"ismobileactive", "mobileactive", "isphoneactive", "phoneactive"},
+	models.ReceiverColumnEmail:            {"email", "mail"},
+	models.ReceiverColumnIsEmailActive:    {"isemailactive", "emailactive"},
+	models.ReceiverColumnCity:             {"city", "town"},
+	models.ReceiverColumnTelegram:         {"telegram"},
+	models.ReceiverColumnIsTelegramActive: {"istelegramactive", "telegramactive"},
+	models.ReceiverColumnLanguage:         {"language", "lang"},
+	models.ReceiverColumnWhatsApp:         {"whatsapp"},
+	models.ReceiverColumnIsWhatsAppActive: {"iswhatsappactive", "whatsappactive"},
+	models.ReceiverColumnTags:             {"tags", "tag"},
+}
+
+// ImportRows reads the receivers from a file of the format of the options, row by row.
+// The columns are found by their names in any order, the other columns are ignored.
+// Every row is validated, invalid and repeated rows are skipped with the reason,
+// valid rows are saved in batches creating receivers or updating the ones with the same natural key.
+// The progress function is called with the rows reported since its previous call after every batch,
+// the import stops with its error, e.g. when the import is cancelled. Batches which are saved are kept.
+// Importing a file again does not repeat its receivers.
+func (s *ReceiverService) ImportRows(ctx context.Context, data io.Reader, options models.ImportOptions, progress func(rows []models.ImportRow) error) error {
+	reader, err := s.newImportReader(data, options)
+	if err != nil {
+		return err
+	}
+
+	var rows []models.ImportRow
+	seen := make(map[string]int)
+	batch := make([]importedReceiver, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) > 0 {
+			rows = s.importBatch(ctx, batch, options, rows)
+			batch = batch[:0]
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		err := progress(rows)
+		rows = nil
+		return err
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *parsers.RowError
+		if errors.As(err, &rowErr) {
+			rows = append(rows, models.ImportRow{Line: rowErr.Line, Status: models.ImportSkipped, Reason: rowErr.Err.Error()})
+		} else if err != nil {
+			s.log.Error("reading import", err)
+			return errorx.ErrValidation
+		} else {
+			record := receiverRecord(row, options.Columns)
+			receiver, warnings, err := parseReceiverRow(record, s.phoneCountry)
+			if err != nil {
+				rows = append(rows, models.ImportRow{Line: row.Line, Status: models.ImportSkipped, Reason: err.Error(), Record: record})
+			} else {
+				key := models.ReceiverNaturalKey(receiver.FirstName, receiver.LastName, receiver.City, receiver.Contacts)
+				if first, ok := seen[key]; ok {
+					rows = append(rows, models.ImportRow{Line: row.Line, Status: models.ImportSkipped, Reason: fmt.Sprintf("repeats the receiver of line %d", first), Record: record})
+				} else {
+					seen[key] = row.Line
+					batch = append(batch, importedReceiver{line: row.Line, receiver: receiver, warnings: warnings, record: record})
+				}
+			}
+		}
+
+		if len(batch) == importBatchSize || len(rows) >= importBatchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// CountRows returns the number of rows of a file of the format of the options without the header row.
+func (s *ReceiverService) CountRows(data io.Reader, options models.ImportOptions) (int, error) {
+	reader, err := s.newImportReader(data, options)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		_, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		var rowErr *parsers.RowError
+		if err != nil && !errors.As(err, &rowErr) {
+			s.log.Error("reading import", err)
+			return 0, errorx.ErrValidation
+		}
+		count++
+	}
+}
+
+func (s *ReceiverService) newImportReader(data io.Reader, options models.ImportOptions) (parsers.Reader, error) {
+	format := options.Format
+	if format == "" {
+		format = models.ImportFormatCSV
+	}
+	reader, err := parsers.New(format, data)
+	if err != nil {
+		s.log.With(slog.Any("format", format)).
+			Error("opening import", err)
+		return nil, errorx.ErrValidation
+	}
+	return reader, nil
+}
+
+// receiverRecord returns the values of the row in the order of the receivers layout.
+// A column is taken from the column of the file set by the options, or else the column having its name or one of its aliases.
+func receiverRecord(row parsers.Row, columns map[string]string) []string {
+	names := make(map[string]string, len(row.Values))
+	for name := range row.Values {
+		normalized := normalizeColumnName(name)
+		if _, ok := names[normalized]; !ok {
+			names[normalized] = name
+		}
+	}
+
+	record := make([]string, len(models.ReceiverCSVHeader))
+	for i, column := range models.ReceiverCSVHeader {
+		if name, ok := columns[column]; ok {
+			value, ok := row.Values[name]
+			if !ok {
+				value = row.Values[names[normalizeColumnName(name)]]
+			}
+			record[i] = value
+			continue
+		}
+		for _, alias := range receiverColumnAliases[column] {
+			if name, ok := names[alias]; ok {
+				record[i] = row.Values[name]
+				break
+			}
+		}
+	}
+	return record
+}
+
+func normalizeColumnName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_', '.':
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// importBatch saves the receivers of the batch in a transaction and adds their rows to the reported ones,
+// the rows are skipped when the batch cannot be saved.
+func (s *ReceiverService) importBatch(ctx context.Context, batch []importedReceiver, options models.ImportOptions, rows []models.ImportRow) []models.ImportRow {
+	entities := make([]*models.ReceiverEntity, 0, len(batch))
+	for _, b := range batch {
+		entity, _ := s.transformReceiverCreateToStoreModel(b.receiver)
+		entity.Language = language(entity.Language)
+		if entity.Contacts == nil {
+			entity.Contacts = []models.Contact{}
+		}
+		entities = append(entities, entity)
+	}
+
+	if err := s.keepImportedOptOuts(ctx, entities); err != nil {
+		s.log.With(slog.Int("from_line", batch[0].line), slog.Int("to_line", batch[len(batch)-1].line)).
+			Error("finding opted out contacts", err)
+		for _, b := range batch {
+			rows = append(rows, models.ImportRow{Line: b.line, Status: models.ImportSkipped, Reason: "saving failed", Record: b.record})
+		}
+		return rows
+	}
+	return s.saveBatch(ctx, batch, entities, options, rows)
+}
+
+// saveBatch saves the receivers of the batch and adds their rows to the reported ones.
+// When the database rejects a receiver of the batch, the receivers are saved one by one,
+// so only the rejected rows are skipped with the reason of the database.
+func (s *ReceiverService) saveBatch(ctx context.Context, batch []importedReceiver, entities []*models.ReceiverEntity, options models.ImportOptions, rows []models.ImportRow) []models.ImportRow {
+	statuses, err := s.receiverStore.Import(ctx, entities, mergeReceiver, options.DryRun)
+	if err != nil {
+		var rowErr *models.ImportRowError
+		rejected := errors.As(err, &rowErr)
+		if rejected && len(batch) > 1 {
+			for i := range batch {
+				rows = s.saveBatch(ctx, batch[i:i+1], entities[i:i+1], options, rows)
+			}
+			return rows
+		}
+
+		s.log.With(slog.Int("from_line", batch[0].line), slog.Int("to_line", batch[len(batch)-1].line)).
+			Error("importing receivers", err)
+		reason := "saving failed"
+		if rejected {
+			reason += ": " + rowErr.Reason
+		}
+		for _, b := range batch {
+			rows = append(rows, models.ImportRow{Line: b.line, Status: models.ImportSkipped, Reason: reason, Record: b.record})
+		}
+		return rows
+	}
+
+	for i, b := range batch {
+		row := models.ImportRow{Line: b.line, Status: statuses[i], Warnings: b.warnings}
+		if statuses[i] == models.ImportSkipped {
+			row.Reason = models.ImportReasonNoChanges
+		}
+		if len(b.warnings) > 0 {
+			row.Record = b.record
+		}
+		// the receivers created by a dry run are rolled back
+		if !options.DryRun || statuses[i] != models.ImportCreated {
+			id := entities[i].ID
+			row.ReceiverID = &id
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// keepImportedOptOuts marks the imported contacts whose numbers opted out as opted out, see keepOptOuts.
+func (s *ReceiverService) keepImportedOptOuts(ctx context.Context, entities []*models.ReceiverEntity) error {
+	var contacts []models.Contact
+	for _, e := range entities {
+		contacts = append(contacts, e.Contacts...)
+	}
+	if err := s.keepOptOuts(ctx, contacts); err != nil {
+		return err
+	}
+	for _, e := range entities {
+		n := copy(e.Contacts, contacts)
+		contacts = contacts[n:]
+	}
+	return nil
+}
+
+// mergeReceiver changes the existing receiver by the imported one and reports whether it is changed.
+// The fields which are not imported are kept, the imported contacts are added or switched, the other contacts are kept.
+func mergeReceiver(existing, imported *models.ReceiverEntity) bool {
+	changed := false
+	for _, f := range []struct {
+		existing *string
+		imported string
+	}{
+		{existing: &existing.FirstName, imported: imported.FirstName},
+		{existing: &existing.LastName, imported: imported.LastName},
+		{existing: &existing.City, imported: imported.City},
+		{existing: &existing.Language, imported: imported.Language},
+	} {
+		if f.imported != "" && *f.existing != f.imported {
+			*f.existing = f.imported
+			changed = true
+		}
+	}
+	if len(imported.Tags) > 0 && strings.Join(existing.Tags, "\n") != strings.Join(imported.Tags, "\n") {
+		existing.Tags = imported.Tags
+		changed = true
+	}
+	for _, c := range imported.Contacts {
+		i := findContact(existing.Contacts, c.Key())
+		if i < 0 {
+			existing.Contacts = append(existing.Contacts, c)
+			changed = true
+			continue
+		}
+		if c.IsOptedOut() && !existing.Contacts[i].IsOptedOut() {
+			existing.Contacts[i].OptedOutAt = c.OptedOutAt
+			changed = true
+		}
+		// a file does not activate a contact whose receiver opted out
+		active := c.IsActive && !existing.Contacts[i].IsOptedOut()
+		if existing.Contacts[i].IsActive != active || existing.Contacts[i].InactiveReason != c.InactiveReason {
+			existing.Contacts[i].IsActive = active
+			existing.Contacts[i].InactiveReason = c.InactiveReason
+			changed = true
+		}
+	}
+	return changed
+}
+
+// parseReceiverRow parses and validates a row of an imported file in the order of the receivers layout.
+// The contacts are normalized, an invalid contact is imported inactive and reported in the warnings,
+// so is an invalid language which is not imported.
+// It returns an error describing the first problem of the row.
+func parseReceiverRow(v []string, phoneCountry string) (*models.ReceiverCreate, []string, error) {
+	for i := range v {
+		v[i] = strings.TrimSpace(v[i])
+	}
+
+	// v[0] is firstName
+	// v[1] is secondName
+	// v[6] is City
+	// v[9] is Language, optional
+	// v[12] is Tags, optional
+	contacts, warnings, err := getContacts(v, phoneCountry)
+	if err != nil {
+		return nil, nil, err
+	}
+	receiver := &models.ReceiverCreate{
+		FirstName: v[0],
+		LastName:  v[1],
+		Contacts:  contacts,
+		City:      v[6],
+		Tags:      splitReceiverValues(v[12]),
+	}
+	// a receiver whose language is not supported is imported without it and gets the alerts in the fallback language
+	if receiver.Language, err = models.ParseLanguage(v[9]); err != nil {
+		warnings = append(warnings, fmt.Sprintf("%s: %s, imported without a language", models.ReceiverColumnLanguage, err))
+	}
+	if err = receiver.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return receiver, warnings, nil
+}
+
+// receiverContactColumns are the positions of the contacts in the receivers layout, a column holds a contact of its type
+var receiverContactColumns = []struct {
+	value, active    int
+	name, activeName string
+	contactType      models.ContactType
+}{
+	// v[2] is MobilePhone, v[3] is IsMobileActive
+	{value: 2, active: 3, name: models.ReceiverColumnMobilePhone, activeName: models.ReceiverColumnIsMobileActive, contactType: models.ContactTypeSMS},
+	// v[4] is Email, v[5] is IsEmailActive
+	{value: 4, active: 5, name: models.ReceiverColumnEmail, activeName: models.ReceiverColumnIsEmailActive, contactType: models.ContactTypeEmail},
+	// v[7] is Telegram, v[8] is IsTelegramActive
+	{value: 7, active: 8, name: models.ReceiverColumnTelegram, activeName: models.ReceiverColumnIsTelegramActive, contactType: models.ContactTypeTelegram},
+	// v[10] is WhatsApp, v[11] is IsWhatsAppActive
+	{value: 10, active: 11, name: models.ReceiverColumnWhatsApp, activeName: models.ReceiverColumnIsWhatsAppActive, contactType: models.ContactTypeWhatsApp},
+}
+
+// splitReceiverValues returns the values of a cell separated by commas, the empty ones are dropped.
+func splitReceiverValues(cell string) []string {
+	var values []string
+	for _, v := range strings.Split(cell, models.ReceiverValueSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// getContacts returns the contacts of the row, a cell holds the contacts of its type separated by commas
+// and the Is...Active cell holds a value for every contact or one value for all of them.
+func getContacts(v []string, phoneCountry string) ([]models.Contact, []string, error) {
+	var contacts []models.Contact
+	var warnings []string
+	for _, c := range receiverContactColumns {
+		values := splitReceiverValues(v[c.value])
+		if len(values) == 0 {
+			continue
+		}
+		// a contact without the active column is active
+		actives := splitReceiverValues(v[c.active])
+		if len(actives) > 1 && len(actives) != len(values) {
+			return nil, nil, fmt.Errorf("%s: %d values for %d contacts", c.activeName, len(actives), len(values))
+		}
+
+		for i, value := range values {
+			// a masked export is not a backup, its contacts would overwrite the real ones
+			if models.IsMaskedValue(value) {
+				return nil, nil, fmt.Errorf("%s: masked value %q cannot be imported", c.name, value)
+			}
+			isActive := true
+			if len(actives) > 0 {
+				active := actives[0]
+				if len(actives) > 1 {
+					active = actives[i]
+				}
+				var err error
+				if isActive, err = strconv.ParseBool(active); err != nil {
+					return nil, nil, fmt.Errorf("%s: invalid boolean %q", c.activeName, active)
+				}
+			}
+			contact := models.Contact{
+				Value:    value,
+				Type:     c.contactType,
+				IsActive: isActive,
+			}
+			if err := contact.Normalize(phoneCountry); err != nil {
+				contact.IsActive, contact.InactiveReason = false, err.Error()
+				warnings = append(warnings, fmt.Sprintf("%s: %s", c.name, err))
+			}
+			contacts = append(contacts, contact)
+		}
+	}
+	return contacts, warnings, nil
+}
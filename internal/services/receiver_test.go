@@ -3,13 +3,20 @@ package services
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"projects/emergency-messages/internal/errorx"
 	"projects/emergency-messages/internal/models"
 	"projects/emergency-messages/internal/services/mocks"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -21,7 +28,7 @@ func TestReceiverService_GetByCity(t *testing.T) {
 
 	ctx := context.Background()
 	receiverStore := mock_services.NewMockReceiverStore(ctrl)
-	receiverService := NewReceiverService(receiverStore, log)
+	receiverService := NewReceiverService(receiverStore, noOptOuts(ctrl), "RU", log)
 
 	t.Run("when have city and services then no error", func(t *testing.T) {
 		city := "Moscow"
@@ -73,7 +80,7 @@ func TestReceiverService_GetByCity(t *testing.T) {
 	})
 }
 
-func TestReceiverService_Upload(t *testing.T) {
+func TestReceiverService_ImportRows(t *testing.T) {
 	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -81,9 +88,9 @@ func TestReceiverService_Upload(t *testing.T) {
 	receiverstore := mock_services.NewMockReceiverStore(ctrl)
 	ctx := context.Background()
 
-	receiverservice := NewReceiverService(receiverstore, log)
+	receiverservice := NewReceiverService(receiverstore, noOptOuts(ctrl), "RU", log)
 
-	t.Run("when all queue have then no error", func(t *testing.T) {
+	t.Run("when all queue have then created", func(t *testing.T) {
 		receiverCreate := &models.ReceiverEntity{
 			FirstName: "Robert",
 			LastName:  "Smith",
@@ -102,17 +109,48 @@ func TestReceiverService_Upload(t *testing.T) {
 		}
 		receiverstore.
 			EXPECT().
-			Create(ctx, receiverCreate).
-			Return(nil)
+			Import(ctx, []*models.ReceiverEntity{receiverCreate}, gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
 
 		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nRobert;Smith;+48178323;false;iaiw3br@gmail.com;true;Saint-Petersburg"
 		buf := bytes.NewBuffer([]byte(data))
 
-		receivers, err := receiverservice.Upload(buf)
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
 		assert.NoError(t, err)
-		assert.NotNil(t, receivers)
+		assert.Equal(t, 1, progress.Created)
+		assert.Equal(t, []models.ImportRow{{Line: 2, Status: models.ImportCreated, ReceiverID: &uuid.UUID{}}}, rows)
+	})
+	t.Run("when names in csv are empty then rows are skipped with reason", func(t *testing.T) {
+		receiverstore.
+			EXPECT().
+			Import(ctx, gomock.Len(1), gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
+
+		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nBoris;;+7312787312;false;iaiw3br@gmail.com;true;Saint-Petersburg\n;Smith;+48178323;false;iaiw3br@gmail.com;true;Saint-Petersburg\nRobert;Smith;+48178323;false;iaiw3br@gmail.com;true;Saint-Petersburg"
+		buf := bytes.NewBuffer([]byte(data))
+
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Created)
+		assert.Equal(t, 2, progress.Skipped)
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Equal(t, "last name is empty", rows[0].Reason)
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Equal(t, 4, rows[2].Line)
+	})
+	t.Run("when boolean in csv is invalid then row is skipped with reason", func(t *testing.T) {
+		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nRobert;Smith;+48178323;yes;;;Saint-Petersburg"
+		buf := bytes.NewBuffer([]byte(data))
+
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Skipped)
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Equal(t, `IsMobileActive: invalid boolean "yes"`, rows[0].Reason)
+		assert.True(t, rows[0].IsError())
+		assert.Equal(t, "yes", rows[0].Record[3])
 	})
-	t.Run("when first name in csv is empty then no error", func(t *testing.T) {
+	t.Run("when csv has telegram columns then telegram contact is created", func(t *testing.T) {
 		receiverCreate := &models.ReceiverEntity{
 			FirstName: "Robert",
 			LastName:  "Smith",
@@ -126,65 +164,1009 @@ func TestReceiverService_Upload(t *testing.T) {
 					Type:     models.ContactTypeEmail,
 					IsActive: true,
 				},
+				{
+					Value:    "123456789",
+					Type:     models.ContactTypeTelegram,
+					IsActive: true,
+				},
 			},
 			City: "Saint-Petersburg",
 		}
 		receiverstore.
 			EXPECT().
-			Create(ctx, receiverCreate).
-			Return(nil)
+			Import(ctx, []*models.ReceiverEntity{receiverCreate}, gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
 
-		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nBoris;;+7312787312;false;iaiw3br@gmail.com;true;Saint-Petersburg\nRobert;Smith;+48178323;false;iaiw3br@gmail.com;true;Saint-Petersburg"
+		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City;Telegram;IsTelegramActive\nRobert;Smith;+48178323;false;iaiw3br@gmail.com;true;Saint-Petersburg;123456789;true"
 		buf := bytes.NewBuffer([]byte(data))
 
-		receivers, err := receiverservice.Upload(buf)
+		_, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
 		assert.NoError(t, err)
-		assert.Equal(t, 1, len(receivers))
+		assert.Equal(t, 1, progress.Created)
 	})
-	t.Run("when last name in csv is empty then no error", func(t *testing.T) {
+	t.Run("when cell has several contacts then every contact is created", func(t *testing.T) {
+		receiverCreate := &models.ReceiverEntity{
+			FirstName: "Robert",
+			LastName:  "Smith",
+			Contacts: []models.Contact{
+				{Value: "+79001234567", Type: models.ContactTypeSMS, IsActive: true},
+				{Value: "+79007654321", Type: models.ContactTypeSMS},
+				{Value: "+79001234567", Type: models.ContactTypeWhatsApp, IsActive: true},
+			},
+			City: "Kazan",
+			Tags: []string{"volunteer", "school-12"},
+		}
+		receiverstore.
+			EXPECT().
+			Import(ctx, []*models.ReceiverEntity{receiverCreate}, gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
 
-		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nBoris;;+7312787312;false;iaiw3br@gmail.com;true;Saint-Petersburg\nRobert;;+48178323;false;iaiw3br@gmail.com;true;Saint-Petersburg"
+		data := "firstName;secondName;MobilePhone;IsMobileActive;City;WhatsApp;Tags\nRobert;Smith;+79001234567, +79007654321;true,false;Kazan;+79001234567;volunteer,school-12"
 		buf := bytes.NewBuffer([]byte(data))
 
-		receivers, err := receiverservice.Upload(buf)
+		_, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
 		assert.NoError(t, err)
-		assert.Equal(t, 0, len(receivers))
+		assert.Equal(t, 1, progress.Created)
 	})
-	t.Run("when csv is invalid, there are too few cells then error", func(t *testing.T) {
-		data := "FirstName;SecondName;MobilePhone;Email\nAlbert;Guss;+8748327432;al@gmail.com\n"
+	t.Run("when active values do not match contacts then row is skipped", func(t *testing.T) {
+		data := "firstName;secondName;MobilePhone;IsMobileActive;City\nRobert;Smith;+79001234567,+79007654321,+79005554433;true,false;Kazan"
 		buf := bytes.NewBuffer([]byte(data))
 
-		receivers, err := receiverservice.Upload(buf)
-		assert.Error(t, err)
-		assert.Nil(t, receivers)
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Skipped)
+		assert.Equal(t, "IsMobileActive: 2 values for 3 contacts", rows[0].Reason)
 	})
-	t.Run("when csv is valid, but stores returns error then error", func(t *testing.T) {
+	t.Run("when csv has language column then language is canonical", func(t *testing.T) {
 		receiverCreate := &models.ReceiverEntity{
-			FirstName: "Boris",
-			LastName:  "Ivanov",
+			FirstName: "Ildar",
+			LastName:  "Sabirov",
 			Contacts: []models.Contact{
 				{
-					Value: "+7312787312",
-					Type:  models.ContactTypeSMS,
+					Value:    "+79001234567",
+					Type:     models.ContactTypeSMS,
+					IsActive: true,
 				},
+			},
+			City:     "Kazan",
+			Language: "tt-RU",
+		}
+		receiverstore.
+			EXPECT().
+			Import(ctx, []*models.ReceiverEntity{receiverCreate}, gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportUpdated}, nil)
+
+		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City;Telegram;IsTelegramActive;Language\nIldar;Sabirov;+79001234567;true;;;Kazan;;;tt-ru"
+		buf := bytes.NewBuffer([]byte(data))
+
+		_, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Updated)
+	})
+	t.Run("when language in csv is invalid then receiver is imported without language with warning", func(t *testing.T) {
+		receiverCreate := &models.ReceiverEntity{
+			FirstName: "Ildar",
+			LastName:  "Sabirov",
+			Contacts: []models.Contact{
 				{
-					Value:    "iaiw3br@gmail.com",
-					Type:     models.ContactTypeEmail,
+					Value:    "+79001234567",
+					Type:     models.ContactTypeSMS,
 					IsActive: true,
 				},
 			},
-			City: "Saint-Petersburg",
+			City: "Kazan",
+		}
+		receiverstore.
+			EXPECT().
+			Import(ctx, []*models.ReceiverEntity{receiverCreate}, gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
+
+		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City;Telegram;IsTelegramActive;Language\nIldar;Sabirov;+79001234567;true;;;Kazan;;;not a language"
+		buf := bytes.NewBuffer([]byte(data))
+
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Created)
+		assert.Len(t, rows[0].Warnings, 1)
+		assert.Contains(t, rows[0].Warnings[0], "Language")
+	})
+	t.Run("when row has more cells than header then it is skipped", func(t *testing.T) {
+		data := "FirstName;SecondName;MobilePhone;City\nAlbert;Guss;+8748327432;Kazan;al@gmail.com\n"
+		buf := bytes.NewBuffer([]byte(data))
+
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Skipped)
+		assert.Equal(t, "expected 4 cells, have 5 cells", rows[0].Reason)
+	})
+	t.Run("when columns are reordered with other columns then they are found by names", func(t *testing.T) {
+		receiverCreate := &models.ReceiverEntity{
+			FirstName: "Ildar",
+			LastName:  "Sabirov",
+			Contacts: []models.Contact{
+				{
+					Value:    "+79001234567",
+					Type:     models.ContactTypeSMS,
+					IsActive: true,
+				},
+			},
+			City: "Kazan",
+		}
+		receiverstore.
+			EXPECT().
+			Import(ctx, []*models.ReceiverEntity{receiverCreate}, gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
+
+		data := "\ufeffCity;Registry ID;Last name;First_Name;Phone\nKazan;1042;Sabirov;Ildar;+79001234567"
+		buf := bytes.NewBuffer([]byte(data))
+
+		_, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Created)
+	})
+	t.Run("when columns are mapped then mapped columns are used", func(t *testing.T) {
+		receiverCreate := &models.ReceiverEntity{
+			FirstName: "Ildar",
+			LastName:  "Sabirov",
+			Contacts: []models.Contact{
+				{
+					Value:    "+79001234567",
+					Type:     models.ContactTypeSMS,
+					IsActive: true,
+				},
+			},
+			City: "Kazan",
 		}
 		receiverstore.
 			EXPECT().
-			Create(ctx, receiverCreate).
-			Return(errors.New(""))
+			Import(ctx, []*models.ReceiverEntity{receiverCreate}, gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
+
+		data := "Имя;Фамилия;Город;Телефон;Phone\nIldar;Sabirov;Kazan;+79001234567;+79000000000"
+		buf := bytes.NewBuffer([]byte(data))
+
+		options := models.ImportOptions{Columns: map[string]string{
+			models.ReceiverColumnFirstName:   "имя",
+			models.ReceiverColumnLastName:    "Фамилия",
+			models.ReceiverColumnCity:        "Город",
+			models.ReceiverColumnMobilePhone: "Телефон",
+		}}
+		_, progress, err := importRows(ctx, receiverservice, buf, options)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Created)
+	})
+	t.Run("when file is json lines then rows are objects", func(t *testing.T) {
+		receiverstore.
+			EXPECT().
+			Import(ctx, gomock.Len(1), gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
+
+		data := `{"first_name":"Ildar","last_name":"Sabirov","city":"Kazan","phone":"+79001234567","phone_active":true}` + "\n\n" +
+			`{"first_name":"Boris","city":"Kazan"}` + "\n" +
+			`{"first_name":`
+		buf := bytes.NewBuffer([]byte(data))
+
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{Format: models.ImportFormatJSONLines})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Created)
+		assert.Equal(t, 2, progress.Skipped)
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Equal(t, "last name is empty", rows[1].Reason)
+		assert.Equal(t, []string{"Boris", "", "", "", "", "", "Kazan", "", "", "", "", "", ""}, rows[1].Record)
+		assert.Equal(t, 4, rows[2].Line)
+		assert.Contains(t, rows[2].Reason, "invalid JSON object")
+	})
+	t.Run("when format is unknown then import fails", func(t *testing.T) {
+		err := receiverservice.ImportRows(ctx, bytes.NewBufferString(""), models.ImportOptions{Format: "pdf"}, func([]models.ImportRow) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when contacts are invalid then imported inactive with warnings", func(t *testing.T) {
+		receiverCreate := &models.ReceiverEntity{
+			FirstName: "Ildar",
+			LastName:  "Sabirov",
+			Contacts: []models.Contact{
+				{
+					Value:    "+79001234567",
+					Type:     models.ContactTypeSMS,
+					IsActive: true,
+				},
+				{
+					Value:          "ildar@example",
+					Type:           models.ContactTypeEmail,
+					InactiveReason: `invalid email "ildar@example": invalid domain "example"`,
+				},
+			},
+			City: "Kazan",
+		}
+		receiverstore.
+			EXPECT().
+			Import(ctx, []*models.ReceiverEntity{receiverCreate}, gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
+
+		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nIldar;Sabirov;8 900 123-45-67;true;ildar@example;true;Kazan"
+		buf := bytes.NewBuffer([]byte(data))
+
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Created)
+		assert.Equal(t, []string{`Email: invalid email "ildar@example": invalid domain "example"`}, rows[0].Warnings)
+		assert.Equal(t, "ildar@example", rows[0].Record[4])
+		assert.False(t, rows[0].IsError())
+	})
+	t.Run("when row repeats a receiver then it is skipped", func(t *testing.T) {
+		receiverstore.
+			EXPECT().
+			Import(ctx, gomock.Len(1), gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
+
+		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nRobert;Smith;+48178323;false;;;Kazan\nROBERT;smith;+48178323;true;;;Kazan"
+		buf := bytes.NewBuffer([]byte(data))
+
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Skipped)
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Equal(t, "repeats the receiver of line 2", rows[1].Reason)
+	})
+	t.Run("when dry run then created receivers have no id", func(t *testing.T) {
+		receiverstore.
+			EXPECT().
+			Import(ctx, gomock.Len(2), gomock.Any(), true).
+			Return([]models.ImportStatus{models.ImportCreated, models.ImportSkipped}, nil)
+
+		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nRobert;Smith;+48178323;false;;;Kazan\nBoris;Ivanov;+7312787312;true;;;Kazan"
+		buf := bytes.NewBuffer([]byte(data))
+
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Created)
+		assert.Nil(t, rows[0].ReceiverID)
+		assert.Equal(t, "no changes", rows[1].Reason)
+		assert.False(t, rows[1].IsError())
+		assert.NotNil(t, rows[1].ReceiverID)
+	})
+	t.Run("when rows exceed a batch then they are saved in batches", func(t *testing.T) {
+		receiverstore.
+			EXPECT().
+			Import(ctx, gomock.Len(importBatchSize), gomock.Any(), false).
+			Return(make([]models.ImportStatus, importBatchSize), nil)
+		receiverstore.
+			EXPECT().
+			Import(ctx, gomock.Len(1), gomock.Any(), false).
+			Return([]models.ImportStatus{models.ImportCreated}, nil)
+
+		var data bytes.Buffer
+		data.WriteString("firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\n")
+		for i := 0; i <= importBatchSize; i++ {
+			data.WriteString(fmt.Sprintf("Robert;Smith;+7900%07d;true;;;Kazan\n", i))
+		}
+
+		rows, progress, err := importRows(ctx, receiverservice, &data, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, rows, importBatchSize+1)
+		assert.Equal(t, importBatchSize+1, progress.Processed)
+	})
+	t.Run("when progress fails then import stops", func(t *testing.T) {
+		receiverstore.
+			EXPECT().
+			Import(ctx, gomock.Len(importBatchSize), gomock.Any(), false).
+			Return(make([]models.ImportStatus, importBatchSize), nil)
+
+		var data bytes.Buffer
+		data.WriteString("firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\n")
+		for i := 0; i < importBatchSize*2; i++ {
+			data.WriteString(fmt.Sprintf("Robert;Smith;+7900%07d;true;;;Kazan\n", i))
+		}
+
+		cancelled := errors.New("cancelled")
+		calls := 0
+		err := receiverservice.ImportRows(ctx, &data, models.ImportOptions{}, func(rows []models.ImportRow) error {
+			calls++
+			return cancelled
+		})
+		assert.ErrorIs(t, err, cancelled)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("when stores returns error then rows of the batch are skipped", func(t *testing.T) {
+		receiverstore.
+			EXPECT().
+			Import(ctx, gomock.Any(), gomock.Any(), false).
+			Return(nil, errors.New(""))
 
 		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nBoris;Ivanov;+7312787312;false;iaiw3br@gmail.com;true;Saint-Petersburg\nRobert;Smith;+48178323;false;iaiw3br@gmail.com;true;Saint-Petersburg"
 		buf := bytes.NewBuffer([]byte(data))
 
-		receivers, err := receiverservice.Upload(buf)
-		assert.Error(t, err)
-		assert.Nil(t, receivers)
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, progress.Skipped)
+		assert.Equal(t, "saving failed", rows[0].Reason)
+	})
+	t.Run("when database rejects a row then other rows of the batch are saved", func(t *testing.T) {
+		rejected := fmt.Errorf("importing receivers: %w", &models.ImportRowError{Reason: "value too long for type character varying(50)"})
+		gomock.InOrder(
+			receiverstore.
+				EXPECT().
+				Import(ctx, gomock.Len(2), gomock.Any(), false).
+				Return(nil, rejected),
+			receiverstore.
+				EXPECT().
+				Import(ctx, gomock.Len(1), gomock.Any(), false).
+				Return([]models.ImportStatus{models.ImportCreated}, nil),
+			receiverstore.
+				EXPECT().
+				Import(ctx, gomock.Len(1), gomock.Any(), false).
+				Return(nil, rejected),
+		)
+
+		data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nBoris;Ivanov;+7312787312;false;iaiw3br@gmail.com;true;Saint-Petersburg\nRobert;Smith;+48178323;false;iaiw3br@gmail.com;true;Saint-Petersburg"
+		buf := bytes.NewBuffer([]byte(data))
+
+		rows, progress, err := importRows(ctx, receiverservice, buf, models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Created)
+		assert.Equal(t, 1, progress.Skipped)
+		assert.Equal(t, models.ImportCreated, rows[0].Status)
+		assert.Equal(t, "saving failed: value too long for type character varying(50)", rows[1].Reason)
+	})
+}
+
+func TestReceiverService_CountRows(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	receiverservice := NewReceiverService(nil, nil, "RU", log)
+
+	data := "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City\nRobert;Smith;+48178323;true;;;Kazan\n\"Boris;Ivanov\nAlbert;Guss;+8748327432;true;;;Kazan\n"
+	count, err := receiverservice.CountRows(bytes.NewBufferString(data), models.ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+// importRows imports the CSV data and returns the reported rows ordered by line with their numbers.
+// noOptOuts returns a consent store without opted out contacts.
+func noOptOuts(ctrl *gomock.Controller) *mock_services.MockContactConsentStore {
+	consentStore := mock_services.NewMockContactConsentStore(ctrl)
+	consentStore.
+		EXPECT().
+		FindOptedOut(gomock.Any(), gomock.Any()).
+		Return(map[models.ContactKey]time.Time{}, nil).
+		AnyTimes()
+	return consentStore
+}
+
+func importRows(ctx context.Context, s *ReceiverService, data io.Reader, options models.ImportOptions) ([]models.ImportRow, models.ImportProgress, error) {
+	var progress models.ImportProgress
+	rows := make([]models.ImportRow, 0)
+	err := s.ImportRows(ctx, data, options, func(reported []models.ImportRow) error {
+		progress.Add(reported)
+		rows = append(rows, reported...)
+		return nil
+	})
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Line < rows[j].Line
+	})
+	return rows, progress, err
+}
+
+func Test_mergeReceiver(t *testing.T) {
+	existing := func() *models.ReceiverEntity {
+		return &models.ReceiverEntity{
+			FirstName: "Robert",
+			LastName:  "Smith",
+			City:      "Kazan",
+			Language:  "ru",
+			Contacts: []models.Contact{
+				{Type: models.ContactTypeSMS, Value: "+48178323", IsActive: true},
+				{Type: models.ContactTypeTelegram, Value: "12345", IsActive: true},
+			},
+		}
+	}
+
+	t.Run("when imported receiver is the same then not changed", func(t *testing.T) {
+		imported := existing()
+		imported.Language = ""
+		imported.Contacts = imported.Contacts[:1]
+		assert.False(t, mergeReceiver(existing(), imported))
+	})
+	t.Run("when contacts are imported then they are added or switched", func(t *testing.T) {
+		receiver := existing()
+		imported := &models.ReceiverEntity{
+			FirstName: "Robert",
+			LastName:  "Smith",
+			City:      "Kazan",
+			Contacts: []models.Contact{
+				{Type: models.ContactTypeSMS, Value: "+48178323"},
+				{Type: models.ContactTypeEmail, Value: "robert@example.com", IsActive: true},
+			},
+		}
+		assert.True(t, mergeReceiver(receiver, imported))
+		assert.Equal(t, "ru", receiver.Language)
+		assert.Equal(t, []models.Contact{
+			{Type: models.ContactTypeSMS, Value: "+48178323"},
+			{Type: models.ContactTypeTelegram, Value: "12345", IsActive: true},
+			{Type: models.ContactTypeEmail, Value: "robert@example.com", IsActive: true},
+		}, receiver.Contacts)
+	})
+	t.Run("when opted out contact is imported active then it stays inactive", func(t *testing.T) {
+		optedOutAt := time.Now()
+		receiver := existing()
+		receiver.Contacts[0].IsActive, receiver.Contacts[0].OptedOutAt = false, &optedOutAt
+		imported := existing()
+		imported.Contacts = imported.Contacts[:1]
+		assert.False(t, mergeReceiver(receiver, imported))
+		assert.False(t, receiver.Contacts[0].IsActive)
+	})
+	t.Run("when number of imported contact opted out then existing contact is opted out", func(t *testing.T) {
+		optedOutAt := time.Now()
+		receiver := existing()
+		imported := existing()
+		imported.Contacts = imported.Contacts[:1]
+		imported.Contacts[0].OptedOutAt = &optedOutAt
+		assert.True(t, mergeReceiver(receiver, imported))
+		assert.True(t, receiver.Contacts[0].IsOptedOut())
+	})
+}
+
+func TestReceiverService_Create(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	receiverService := NewReceiverService(receiverStore, noOptOuts(ctrl), "RU", log)
+
+	t.Run("when receiver is valid then created", func(t *testing.T) {
+		receiver := &models.ReceiverCreate{
+			FirstName: "Ivan",
+			LastName:  "Petrov",
+			City:      "Kazan",
+			Language:  "TT",
+		}
+		receiverStore.
+			EXPECT().
+			Create(ctx, &models.ReceiverEntity{
+				FirstName: "Ivan",
+				LastName:  "Petrov",
+				City:      "Kazan",
+				Language:  "tt",
+				Contacts:  []models.Contact{},
+			}).
+			Return(nil)
+
+		created, err := receiverService.Create(ctx, receiver)
+		assert.NoError(t, err)
+		assert.Equal(t, "tt", created.Language)
+	})
+	t.Run("when first name is taken then another receiver is created", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, receiver *models.ReceiverEntity) error {
+				receiver.ID = uuid.New()
+				return nil
+			}).
+			Times(2)
+
+		first, err := receiverService.Create(ctx, &models.ReceiverCreate{FirstName: "Ivan", LastName: "Petrov", City: "Kazan"})
+		assert.NoError(t, err)
+		second, err := receiverService.Create(ctx, &models.ReceiverCreate{FirstName: "Ivan", LastName: "Sidorov", City: "Kazan"})
+		assert.NoError(t, err)
+		assert.Equal(t, first.FirstName, second.FirstName)
+		assert.NotEqual(t, first.ID, second.ID)
+	})
+	t.Run("when contact is repeated then error", func(t *testing.T) {
+		receiver := &models.ReceiverCreate{
+			FirstName: "Ivan",
+			LastName:  "Petrov",
+			City:      "Kazan",
+			Contacts: []models.Contact{
+				{Type: models.ContactTypeSMS, Value: "+79001234567"},
+				{Type: models.ContactTypeSMS, Value: "+79001234567", IsActive: true},
+			},
+		}
+
+		_, err := receiverService.Create(ctx, receiver)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when contacts are written differently then normalized and invalid ones are inactive", func(t *testing.T) {
+		receiver := &models.ReceiverCreate{
+			FirstName: "Ivan",
+			LastName:  "Petrov",
+			City:      "Kazan",
+			Contacts: []models.Contact{
+				{Type: models.ContactTypeSMS, Value: "8 (900) 123-45-67", IsActive: true},
+				{Type: models.ContactTypeEmail, Value: "Ivan.Petrov@Example.com", IsActive: true},
+				{Type: models.ContactTypeWhatsApp, Value: "12-34", IsActive: true},
+			},
+		}
+		receiverStore.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil)
+
+		created, err := receiverService.Create(ctx, receiver)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Contact{
+			{Type: models.ContactTypeSMS, Value: "+79001234567", IsActive: true},
+			{Type: models.ContactTypeEmail, Value: "ivan.petrov@example.com", IsActive: true},
+			{Type: models.ContactTypeWhatsApp, Value: "12-34", InactiveReason: `invalid phone number "12-34": expected 7 to 15 digits`},
+		}, created.Contacts)
+	})
+	t.Run("when contacts are the same after normalization then error", func(t *testing.T) {
+		receiver := &models.ReceiverCreate{
+			FirstName: "Ivan",
+			LastName:  "Petrov",
+			City:      "Kazan",
+			Contacts: []models.Contact{
+				{Type: models.ContactTypeSMS, Value: "+7 900 123 45 67"},
+				{Type: models.ContactTypeSMS, Value: "89001234567"},
+			},
+		}
+
+		_, err := receiverService.Create(ctx, receiver)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when contact type is unknown then error", func(t *testing.T) {
+		receiver := &models.ReceiverCreate{
+			FirstName: "Ivan",
+			LastName:  "Petrov",
+			City:      "Kazan",
+			Contacts:  []models.Contact{{Type: "pigeon", Value: "roof"}},
+		}
+
+		_, err := receiverService.Create(ctx, receiver)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+}
+
+func TestReceiverService_Update(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	receiverService := NewReceiverService(receiverStore, noOptOuts(ctrl), "RU", log)
+	id := uuid.New()
+
+	t.Run("when field is set then only it is changed", func(t *testing.T) {
+		city := "Kazan"
+		receiverStore.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.ReceiverEntity{ID: id, FirstName: "Ivan", LastName: "Petrov", City: "Moscow"}, nil)
+		receiverStore.
+			EXPECT().
+			Update(ctx, &models.ReceiverEntity{ID: id, FirstName: "Ivan", LastName: "Petrov", City: "Kazan"}).
+			Return(nil)
+
+		updated, err := receiverService.Update(ctx, &models.ReceiverUpdate{ID: id.String(), City: &city})
+		assert.NoError(t, err)
+		assert.Equal(t, "Kazan", updated.City)
+		assert.Equal(t, "Ivan", updated.FirstName)
+	})
+	t.Run("when receiver is not found then error", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(nil, sql.ErrNoRows)
+
+		_, err := receiverService.Update(ctx, &models.ReceiverUpdate{ID: id.String()})
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+	t.Run("when first name is empty then error", func(t *testing.T) {
+		empty := ""
+		_, err := receiverService.Update(ctx, &models.ReceiverUpdate{ID: id.String(), FirstName: &empty})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+}
+
+func TestReceiverService_List(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	receiverService := NewReceiverService(receiverStore, noOptOuts(ctrl), "RU", log)
+	first, second := uuid.New(), uuid.New()
+
+	t.Run("when page is full then next cursor", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			List(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter models.ReceiverFilter) ([]models.ReceiverEntity, int, error) {
+				assert.Equal(t, first, filter.After)
+				assert.Equal(t, 1, filter.Limit)
+				return []models.ReceiverEntity{{ID: second, City: "Kazan"}}, 3, nil
+			})
+
+		list, err := receiverService.List(ctx, models.ReceiverFilter{City: "Kazan", Cursor: models.ReceiverCursor(first), Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, list.Total)
+		assert.Len(t, list.Receivers, 1)
+		assert.Equal(t, models.ReceiverCursor(second), list.NextCursor)
+	})
+	t.Run("when page is not full then no next cursor", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			List(ctx, gomock.Any()).
+			Return([]models.ReceiverEntity{{ID: first}}, 1, nil)
+
+		list, err := receiverService.List(ctx, models.ReceiverFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, list.Total)
+		assert.Empty(t, list.NextCursor)
+	})
+	t.Run("when cursor is invalid then error", func(t *testing.T) {
+		_, err := receiverService.List(ctx, models.ReceiverFilter{Cursor: "abc"})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when store fails then error", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			List(ctx, gomock.Any()).
+			Return(nil, 0, errors.New("connection refused"))
+
+		_, err := receiverService.List(ctx, models.ReceiverFilter{})
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+	})
+}
+
+func TestReceiverService_Export(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	receiverService := NewReceiverService(receiverStore, noOptOuts(ctrl), "RU", log)
+	filter := models.ReceiverFilter{City: "Kazan", ContactType: models.ContactTypeSMS, WithoutTotal: true}
+	receivers := []models.ReceiverEntity{
+		{
+			FirstName: "Ildar",
+			LastName:  "Sabirov",
+			City:      "Kazan",
+			Language:  "tt",
+			Tags:      []string{"volunteer", "school-12"},
+			Contacts: []models.Contact{
+				{Type: models.ContactTypeSMS, Value: "+79001234567", IsActive: true},
+				{Type: models.ContactTypeSMS, Value: "+79007654321"},
+				{Type: models.ContactTypeEmail, Value: "ildar@example.com"},
+				{Type: models.ContactTypeWhatsApp, Value: "+79001234567", IsActive: true},
+			},
+		},
+		{
+			FirstName: "Boris",
+			LastName:  "Ivanov",
+			City:      "Kazan",
+			Contacts:  []models.Contact{{Type: models.ContactTypeSMS, Value: "+7312787312", IsActive: true}},
+		},
+	}
+	exportReceivers := func(_ context.Context, _ models.ReceiverFilter, fn func(models.ReceiverEntity) error) error {
+		for _, r := range receivers {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	t.Run("when format is csv then import layout", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Export(ctx, filter, gomock.Any()).
+			DoAndReturn(exportReceivers)
+
+		var buf bytes.Buffer
+		err := receiverService.Export(ctx, models.ReceiverExport{City: "Kazan", ContactType: models.ContactTypeSMS}, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "firstName;secondName;MobilePhone;IsMobileActive;Email;IsEmailActive;City;Telegram;IsTelegramActive;Language;WhatsApp;IsWhatsAppActive;Tags\n"+
+			"Ildar;Sabirov;'+79001234567,+79007654321;true,false;ildar@example.com;false;Kazan;;;tt;'+79001234567;true;volunteer,school-12\n"+
+			"Boris;Ivanov;'+7312787312;true;;;Kazan;;;;;;\n", buf.String())
+	})
+	t.Run("when cell starts a formula then it is escaped", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Export(ctx, filter, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ models.ReceiverFilter, fn func(models.ReceiverEntity) error) error {
+				return fn(models.ReceiverEntity{FirstName: "=HYPERLINK(\"http://example.com\")", LastName: "@Ivanov", City: "Kazan"})
+			})
+
+		var buf bytes.Buffer
+		err := receiverService.Export(ctx, models.ReceiverExport{City: "Kazan", ContactType: models.ContactTypeSMS}, &buf)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), `"'=HYPERLINK(""http://example.com"")";'@Ivanov;`)
+	})
+	t.Run("when format is json lines and masked then objects with masked contacts", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Export(ctx, filter, gomock.Any()).
+			DoAndReturn(exportReceivers)
+
+		var buf bytes.Buffer
+		err := receiverService.Export(ctx, models.ReceiverExport{City: "Kazan", ContactType: models.ContactTypeSMS, Format: models.ImportFormatJSONLines, Mask: true}, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"firstName":"Ildar","secondName":"Sabirov","MobilePhone":"+7********67,+7********21","IsMobileActive":"true,false","Email":"i***@example.com","IsEmailActive":false,"City":"Kazan","Language":"tt","WhatsApp":"+7********67","IsWhatsAppActive":true,"Tags":"volunteer,school-12"}`+"\n"+
+			`{"firstName":"Boris","secondName":"Ivanov","MobilePhone":"+7*******12","IsMobileActive":true,"City":"Kazan"}`+"\n", buf.String())
+	})
+	t.Run("when export is imported then same receivers", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Export(ctx, filter, gomock.Any()).
+			DoAndReturn(exportReceivers)
+		receiverStore.
+			EXPECT().
+			Import(ctx, gomock.Len(2), gomock.Any(), true).
+			DoAndReturn(func(_ context.Context, imported []*models.ReceiverEntity, _ func(existing, imported *models.ReceiverEntity) bool, _ bool) ([]models.ImportStatus, error) {
+				assert.Equal(t, receivers[0].Contacts, imported[0].Contacts)
+				assert.Equal(t, receivers[0].Tags, imported[0].Tags)
+				assert.Equal(t, receivers[1].Contacts, imported[1].Contacts)
+				assert.Equal(t, "tt", imported[0].Language)
+				return []models.ImportStatus{models.ImportSkipped, models.ImportSkipped}, nil
+			})
+
+		var buf bytes.Buffer
+		export := models.ReceiverExport{City: "Kazan", ContactType: models.ContactTypeSMS, Format: models.ImportFormatJSONLines}
+		assert.NoError(t, receiverService.Export(ctx, export, &buf))
+		_, progress, err := importRows(ctx, receiverService, &buf, models.ImportOptions{DryRun: true, Format: models.ImportFormatJSONLines})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, progress.Skipped)
+	})
+	t.Run("when csv export is imported then same receivers", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Export(ctx, filter, gomock.Any()).
+			DoAndReturn(exportReceivers)
+		receiverStore.
+			EXPECT().
+			Import(ctx, gomock.Len(2), gomock.Any(), true).
+			DoAndReturn(func(_ context.Context, imported []*models.ReceiverEntity, _ func(existing, imported *models.ReceiverEntity) bool, _ bool) ([]models.ImportStatus, error) {
+				assert.Equal(t, receivers[0].Contacts, imported[0].Contacts)
+				assert.Equal(t, receivers[0].Tags, imported[0].Tags)
+				assert.Equal(t, receivers[1].Contacts, imported[1].Contacts)
+				return []models.ImportStatus{models.ImportSkipped, models.ImportSkipped}, nil
+			})
+
+		var buf bytes.Buffer
+		assert.NoError(t, receiverService.Export(ctx, models.ReceiverExport{City: "Kazan", ContactType: models.ContactTypeSMS}, &buf))
+		_, progress, err := importRows(ctx, receiverService, &buf, models.ImportOptions{DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, progress.Skipped)
+	})
+	t.Run("when masked export is imported then rows are skipped", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Export(ctx, filter, gomock.Any()).
+			DoAndReturn(exportReceivers)
+
+		var buf bytes.Buffer
+		export := models.ReceiverExport{City: "Kazan", ContactType: models.ContactTypeSMS, Format: models.ImportFormatJSONLines, Mask: true}
+		assert.NoError(t, receiverService.Export(ctx, export, &buf))
+		rows, progress, err := importRows(ctx, receiverService, &buf, models.ImportOptions{DryRun: true, Format: models.ImportFormatJSONLines})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, progress.Skipped)
+		assert.Equal(t, `MobilePhone: masked value "+7********67" cannot be imported`, rows[0].Reason)
+	})
+	t.Run("when format cannot be exported then error", func(t *testing.T) {
+		err := receiverService.Export(ctx, models.ReceiverExport{Format: models.ImportFormatVCard}, io.Discard)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when store fails then nothing is written", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Export(ctx, gomock.Any(), gomock.Any()).
+			Return(errors.New("connection refused"))
+
+		var buf bytes.Buffer
+		err := receiverService.Export(ctx, models.ReceiverExport{}, &buf)
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+		assert.Zero(t, buf.Len())
+	})
+}
+
+func TestReceiverService_Delete(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	receiverService := NewReceiverService(receiverStore, noOptOuts(ctrl), "RU", log)
+	id := uuid.New()
+
+	t.Run("when receiver exists then deleted", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Delete(ctx, id, gomock.Any()).
+			Return(nil)
+
+		assert.NoError(t, receiverService.Delete(ctx, id.String()))
+	})
+	t.Run("when receiver is already deleted then not found", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			Delete(ctx, id, gomock.Any()).
+			Return(sql.ErrNoRows)
+
+		assert.ErrorIs(t, receiverService.Delete(ctx, id.String()), errorx.ErrNotFound)
+	})
+	t.Run("when id is not uuid then error", func(t *testing.T) {
+		assert.ErrorIs(t, receiverService.Delete(ctx, "abc"), errorx.ErrValidation)
+	})
+}
+
+func TestReceiverService_Contacts(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	receiverService := NewReceiverService(receiverStore, noOptOuts(ctrl), "RU", log)
+	id := uuid.New()
+	sms := models.Contact{Type: models.ContactTypeSMS, Value: "+79001234567"}
+
+	// changeContacts applies the change of the service to the contacts as the store does
+	changeContacts := func(contacts []models.Contact) func(context.Context, uuid.UUID, func([]models.Contact) ([]models.Contact, error)) (*models.ReceiverEntity, error) {
+		return func(_ context.Context, id uuid.UUID, change func([]models.Contact) ([]models.Contact, error)) (*models.ReceiverEntity, error) {
+			changed, err := change(contacts)
+			if err != nil {
+				return nil, err
+			}
+			return &models.ReceiverEntity{ID: id, Contacts: changed}, nil
+		}
+	}
+
+	t.Run("when contact is new then added", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(changeContacts(nil))
+
+		receiver, err := receiverService.AddContact(ctx, id.String(), sms)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Contact{sms}, receiver.Contacts)
+	})
+	t.Run("when contact exists then error", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{sms}))
+
+		_, err := receiverService.AddContact(ctx, id.String(), sms)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when contact is activated then active", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{sms}))
+
+		receiver, err := receiverService.SetContactActive(ctx, id.String(), sms.Key(), true)
+		assert.NoError(t, err)
+		assert.True(t, receiver.Contacts[0].IsActive)
+	})
+	t.Run("when removed contact is not found then error", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(changeContacts(nil))
+
+		_, err := receiverService.RemoveContact(ctx, id.String(), sms.Key())
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+	t.Run("when contact is added then normalized", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(changeContacts(nil))
+
+		receiver, err := receiverService.AddContact(ctx, id.String(), models.Contact{Type: models.ContactTypeEmail, Value: " Ivan@Example.COM ", IsActive: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Contact{{Type: models.ContactTypeEmail, Value: "ivan@example.com", IsActive: true}}, receiver.Contacts)
+	})
+	t.Run("when invalid contact is activated then error", func(t *testing.T) {
+		invalid := models.Contact{Type: models.ContactTypeEmail, Value: "ivan@", InactiveReason: "invalid email"}
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{invalid}))
+
+		_, err := receiverService.SetContactActive(ctx, id.String(), invalid.Key(), true)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when opted out contact is activated then error", func(t *testing.T) {
+		optedOutAt := time.Now()
+		optedOut := models.Contact{Type: models.ContactTypeSMS, Value: "+79001234567", OptedOutAt: &optedOutAt}
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{optedOut}))
+
+		_, err := receiverService.SetContactActive(ctx, id.String(), optedOut.Key(), true)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when contact is removed by national number then normalized one is removed", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{sms}))
+
+		receiver, err := receiverService.RemoveContact(ctx, id.String(), models.ContactKey{Type: models.ContactTypeSMS, Value: "89001234567"})
+		assert.NoError(t, err)
+		assert.Empty(t, receiver.Contacts)
+	})
+	t.Run("when contact is removed then the others are kept", func(t *testing.T) {
+		email := models.Contact{Type: models.ContactTypeEmail, Value: "ivan@example.com"}
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(changeContacts([]models.Contact{sms, email}))
+
+		receiver, err := receiverService.RemoveContact(ctx, id.String(), sms.Key())
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Contact{email}, receiver.Contacts)
+	})
+}
+
+func TestReceiverService_keepOptOuts(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	consentStore := mock_services.NewMockContactConsentStore(ctrl)
+	receiverService := NewReceiverService(receiverStore, consentStore, "RU", log)
+	id := uuid.New()
+	optedOutAt := time.Now().Add(-time.Hour)
+	sms := models.Contact{Type: models.ContactTypeSMS, Value: "+79001234567", IsActive: true}
+
+	t.Run("when number opted out then added contact stays opted out", func(t *testing.T) {
+		consentStore.
+			EXPECT().
+			FindOptedOut(ctx, []models.ContactKey{sms.Key()}).
+			Return(map[models.ContactKey]time.Time{sms.Key(): optedOutAt}, nil)
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(func(_ context.Context, id uuid.UUID, change func([]models.Contact) ([]models.Contact, error)) (*models.ReceiverEntity, error) {
+				changed, err := change(nil)
+				return &models.ReceiverEntity{ID: id, Contacts: changed}, err
+			})
+
+		receiver, err := receiverService.AddContact(ctx, id.String(), models.Contact{Type: models.ContactTypeSMS, Value: "8 900 123-45-67", IsActive: true})
+		assert.NoError(t, err)
+		assert.True(t, receiver.Contacts[0].IsOptedOut())
+		assert.True(t, receiver.Contacts[0].IsActive)
+	})
+	t.Run("when number opted out then imported contact stays opted out", func(t *testing.T) {
+		consentStore.
+			EXPECT().
+			FindOptedOut(ctx, []models.ContactKey{sms.Key()}).
+			Return(map[models.ContactKey]time.Time{sms.Key(): optedOutAt}, nil)
+		receiverStore.
+			EXPECT().
+			Import(ctx, gomock.Len(1), gomock.Any(), false).
+			DoAndReturn(func(_ context.Context, imported []*models.ReceiverEntity, _ func(existing, imported *models.ReceiverEntity) bool, _ bool) ([]models.ImportStatus, error) {
+				assert.Equal(t, &optedOutAt, imported[0].Contacts[0].OptedOutAt)
+				return []models.ImportStatus{models.ImportCreated}, nil
+			})
+
+		data := "firstName;secondName;MobilePhone;City\nIvan;Ivanov;+79001234567;Kazan\n"
+		_, progress, err := importRows(ctx, receiverService, bytes.NewBufferString(data), models.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Created)
+	})
+	t.Run("when consents cannot be found then error", func(t *testing.T) {
+		consentStore.
+			EXPECT().
+			FindOptedOut(ctx, gomock.Any()).
+			Return(nil, errors.New("connection refused"))
+
+		_, err := receiverService.AddContact(ctx, id.String(), sms)
+		assert.ErrorIs(t, err, errorx.ErrInternal)
 	})
 }
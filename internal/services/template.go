@@ -9,6 +9,8 @@ import (
 	"log/slog"
 	"projects/emergency-messages/internal/errorx"
 	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/render"
+	"sort"
 	"time"
 )
 
@@ -22,6 +24,9 @@ type TemplateStore interface {
 	Update(ctx context.Context, t *models.TemplateEntity) error
 	Delete(ctx context.Context, id uuid.UUID, now time.Time) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.TemplateEntity, error)
+	List(ctx context.Context, filter models.TemplateFilter) ([]models.TemplateEntity, int, error)
+	Versions(ctx context.Context, id uuid.UUID) ([]models.TemplateVersionEntity, error)
+	Rollback(ctx context.Context, id uuid.UUID, version int, author string) (*models.TemplateEntity, error)
 }
 
 func NewTemplate(templateStore TemplateStore, log *slog.Logger) TemplateService {
@@ -31,27 +36,145 @@ func NewTemplate(templateStore TemplateStore, log *slog.Logger) TemplateService
 	}
 }
 
-func (s *TemplateService) Create(ctx context.Context, template *models.TemplateCreate) error {
+// Create creates the template and returns it with its ID and timestamps.
+func (s *TemplateService) Create(ctx context.Context, template *models.TemplateCreate) (*models.Template, error) {
 	if err := template.Validate(); err != nil {
 		s.log.With(slog.Any("template", template)).
 			Error("validating template", err)
-		return errorx.ErrValidation
+		return nil, errorx.ErrValidation
+	}
+	if err := checkTemplate(template.Subject, template.Text, template.Variables, template.Variants, template.Translations, template.WhatsAppParameters); err != nil {
+		s.log.With(slog.Any("template", template)).
+			Error("checking template variables", err)
+		return nil, errorx.ErrValidation
 	}
 
 	storeModel, err := s.transformTemplateCreateToStoreModel(template)
 	if err != nil {
 		s.log.With(slog.Any("template", template)).
 			Error("transforming template to store model", err)
-		return errorx.ErrValidation
+		return nil, errorx.ErrValidation
+	}
+	if err = checkSegments(storeModel); err != nil {
+		s.log.With(slog.Any("template", template)).
+			Error("checking sms segments", err)
+		return nil, errorx.ErrValidation
 	}
 
 	if err = s.templateStore.Create(ctx, storeModel); err != nil {
 		s.log.With(slog.Any("template", storeModel)).
 			Error("creating template", err)
-		return errorx.ErrInternal
+		return nil, errorx.ErrInternal
 	}
 
-	return nil
+	result := transformTemplateStoreModelToTemplate(storeModel)
+	return &result, nil
+}
+
+// GetByID returns the template unless it is deleted.
+func (s *TemplateService) GetByID(ctx context.Context, id string) (*models.Template, error) {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
+
+	template, err := s.templateStore.GetByID(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("templateID", id)).
+			Error("getting template", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errorx.ErrNotFound
+		}
+		return nil, errorx.ErrInternal
+	}
+
+	result := transformTemplateStoreModelToTemplate(template)
+	return &result, nil
+}
+
+// List returns a page of the templates which are not deleted.
+func (s *TemplateService) List(ctx context.Context, filter models.TemplateFilter) (*models.TemplateList, error) {
+	if err := filter.Validate(); err != nil {
+		s.log.With(slog.Any("filter", filter)).
+			Error("validating template filter", err)
+		return nil, errorx.ErrValidation
+	}
+
+	templates, total, err := s.templateStore.List(ctx, filter)
+	if err != nil {
+		s.log.With(slog.Any("filter", filter)).
+			Error("listing templates", err)
+		return nil, errorx.ErrInternal
+	}
+
+	result := &models.TemplateList{
+		Templates: make([]models.Template, 0, len(templates)),
+		Total:     total,
+	}
+	for i := range templates {
+		result.Templates = append(result.Templates, transformTemplateStoreModelToTemplate(&templates[i]))
+	}
+	return result, nil
+}
+
+// Versions returns the history of the template, the newest version first.
+func (s *TemplateService) Versions(ctx context.Context, id string) ([]models.TemplateVersion, error) {
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
+
+	template, err := s.templateStore.GetByID(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("templateID", id)).
+			Error("getting template", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errorx.ErrNotFound
+		}
+		return nil, errorx.ErrInternal
+	}
+
+	versions, err := s.templateStore.Versions(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("templateID", id)).
+			Error("finding template versions", err)
+		return nil, errorx.ErrInternal
+	}
+
+	result := make([]models.TemplateVersion, 0, len(versions))
+	for i := range versions {
+		result = append(result, transformTemplateVersionStoreModelToTemplateVersion(&versions[i], template.Version))
+	}
+	return result, nil
+}
+
+// Rollback makes an older version of the template current, the older wording is added as a new version.
+func (s *TemplateService) Rollback(ctx context.Context, rollback *models.TemplateRollback) (*models.Template, error) {
+	if err := rollback.Validate(); err != nil {
+		s.log.With(slog.Any("rollback", rollback)).
+			Error("validating template rollback", err)
+		return nil, errorx.ErrValidation
+	}
+	uuidValue, err := uuid.Parse(rollback.ID)
+	if err != nil {
+		s.log.Error("parsing uuid", rollback.ID, err)
+		return nil, errorx.ErrValidation
+	}
+
+	template, err := s.templateStore.Rollback(ctx, uuidValue, rollback.Version, rollback.Author)
+	if err != nil {
+		s.log.With(slog.Any("rollback", rollback)).
+			Error("rolling back template", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errorx.ErrNotFound
+		}
+		return nil, errorx.ErrInternal
+	}
+
+	result := transformTemplateStoreModelToTemplate(template)
+	return &result, nil
 }
 
 func (s *TemplateService) Delete(ctx context.Context, id string) error {
@@ -82,12 +205,22 @@ func (s *TemplateService) Update(ctx context.Context, template *models.TemplateU
 			Error("validating template", err)
 		return errorx.ErrValidation
 	}
+	if err := checkTemplate(template.Subject, template.Text, template.Variables, template.Variants, template.Translations, template.WhatsAppParameters); err != nil {
+		s.log.With(slog.Any("template", template)).
+			Error("checking template variables", err)
+		return errorx.ErrValidation
+	}
 	storeModel, err := s.transformTemplateUpdateToStoreModel(template)
 	if err != nil {
 		s.log.With(slog.Any("template", template)).
 			Error("transforming template to store model", err)
 		return errorx.ErrValidation
 	}
+	if err = checkSegments(storeModel); err != nil {
+		s.log.With(slog.Any("template", template)).
+			Error("checking sms segments", err)
+		return errorx.ErrValidation
+	}
 
 	if err = s.templateStore.Update(ctx, storeModel); err != nil {
 		s.log.With(slog.Any("templateID", storeModel.ID)).
@@ -102,11 +235,145 @@ func (s *TemplateService) Update(ctx context.Context, template *models.TemplateU
 	return nil
 }
 
+// Preview renders the template with sample variables as every channel sends it.
+// Missing, unknown and undeclared variables are reported in the result instead of failing the preview,
+// missing values are rendered empty.
+func (s *TemplateService) Preview(ctx context.Context, preview *models.TemplatePreview) (*models.TemplatePreviewResult, error) {
+	uuidValue, err := uuid.Parse(preview.ID)
+	if err != nil {
+		s.log.Error("parsing uuid", preview.ID, err)
+		return nil, errorx.ErrValidation
+	}
+
+	template, err := s.templateStore.GetByID(ctx, uuidValue)
+	if err != nil {
+		s.log.With(slog.Any("templateID", preview.ID)).
+			Error("getting template", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errorx.ErrNotFound
+		}
+		return nil, errorx.ErrInternal
+	}
+
+	result := &models.TemplatePreviewResult{
+		Channels: make([]models.ChannelPreview, 0),
+		Errors:   make([]models.TemplateVariableError, 0),
+	}
+
+	parsed, err := render.ParseChannels(template.Subject, template.Text, template.Variants.Render())
+	if err != nil {
+		result.Errors = append(result.Errors, models.TemplateVariableError{Message: err.Error()})
+		return result, nil
+	}
+	for _, name := range parsed.Undeclared(variableNames(template.Variables)) {
+		result.Errors = append(result.Errors, models.TemplateVariableError{Variable: name, Message: "is not declared by the template"})
+	}
+
+	params := make(map[string]string, len(template.Variables))
+	declared := make(map[string]bool, len(template.Variables))
+	for _, v := range template.Variables {
+		declared[v.Name] = true
+		value, ok := preview.Params[v.Name]
+		if !ok && v.Required {
+			result.Errors = append(result.Errors, models.TemplateVariableError{Variable: v.Name, Message: "is required"})
+		}
+		params[v.Name] = value
+	}
+	for _, name := range sortedKeys(preview.Params) {
+		if !declared[name] {
+			result.Errors = append(result.Errors, models.TemplateVariableError{Variable: name, Message: "is unknown"})
+		}
+	}
+
+	variables := render.Variables{
+		City:     preview.City,
+		Strength: preview.Strength,
+		Receiver: render.Receiver{
+			FirstName: preview.Receiver.FirstName,
+			LastName:  preview.Receiver.LastName,
+			City:      preview.Receiver.City,
+		},
+		Params: params,
+	}
+	rendered, err := parsed.Render(variables)
+	if err != nil {
+		result.Errors = append(result.Errors, models.TemplateVariableError{Message: err.Error()})
+		return result, nil
+	}
+
+	result.Channels = previewChannels(template, rendered, variables)
+	return result, nil
+}
+
+// previewChannels composes the rendered template as the providers send it to every channel.
+func previewChannels(template *models.TemplateEntity, rendered *render.Rendered, variables render.Variables) []models.ChannelPreview {
+	segments := render.CountSegments(rendered.SMS)
+	// a telegram message has no subject, the subject is the first paragraph of the text unless it is empty
+	telegram := models.ChannelPreview{
+		Type: models.ContactTypeTelegram,
+		Text: rendered.Text,
+	}
+	subject := rendered.Subject
+	if rendered.Markdown != "" {
+		telegram.Text = rendered.Markdown
+		telegram.Format = models.TextFormatMarkdown
+		subject = "*" + render.EscapeMarkdown(subject) + "*"
+	}
+	if rendered.Subject != "" {
+		telegram.Text = subject + "\n\n" + telegram.Text
+	}
+	channels := []models.ChannelPreview{
+		{
+			Type:    models.ContactTypeEmail,
+			Subject: rendered.Subject,
+			Text:    rendered.Email,
+			HTML:    rendered.EmailHTML,
+		},
+		{
+			Type: models.ContactTypeSMS,
+			Text: rendered.SMS,
+			SMS: &models.SMSSegments{
+				Encoding:   string(segments.Encoding),
+				Characters: segments.Characters,
+				Segments:   segments.Count,
+			},
+		},
+		telegram,
+	}
+	if whatsApp := whatsAppTemplate(template); whatsApp != nil {
+		whatsApp.Parameters = variables.Values(template.WhatsAppParameters)
+		channels = append(channels, models.ChannelPreview{
+			Type:     models.ContactTypeWhatsApp,
+			WhatsApp: whatsApp,
+		})
+	}
+	return channels
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func (s *TemplateService) transformTemplateCreateToStoreModel(t *models.TemplateCreate) (*models.TemplateEntity, error) {
 	storeModel := &models.TemplateEntity{
-		Subject:   t.Subject,
-		Text:      t.Text,
-		DeletedAt: nil,
+		Subject:            t.Subject,
+		Text:               t.Text,
+		Variables:          t.Variables,
+		Variants:           templateVariants(t.Variants),
+		Language:           language(t.Language),
+		Translations:       t.Translations.Canonical(),
+		MaxSegments:        t.MaxSegments,
+		SegmentPolicy:      segmentPolicy(t.MaxSegments, t.SegmentPolicy),
+		WhatsAppName:       t.WhatsAppName,
+		WhatsAppLanguage:   t.WhatsAppLanguage,
+		WhatsAppParameters: t.WhatsAppParameters,
+		UpdatedBy:          t.Author,
+		DeletedAt:          nil,
 	}
 	return storeModel, nil
 }
@@ -117,9 +384,146 @@ func (s *TemplateService) transformTemplateUpdateToStoreModel(t *models.Template
 		return nil, fmt.Errorf("updating template: couldn't parse id: %s to UUID. Error: %w", t.ID, err)
 	}
 	storeModel := &models.TemplateEntity{
-		ID:      uuidValue,
-		Subject: t.Subject,
-		Text:    t.Text,
+		ID:                 uuidValue,
+		Subject:            t.Subject,
+		Text:               t.Text,
+		Variables:          t.Variables,
+		Variants:           templateVariants(t.Variants),
+		Language:           language(t.Language),
+		Translations:       t.Translations.Canonical(),
+		MaxSegments:        t.MaxSegments,
+		SegmentPolicy:      segmentPolicy(t.MaxSegments, t.SegmentPolicy),
+		WhatsAppName:       t.WhatsAppName,
+		WhatsAppLanguage:   t.WhatsAppLanguage,
+		WhatsAppParameters: t.WhatsAppParameters,
+		UpdatedBy:          t.Author,
 	}
 	return storeModel, nil
 }
+
+// checkTemplate checks the variables referenced by the template, its channel variants, its translations
+// and the parameters of its WhatsApp template.
+func checkTemplate(subject, text string, variables []models.TemplateVariable, variants *models.TemplateVariants, translations models.Translations, whatsAppParameters []string) error {
+	declared := variableNames(variables)
+	if err := render.CheckFields(whatsAppParameters, declared); err != nil {
+		return fmt.Errorf("whatsapp parameters: %w", err)
+	}
+	if err := render.CheckChannels(subject, text, variants.Render(), declared); err != nil {
+		return err
+	}
+	for tag, t := range translations {
+		if err := render.CheckChannels(t.Subject, t.Text, t.Variants.Render(), declared); err != nil {
+			return fmt.Errorf("%s translation: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// checkSegments checks that the SMS of the template and of its translations rendered with empty variables
+// fit the maximum number of segments when the policy rejects longer ones.
+func checkSegments(t *models.TemplateEntity) error {
+	if t.MaxSegments == 0 || t.SegmentPolicy != models.SegmentPolicyReject {
+		return nil
+	}
+	params := make(map[string]string, len(t.Variables))
+	for _, v := range t.Variables {
+		params[v.Name] = ""
+	}
+
+	bodies := map[string]models.TemplateTranslation{
+		t.Language: {Subject: t.Subject, Text: t.Text, Variants: t.Variants},
+	}
+	for language, translation := range t.Translations {
+		bodies[language] = translation
+	}
+	for language, body := range bodies {
+		parsed, err := render.ParseChannels(body.Subject, body.Text, body.Variants.Render())
+		if err != nil {
+			return err
+		}
+		rendered, err := parsed.Render(render.Variables{Params: params})
+		if err != nil {
+			return err
+		}
+		if segments := render.CountSegments(rendered.SMS); segments.Count > t.MaxSegments {
+			return fmt.Errorf("sms %s takes at least %d %s segments, max is %d", language, segments.Count, segments.Encoding, t.MaxSegments)
+		}
+	}
+	return nil
+}
+
+// segmentPolicy returns the policy of a template limiting its SMS segments, truncate by default,
+// so a long SMS is still delivered.
+func segmentPolicy(maxSegments int, policy models.SegmentPolicy) models.SegmentPolicy {
+	if maxSegments == 0 {
+		return ""
+	}
+	if policy == "" {
+		return models.SegmentPolicyTruncate
+	}
+	return policy
+}
+
+// language returns the canonical form of a validated language tag.
+func language(tag string) string {
+	canonical, _ := models.ParseLanguage(tag)
+	return canonical
+}
+
+// templateVariants returns the variants to be stored, nil when no variant is set.
+func templateVariants(variants *models.TemplateVariants) *models.TemplateVariants {
+	if variants.IsEmpty() {
+		return nil
+	}
+	return variants
+}
+
+func variableNames(variables []models.TemplateVariable) []string {
+	names := make([]string, 0, len(variables))
+	for _, v := range variables {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func transformTemplateStoreModelToTemplate(t *models.TemplateEntity) models.Template {
+	return models.Template{
+		ID:                 t.ID,
+		Subject:            t.Subject,
+		Text:               t.Text,
+		Variables:          t.Variables,
+		Variants:           t.Variants,
+		Language:           t.Language,
+		Translations:       t.Translations,
+		MaxSegments:        t.MaxSegments,
+		SegmentPolicy:      t.SegmentPolicy,
+		WhatsAppName:       t.WhatsAppName,
+		WhatsAppLanguage:   t.WhatsAppLanguage,
+		WhatsAppParameters: t.WhatsAppParameters,
+		Version:            t.Version,
+		UpdatedBy:          t.UpdatedBy,
+		CreatedAt:          t.CreatedAt,
+		UpdatedAt:          t.UpdatedAt,
+	}
+}
+
+func transformTemplateVersionStoreModelToTemplateVersion(v *models.TemplateVersionEntity, current int) models.TemplateVersion {
+	return models.TemplateVersion{
+		Version:            v.Version,
+		Subject:            v.Subject,
+		Text:               v.Text,
+		Variables:          v.Variables,
+		Variants:           v.Variants,
+		Language:           v.Language,
+		Translations:       v.Translations,
+		MaxSegments:        v.MaxSegments,
+		SegmentPolicy:      v.SegmentPolicy,
+		WhatsAppName:       v.WhatsAppName,
+		WhatsAppLanguage:   v.WhatsAppLanguage,
+		WhatsAppParameters: v.WhatsAppParameters,
+		Author:             v.Author,
+		RestoredFrom:       v.RestoredFrom,
+		Current:            v.Version == current,
+		CreatedAt:          v.CreatedAt,
+	}
+}
@@ -2,14 +2,18 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"log/slog"
 	"os"
+	"projects/emergency-messages/internal/errorx"
 	"projects/emergency-messages/internal/models"
 	"projects/emergency-messages/internal/services/mocks"
+	"strings"
 	"testing"
 )
 
@@ -38,15 +42,17 @@ func TestTemplate_Create(t *testing.T) {
 			Create(ctx, templateStore).
 			Return(nil)
 
-		err := service.Create(ctx, template)
+		created, err := service.Create(ctx, template)
 		assert.NoError(t, err)
+		assert.Equal(t, "1", created.Subject)
+		assert.Equal(t, "2", created.Text)
 	})
 	t.Run("when subject is empty then error", func(t *testing.T) {
 		template := &models.TemplateCreate{
 			Text: "2",
 		}
 
-		err := service.Create(ctx, template)
+		_, err := service.Create(ctx, template)
 		assert.Error(t, err)
 	})
 	t.Run("when text is empty then error", func(t *testing.T) {
@@ -54,9 +60,142 @@ func TestTemplate_Create(t *testing.T) {
 			Subject: "2",
 		}
 
-		err := service.Create(ctx, template)
+		_, err := service.Create(ctx, template)
 		assert.Error(t, err)
 	})
+	t.Run("when text references an undeclared variable then error", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:   "Flood in {{.City}}",
+			Text:      "Water level {{.Level}} m, {{.Receiver.FirstName}}",
+			Variables: []models.TemplateVariable{{Name: "Levle"}},
+		}
+
+		_, err := service.Create(ctx, template)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when sms variant is too long then error", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:  "Flood",
+			Text:     "Flood in {{.City}}",
+			Variants: &models.TemplateVariants{SMS: strings.Repeat("a", models.MaxSMSVariantLength+1)},
+		}
+
+		_, err := service.Create(ctx, template)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when variant references an undeclared variable then error", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:  "Flood",
+			Text:     "Flood in {{.City}}",
+			Variants: &models.TemplateVariants{EmailHTML: "<p>{{.Level}}</p>"},
+		}
+
+		_, err := service.Create(ctx, template)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when whatsapp parameter is not a variable then error", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:            "Flood",
+			Text:               "Flood in {{.City}}",
+			WhatsAppName:       "flood",
+			WhatsAppLanguage:   "ru",
+			WhatsAppParameters: []string{"City", "Level"},
+		}
+
+		_, err := service.Create(ctx, template)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when sms text alone exceeds max segments then error", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:       "Наводнение",
+			Text:          strings.Repeat("Наводнение в {{.City}}. ", 5),
+			MaxSegments:   1,
+			SegmentPolicy: models.SegmentPolicyReject,
+		}
+
+		_, err := service.Create(ctx, template)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when segment policy is not set then sms is truncated", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:     "Наводнение",
+			Text:        strings.Repeat("Наводнение. ", 10),
+			MaxSegments: 1,
+		}
+		templateStore := &models.TemplateEntity{
+			Subject:       template.Subject,
+			Text:          template.Text,
+			MaxSegments:   1,
+			SegmentPolicy: models.SegmentPolicyTruncate,
+		}
+
+		store.
+			EXPECT().
+			Create(ctx, templateStore).
+			Return(nil)
+
+		_, err := service.Create(ctx, template)
+		assert.NoError(t, err)
+	})
+	t.Run("when segment policy is unknown then error", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:       "Flood",
+			Text:          "Flood",
+			MaxSegments:   1,
+			SegmentPolicy: "compress",
+		}
+
+		_, err := service.Create(ctx, template)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when template has translations then languages are canonical", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:  "Наводнение",
+			Text:     "Наводнение в {{.City}}",
+			Language: "RU",
+			Translations: models.Translations{
+				"tt-ru": {Subject: "Су басу", Text: "{{.City}} шәһәрендә су басу"},
+			},
+		}
+		templateStore := &models.TemplateEntity{
+			Subject:  template.Subject,
+			Text:     template.Text,
+			Language: "ru",
+			Translations: models.Translations{
+				"tt-RU": {Subject: "Су басу", Text: "{{.City}} шәһәрендә су басу"},
+			},
+		}
+
+		store.
+			EXPECT().
+			Create(ctx, templateStore).
+			Return(nil)
+
+		created, err := service.Create(ctx, template)
+		assert.NoError(t, err)
+		assert.Equal(t, "ru", created.Language)
+		assert.Contains(t, created.Translations, "tt-RU")
+	})
+	t.Run("when translation references an undeclared variable then error", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:      "Flood",
+			Text:         "Flood in {{.City}}",
+			Translations: models.Translations{"tt": {Subject: "Су басу", Text: "{{.Level}}"}},
+		}
+
+		_, err := service.Create(ctx, template)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when translation language is invalid then error", func(t *testing.T) {
+		template := &models.TemplateCreate{
+			Subject:      "Flood",
+			Text:         "Flood in {{.City}}",
+			Translations: models.Translations{"not a language": {Subject: "Flood", Text: "Flood"}},
+		}
+
+		_, err := service.Create(ctx, template)
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
 	t.Run("when error in update then error", func(t *testing.T) {
 		template := &models.TemplateCreate{
 			Text:    "2",
@@ -73,11 +212,173 @@ func TestTemplate_Create(t *testing.T) {
 			Return(errors.New("")).
 			AnyTimes()
 
-		err := service.Create(ctx, template)
+		_, err := service.Create(ctx, template)
 		assert.Error(t, err)
 	})
 }
 
+func TestTemplate_GetByID(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	store := mock_services.NewMockTemplateStore(controller)
+	service := NewTemplate(store, log)
+
+	uidStr := "9dfc0a1d-7582-40eb-bc50-53a973bd1dbf"
+	uid := uuid.MustParse(uidStr)
+
+	t.Run("when template exists then template", func(t *testing.T) {
+		store.
+			EXPECT().
+			GetByID(ctx, uid).
+			Return(&models.TemplateEntity{ID: uid, Subject: "Flood", Text: "{{.City}}"}, nil)
+
+		template, err := service.GetByID(ctx, uidStr)
+		assert.NoError(t, err)
+		assert.Equal(t, uid, template.ID)
+		assert.Equal(t, "Flood", template.Subject)
+	})
+	t.Run("when template is not found then not found error", func(t *testing.T) {
+		store.
+			EXPECT().
+			GetByID(ctx, uid).
+			Return(nil, sql.ErrNoRows)
+
+		_, err := service.GetByID(ctx, uidStr)
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+	t.Run("when id is not uuid then validation error", func(t *testing.T) {
+		_, err := service.GetByID(ctx, "1")
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+}
+
+func TestTemplate_List(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	store := mock_services.NewMockTemplateStore(controller)
+	service := NewTemplate(store, log)
+
+	t.Run("when limit is not set then default limit", func(t *testing.T) {
+		filter := models.TemplateFilter{Subject: "flood", Limit: models.DefaultTemplateLimit}
+		store.
+			EXPECT().
+			List(ctx, filter).
+			Return([]models.TemplateEntity{{Subject: "Flood"}, {Subject: "Flood warning"}}, 12, nil)
+
+		list, err := service.List(ctx, models.TemplateFilter{Subject: "flood"})
+		assert.NoError(t, err)
+		assert.Equal(t, 12, list.Total)
+		assert.Len(t, list.Templates, 2)
+		assert.Equal(t, "Flood warning", list.Templates[1].Subject)
+	})
+	t.Run("when limit is too large then validation error", func(t *testing.T) {
+		_, err := service.List(ctx, models.TemplateFilter{Limit: models.MaxTemplateLimit + 1})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when offset is negative then validation error", func(t *testing.T) {
+		_, err := service.List(ctx, models.TemplateFilter{Offset: -1})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when stores returns error then internal error", func(t *testing.T) {
+		store.
+			EXPECT().
+			List(ctx, gomock.Any()).
+			Return(nil, 0, errors.New(""))
+
+		_, err := service.List(ctx, models.TemplateFilter{})
+		assert.ErrorIs(t, err, errorx.ErrInternal)
+	})
+}
+
+func TestTemplate_Versions(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	store := mock_services.NewMockTemplateStore(controller)
+	service := NewTemplate(store, log)
+
+	uidStr := "9dfc0a1d-7582-40eb-bc50-53a973bd1dbf"
+	uid := uuid.MustParse(uidStr)
+
+	t.Run("when template has versions then current version is marked", func(t *testing.T) {
+		store.
+			EXPECT().
+			GetByID(ctx, uid).
+			Return(&models.TemplateEntity{ID: uid, Version: 3}, nil)
+		store.
+			EXPECT().
+			Versions(ctx, uid).
+			Return([]models.TemplateVersionEntity{
+				{TemplateID: uid, Version: 3, Subject: "Flood", Author: "operator", RestoredFrom: 1},
+				{TemplateID: uid, Version: 2, Subject: "Flood warning"},
+				{TemplateID: uid, Version: 1, Subject: "Flood"},
+			}, nil)
+
+		versions, err := service.Versions(ctx, uidStr)
+		assert.NoError(t, err)
+		assert.Len(t, versions, 3)
+		assert.True(t, versions[0].Current)
+		assert.Equal(t, "operator", versions[0].Author)
+		assert.Equal(t, 1, versions[0].RestoredFrom)
+		assert.False(t, versions[1].Current)
+	})
+	t.Run("when template is not found then not found error", func(t *testing.T) {
+		store.
+			EXPECT().
+			GetByID(ctx, uid).
+			Return(nil, sql.ErrNoRows)
+
+		_, err := service.Versions(ctx, uidStr)
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+}
+
+func TestTemplate_Rollback(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	ctx := context.Background()
+	store := mock_services.NewMockTemplateStore(controller)
+	service := NewTemplate(store, log)
+
+	uidStr := "9dfc0a1d-7582-40eb-bc50-53a973bd1dbf"
+	uid := uuid.MustParse(uidStr)
+
+	t.Run("when version exists then it becomes current", func(t *testing.T) {
+		store.
+			EXPECT().
+			Rollback(ctx, uid, 1, "operator").
+			Return(&models.TemplateEntity{ID: uid, Subject: "Flood", Version: 4, UpdatedBy: "operator"}, nil)
+
+		template, err := service.Rollback(ctx, &models.TemplateRollback{ID: uidStr, Version: 1, Author: "operator"})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, template.Version)
+		assert.Equal(t, "operator", template.UpdatedBy)
+	})
+	t.Run("when version is not found then not found error", func(t *testing.T) {
+		store.
+			EXPECT().
+			Rollback(ctx, uid, 7, "").
+			Return(nil, fmt.Errorf("rolling back template: %w", sql.ErrNoRows))
+
+		_, err := service.Rollback(ctx, &models.TemplateRollback{ID: uidStr, Version: 7})
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+	t.Run("when version is not positive then validation error", func(t *testing.T) {
+		_, err := service.Rollback(ctx, &models.TemplateRollback{ID: uidStr})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+}
+
 func TestNewTemplate(t *testing.T) {
 	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	controller := gomock.NewController(t)
@@ -200,3 +501,122 @@ func TestTemplate_Update(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestTemplate_Preview(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	store := mock_services.NewMockTemplateStore(controller)
+	ctx := context.Background()
+	service := NewTemplate(store, log)
+	id := uuid.New()
+
+	t.Run("when sample is complete then every channel is rendered", func(t *testing.T) {
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.TemplateEntity{
+				ID:                 id,
+				Subject:            "Flood in {{.City}}",
+				Text:               "{{.Receiver.FirstName}}, level {{.Level}} m",
+				Variables:          []models.TemplateVariable{{Name: "Level", Required: true}},
+				WhatsAppName:       "flood",
+				WhatsAppLanguage:   "ru",
+				WhatsAppParameters: []string{"City", "Receiver.FirstName"},
+			}, nil)
+
+		result, err := service.Preview(ctx, &models.TemplatePreview{
+			ID:       id.String(),
+			City:     "Kazan",
+			Strength: "high",
+			Receiver: models.Receiver{FirstName: "Ivan"},
+			Params:   map[string]string{"Level": "3"},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, []models.ChannelPreview{
+			{Type: models.ContactTypeEmail, Subject: "Flood in Kazan", Text: "Ivan, level 3 m"},
+			{Type: models.ContactTypeSMS, Text: "Ivan, level 3 m", SMS: &models.SMSSegments{Encoding: "GSM-7", Characters: 15, Segments: 1}},
+			{Type: models.ContactTypeTelegram, Text: "Flood in Kazan\n\nIvan, level 3 m"},
+			{Type: models.ContactTypeWhatsApp, WhatsApp: &models.WhatsAppTemplate{Name: "flood", Language: "ru", Parameters: []string{"Kazan", "Ivan"}}},
+		}, result.Channels)
+	})
+	t.Run("when template has variants then channels render them", func(t *testing.T) {
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.TemplateEntity{
+				ID:      id,
+				Subject: "Flood in {{.City}}",
+				Text:    "{{.Receiver.FirstName}}, the water level in {{.City}} is rising",
+				Variants: &models.TemplateVariants{
+					SMS:       "Flood {{.City}}",
+					EmailHTML: "<p>{{.Receiver.FirstName}}, the water level is rising</p>",
+					Markdown:  "*{{.Strength}}* flood",
+				},
+			}, nil)
+
+		result, err := service.Preview(ctx, &models.TemplatePreview{
+			ID:       id.String(),
+			City:     "Kazan",
+			Strength: "high.",
+			Receiver: models.Receiver{FirstName: "Ivan"},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, []models.ChannelPreview{
+			{Type: models.ContactTypeEmail, Subject: "Flood in Kazan", Text: "Ivan, the water level in Kazan is rising", HTML: "<p>Ivan, the water level is rising</p>"},
+			{Type: models.ContactTypeSMS, Text: "Flood Kazan", SMS: &models.SMSSegments{Encoding: "GSM-7", Characters: 11, Segments: 1}},
+			{Type: models.ContactTypeTelegram, Text: "*Flood in Kazan*\n\n*high\\.* flood", Format: models.TextFormatMarkdown},
+		}, result.Channels)
+	})
+	t.Run("when subject renders empty then telegram text has no subject", func(t *testing.T) {
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.TemplateEntity{
+				ID:       id,
+				Subject:  "{{.Strength}}",
+				Text:     "Flood in {{.City}}",
+				Variants: &models.TemplateVariants{Markdown: "*Flood* in {{.City}}"},
+			}, nil)
+
+		result, err := service.Preview(ctx, &models.TemplatePreview{ID: id.String(), City: "Kazan"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ChannelPreview{Type: models.ContactTypeTelegram, Text: "*Flood* in Kazan", Format: models.TextFormatMarkdown}, result.Channels[2])
+	})
+	t.Run("when variables are missing or unknown then errors are reported", func(t *testing.T) {
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(&models.TemplateEntity{
+				ID:        id,
+				Subject:   "Flood",
+				Text:      "level {{.Level}} m {{.Depth}}",
+				Variables: []models.TemplateVariable{{Name: "Level", Required: true}},
+			}, nil)
+
+		result, err := service.Preview(ctx, &models.TemplatePreview{
+			ID:     id.String(),
+			Params: map[string]string{"Levle": "3"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.TemplateVariableError{
+			{Variable: "Depth", Message: "is not declared by the template"},
+			{Variable: "Level", Message: "is required"},
+			{Variable: "Levle", Message: "is unknown"},
+		}, result.Errors[:3])
+		assert.Empty(t, result.Channels)
+	})
+	t.Run("when template is not found then error", func(t *testing.T) {
+		store.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(nil, sql.ErrNoRows)
+
+		result, err := service.Preview(ctx, &models.TemplatePreview{ID: id.String()})
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultVerificationCodeLength  = 6
+	defaultVerificationTTL         = 10 * time.Minute
+	defaultVerificationMaxAttempts = 5
+	defaultVerificationCooldown    = time.Minute
+	defaultVerificationMaxPerHour  = 5
+	defaultVerificationMaxPerDay   = 10
+)
+
+// VerificationPolicy describes the codes sent to verify contacts.
+// A code is sent to a contact once per Cooldown, at most MaxPerHour times an hour and MaxPerDay times a day,
+// counting the codes of every receiver with the contact.
+// A WhatsApp code is sent with the pre-approved WhatsAppTemplate, the code is its only parameter.
+type VerificationPolicy struct {
+	CodeLength       int
+	TTL              time.Duration
+	MaxAttempts      int
+	Cooldown         time.Duration
+	MaxPerHour       int
+	MaxPerDay        int
+	WhatsAppTemplate string
+	WhatsAppLanguage string
+}
+
+// DefaultVerificationPolicy returns the policy used when nothing is configured.
+func DefaultVerificationPolicy() VerificationPolicy {
+	return VerificationPolicy{
+		CodeLength:  defaultVerificationCodeLength,
+		TTL:         defaultVerificationTTL,
+		MaxAttempts: defaultVerificationMaxAttempts,
+		Cooldown:    defaultVerificationCooldown,
+		MaxPerHour:  defaultVerificationMaxPerHour,
+		MaxPerDay:   defaultVerificationMaxPerDay,
+	}
+}
+
+// checkSent checks that one more code may be sent to a contact, sent are the times the codes of the last day were sent.
+func (p VerificationPolicy) checkSent(sent []time.Time, now time.Time) error {
+	lastHour := 0
+	for _, t := range sent {
+		if now.Sub(t) < p.Cooldown {
+			return fmt.Errorf("a code was sent less than %s ago: %w", p.Cooldown, errorx.ErrValidation)
+		}
+		if now.Sub(t) < time.Hour {
+			lastHour++
+		}
+	}
+	if lastHour >= p.MaxPerHour {
+		return fmt.Errorf("%d codes were sent in the last hour: %w", lastHour, errorx.ErrValidation)
+	}
+	if len(sent) >= p.MaxPerDay {
+		return fmt.Errorf("%d codes were sent in the last day: %w", len(sent), errorx.ErrValidation)
+	}
+	return nil
+}
+
+// verificationText is the message with the code in a language, the code and the minutes it is valid are its arguments
+type verificationText struct {
+	subject string
+	text    string
+}
+
+// verificationTexts are the messages with the code by language, a receiver of another language gets the first one
+var verificationTexts = []struct {
+	language string
+	verificationText
+}{
+	{language: "en", verificationText: verificationText{
+		subject: "Verification code",
+		text:    "Your emergency alerts verification code is %s. It expires in %d minutes.",
+	}},
+	{language: "ru", verificationText: verificationText{
+		subject: "Код подтверждения",
+		text:    "Код подтверждения для экстренных оповещений: %s. Код действует %d мин.",
+	}},
+}
+
+// ContactVerificationService sends one-time codes to the contacts of receivers and confirms them,
+// a confirmed contact is marked as verified.
+type ContactVerificationService struct {
+	verificationStore ContactVerificationStore
+	receiverStore     ReceiverStore
+	sender            CodeSender
+	policy            VerificationPolicy
+	// phoneCountry is the country of the phone numbers written without a country code
+	phoneCountry string
+	log          *slog.Logger
+}
+
+type ContactVerificationStore interface {
+	Create(ctx context.Context, v *models.ContactVerificationEntity, since time.Time, check func(sent []time.Time) error) error
+	Attempt(ctx context.Context, receiverID uuid.UUID, key models.ContactKey, now time.Time) (*models.ContactVerificationEntity, error)
+	Confirm(ctx context.Context, id uuid.UUID, now time.Time) error
+}
+
+type CodeSender interface {
+	Send(message models.MessageSend) (string, error)
+}
+
+func NewContactVerification(verificationStore ContactVerificationStore, receiverStore ReceiverStore, sender CodeSender, policy VerificationPolicy, phoneCountry string, log *slog.Logger) *ContactVerificationService {
+	return &ContactVerificationService{
+		verificationStore: verificationStore,
+		receiverStore:     receiverStore,
+		sender:            sender,
+		policy:            policy,
+		phoneCountry:      phoneCountry,
+		log:               log,
+	}
+}
+
+// Verify sends a one-time code to the active contact of the receiver, the codes sent before expire.
+// A code is not sent when the policy limits the codes of the contact.
+// It returns the contact with the time the code expires.
+func (s *ContactVerificationService) Verify(ctx context.Context, id string, key models.ContactKey) (*models.ContactVerification, error) {
+	if err := key.Validate(); err != nil {
+		s.log.With(slog.Any("contact", key)).
+			Error("validating contact", err)
+		return nil, errorx.ErrValidation
+	}
+	receiverID, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
+
+	receiver, err := s.receiverStore.GetByID(ctx, receiverID)
+	if err != nil {
+		s.log.With(slog.Any("receiverID", id)).
+			Error("getting receiver", err)
+		return nil, receiverStoreError(err)
+	}
+	i := findNormalizedContact(receiver.Contacts, key, s.phoneCountry)
+	if i < 0 {
+		return nil, fmt.Errorf("%s contact %s: %w", key.Type, key.Value, errorx.ErrNotFound)
+	}
+	contact := receiver.Contacts[i]
+	if !contact.IsActive {
+		return nil, fmt.Errorf("%s contact %s is inactive: %w", contact.Type, contact.Value, errorx.ErrValidation)
+	}
+
+	code, err := verificationCode(s.policy.CodeLength)
+	if err != nil {
+		s.log.Error("generating verification code", err)
+		return nil, errorx.ErrInternal
+	}
+	verification := &models.ContactVerificationEntity{
+		ID:          uuid.New(),
+		ReceiverID:  receiverID,
+		Type:        contact.Type,
+		Value:       contact.Value,
+		MaxAttempts: s.policy.MaxAttempts,
+		ExpiresAt:   time.Now().Add(s.policy.TTL),
+	}
+	verification.CodeHash = verificationCodeHash(verification.ID, code)
+	var limitErr error
+	now := time.Now()
+	err = s.verificationStore.Create(ctx, verification, now.Add(-24*time.Hour), func(sent []time.Time) error {
+		limitErr = s.policy.checkSent(sent, now)
+		return limitErr
+	})
+	if limitErr != nil {
+		return nil, fmt.Errorf("%s contact %s: %w", contact.Type, contact.Value, limitErr)
+	}
+	if err != nil {
+		s.log.With(slog.Any("receiverID", id), slog.Any("contact", contact.Key())).
+			Error("creating contact verification", err)
+		return nil, errorx.ErrInternal
+	}
+
+	if _, err = s.sender.Send(s.message(verification, receiver.Language, code)); err != nil {
+		s.log.With(slog.Any("receiverID", id), slog.Any("contact", contact.Key())).
+			Error("sending verification code", err)
+		return nil, errorx.ErrInternal
+	}
+
+	return &models.ContactVerification{
+		ContactKey:  contact.Key(),
+		ExpiresAt:   verification.ExpiresAt,
+		MaxAttempts: verification.MaxAttempts,
+	}, nil
+}
+
+// Confirm checks the code against the last code sent to the contact of the receiver and marks the contact as verified.
+// A code is accepted once, before it expires and within its attempts, every check counts as an attempt.
+func (s *ContactVerificationService) Confirm(ctx context.Context, id string, confirm models.ContactConfirm) (*models.Receiver, error) {
+	if err := confirm.Validate(); err != nil {
+		s.log.With(slog.Any("contact", confirm.ContactKey)).
+			Error("validating contact confirmation", err)
+		return nil, errorx.ErrValidation
+	}
+	receiverID, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error("parsing uuid", id, err)
+		return nil, errorx.ErrValidation
+	}
+
+	key := confirm.ContactKey
+	contact := models.Contact{Type: key.Type, Value: key.Value}
+	if err = contact.Normalize(s.phoneCountry); err == nil {
+		key = contact.Key()
+	}
+
+	now := time.Now()
+	verification, err := s.verificationStore.Attempt(ctx, receiverID, key, now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s contact %s has no code to confirm: %w", key.Type, key.Value, errorx.ErrValidation)
+		}
+		s.log.With(slog.Any("receiverID", id), slog.Any("contact", key)).
+			Error("attempting contact verification", err)
+		return nil, errorx.ErrInternal
+	}
+	hash := verificationCodeHash(verification.ID, confirm.Code)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(verification.CodeHash)) != 1 {
+		return nil, fmt.Errorf("wrong code, %d attempts left: %w", verification.MaxAttempts-verification.Attempts, errorx.ErrValidation)
+	}
+
+	if err = s.verificationStore.Confirm(ctx, verification.ID, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s contact %s has no code to confirm: %w", key.Type, key.Value, errorx.ErrValidation)
+		}
+		s.log.With(slog.Any("verificationID", verification.ID)).
+			Error("confirming contact verification", err)
+		return nil, errorx.ErrInternal
+	}
+
+	receiver, err := s.receiverStore.UpdateContacts(ctx, receiverID, func(contacts []models.Contact) ([]models.Contact, error) {
+		i := findContact(contacts, key)
+		if i < 0 {
+			return nil, fmt.Errorf("%s contact %s: %w", key.Type, key.Value, errorx.ErrNotFound)
+		}
+		contacts[i].VerifiedAt = &now
+		return contacts, nil
+	})
+	if err != nil {
+		s.log.With(slog.Any("receiverID", id)).
+			Error("verifying receiver contact", err)
+		return nil, receiverStoreError(err)
+	}
+
+	result := transformReceiverStoreModelToReceiver(receiver)
+	return &result, nil
+}
+
+// message returns the message with the code in the language of the receiver.
+func (s *ContactVerificationService) message(v *models.ContactVerificationEntity, language, code string) models.MessageSend {
+	text := verificationTexts[0].verificationText
+	for _, t := range verificationTexts {
+		if matchLanguage(language, t.language) {
+			text = t.verificationText
+			break
+		}
+	}
+
+	message := models.MessageSend{
+		ID:         v.ID,
+		Subject:    text.subject,
+		Text:       fmt.Sprintf(text.text, code, int(s.policy.TTL.Minutes())),
+		Format:     models.TextFormatPlain,
+		Status:     models.Created,
+		ReceiverID: v.ReceiverID,
+		Type:       v.Type,
+		Value:      v.Value,
+		Untracked:  true,
+	}
+	if v.Type == models.ContactTypeWhatsApp && s.policy.WhatsAppTemplate != "" {
+		message.WhatsApp = &models.WhatsAppTemplate{
+			Name:       s.policy.WhatsAppTemplate,
+			Language:   s.policy.WhatsAppLanguage,
+			Parameters: []string{code},
+		}
+	}
+	return message
+}
+
+// verificationCode returns a random code of the digits.
+func verificationCode(digits int) (string, error) {
+	n, err := rand.Int(rand.Reader, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// verificationCodeHash returns the hash of the code salted with the ID of the verification,
+// the code itself is not stored.
+func verificationCodeHash(id uuid.UUID, code string) string {
+	sum := sha256.Sum256([]byte(id.String() + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
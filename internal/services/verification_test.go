@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/services/mocks"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestContactVerificationService_Verify(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	verificationStore := mock_services.NewMockContactVerificationStore(ctrl)
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	sender := mock_services.NewMockCodeSender(ctrl)
+	verificationService := NewContactVerification(verificationStore, receiverStore, sender, DefaultVerificationPolicy(), "RU", log)
+
+	id := uuid.New()
+	receiver := &models.ReceiverEntity{
+		ID:       id,
+		Language: "ru",
+		Contacts: []models.Contact{
+			{Type: models.ContactTypeSMS, Value: "+79001234567", IsActive: true},
+			{Type: models.ContactTypeEmail, Value: "ivan@example.com"},
+		},
+	}
+
+	t.Run("when contact is written as national number then code is sent to it", func(t *testing.T) {
+		var created *models.ContactVerificationEntity
+		receiverStore.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(receiver, nil)
+		verificationStore.
+			EXPECT().
+			Create(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *models.ContactVerificationEntity, _ time.Time, check func([]time.Time) error) error {
+				created = v
+				return check(nil)
+			})
+		sender.
+			EXPECT().
+			Send(gomock.Any()).
+			DoAndReturn(func(m models.MessageSend) (string, error) {
+				assert.Equal(t, models.ContactTypeSMS, m.Type)
+				assert.Equal(t, "+79001234567", m.Value)
+				assert.Equal(t, created.ID, m.ID)
+				assert.True(t, strings.HasPrefix(m.Text, "Код подтверждения"))
+				assert.True(t, m.Untracked)
+
+				code := regexp.MustCompile(`\d{6}`).FindString(m.Text)
+				assert.Equal(t, created.CodeHash, verificationCodeHash(created.ID, code))
+				return "SM1", nil
+			})
+
+		verification, err := verificationService.Verify(ctx, id.String(), models.ContactKey{Type: models.ContactTypeSMS, Value: "8 900 123-45-67"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ContactKey{Type: models.ContactTypeSMS, Value: "+79001234567"}, verification.ContactKey)
+		assert.Equal(t, defaultVerificationMaxAttempts, created.MaxAttempts)
+		assert.WithinDuration(t, time.Now().Add(defaultVerificationTTL), verification.ExpiresAt, time.Minute)
+	})
+	t.Run("when codes of contact are limited then code is not sent", func(t *testing.T) {
+		now := time.Now()
+		tests := []struct {
+			name string
+			sent []time.Time
+			want string
+		}{
+			{name: "cooldown", sent: []time.Time{now.Add(-30 * time.Second)}, want: "a code was sent less than 1m0s ago"},
+			{name: "hour", sent: []time.Time{now.Add(-2 * time.Minute), now.Add(-10 * time.Minute), now.Add(-20 * time.Minute), now.Add(-30 * time.Minute), now.Add(-40 * time.Minute)}, want: "5 codes were sent in the last hour"},
+			{name: "day", sent: []time.Time{now.Add(-2 * time.Hour), now.Add(-3 * time.Hour), now.Add(-4 * time.Hour), now.Add(-5 * time.Hour), now.Add(-6 * time.Hour),
+				now.Add(-7 * time.Hour), now.Add(-8 * time.Hour), now.Add(-9 * time.Hour), now.Add(-10 * time.Hour), now.Add(-11 * time.Hour)}, want: "10 codes were sent in the last day"},
+		}
+		for _, tt := range tests {
+			receiverStore.
+				EXPECT().
+				GetByID(ctx, id).
+				Return(receiver, nil)
+			verificationStore.
+				EXPECT().
+				Create(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ *models.ContactVerificationEntity, since time.Time, check func([]time.Time) error) error {
+					assert.WithinDuration(t, now.Add(-24*time.Hour), since, time.Minute)
+					return check(tt.sent)
+				})
+
+			_, err := verificationService.Verify(ctx, id.String(), models.ContactKey{Type: models.ContactTypeSMS, Value: "+79001234567"})
+			assert.ErrorIs(t, err, errorx.ErrValidation, tt.name)
+			assert.ErrorContains(t, err, tt.want, tt.name)
+		}
+	})
+	t.Run("when contact is inactive then error", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(receiver, nil)
+
+		_, err := verificationService.Verify(ctx, id.String(), models.ContactKey{Type: models.ContactTypeEmail, Value: "ivan@example.com"})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when contact is not found then error", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(receiver, nil)
+
+		_, err := verificationService.Verify(ctx, id.String(), models.ContactKey{Type: models.ContactTypeTelegram, Value: "12345"})
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+	t.Run("when receiver is not found then error", func(t *testing.T) {
+		receiverStore.
+			EXPECT().
+			GetByID(ctx, id).
+			Return(nil, sql.ErrNoRows)
+
+		_, err := verificationService.Verify(ctx, id.String(), models.ContactKey{Type: models.ContactTypeSMS, Value: "+79001234567"})
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+}
+
+func TestContactVerificationService_Confirm(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	verificationStore := mock_services.NewMockContactVerificationStore(ctrl)
+	receiverStore := mock_services.NewMockReceiverStore(ctrl)
+	verificationService := NewContactVerification(verificationStore, receiverStore, nil, DefaultVerificationPolicy(), "RU", log)
+
+	id := uuid.New()
+	key := models.ContactKey{Type: models.ContactTypeSMS, Value: "+79001234567"}
+	verification := func() *models.ContactVerificationEntity {
+		v := &models.ContactVerificationEntity{ID: uuid.New(), ReceiverID: id, Type: key.Type, Value: key.Value, Attempts: 2, MaxAttempts: 5}
+		v.CodeHash = verificationCodeHash(v.ID, "123456")
+		return v
+	}
+
+	t.Run("when code is right then contact is verified", func(t *testing.T) {
+		v := verification()
+		verificationStore.
+			EXPECT().
+			Attempt(ctx, id, key, gomock.Any()).
+			Return(v, nil)
+		verificationStore.
+			EXPECT().
+			Confirm(ctx, v.ID, gomock.Any()).
+			Return(nil)
+		receiverStore.
+			EXPECT().
+			UpdateContacts(ctx, id, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, change func([]models.Contact) ([]models.Contact, error)) (*models.ReceiverEntity, error) {
+				contacts, err := change([]models.Contact{{Type: key.Type, Value: key.Value, IsActive: true}})
+				if err != nil {
+					return nil, err
+				}
+				return &models.ReceiverEntity{ID: id, Contacts: contacts}, nil
+			})
+
+		receiver, err := verificationService.Confirm(ctx, id.String(), models.ContactConfirm{
+			ContactKey: models.ContactKey{Type: models.ContactTypeSMS, Value: "89001234567"},
+			Code:       "123456",
+		})
+		assert.NoError(t, err)
+		assert.True(t, receiver.Contacts[0].IsVerified())
+	})
+	t.Run("when code is wrong then error", func(t *testing.T) {
+		verificationStore.
+			EXPECT().
+			Attempt(ctx, id, key, gomock.Any()).
+			Return(verification(), nil)
+
+		_, err := verificationService.Confirm(ctx, id.String(), models.ContactConfirm{ContactKey: key, Code: "654321"})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+		assert.ErrorContains(t, err, "3 attempts left")
+	})
+	t.Run("when code is expired or out of attempts then error", func(t *testing.T) {
+		verificationStore.
+			EXPECT().
+			Attempt(ctx, id, key, gomock.Any()).
+			Return(nil, sql.ErrNoRows)
+
+		_, err := verificationService.Confirm(ctx, id.String(), models.ContactConfirm{ContactKey: key, Code: "123456"})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+	t.Run("when code is empty then error", func(t *testing.T) {
+		_, err := verificationService.Confirm(ctx, id.String(), models.ContactConfirm{ContactKey: key})
+		assert.ErrorIs(t, err, errorx.ErrValidation)
+	})
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/services"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type broadcastStore struct {
+	db *bun.DB
+}
+
+func NewBroadcast(db *bun.DB) services.BroadcastStore {
+	return &broadcastStore{
+		db: db,
+	}
+}
+
+// Create creates the struct of a broadcast in the database.
+// It takes in a context, the new struct of the broadcast.
+// It returns an error if the create operation fails.
+func (s *broadcastStore) Create(ctx context.Context, b *models.BroadcastEntity) error {
+	now := time.Now()
+	b.CreatedAt = &now
+	_, err := s.db.
+		NewInsert().
+		Model(b).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("creating broadcast: couldn't create with: %v. Error: %w", b, err)
+	}
+	return nil
+}
+
+// GetByID retrieves a broadcast from the database by its ID.
+// It takes in a context and the ID of the broadcast.
+// It returns the broadcast and an error if the retrieval operation fails.
+func (s *broadcastStore) GetByID(ctx context.Context, id uuid.UUID) (*models.BroadcastEntity, error) {
+	entity := &models.BroadcastEntity{}
+	err := s.db.
+		NewSelect().
+		Model(entity).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting by id broadcast: couldn't get broadcast with id: %s. Error: %w", id, err)
+	}
+
+	if entity.ID == uuid.Nil {
+		return nil, sql.ErrNoRows
+	}
+	return entity, nil
+}
+
+// CountMessages retrieves the number of messages of a broadcast and their SMS segments grouped by contact type and status.
+// It takes in a context and the ID of the broadcast.
+// It returns the counters and an error if the retrieval operation fails.
+func (s *broadcastStore) CountMessages(ctx context.Context, id uuid.UUID) ([]models.BroadcastStatusCount, error) {
+	counts := make([]models.BroadcastStatusCount, 0)
+	err := s.db.
+		NewSelect().
+		Model((*models.MessageEntity)(nil)).
+		Column("type", "status").
+		ColumnExpr("count(*) AS count").
+		ColumnExpr("coalesce(sum(sms_segments), 0) AS segments").
+		Where("broadcast_id = ?", id).
+		Group("type", "status").
+		Scan(ctx, &counts)
+	if err != nil {
+		return nil, fmt.Errorf("counting broadcast messages: couldn't count messages with broadcast id: %s. Error: %w", id, err)
+	}
+	return counts, nil
+}
+
+// CountFallbacks retrieves the number of receivers of a broadcast whose messages are not in their language.
+// It takes in a context and the ID of the broadcast.
+// It returns the number of receivers and an error if the retrieval operation fails.
+func (s *broadcastStore) CountFallbacks(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	err := s.db.
+		NewSelect().
+		Model((*models.MessageEntity)(nil)).
+		ColumnExpr("count(DISTINCT receiver_id)").
+		Where("broadcast_id = ?", id).
+		Where("language_fallback").
+		Scan(ctx, &count)
+	if err != nil {
+		return 0, fmt.Errorf("counting broadcast fallbacks: couldn't count receivers with broadcast id: %s. Error: %w", id, err)
+	}
+	return count, nil
+}
+
+// FindLatest retrieves the latest broadcasts from the database, the newest first.
+// It takes in a context and the maximum number of broadcasts.
+// It returns a slice of broadcast entities and an error if the find operation fails.
+func (s *broadcastStore) FindLatest(ctx context.Context, limit int) ([]models.BroadcastEntity, error) {
+	entities := make([]models.BroadcastEntity, 0)
+	err := s.db.
+		NewSelect().
+		Model(&entities).
+		OrderExpr("created_at DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding latest broadcasts: couldn't find %d broadcasts. Error: %w", limit, err)
+	}
+	return entities, nil
+}
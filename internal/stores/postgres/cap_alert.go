@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"projects/emergency-messages/internal/cap"
+	"projects/emergency-messages/internal/models"
+	"projects/emergency-messages/internal/services"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type capAlertStore struct {
+	db *bun.DB
+}
+
+func NewCapAlert(db *bun.DB) services.CapAlertStore {
+	return &capAlertStore{
+		db: db,
+	}
+}
+
+// Create creates the struct of a CAP alert in the database unless the sender already sent an alert with the identifier,
+// so an alert received twice at once is delivered once.
+// It takes in a context, the new struct of the CAP alert.
+// It returns true if the alert was created and an error if the create operation fails.
+func (s *capAlertStore) Create(ctx context.Context, a *models.CapAlertEntity) (bool, error) {
+	now := time.Now()
+	a.CreatedAt = &now
+	exec, err := s.db.
+		NewInsert().
+		Model(a).
+		On("CONFLICT (sender, identifier) DO NOTHING").
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("creating cap alert: couldn't create with: %v. Error: %w", a, err)
+	}
+	affected, err := exec.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("creating cap alert: couldn't get the number of rows affected with: %v. Error: %w", a, err)
+	}
+	return affected > 0, nil
+}
+
+// AddBroadcasts links the broadcasts sent for a CAP alert to it.
+// It takes in a context, the ID of the CAP alert and the IDs of the broadcasts.
+// It returns an error if the create operation fails.
+func (s *capAlertStore) AddBroadcasts(ctx context.Context, id uuid.UUID, broadcastIDs []uuid.UUID) error {
+	if len(broadcastIDs) == 0 {
+		return nil
+	}
+	links := make([]models.CapAlertBroadcastEntity, 0, len(broadcastIDs))
+	for _, broadcastID := range broadcastIDs {
+		links = append(links, models.CapAlertBroadcastEntity{CapAlertID: id, BroadcastID: broadcastID})
+	}
+	_, err := s.db.
+		NewInsert().
+		Model(&links).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("creating cap alert broadcasts: couldn't create with id: %s. Error: %w", id, err)
+	}
+	return nil
+}
+
+// Delete deletes a CAP alert from the database, so the alert is accepted again when its sender resends it.
+// It takes in a context and the ID of the CAP alert.
+// It returns an error if the delete operation fails.
+func (s *capAlertStore) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.
+		NewDelete().
+		Model((*models.CapAlertEntity)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("deleting cap alert: couldn't delete with id: %s. Error: %w", id, err)
+	}
+	return nil
+}
+
+// FindBroadcastIDs retrieves the broadcasts sent for the referenced CAP alerts.
+// It takes in a context and the references of the alerts.
+// It returns a slice of broadcast IDs and an error if the find operation fails.
+func (s *capAlertStore) FindBroadcastIDs(ctx context.Context, references []cap.Reference) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0)
+	if len(references) == 0 {
+		return ids, nil
+	}
+
+	err := s.db.
+		NewSelect().
+		Model((*models.CapAlertBroadcastEntity)(nil)).
+		Column("cab.broadcast_id").
+		Join("JOIN cap_alerts AS ca ON ca.id = cab.cap_alert_id").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			for _, r := range references {
+				q = q.WhereOr("ca.sender = ? AND ca.identifier = ?", r.Sender, r.Identifier)
+			}
+			return q
+		}).
+		Scan(ctx, &ids)
+	if err != nil {
+		return nil, fmt.Errorf("finding cap alert broadcasts: couldn't find with references: %v. Error: %w", references, err)
+	}
+	return ids, nil
+}
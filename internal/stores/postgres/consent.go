@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"projects/emergency-messages/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type ContactConsentStore struct {
+	db *bun.DB
+}
+
+func NewContactConsent(db *bun.DB) *ContactConsentStore {
+	return &ContactConsentStore{
+		db: db,
+	}
+}
+
+// Create logs a change of the consent to get messages to a contact in the database.
+// It takes in a context, the new struct of the contact consent.
+// It returns an error if the create operation fails.
+func (s *ContactConsentStore) Create(ctx context.Context, c *models.ContactConsentEntity) error {
+	if c.CreatedAt == nil {
+		now := time.Now()
+		c.CreatedAt = &now
+	}
+	_, err := s.db.
+		NewInsert().
+		Model(c).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("creating contact consent: couldn't create for contact: %s. Error: %w", c.Value, err)
+	}
+	return nil
+}
+
+// FindByReceiver retrieves the changes of the consents of a receiver from the database, the latest first.
+// It takes in a context and the ID of the receiver.
+// It returns the changes and an error if the retrieval operation fails.
+func (s *ContactConsentStore) FindByReceiver(ctx context.Context, receiverID uuid.UUID) ([]models.ContactConsentEntity, error) {
+	consents := make([]models.ContactConsentEntity, 0)
+	err := s.db.
+		NewSelect().
+		Model(&consents).
+		Where("receiver_id = ?", receiverID).
+		Order("created_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding contact consents: couldn't find with receiver id: %s. Error: %w", receiverID, err)
+	}
+	return consents, nil
+}
+
+// FindOptedOut retrieves the contacts whose last change of the consent is an opt out from the database,
+// the consents are logged by the number, so they are kept when a contact is removed and added again.
+// It takes in a context and the contacts.
+// It returns the time every opted out contact opted out at and an error if the retrieval operation fails.
+func (s *ContactConsentStore) FindOptedOut(ctx context.Context, keys []models.ContactKey) (map[models.ContactKey]time.Time, error) {
+	result := make(map[models.ContactKey]time.Time)
+	if len(keys) == 0 {
+		return result, nil
+	}
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, k.Value)
+	}
+
+	consents := make([]models.ContactConsentEntity, 0)
+	err := s.db.
+		NewSelect().
+		Model(&consents).
+		DistinctOn("type, value").
+		Where("value IN (?)", bun.In(values)).
+		Where("action IN (?)", bun.In([]models.ConsentAction{models.ConsentOptOut, models.ConsentOptIn})).
+		OrderExpr("type, value, created_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding opted out contacts: couldn't find with %d contacts. Error: %w", len(keys), err)
+	}
+
+	wanted := make(map[models.ContactKey]bool, len(keys))
+	for _, k := range keys {
+		wanted[k] = true
+	}
+	for _, c := range consents {
+		key := models.ContactKey{Type: c.Type, Value: c.Value}
+		if wanted[key] && c.Action == models.ConsentOptOut && c.CreatedAt != nil {
+			result[key] = *c.CreatedAt
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"projects/emergency-messages/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// forecastAlertLock is the key of the advisory locks serializing the alerts of a city, metric and strength,
+// the second key is the hash of them
+const forecastAlertLock = 7_203_115
+
+type ForecastAlertStore struct {
+	db *bun.DB
+}
+
+func NewForecastAlert(db *bun.DB) *ForecastAlertStore {
+	return &ForecastAlertStore{
+		db: db,
+	}
+}
+
+// CreateUnlessOverlaps creates the struct of a forecast alert in the database
+// unless an alert of the same city, metric and strength overlaps its period.
+// An overlapping alert is the same weather event, so it is extended to the end of the new period instead
+// and keeps the severest value, the lowest one for frost.
+// Replicas polling the same forecast are serialized by an advisory lock, so only one of them creates the alert.
+// It takes in a context, the new struct of the forecast alert.
+// It returns true if the alert was created and an error if the operation fails.
+func (s *ForecastAlertStore) CreateUnlessOverlaps(ctx context.Context, a *models.ForecastAlertEntity) (bool, error) {
+	severest := "GREATEST"
+	if a.Metric == models.ForecastMetricFrost {
+		severest = "LEAST"
+	}
+
+	created := false
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		key := a.City + "/" + string(a.Metric) + "/" + a.Strength
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(?, hashtext(?))", forecastAlertLock, key); err != nil {
+			return err
+		}
+
+		exec, err := tx.
+			NewUpdate().
+			Model((*models.ForecastAlertEntity)(nil)).
+			Set("valid_to = GREATEST(valid_to, ?)", a.ValidTo).
+			Set("value = ?(value, ?)", bun.Safe(severest), a.Value).
+			Where("city = ?", a.City).
+			Where("metric = ?", string(a.Metric)).
+			Where("strength = ?", a.Strength).
+			Where("valid_from <= ?", a.ValidTo).
+			Where("valid_to >= ?", a.ValidFrom).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		affected, err := exec.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected > 0 {
+			return nil
+		}
+
+		now := time.Now()
+		a.CreatedAt = &now
+		if _, err = tx.NewInsert().Model(a).Returning("id").Exec(ctx); err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("creating forecast alert: couldn't create with: %v. Error: %w", a, err)
+	}
+	return created, nil
+}
+
+// SetBroadcast links a forecast alert to the broadcast sent about it.
+// It takes in a context, the ID of the forecast alert and the ID of the broadcast.
+// It returns an error if the update operation fails.
+func (s *ForecastAlertStore) SetBroadcast(ctx context.Context, id, broadcastID uuid.UUID) error {
+	exec, err := s.db.
+		NewUpdate().
+		Model((*models.ForecastAlertEntity)(nil)).
+		Set("broadcast_id = ?", broadcastID).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("updating forecast alert: couldn't update with id: %s. Error: %w", id, err)
+	}
+	affected, err := exec.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("updating forecast alert: couldn't get the number of rows affected with id: %s. Error: %w", id, err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete deletes a forecast alert from the database, so the warning is sent again on the next poll.
+// It takes in a context and the ID of the forecast alert.
+// It returns an error if the delete operation fails.
+func (s *ForecastAlertStore) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.
+		NewDelete().
+		Model((*models.ForecastAlertEntity)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("deleting forecast alert: couldn't delete with id: %s. Error: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"projects/emergency-messages/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// importChunkSize is the size of a part of an imported file stored in a row
+const importChunkSize = 1 << 20
+
+type ImportStore struct {
+	db *bun.DB
+}
+
+func NewImport(db *bun.DB) *ImportStore {
+	return &ImportStore{
+		db: db,
+	}
+}
+
+// Create creates an import job with its file in the database, the file is stored by parts while it is read.
+// It takes in a context, the new struct of the import job and the file.
+// It returns an error if the create operation or reading the file fails.
+func (s *ImportStore) Create(ctx context.Context, job *models.ImportJobEntity, file io.Reader) error {
+	now := time.Now()
+	job.CreatedAt = &now
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(job).Returning("id").Exec(ctx); err != nil {
+			return err
+		}
+
+		buf := make([]byte, importChunkSize)
+		for seq := 0; ; seq++ {
+			n, err := io.ReadFull(file, buf)
+			if n > 0 {
+				chunk := &models.ImportChunkEntity{ImportID: job.ID, Seq: seq, Data: buf[:n]}
+				if _, err := tx.NewInsert().Model(chunk).Exec(ctx); err != nil {
+					return err
+				}
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("creating import: couldn't create with file: %s. Error: %w", job.FileName, err)
+	}
+	return nil
+}
+
+// GetByID retrieves an import job from the database by its ID.
+// It takes in a context and the ID of the import job.
+// It returns the import job and an error if the retrieval operation fails, sql.ErrNoRows if it is not found.
+func (s *ImportStore) GetByID(ctx context.Context, id uuid.UUID) (*models.ImportJobEntity, error) {
+	job := &models.ImportJobEntity{}
+	err := s.db.
+		NewSelect().
+		Model(job).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting by id import: couldn't get import with id: %s. Error: %w", id, err)
+	}
+	return job, nil
+}
+
+// Claim takes the oldest pending import job, or a running one whose lease has expired, and leases it to the owner.
+// The errors and the progress of a job taken over are reset, its rows are imported again.
+// It takes in a context, the owner of the lease, the current time and the time the lease expires at.
+// It returns the claimed import job and an error if the operation fails, sql.ErrNoRows if there is nothing to claim.
+func (s *ImportStore) Claim(ctx context.Context, owner string, now, leaseExpiresAt time.Time) (*models.ImportJobEntity, error) {
+	job := &models.ImportJobEntity{}
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.
+			NewSelect().
+			Model(job).
+			WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.
+					Where("status = ?", models.ImportJobPending).
+					WhereGroup(" OR ", func(q *bun.SelectQuery) *bun.SelectQuery {
+						return q.
+							Where("status = ?", models.ImportJobRunning).
+							Where("lease_expires_at <= ?", now)
+					})
+			}).
+			Order("created_at").
+			Limit(1).
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		job.Status = models.ImportJobRunning
+		job.LeaseOwner = owner
+		job.LeaseExpiresAt = &leaseExpiresAt
+		job.StartedAt = &now
+		job.TotalRows = nil
+		job.ProcessedRows, job.CreatedRows, job.UpdatedRows, job.SkippedRows = 0, 0, 0, 0
+		_, err = tx.
+			NewUpdate().
+			Model(job).
+			Column("status", "lease_owner", "lease_expires_at", "started_at", "total_rows",
+				"processed_rows", "created_rows", "updated_rows", "skipped_rows").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.
+			NewDelete().
+			Model((*models.ImportErrorEntity)(nil)).
+			Where("import_id = ?", job.ID).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("claiming import: couldn't claim import for owner: %s. Error: %w", owner, err)
+	}
+	return job, nil
+}
+
+// Open returns the reader of the file of an import job, the parts of the file are retrieved one by one.
+// It takes in a context and the ID of the import job.
+func (s *ImportStore) Open(ctx context.Context, id uuid.UUID) io.Reader {
+	return &importFileReader{ctx: ctx, db: s.db, id: id}
+}
+
+// importFileReader reads the parts of the file of an import job in order
+type importFileReader struct {
+	ctx  context.Context
+	db   *bun.DB
+	id   uuid.UUID
+	seq  int
+	data []byte
+}
+
+func (r *importFileReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		chunk := &models.ImportChunkEntity{}
+		err := r.db.
+			NewSelect().
+			Model(chunk).
+			Where("import_id = ?", r.id).
+			Where("seq = ?", r.seq).
+			Scan(r.ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, fmt.Errorf("reading import: couldn't read part %d of import with id: %s. Error: %w", r.seq, r.id, err)
+		}
+		r.seq++
+		r.data = chunk.Data
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// SetTotal sets the number of rows of the file of an import job leased to the owner.
+// It takes in a context, the ID of the import job, the owner of the lease and the number of rows.
+// It returns an error if the update operation fails, sql.ErrNoRows if the job is leased to another owner.
+func (s *ImportStore) SetTotal(ctx context.Context, id uuid.UUID, owner string, total int) error {
+	res, err := s.db.
+		NewUpdate().
+		Model((*models.ImportJobEntity)(nil)).
+		Set("total_rows = ?", total).
+		Where("id = ?", id).
+		Where("lease_owner = ?", owner).
+		Exec(ctx)
+	if err == nil {
+		err = checkImportLease(res)
+	}
+	if err != nil {
+		return fmt.Errorf("updating import: couldn't set total rows with id: %s. Error: %w", id, err)
+	}
+	return nil
+}
+
+// Progress adds the processed rows to an import job leased to the owner, stores the rows which cannot be imported
+// and extends the lease.
+// It takes in a context, the ID of the import job, the owner of the lease, the numbers of the processed rows,
+// their errors and the time the lease expires at.
+// It returns the status of the import job, e.g. cancelled, and an error if the operation fails,
+// sql.ErrNoRows if the job is leased to another owner.
+func (s *ImportStore) Progress(ctx context.Context, id uuid.UUID, owner string, progress models.ImportProgress, rowErrors []models.ImportErrorEntity, leaseExpiresAt time.Time) (models.ImportJobStatus, error) {
+	var status models.ImportJobStatus
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.
+			NewUpdate().
+			Model((*models.ImportJobEntity)(nil)).
+			Set("processed_rows = processed_rows + ?", progress.Processed).
+			Set("created_rows = created_rows + ?", progress.Created).
+			Set("updated_rows = updated_rows + ?", progress.Updated).
+			Set("skipped_rows = skipped_rows + ?", progress.Skipped).
+			Set("lease_expires_at = ?", leaseExpiresAt).
+			Where("id = ?", id).
+			Where("lease_owner = ?", owner).
+			Returning("status").
+			Scan(ctx, &status)
+		if err != nil {
+			return err
+		}
+
+		if len(rowErrors) == 0 {
+			return nil
+		}
+		_, err = tx.NewInsert().Model(&rowErrors).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("updating import: couldn't add progress with id: %s. Error: %w", id, err)
+	}
+	return status, nil
+}
+
+// Finish marks a running import job leased to the owner as completed or failed and removes its file,
+// a cancelled job keeps its status.
+// It takes in a context, the ID of the import job, the owner of the lease, the status, the error of a failed job and the current time.
+// It returns an error if the operation fails, sql.ErrNoRows if the job is leased to another owner.
+func (s *ImportStore) Finish(ctx context.Context, id uuid.UUID, owner string, status models.ImportJobStatus, reason string, now time.Time) error {
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.
+			NewUpdate().
+			Model((*models.ImportJobEntity)(nil)).
+			Set("status = CASE WHEN status = ? THEN ? ELSE status END", models.ImportJobRunning, status).
+			Set("error = ?", sql.NullString{String: reason, Valid: reason != ""}).
+			Set("finished_at = coalesce(finished_at, ?)", now).
+			Set("lease_owner = NULL").
+			Set("lease_expires_at = NULL").
+			Where("id = ?", id).
+			Where("lease_owner = ?", owner).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if err = checkImportLease(res); err != nil {
+			return err
+		}
+		return deleteImportChunks(ctx, tx, id)
+	})
+	if err != nil {
+		return fmt.Errorf("finishing import: couldn't finish with id: %s. Error: %w", id, err)
+	}
+	return nil
+}
+
+// Cancel marks a pending or running import job as cancelled, the file of a pending job is removed,
+// a running job is stopped by its worker after the batch it imports.
+// It takes in a context, the ID of the import job and the current time.
+// It returns an error if the operation fails, sql.ErrNoRows if the job is not found or finished.
+func (s *ImportStore) Cancel(ctx context.Context, id uuid.UUID, now time.Time) error {
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var status models.ImportJobStatus
+		err := tx.
+			NewSelect().
+			Model((*models.ImportJobEntity)(nil)).
+			Column("status").
+			Where("id = ?", id).
+			Where("status IN (?)", bun.In([]models.ImportJobStatus{models.ImportJobPending, models.ImportJobRunning})).
+			For("UPDATE").
+			Scan(ctx, &status)
+		if err != nil {
+			return err
+		}
+
+		query := tx.
+			NewUpdate().
+			Model((*models.ImportJobEntity)(nil)).
+			Set("status = ?", models.ImportJobCancelled).
+			Where("id = ?", id)
+		if status == models.ImportJobPending {
+			query = query.Set("finished_at = ?", now)
+		}
+		if _, err = query.Exec(ctx); err != nil {
+			return err
+		}
+		if status == models.ImportJobPending {
+			return deleteImportChunks(ctx, tx, id)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return fmt.Errorf("cancelling import: couldn't cancel with id: %s. Error: %w", id, err)
+	}
+	return nil
+}
+
+// Errors retrieves the rows of an import job which cannot be imported ordered by line and passes them to the function one by one.
+// It takes in a context, the ID of the import job and the function.
+// It returns an error if the retrieval operation or the function fails.
+func (s *ImportStore) Errors(ctx context.Context, id uuid.UUID, fn func(models.ImportErrorEntity) error) error {
+	rows, err := s.db.
+		NewSelect().
+		Model((*models.ImportErrorEntity)(nil)).
+		Where("import_id = ?", id).
+		Order("line").
+		Rows(ctx)
+	if err != nil {
+		return fmt.Errorf("finding import errors: couldn't find errors with id: %s. Error: %w", id, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.ImportErrorEntity
+		if err = s.db.ScanRow(ctx, rows, &e); err != nil {
+			return fmt.Errorf("finding import errors: couldn't scan error with id: %s. Error: %w", id, err)
+		}
+		if err = fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// checkImportLease returns sql.ErrNoRows if an update of a leased import job has not found the job,
+// its lease has expired and it is taken over by another owner.
+func checkImportLease(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func deleteImportChunks(ctx context.Context, tx bun.Tx, id uuid.UUID) error {
+	_, err := tx.
+		NewDelete().
+		Model((*models.ImportChunkEntity)(nil)).
+		Where("import_id = ?", id).
+		Exec(ctx)
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
 	"projects/emergency-messages/internal/models"
+	"time"
 )
 
 type MessageStore struct {
@@ -79,3 +80,227 @@ func (s *MessageStore) FindByStatus(ctx context.Context, status models.MessageSt
 
 	return entities, nil
 }
+
+// Claim moves a batch of messages ready to be sent to the sending status and leases them to the owner.
+// Created messages whose next attempt is due and sending messages whose lease has expired are claimable,
+// so messages of a crashed worker are picked up again. Rows locked by other workers are skipped.
+// Messages of a cancelled broadcast are cancelled instead of being claimed.
+// It takes in a context, the owner of the lease, the maximum number of messages, the current time and the lease expiry.
+// It returns a slice of claimed message entities and an error if the claim operation fails.
+func (s *MessageStore) Claim(ctx context.Context, owner string, limit int, now, leaseExpiresAt time.Time) ([]models.MessageEntity, error) {
+	claimable := s.db.
+		NewSelect().
+		Model((*models.MessageEntity)(nil)).
+		ColumnExpr("m.id").
+		ColumnExpr("b.cancelled_at IS NOT NULL AS cancelled").
+		Join("LEFT JOIN broadcasts AS b ON b.id = m.broadcast_id").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				Where("m.status = ?", string(models.Created)).
+				WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+					return q.
+						Where("m.next_attempt_at IS NULL").
+						WhereOr("m.next_attempt_at <= ?", now)
+				})
+		}).
+		WhereGroup(" OR ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				Where("m.status = ?", string(models.Sending)).
+				Where("m.lease_expires_at <= ?", now)
+		}).
+		OrderExpr("m.next_attempt_at ASC NULLS FIRST").
+		Limit(limit).
+		For("UPDATE OF m SKIP LOCKED")
+
+	entities := make([]models.MessageEntity, 0)
+	err := s.db.
+		NewUpdate().
+		With("claimable", claimable).
+		Model((*models.MessageEntity)(nil)).
+		TableExpr("claimable").
+		Set("status = CASE WHEN claimable.cancelled THEN ?::message_status ELSE ?::message_status END", string(models.Cancelled), string(models.Sending)).
+		Set("lease_owner = CASE WHEN claimable.cancelled THEN NULL ELSE ? END", owner).
+		Set("lease_expires_at = CASE WHEN claimable.cancelled THEN NULL ELSE ?::timestamp END", leaseExpiresAt).
+		Set("next_attempt_at = CASE WHEN claimable.cancelled THEN NULL ELSE m.next_attempt_at END").
+		Where("m.id = claimable.id").
+		Returning("m.*").
+		Scan(ctx, &entities)
+	if err != nil {
+		return nil, fmt.Errorf("claiming messages: couldn't claim messages for owner: %s. Error: %w", owner, err)
+	}
+
+	claimed := entities[:0]
+	for _, e := range entities {
+		if e.Status == models.Sending {
+			claimed = append(claimed, e)
+		}
+	}
+	entities = claimed
+
+	if len(entities) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return entities, nil
+}
+
+// ScheduleRetry stores a failed attempt of a message, releases its lease and sets the time of the next attempt.
+// It takes in a context, the ID of the message, the number of attempts, the next attempt time and the provider error.
+// It returns an error if the update operation fails.
+func (s *MessageStore) ScheduleRetry(ctx context.Context, id uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error {
+	exec, err := s.db.
+		NewUpdate().
+		Model(&models.MessageEntity{}).
+		Set("status = ?", string(models.Created)).
+		Set("attempts = ?", attempts).
+		Set("next_attempt_at = ?", nextAttemptAt).
+		Set("last_error = ?", lastError).
+		Set("lease_owner = NULL").
+		Set("lease_expires_at = NULL").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("scheduling message retry: couldn't update with id: %s. Error: %w", id, err)
+	}
+	affected, err := exec.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("scheduling message retry: couldn't get the number of rows affected with id: %s. Error: %w", id, err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Fail marks a message as failed after the last attempt.
+// It takes in a context, the ID of the message, the number of attempts and the provider error.
+// It returns an error if the update operation fails.
+func (s *MessageStore) Fail(ctx context.Context, id uuid.UUID, attempts int, lastError string) error {
+	exec, err := s.db.
+		NewUpdate().
+		Model(&models.MessageEntity{}).
+		Set("status = ?", string(models.Failed)).
+		Set("attempts = ?", attempts).
+		Set("next_attempt_at = NULL").
+		Set("last_error = ?", lastError).
+		Set("lease_owner = NULL").
+		Set("lease_expires_at = NULL").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failing message: couldn't update with id: %s. Error: %w", id, err)
+	}
+	affected, err := exec.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failing message: couldn't get the number of rows affected with id: %s. Error: %w", id, err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Accept moves a message sent by its provider to the status and releases its lease,
+// so it is not claimed and sent again when the lease expires.
+// It takes in a context, the ID of the message, the ID given by the provider and the status.
+// It returns an error if the update operation fails.
+func (s *MessageStore) Accept(ctx context.Context, id uuid.UUID, providerMessageID string, status models.MessageStatus) error {
+	exec, err := s.db.
+		NewUpdate().
+		Model(&models.MessageEntity{}).
+		Set("status = ?", string(status)).
+		Set("provider_message_id = NULLIF(?, '')", providerMessageID).
+		Set("next_attempt_at = NULL").
+		Set("lease_owner = NULL").
+		Set("lease_expires_at = NULL").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("accepting message: couldn't update with id: %s. Error: %w", id, err)
+	}
+	affected, err := exec.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("accepting message: couldn't get the number of rows affected with id: %s. Error: %w", id, err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UpdateDelivery changes a message by a delivery report of its provider.
+// The message is locked while it is changed, so concurrent reports of the same message are applied one by one.
+// It takes in a context, the contact type and the provider ID of the message and the function changing the message,
+// the message is saved if the function reports a change.
+// It returns the message, whether it is changed and an error if the operation fails, sql.ErrNoRows if it is not found.
+func (s *MessageStore) UpdateDelivery(ctx context.Context, contactType models.ContactType, providerMessageID string, change func(*models.MessageEntity) bool) (*models.MessageEntity, bool, error) {
+	entity := &models.MessageEntity{}
+	changed := false
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.
+			NewSelect().
+			Model(entity).
+			Where("type = ?", string(contactType)).
+			Where("provider_message_id = ?", providerMessageID).
+			Limit(1).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		if changed = change(entity); !changed {
+			return nil
+		}
+		_, err = tx.
+			NewUpdate().
+			Model(entity).
+			Column("status", "provider_status", "last_error").
+			WherePK().
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("updating message delivery: couldn't update with provider message id: %s. Error: %w", providerMessageID, err)
+	}
+	return entity, changed, nil
+}
+
+// CancelByBroadcasts marks the broadcasts cancelled and cancels their messages which are not sent yet.
+// Messages claimed by a worker are being sent and are not cancelled,
+// messages created for a cancelled broadcast later are cancelled when they are claimed.
+// It takes in a context and the IDs of the broadcasts.
+// It returns the number of cancelled messages and an error if the update operation fails.
+func (s *MessageStore) CancelByBroadcasts(ctx context.Context, broadcastIDs []uuid.UUID) (int, error) {
+	var affected int64
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.
+			NewUpdate().
+			Model((*models.BroadcastEntity)(nil)).
+			Set("cancelled_at = ?", time.Now()).
+			Where("id IN (?)", bun.In(broadcastIDs)).
+			Where("cancelled_at IS NULL").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		exec, err := tx.
+			NewUpdate().
+			Model(&models.MessageEntity{}).
+			Set("status = ?", string(models.Cancelled)).
+			Set("next_attempt_at = NULL").
+			Where("broadcast_id IN (?)", bun.In(broadcastIDs)).
+			Where("status = ?", string(models.Created)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		affected, err = exec.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cancelling messages: couldn't update with broadcast ids: %v. Error: %w", broadcastIDs, err)
+	}
+	return int(affected), nil
+}
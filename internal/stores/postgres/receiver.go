@@ -2,14 +2,28 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"projects/emergency-messages/internal/errorx"
 	"projects/emergency-messages/internal/models"
 	"projects/emergency-messages/internal/services"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
 )
 
+// receiverImportLock is the key of the advisory lock serializing imports of receivers
+const receiverImportLock = 7_203_114
+
+// errDryRun rolls back the transaction of a dry run
+var errDryRun = errors.New("dry run")
+
 type receiverStore struct {
 	db *bun.DB
 }
@@ -24,9 +38,14 @@ func NewReceiverStore(db *bun.DB) services.ReceiverStore {
 // It takes in a context, the new struct of the receiver.
 // It returns an error if the create operation fails.
 func (s *receiverStore) Create(ctx context.Context, u *models.ReceiverEntity) error {
+	now := time.Now()
+	u.CreatedAt = &now
+	u.UpdatedAt = &now
+	u.SetNaturalKey()
 	_, err := s.db.
 		NewInsert().
 		Model(u).
+		Returning("id").
 		Exec(ctx)
 
 	if err != nil {
@@ -35,7 +54,7 @@ func (s *receiverStore) Create(ctx context.Context, u *models.ReceiverEntity) er
 	return nil
 }
 
-// FindByCity retrieves receivers from the database by city.
+// FindByCity retrieves receivers from the database by city, deleted receivers are skipped.
 // It takes in a context and the city of the receiver.
 // It returns receivers and an error if the retrieval operation fails.
 func (s *receiverStore) FindByCity(ctx context.Context, city string) ([]models.ReceiverEntity, error) {
@@ -45,6 +64,7 @@ func (s *receiverStore) FindByCity(ctx context.Context, city string) ([]models.R
 		NewSelect().
 		Model(&entities).
 		Where("city = ?", city).
+		Where("deleted_at IS NULL").
 		Scan(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("finding receivers by city: couldn't find receivers by city: %s. Error: %w", city, err)
@@ -56,3 +76,319 @@ func (s *receiverStore) FindByCity(ctx context.Context, city string) ([]models.R
 
 	return entities, nil
 }
+
+// GetByID retrieves a receiver from the database by its ID.
+// It takes in a context and the ID of the receiver.
+// It returns the receiver and an error if the retrieval operation fails, sql.ErrNoRows if it is not found or deleted.
+func (s *receiverStore) GetByID(ctx context.Context, id uuid.UUID) (*models.ReceiverEntity, error) {
+	entity := &models.ReceiverEntity{}
+	err := s.db.
+		NewSelect().
+		Model(entity).
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting by id receiver: couldn't get receiver with id: %s. Error: %w", id, err)
+	}
+	return entity, nil
+}
+
+// FindByContact retrieves the receivers having the contact from the database, deleted receivers are skipped.
+// It takes in a context and the type and the value of the contact.
+// It returns the receivers and an error if the retrieval operation fails.
+func (s *receiverStore) FindByContact(ctx context.Context, key models.ContactKey) ([]models.ReceiverEntity, error) {
+	b, _ := json.Marshal([]models.ContactKey{key})
+	entities := make([]models.ReceiverEntity, 0)
+	err := s.db.
+		NewSelect().
+		Model(&entities).
+		Where("deleted_at IS NULL").
+		Where("contacts @> ?::jsonb", string(b)).
+		Order("id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding by contact receivers: couldn't find with contact: %s %s. Error: %w", key.Type, key.Value, err)
+	}
+	return entities, nil
+}
+
+// List retrieves a page of receivers from the database by the filter, deleted receivers are skipped.
+// Receivers are ordered by ID, the page starts after the receiver filter.After.
+// It takes in a context and the validated filter.
+// It returns the receivers of the page, the number of all receivers matching the filter (0 if filter.WithoutTotal is set)
+// and an error if the retrieval operation fails.
+func (s *receiverStore) List(ctx context.Context, filter models.ReceiverFilter) ([]models.ReceiverEntity, int, error) {
+	var total int
+	if !filter.WithoutTotal {
+		var err error
+		total, err = s.filter(s.db.NewSelect().Model((*models.ReceiverEntity)(nil)), filter).Count(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("listing receivers: couldn't count with filter: %+v. Error: %w", filter, err)
+		}
+	}
+
+	entities := make([]models.ReceiverEntity, 0, filter.Limit)
+	query := s.filter(s.db.NewSelect().Model(&entities), filter)
+	if filter.After != uuid.Nil {
+		query = query.Where("id > ?", filter.After)
+	}
+	err := query.
+		Order("id").
+		Limit(filter.Limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing receivers: couldn't list with filter: %+v. Error: %w", filter, err)
+	}
+	return entities, total, nil
+}
+
+// Export retrieves the receivers from the database by the filter ordered by ID and passes them to the function one by one,
+// the receivers are read while they are passed, deleted receivers are skipped. The page of the filter is ignored.
+// It takes in a context, the validated filter and the function.
+// It returns an error if the retrieval operation or the function fails.
+func (s *receiverStore) Export(ctx context.Context, filter models.ReceiverFilter, fn func(models.ReceiverEntity) error) error {
+	rows, err := s.filter(s.db.NewSelect().Model((*models.ReceiverEntity)(nil)), filter).
+		Order("id").
+		Rows(ctx)
+	if err != nil {
+		return fmt.Errorf("exporting receivers: couldn't find with filter: %+v. Error: %w", filter, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity models.ReceiverEntity
+		if err = s.db.ScanRow(ctx, rows, &entity); err != nil {
+			return fmt.Errorf("exporting receivers: couldn't scan with filter: %+v. Error: %w", filter, err)
+		}
+		if err = fn(entity); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *receiverStore) filter(query *bun.SelectQuery, filter models.ReceiverFilter) *bun.SelectQuery {
+	query = query.Where("deleted_at IS NULL")
+	if filter.City != "" {
+		query = query.Where("city = ?", filter.City)
+	}
+	if filter.Name != "" {
+		prefix := escapeLike(filter.Name) + "%"
+		query = query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				Where("first_name ILIKE ?", prefix).
+				WhereOr("last_name ILIKE ?", prefix)
+		})
+	}
+	if contact := contactFilter(filter); contact != nil {
+		b, _ := json.Marshal([]map[string]any{contact})
+		query = query.Where("contacts @> ?::jsonb", string(b))
+	}
+	if filter.Language != "" {
+		query = query.Where("language = ?", filter.Language)
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("tags @> ?", pgdialect.Array(filter.Tags))
+	}
+	return query
+}
+
+// contactFilter returns the JSON object a contact of a matching receiver contains, nil if contacts are not filtered.
+func contactFilter(filter models.ReceiverFilter) map[string]any {
+	contact := make(map[string]any)
+	if filter.ContactType != "" {
+		contact["type"] = filter.ContactType
+	}
+	if filter.Active != nil {
+		contact["is_active"] = *filter.Active
+	}
+	if len(contact) == 0 {
+		return nil
+	}
+	return contact
+}
+
+// Update updates the names, the city, the language and the tags of a receiver in the database.
+// It takes in a context and the struct of the receiver.
+// It returns an error if the update operation fails, sql.ErrNoRows if the receiver is not found or deleted.
+func (s *receiverStore) Update(ctx context.Context, u *models.ReceiverEntity) error {
+	now := time.Now()
+	u.UpdatedAt = &now
+	u.SetNaturalKey()
+	exec, err := s.db.
+		NewUpdate().
+		Model(u).
+		Column("first_name", "last_name", "city", "language", "tags", "natural_key", "updated_at").
+		Where("id = ?", u.ID).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("updating receiver: couldn't update with id: %s. Error: %w", u.ID, err)
+	}
+	affected, err := exec.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("updating receiver: couldn't get the number of rows affected with id: %s. Error: %w", u.ID, err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UpdateContacts changes the contacts of a receiver in the database.
+// The receiver is locked while the contacts are changed, so concurrent changes are not lost.
+// It takes in a context, the ID of the receiver and the function returning the changed contacts.
+// It returns the receiver and an error if the operation fails, sql.ErrNoRows if the receiver is not found or deleted.
+func (s *receiverStore) UpdateContacts(ctx context.Context, id uuid.UUID, change func([]models.Contact) ([]models.Contact, error)) (*models.ReceiverEntity, error) {
+	entity := &models.ReceiverEntity{}
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.
+			NewSelect().
+			Model(entity).
+			Where("id = ?", id).
+			Where("deleted_at IS NULL").
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		if entity.Contacts, err = change(entity.Contacts); err != nil {
+			return err
+		}
+		now := time.Now()
+		entity.UpdatedAt = &now
+		entity.SetNaturalKey()
+		_, err = tx.
+			NewUpdate().
+			Model(entity).
+			Column("contacts", "natural_key", "updated_at").
+			WherePK().
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("updating receiver contacts: couldn't update contacts with id: %s. Error: %w", id, err)
+	}
+	return entity, nil
+}
+
+// Import creates the receivers or updates the ones with the same natural key in a transaction.
+// Imports are serialized, so rows of concurrent imports do not create the same receiver twice.
+// An existing receiver is changed by the merge function, it is saved if the function reports a change.
+// A dry run rolls the transaction back, the statuses are the same as if it was committed.
+// It takes in a context, the receivers, the merge function and whether the import is a dry run.
+// It returns the status of every receiver, the IDs of the receivers are set, and an error if the operation fails,
+// *models.ImportRowError if the database rejects the data of a receiver.
+func (s *receiverStore) Import(ctx context.Context, receivers []*models.ReceiverEntity, merge func(existing, imported *models.ReceiverEntity) bool, dryRun bool) ([]models.ImportStatus, error) {
+	statuses := make([]models.ImportStatus, len(receivers))
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(?)", receiverImportLock); err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, len(receivers))
+		for _, r := range receivers {
+			r.SetNaturalKey()
+			keys = append(keys, r.NaturalKey)
+		}
+		existing := make([]models.ReceiverEntity, 0, len(receivers))
+		err := tx.
+			NewSelect().
+			Model(&existing).
+			Where("natural_key IN (?)", bun.In(keys)).
+			Where("deleted_at IS NULL").
+			Order("id").
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+		byKey := make(map[string]*models.ReceiverEntity, len(existing))
+		for i := range existing {
+			if _, ok := byKey[existing[i].NaturalKey]; !ok {
+				byKey[existing[i].NaturalKey] = &existing[i]
+			}
+		}
+
+		now := time.Now()
+		created := make([]*models.ReceiverEntity, 0, len(receivers))
+		for i, r := range receivers {
+			found, ok := byKey[r.NaturalKey]
+			if !ok {
+				r.CreatedAt = &now
+				r.UpdatedAt = &now
+				created = append(created, r)
+				statuses[i] = models.ImportCreated
+				continue
+			}
+
+			r.ID = found.ID
+			if !merge(found, r) {
+				statuses[i] = models.ImportSkipped
+				continue
+			}
+			found.UpdatedAt = &now
+			found.SetNaturalKey()
+			_, err = tx.
+				NewUpdate().
+				Model(found).
+				Column("first_name", "last_name", "city", "language", "tags", "contacts", "natural_key", "updated_at").
+				WherePK().
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+			statuses[i] = models.ImportUpdated
+		}
+
+		if len(created) > 0 {
+			if _, err = tx.NewInsert().Model(&created).Returning("id").Exec(ctx); err != nil {
+				return err
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, fmt.Errorf("importing receivers: couldn't import %d receivers. Error: %w", len(receivers), importRowError(err))
+	}
+	return statuses, nil
+}
+
+// importRowError returns *models.ImportRowError wrapping the error if it is a data exception
+// or an integrity constraint violation, the error otherwise.
+func importRowError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")) {
+		return fmt.Errorf("%w: %w", &models.ImportRowError{Reason: pgErr.Message}, err)
+	}
+	return err
+}
+
+// Delete marks a receiver as deleted in the database, its messages are kept.
+// It takes in a context, the ID of the receiver and the time of the deletion.
+// It returns an error if the delete operation fails, sql.ErrNoRows if the receiver is not found or already deleted.
+func (s *receiverStore) Delete(ctx context.Context, id uuid.UUID, now time.Time) error {
+	exec, err := s.db.
+		NewUpdate().
+		Model(&models.ReceiverEntity{}).
+		Set("deleted_at = ?", now).
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("deleting receiver: couldn't delete with id: %s. Error: %w", id, err)
+	}
+	affected, err := exec.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("deleting receiver: couldn't get the number of rows affected with id: %s. Error: %w", id, err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
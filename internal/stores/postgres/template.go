@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"projects/emergency-messages/internal/models"
 	"projects/emergency-messages/internal/services"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,42 +23,179 @@ func NewTemplate(db *bun.DB) services.TemplateStore {
 	}
 }
 
-// Create creates the struct of a template in the database.
-// It takes in a context, the new struct of the template.
+// Create creates the struct of a template in the database with its first version.
+// It takes in a context, the new struct of the template, UpdatedBy is the author of the version.
 // It returns an error if the create operation fails.
 func (s *templateStore) Create(ctx context.Context, t *models.TemplateEntity) error {
 	now := time.Now()
 	t.CreatedAt = &now
-	_, err := s.db.
-		NewInsert().
-		Model(t).
-		Exec(ctx)
+	t.UpdatedAt = &now
+	t.Version = 1
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(t).Returning("id").Exec(ctx); err != nil {
+			return err
+		}
+		_, err := tx.NewInsert().Model(transformTemplateToVersion(t, 0)).Exec(ctx)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("creating template: couldn't create with: %v. Error: %w", t, err)
 	}
 	return nil
 }
 
-// Update updates the struct of a template in the database.
-// It takes in a context, the new struct of the template.
-// It returns an error if the update operation fails.
+// Update adds a version of a template in the database and makes it current.
+// The previous versions are kept as they are.
+// It takes in a context, the new struct of the template, UpdatedBy is the author of the version.
+// It returns an error if the update operation fails, sql.ErrNoRows if the template is not found.
 func (s *templateStore) Update(ctx context.Context, t *models.TemplateEntity) error {
-	exec, err := s.db.
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		current, err := lockTemplate(ctx, tx, t.ID)
+		if err != nil {
+			return err
+		}
+		t.Version = current.Version + 1
+		return addVersion(ctx, tx, t, 0)
+	})
+	if err != nil {
+		return fmt.Errorf("updating template: couldn't update with id: %s. Error: %w", t.ID, err)
+	}
+	return nil
+}
+
+// Rollback makes an older version of a template current.
+// The wording of the older version is copied into a new version, so the history is never rewritten.
+// Rolling back to the current version changes nothing.
+// It takes in a context, the ID of the template, the version to restore and the author of the rollback.
+// It returns the template and an error if the operation fails, sql.ErrNoRows if the template or the version is not found.
+func (s *templateStore) Rollback(ctx context.Context, id uuid.UUID, version int, author string) (*models.TemplateEntity, error) {
+	var t *models.TemplateEntity
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		current, err := lockTemplate(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		restored := &models.TemplateVersionEntity{}
+		err = tx.
+			NewSelect().
+			Model(restored).
+			Where("template_id = ?", id).
+			Where("version = ?", version).
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		if version == current.Version {
+			t = current
+			return nil
+		}
+
+		t = &models.TemplateEntity{
+			ID:                 id,
+			Subject:            restored.Subject,
+			Text:               restored.Text,
+			Variables:          restored.Variables,
+			Variants:           restored.Variants,
+			Language:           restored.Language,
+			Translations:       restored.Translations,
+			MaxSegments:        restored.MaxSegments,
+			SegmentPolicy:      restored.SegmentPolicy,
+			WhatsAppName:       restored.WhatsAppName,
+			WhatsAppLanguage:   restored.WhatsAppLanguage,
+			WhatsAppParameters: restored.WhatsAppParameters,
+			Version:            current.Version + 1,
+			UpdatedBy:          author,
+			CreatedAt:          current.CreatedAt,
+		}
+		return addVersion(ctx, tx, t, version)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rolling back template: couldn't roll back with id: %s to version: %d. Error: %w", id, version, err)
+	}
+	return t, nil
+}
+
+// Versions retrieves the versions of a template from the database, the newest first.
+// It takes in a context and the ID of the template.
+// It returns the versions and an error if the find operation fails.
+func (s *templateStore) Versions(ctx context.Context, id uuid.UUID) ([]models.TemplateVersionEntity, error) {
+	versions := make([]models.TemplateVersionEntity, 0)
+	err := s.db.
+		NewSelect().
+		Model(&versions).
+		Where("template_id = ?", id).
+		Order("version DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding template versions: couldn't find with template id: %s. Error: %w", id, err)
+	}
+	return versions, nil
+}
+
+// lockTemplate selects a template which is not deleted for update,
+// so concurrent edits get consecutive version numbers.
+func lockTemplate(ctx context.Context, tx bun.Tx, id uuid.UUID) (*models.TemplateEntity, error) {
+	current := &models.TemplateEntity{}
+	err := tx.
+		NewSelect().
+		Model(current).
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// addVersion inserts the version of a template and makes it current.
+func addVersion(ctx context.Context, tx bun.Tx, t *models.TemplateEntity, restoredFrom int) error {
+	now := time.Now()
+	t.UpdatedAt = &now
+	exec, err := tx.
 		NewUpdate().
 		Model(t).
+		Column("subject", "text", "variables", "variants", "language", "translations", "max_segments", "segment_policy", "whatsapp_name", "whatsapp_language", "whatsapp_parameters", "version", "updated_by", "updated_at").
 		Where("id = ?", t.ID).
+		Where("deleted_at IS NULL").
 		Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("updating template: couldn't update with id: %s. Error: %w", t.ID, err)
+		return err
 	}
 	affected, err := exec.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("updating template: couldn't get the number of rows affected with id: %s. Error: %w", t.ID, err)
+		return err
 	}
 	if affected == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+
+	_, err = tx.NewInsert().Model(transformTemplateToVersion(t, restoredFrom)).Exec(ctx)
+	return err
+}
+
+func transformTemplateToVersion(t *models.TemplateEntity, restoredFrom int) *models.TemplateVersionEntity {
+	return &models.TemplateVersionEntity{
+		TemplateID:         t.ID,
+		Version:            t.Version,
+		Subject:            t.Subject,
+		Text:               t.Text,
+		Variables:          t.Variables,
+		Variants:           t.Variants,
+		Language:           t.Language,
+		Translations:       t.Translations,
+		MaxSegments:        t.MaxSegments,
+		SegmentPolicy:      t.SegmentPolicy,
+		WhatsAppName:       t.WhatsAppName,
+		WhatsAppLanguage:   t.WhatsAppLanguage,
+		WhatsAppParameters: t.WhatsAppParameters,
+		Author:             t.UpdatedBy,
+		RestoredFrom:       restoredFrom,
+		CreatedAt:          t.UpdatedAt,
+	}
 }
 
 // Delete deletes the struct of a template in the database.
@@ -69,6 +207,7 @@ func (s *templateStore) Delete(ctx context.Context, id uuid.UUID, now time.Time)
 		Model(&models.TemplateEntity{}).
 		Set("deleted_at = ?", now).
 		Where("id = ?", id).
+		Where("deleted_at IS NULL").
 		Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("deleting template: couldn't delete with id: %s. Error: %w", id, err)
@@ -92,6 +231,7 @@ func (s *templateStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Temp
 		NewSelect().
 		Model(entity).
 		Where("id = ?", id).
+		Where("deleted_at IS NULL").
 		Scan(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("getting by id template: couldn't get template with id: %s. Error: %w", id, err)
@@ -102,9 +242,51 @@ func (s *templateStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Temp
 	}
 
 	template := &models.TemplateEntity{
-		ID:      entity.ID,
-		Subject: entity.Subject,
-		Text:    entity.Text,
+		ID:                 entity.ID,
+		Subject:            entity.Subject,
+		Text:               entity.Text,
+		Variables:          entity.Variables,
+		Variants:           entity.Variants,
+		Language:           entity.Language,
+		Translations:       entity.Translations,
+		MaxSegments:        entity.MaxSegments,
+		SegmentPolicy:      entity.SegmentPolicy,
+		WhatsAppName:       entity.WhatsAppName,
+		WhatsAppLanguage:   entity.WhatsAppLanguage,
+		WhatsAppParameters: entity.WhatsAppParameters,
+		Version:            entity.Version,
+		UpdatedBy:          entity.UpdatedBy,
+		CreatedAt:          entity.CreatedAt,
+		UpdatedAt:          entity.UpdatedAt,
 	}
 	return template, nil
 }
+
+// List retrieves a page of templates which are not deleted, the newest first.
+// It takes in a context and the filter with the subject text and the page.
+// It returns the templates, the number of all matching templates and an error if the find operation fails.
+func (s *templateStore) List(ctx context.Context, filter models.TemplateFilter) ([]models.TemplateEntity, int, error) {
+	entities := make([]models.TemplateEntity, 0)
+	query := s.db.
+		NewSelect().
+		Model(&entities).
+		Where("deleted_at IS NULL")
+	if filter.Subject != "" {
+		query = query.Where("subject ILIKE ?", "%"+escapeLike(filter.Subject)+"%")
+	}
+
+	total, err := query.
+		OrderExpr("created_at DESC NULLS LAST, id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing templates: couldn't list with filter: %+v. Error: %w", filter, err)
+	}
+	return entities, total, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
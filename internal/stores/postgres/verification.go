@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"projects/emergency-messages/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// contactVerificationLock is the key of the advisory locks serializing the codes sent to a contact,
+// the second key is the hash of its type and value
+const contactVerificationLock = 7_203_116
+
+type ContactVerificationStore struct {
+	db *bun.DB
+}
+
+func NewContactVerification(db *bun.DB) *ContactVerificationStore {
+	return &ContactVerificationStore{
+		db: db,
+	}
+}
+
+// Create creates the code sent to a contact in the database, the codes sent to the contact before expire.
+// The check is called with the times the codes were sent to the contact by any receiver since the time, the latest first,
+// the code is not created when it fails. Concurrent codes of a contact are serialized by an advisory lock.
+// It takes in a context, the new struct of the contact verification, the time and the check.
+// It returns an error if the create operation or the check fails.
+func (s *ContactVerificationStore) Create(ctx context.Context, v *models.ContactVerificationEntity, since time.Time, check func(sent []time.Time) error) error {
+	now := time.Now()
+	v.CreatedAt = &now
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(?, hashtext(?))", contactVerificationLock, string(v.Type)+":"+v.Value)
+		if err != nil {
+			return err
+		}
+
+		var sent []time.Time
+		err = tx.
+			NewSelect().
+			Model((*models.ContactVerificationEntity)(nil)).
+			Column("created_at").
+			Where("type = ?", v.Type).
+			Where("value = ?", v.Value).
+			Where("created_at > ?", since).
+			Order("created_at DESC").
+			Scan(ctx, &sent)
+		if err != nil {
+			return err
+		}
+		if err = check(sent); err != nil {
+			return err
+		}
+
+		_, err = tx.
+			NewUpdate().
+			Model((*models.ContactVerificationEntity)(nil)).
+			Set("expires_at = ?", now).
+			Where("receiver_id = ?", v.ReceiverID).
+			Where("type = ?", v.Type).
+			Where("value = ?", v.Value).
+			Where("confirmed_at IS NULL").
+			Where("expires_at > ?", now).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewInsert().Model(v).Returning("id").Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("creating contact verification: couldn't create for receiver: %s. Error: %w", v.ReceiverID, err)
+	}
+	return nil
+}
+
+// Attempt counts an attempt to confirm the last code sent to the contact of the receiver,
+// the code must not be confirmed, expired or out of attempts.
+// It takes in a context, the ID of the receiver, the contact and the current time.
+// It returns the contact verification with the attempt counted and an error if the operation fails,
+// sql.ErrNoRows if the contact has no code to confirm.
+func (s *ContactVerificationStore) Attempt(ctx context.Context, receiverID uuid.UUID, key models.ContactKey, now time.Time) (*models.ContactVerificationEntity, error) {
+	v := &models.ContactVerificationEntity{}
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.
+			NewSelect().
+			Model(v).
+			Where("receiver_id = ?", receiverID).
+			Where("type = ?", key.Type).
+			Where("value = ?", key.Value).
+			Where("confirmed_at IS NULL").
+			Where("expires_at > ?", now).
+			Where("attempts < max_attempts").
+			Order("created_at DESC").
+			Limit(1).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		v.Attempts++
+		_, err = tx.
+			NewUpdate().
+			Model(v).
+			Column("attempts").
+			WherePK().
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("attempting contact verification: couldn't attempt for receiver: %s. Error: %w", receiverID, err)
+	}
+	return v, nil
+}
+
+// Confirm marks the code as confirmed, a confirmed code is not accepted again.
+// It takes in a context, the ID of the contact verification and the current time.
+// It returns an error if the operation fails, sql.ErrNoRows if the code is confirmed already.
+func (s *ContactVerificationStore) Confirm(ctx context.Context, id uuid.UUID, now time.Time) error {
+	res, err := s.db.
+		NewUpdate().
+		Model((*models.ContactVerificationEntity)(nil)).
+		Set("confirmed_at = ?", now).
+		Where("id = ?", id).
+		Where("confirmed_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("confirming contact verification: couldn't confirm with id: %s. Error: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"projects/emergency-messages/internal/models"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type WebhookTokenStore struct {
+	db *bun.DB
+}
+
+func NewWebhookToken(db *bun.DB) *WebhookTokenStore {
+	return &WebhookTokenStore{
+		db: db,
+	}
+}
+
+// Use processes a webhook request once by its token, the token is recorded in a transaction with the processing,
+// so a request failing to be processed is processed again when it is retried.
+// A concurrent request with the same token waits until the first one is processed, the expired tokens are deleted.
+// It takes in a context, the token, the time it expires at and the function processing the request.
+// It returns false if the token is used already and an error if the operation or the processing fails.
+func (s *WebhookTokenStore) Use(ctx context.Context, token string, expiresAt time.Time, process func(ctx context.Context) error) (bool, error) {
+	used := false
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.
+			NewDelete().
+			Model((*models.WebhookTokenEntity)(nil)).
+			Where("expires_at < ?", time.Now()).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		exec, err := tx.
+			NewInsert().
+			Model(&models.WebhookTokenEntity{Token: token, ExpiresAt: expiresAt}).
+			On("CONFLICT (token) DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		affected, err := exec.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			used = true
+			return nil
+		}
+		return process(ctx)
+	})
+	if err != nil {
+		return false, fmt.Errorf("using webhook token: couldn't process request with token: %s. Error: %w", token, err)
+	}
+	return !used, nil
+}
@@ -0,0 +1,36 @@
+package workers
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultLeaseDuration = 5 * time.Minute
+)
+
+// ClaimPolicy describes how many messages a worker takes at once and for how long they are leased to it.
+// A lease which is not released before it expires is taken over by another worker.
+type ClaimPolicy struct {
+	BatchSize     int
+	LeaseDuration time.Duration
+}
+
+// DefaultClaimPolicy returns the policy used when nothing is configured.
+func DefaultClaimPolicy() ClaimPolicy {
+	return ClaimPolicy{
+		BatchSize:     defaultBatchSize,
+		LeaseDuration: defaultLeaseDuration,
+	}
+}
+
+// workerID returns the lease owner identifying this process among the replicas.
+func workerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
@@ -0,0 +1,149 @@
+package workers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// errImportCancelled stops an import job which is cancelled while it is imported
+var errImportCancelled = errors.New("import is cancelled")
+
+// errImportLeaseLost stops an import job whose lease has expired and which is taken over by another worker
+var errImportLeaseLost = errors.New("import lease is lost")
+
+type Import struct {
+	importStore   ImportStore
+	importer      Importer
+	leaseDuration time.Duration
+	owner         string
+	log           *slog.Logger
+}
+
+type ImportStore interface {
+	Claim(ctx context.Context, owner string, now, leaseExpiresAt time.Time) (*models.ImportJobEntity, error)
+	Open(ctx context.Context, id uuid.UUID) io.Reader
+	SetTotal(ctx context.Context, id uuid.UUID, owner string, total int) error
+	Progress(ctx context.Context, id uuid.UUID, owner string, progress models.ImportProgress, rowErrors []models.ImportErrorEntity, leaseExpiresAt time.Time) (models.ImportJobStatus, error)
+	Finish(ctx context.Context, id uuid.UUID, owner string, status models.ImportJobStatus, reason string, now time.Time) error
+}
+
+type Importer interface {
+	CountRows(data io.Reader, options models.ImportOptions) (int, error)
+	ImportRows(ctx context.Context, data io.Reader, options models.ImportOptions, progress func(rows []models.ImportRow) error) error
+}
+
+func NewImport(importStore ImportStore, importer Importer, leaseDuration time.Duration, log *slog.Logger) *Import {
+	return &Import{
+		importStore:   importStore,
+		importer:      importer,
+		leaseDuration: leaseDuration,
+		owner:         workerID(),
+		log:           log,
+	}
+}
+
+// Run claims pending import jobs and imports them one by one until nothing is left.
+func (i *Import) Run() {
+	ctx := context.Background()
+	for {
+		now := time.Now()
+		job, err := i.importStore.Claim(ctx, i.owner, now, now.Add(i.leaseDuration))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				i.log.Debug("nothing to import")
+				return
+			}
+			i.log.Error("claiming import", err)
+			return
+		}
+		i.process(ctx, job)
+	}
+}
+
+// process counts the rows of the file of the job and imports them, the progress is stored after every batch.
+// A job which cannot be marked as finished is left running, it is taken over when its lease expires.
+// A job taken over by another worker is stopped and left to it.
+func (i *Import) process(ctx context.Context, job *models.ImportJobEntity) {
+	log := i.log.With(slog.Any("import id", job.ID))
+	options := models.ImportOptions{
+		DryRun:  job.DryRun,
+		Format:  job.Format,
+		Columns: job.Columns,
+	}
+
+	total, err := i.importer.CountRows(i.importStore.Open(ctx, job.ID), options)
+	if err == nil {
+		err = leaseError(i.importStore.SetTotal(ctx, job.ID, i.owner, total))
+	}
+	if err == nil {
+		err = i.importer.ImportRows(ctx, i.importStore.Open(ctx, job.ID), options, func(rows []models.ImportRow) error {
+			var progress models.ImportProgress
+			progress.Add(rows)
+			status, err := i.importStore.Progress(ctx, job.ID, i.owner, progress, importErrors(job.ID, rows), time.Now().Add(i.leaseDuration))
+			if err != nil {
+				return leaseError(err)
+			}
+			if status == models.ImportJobCancelled {
+				return errImportCancelled
+			}
+			return nil
+		})
+	}
+
+	status, reason := models.ImportJobCompleted, ""
+	switch {
+	case err == nil:
+	case errors.Is(err, errImportLeaseLost):
+		log.Warn("import is taken over by another worker")
+		return
+	case errors.Is(err, errImportCancelled):
+		status = models.ImportJobCancelled
+	case errors.Is(err, errorx.ErrValidation):
+		status, reason = models.ImportJobFailed, "the file cannot be read"
+	default:
+		log.Error("importing", err)
+		status, reason = models.ImportJobFailed, "the import is interrupted"
+	}
+	err = i.importStore.Finish(ctx, job.ID, i.owner, status, reason, time.Now())
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		log.Warn("import is taken over by another worker")
+	case err != nil:
+		log.Error("finishing import", err)
+	}
+}
+
+// leaseError returns errImportLeaseLost if the import job is not found with the lease of the worker.
+func leaseError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return errImportLeaseLost
+	}
+	return err
+}
+
+// importErrors returns the rows which cannot be imported and the rows imported with invalid contacts.
+func importErrors(id uuid.UUID, rows []models.ImportRow) []models.ImportErrorEntity {
+	result := make([]models.ImportErrorEntity, 0)
+	for _, row := range rows {
+		reason, ok := row.Problem()
+		if !ok {
+			continue
+		}
+		result = append(result, models.ImportErrorEntity{
+			ImportID: id,
+			Line:     row.Line,
+			Status:   row.Status,
+			Reason:   reason,
+			Record:   row.Record,
+		})
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+package workers
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/errorx"
+	"projects/emergency-messages/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeImportStore struct {
+	jobs     []*models.ImportJobEntity
+	status   models.ImportJobStatus
+	total    int
+	progress models.ImportProgress
+	errors   []models.ImportErrorEntity
+	finished map[uuid.UUID]models.ImportJobStatus
+	reasons  map[uuid.UUID]string
+	lost     bool
+}
+
+func (s *fakeImportStore) Claim(_ context.Context, _ string, _, _ time.Time) (*models.ImportJobEntity, error) {
+	if len(s.jobs) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	job := s.jobs[0]
+	s.jobs = s.jobs[1:]
+	return job, nil
+}
+
+func (s *fakeImportStore) Open(_ context.Context, _ uuid.UUID) io.Reader {
+	return strings.NewReader("file")
+}
+
+func (s *fakeImportStore) SetTotal(_ context.Context, _ uuid.UUID, _ string, total int) error {
+	s.total = total
+	return nil
+}
+
+func (s *fakeImportStore) Progress(_ context.Context, _ uuid.UUID, _ string, progress models.ImportProgress, rowErrors []models.ImportErrorEntity, _ time.Time) (models.ImportJobStatus, error) {
+	if s.lost {
+		return "", sql.ErrNoRows
+	}
+	s.progress.Processed += progress.Processed
+	s.progress.Created += progress.Created
+	s.progress.Updated += progress.Updated
+	s.progress.Skipped += progress.Skipped
+	s.errors = append(s.errors, rowErrors...)
+	return s.status, nil
+}
+
+func (s *fakeImportStore) Finish(_ context.Context, id uuid.UUID, _ string, status models.ImportJobStatus, reason string, _ time.Time) error {
+	if s.lost {
+		return sql.ErrNoRows
+	}
+	s.finished[id] = status
+	s.reasons[id] = reason
+	return nil
+}
+
+type fakeImporter struct {
+	batches  [][]models.ImportRow
+	countErr error
+}
+
+func (i *fakeImporter) CountRows(_ io.Reader, _ models.ImportOptions) (int, error) {
+	if i.countErr != nil {
+		return 0, i.countErr
+	}
+	count := 0
+	for _, rows := range i.batches {
+		count += len(rows)
+	}
+	return count, nil
+}
+
+func (i *fakeImporter) ImportRows(_ context.Context, _ io.Reader, _ models.ImportOptions, progress func(rows []models.ImportRow) error) error {
+	for _, rows := range i.batches {
+		if err := progress(rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestImport_Run(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	batches := [][]models.ImportRow{
+		{
+			{Line: 2, Status: models.ImportCreated},
+			{Line: 3, Status: models.ImportSkipped, Reason: "last name is empty", Record: []string{"Boris", ""}},
+		},
+		{
+			{Line: 4, Status: models.ImportSkipped, Reason: models.ImportReasonNoChanges},
+			{Line: 5, Status: models.ImportUpdated, Warnings: []string{"MobilePhone: invalid phone", "Email: invalid email"}, Record: []string{"Ildar"}},
+		},
+	}
+	newStore := func(status models.ImportJobStatus, jobs ...*models.ImportJobEntity) *fakeImportStore {
+		return &fakeImportStore{
+			jobs:     jobs,
+			status:   status,
+			finished: make(map[uuid.UUID]models.ImportJobStatus),
+			reasons:  make(map[uuid.UUID]string),
+		}
+	}
+
+	t.Run("when import is running then progress and errors are stored until it is completed", func(t *testing.T) {
+		job := &models.ImportJobEntity{ID: uuid.New()}
+		store := newStore(models.ImportJobRunning, job)
+		worker := NewImport(store, &fakeImporter{batches: batches}, time.Minute, log)
+
+		worker.Run()
+
+		assert.Equal(t, 4, store.total)
+		assert.Equal(t, models.ImportProgress{Processed: 4, Created: 1, Updated: 1, Skipped: 2}, store.progress)
+		assert.Equal(t, []models.ImportErrorEntity{
+			{ImportID: job.ID, Line: 3, Status: models.ImportSkipped, Reason: "last name is empty", Record: []string{"Boris", ""}},
+			{ImportID: job.ID, Line: 5, Status: models.ImportUpdated, Reason: "MobilePhone: invalid phone; Email: invalid email", Record: []string{"Ildar"}},
+		}, store.errors)
+		assert.Equal(t, models.ImportJobCompleted, store.finished[job.ID])
+	})
+	t.Run("when import is cancelled then it stops after the batch", func(t *testing.T) {
+		job := &models.ImportJobEntity{ID: uuid.New()}
+		store := newStore(models.ImportJobCancelled, job)
+		worker := NewImport(store, &fakeImporter{batches: batches}, time.Minute, log)
+
+		worker.Run()
+
+		assert.Equal(t, 2, store.progress.Processed)
+		assert.Equal(t, models.ImportJobCancelled, store.finished[job.ID])
+	})
+	t.Run("when file cannot be read then failed", func(t *testing.T) {
+		job := &models.ImportJobEntity{ID: uuid.New()}
+		store := newStore(models.ImportJobRunning, job)
+		worker := NewImport(store, &fakeImporter{countErr: errorx.ErrValidation}, time.Minute, log)
+
+		worker.Run()
+
+		assert.Equal(t, models.ImportJobFailed, store.finished[job.ID])
+		assert.Equal(t, "the file cannot be read", store.reasons[job.ID])
+	})
+	t.Run("when import is taken over by another worker then it stops without finishing", func(t *testing.T) {
+		job := &models.ImportJobEntity{ID: uuid.New()}
+		store := newStore(models.ImportJobRunning, job)
+		store.lost = true
+		worker := NewImport(store, &fakeImporter{batches: batches}, time.Minute, log)
+
+		worker.Run()
+
+		assert.Zero(t, store.progress.Processed)
+		assert.NotContains(t, store.finished, job.ID)
+	})
+}
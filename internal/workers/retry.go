@@ -0,0 +1,48 @@
+package workers
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBaseDelay   = 30 * time.Second
+	defaultMaxDelay    = 30 * time.Minute
+)
+
+// RetryPolicy describes how many times a message is sent and how long to wait between attempts.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used when nothing is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultMaxAttempts,
+		BaseDelay:   defaultBaseDelay,
+		MaxDelay:    defaultMaxDelay,
+	}
+}
+
+// Exhausted reports whether no attempts are left after the given number of attempts.
+func (p RetryPolicy) Exhausted(attempts int) bool {
+	return attempts >= p.MaxAttempts
+}
+
+// Delay returns the time to wait after the given number of failed attempts.
+// The delay grows exponentially from BaseDelay up to MaxDelay, and a random jitter
+// of up to a half of it spreads retries of messages which failed at the same time.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	backoff := p.MaxDelay
+	if attempts > 0 && attempts < 32 {
+		if d := p.BaseDelay << (attempts - 1); d > 0 && d < p.MaxDelay {
+			backoff = d
+		}
+	}
+
+	half := backoff / 2
+	return backoff - half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,45 @@
+package workers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   10 * time.Second,
+		MaxDelay:    time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		attempts int
+		backoff  time.Duration
+	}{
+		{name: "first attempt", attempts: 1, backoff: 10 * time.Second},
+		{name: "second attempt", attempts: 2, backoff: 20 * time.Second},
+		{name: "third attempt", attempts: 3, backoff: 40 * time.Second},
+		{name: "capped by max delay", attempts: 4, backoff: time.Minute},
+		{name: "shift overflow is capped", attempts: 100, backoff: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				delay := policy.Delay(tt.attempts)
+				assert.GreaterOrEqual(t, delay, tt.backoff/2)
+				assert.LessOrEqual(t, delay, tt.backoff)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Exhausted(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3}
+
+	assert.False(t, policy.Exhausted(1))
+	assert.False(t, policy.Exhausted(2))
+	assert.True(t, policy.Exhausted(3))
+}
@@ -3,72 +3,144 @@ package workers
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"projects/emergency-messages/internal/models"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Message struct {
-	messageStore MessageFinder
+	messageStore MessageStore
 	sender       Sender
 	producer     Producer
-	maxRetries   int
+	retryPolicy  RetryPolicy
+	claimPolicy  ClaimPolicy
+	owner        string
 	log          *slog.Logger
 }
 
-type MessageFinder interface {
-	FindByStatus(ctx context.Context, status models.MessageStatus) ([]models.MessageEntity, error)
+type MessageStore interface {
+	Claim(ctx context.Context, owner string, limit int, now, leaseExpiresAt time.Time) ([]models.MessageEntity, error)
+	ScheduleRetry(ctx context.Context, id uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error
+	Fail(ctx context.Context, id uuid.UUID, attempts int, lastError string) error
+	Accept(ctx context.Context, id uuid.UUID, providerMessageID string, status models.MessageStatus) error
 }
 
 type Producer interface {
 	Delivered(messageID []byte) error
-	Failed(messageID []byte) error
 }
 
+// Sender sends a message by its provider.
+// It returns the ID the provider gives the message, the delivery reports of the provider refer to it.
+// ReportsDelivery reports whether the provider of the contact type is configured to call back with the delivery statuses.
 type Sender interface {
-	Send(message models.MessageSend) error
+	Send(message models.MessageSend) (string, error)
+	ReportsDelivery(contactType models.ContactType) bool
 }
 
-func NewSendMessage(messageStore MessageFinder, producer Producer, sender Sender, log *slog.Logger) *Message {
+func NewSendMessage(messageStore MessageStore, producer Producer, sender Sender, retryPolicy RetryPolicy, claimPolicy ClaimPolicy, log *slog.Logger) *Message {
 	return &Message{
 		messageStore: messageStore,
 		producer:     producer,
 		sender:       sender,
+		retryPolicy:  retryPolicy,
+		claimPolicy:  claimPolicy,
+		owner:        workerID(),
 		log:          log,
 	}
 }
 
+// Send claims batches of messages ready to be sent and sends them until nothing is left.
 func (m *Message) Send() {
 	fmt.Println("send worker")
-	messagesStore, err := m.messageStore.FindByStatus(context.Background(), models.Created)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			m.log.Debug("nothing to send")
+	ctx := context.Background()
+	for {
+		claimed, err := m.sendBatch(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				m.log.Debug("nothing to send")
+				return
+			}
+			m.log.Error("claiming messages", err)
+			return
+		}
+		if claimed < m.claimPolicy.BatchSize {
 			return
 		}
-		m.log.Error("finding messages by status", err)
-		return
+	}
+}
+
+// sendBatch claims one batch of messages and sends them.
+// It returns the number of claimed messages.
+func (m *Message) sendBatch(ctx context.Context) (int, error) {
+	now := time.Now()
+	messagesStore, err := m.messageStore.Claim(ctx, m.owner, m.claimPolicy.BatchSize, now, now.Add(m.claimPolicy.LeaseDuration))
+	if err != nil {
+		return 0, err
 	}
 
 	messages, err := m.transformMessagesStoreToMessages(messagesStore)
 	if err != nil {
-		m.log.Error("transforming messages store to messages", err)
-		return
+		return 0, err
 	}
 
-	// TODO: send message N times with timeout if failed and then update status to failed
 	for _, message := range messages {
-		b := []byte(message.ID.String())
-		if err = m.sender.Send(message); err != nil {
-			if err = m.producer.Failed(message.ID.NodeID()); err != nil {
-				m.log.Error("sending failed message", err)
-			}
+		providerMessageID, err := m.sender.Send(message)
+		if err != nil {
+			m.retry(ctx, message, err)
 			continue
 		}
+		m.accept(ctx, message, providerMessageID)
+	}
+	return len(messages), nil
+}
 
-		if err = m.producer.Delivered(b); err != nil {
-			m.log.Error("sending delivered message", err)
+// accept stores the provider ID of a sent message and releases its lease before the next message is sent.
+// A message whose provider calls back with the delivery statuses is accepted and changed by the reports later,
+// e.g. an SMS when WEBHOOK_BASE_URL is set, other messages are delivered and
+// the delivered event is published for the consumers of the topic.
+func (m *Message) accept(ctx context.Context, message models.MessageSend, providerMessageID string) {
+	status := models.Delivered
+	if m.sender.ReportsDelivery(message.Type) {
+		status = models.Accepted
+	}
+	if err := m.messageStore.Accept(ctx, message.ID, providerMessageID, status); err != nil {
+		m.log.With(slog.Any("message id", message.ID)).
+			Error("accepting message", err)
+	}
+	if status == models.Accepted {
+		return
+	}
+
+	b := []byte(message.ID.String())
+	if err := m.producer.Delivered(b); err != nil {
+		m.log.Error("sending delivered message", err)
+	}
+}
+
+// retry schedules the next attempt of the message with backoff,
+// or marks the message as failed when there are no attempts left.
+func (m *Message) retry(ctx context.Context, message models.MessageSend, sendErr error) {
+	attempts := message.Attempts + 1
+	log := m.log.With(
+		slog.Any("message id", message.ID),
+		slog.Int("attempts", attempts),
+		slog.String("error", sendErr.Error()),
+	)
+
+	if m.retryPolicy.Exhausted(attempts) {
+		if err := m.messageStore.Fail(ctx, message.ID, attempts, sendErr.Error()); err != nil {
+			log.Error("failing message", err)
 		}
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(m.retryPolicy.Delay(attempts))
+	if err := m.messageStore.ScheduleRetry(ctx, message.ID, attempts, nextAttemptAt, sendErr.Error()); err != nil {
+		log.Error("scheduling message retry", err)
 	}
 }
 
@@ -79,10 +151,14 @@ func (m *Message) transformMessagesStoreToMessages(messagesStore []models.Messag
 			ID:         message.ID,
 			Subject:    message.Subject,
 			Text:       message.Text,
+			HTML:       message.HTML,
+			Format:     message.Format,
 			Status:     message.Status,
 			ReceiverID: message.ReceiverID,
 			Type:       message.Type,
 			Value:      message.Value,
+			Attempts:   message.Attempts,
+			WhatsApp:   message.WhatsApp,
 		}
 		messages = append(messages, newMessage)
 	}
@@ -0,0 +1,179 @@
+package workers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"projects/emergency-messages/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMessageStore struct {
+	batches  [][]models.MessageEntity
+	limits   []int
+	retried  map[uuid.UUID]int
+	failed   map[uuid.UUID]string
+	accepted map[uuid.UUID]string
+	statuses map[uuid.UUID]models.MessageStatus
+	claimErr error
+}
+
+func (s *fakeMessageStore) Claim(_ context.Context, _ string, limit int, _, _ time.Time) ([]models.MessageEntity, error) {
+	s.limits = append(s.limits, limit)
+	if s.claimErr != nil {
+		return nil, s.claimErr
+	}
+	if len(s.batches) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	return batch, nil
+}
+
+func (s *fakeMessageStore) ScheduleRetry(_ context.Context, id uuid.UUID, attempts int, _ time.Time, _ string) error {
+	s.retried[id] = attempts
+	return nil
+}
+
+func (s *fakeMessageStore) Fail(_ context.Context, id uuid.UUID, _ int, lastError string) error {
+	s.failed[id] = lastError
+	return nil
+}
+
+func (s *fakeMessageStore) Accept(_ context.Context, id uuid.UUID, providerMessageID string, status models.MessageStatus) error {
+	if s.accepted == nil {
+		s.accepted = make(map[uuid.UUID]string)
+		s.statuses = make(map[uuid.UUID]models.MessageStatus)
+	}
+	s.accepted[id] = providerMessageID
+	s.statuses[id] = status
+	return nil
+}
+
+type fakeProducer struct {
+	delivered []string
+}
+
+func (p *fakeProducer) Delivered(messageID []byte) error {
+	p.delivered = append(p.delivered, string(messageID))
+	return nil
+}
+
+type fakeSender struct {
+	failing   map[uuid.UUID]bool
+	reporting map[models.ContactType]bool
+}
+
+func (s *fakeSender) Send(message models.MessageSend) (string, error) {
+	if s.failing[message.ID] {
+		return "", errors.New("provider is unavailable")
+	}
+	return "provider-" + message.ID.String(), nil
+}
+
+func (s *fakeSender) ReportsDelivery(contactType models.ContactType) bool {
+	return s.reporting[contactType]
+}
+
+func TestMessage_Send(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	retryPolicy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+	t.Run("when sending fails then retry is scheduled until attempts are exhausted", func(t *testing.T) {
+		delivered := models.MessageEntity{ID: uuid.New()}
+		retried := models.MessageEntity{ID: uuid.New(), Attempts: 1}
+		exhausted := models.MessageEntity{ID: uuid.New(), Attempts: 2}
+
+		store := &fakeMessageStore{
+			batches: [][]models.MessageEntity{{delivered, retried, exhausted}},
+			retried: make(map[uuid.UUID]int),
+			failed:  make(map[uuid.UUID]string),
+		}
+		producer := &fakeProducer{}
+		sender := &fakeSender{failing: map[uuid.UUID]bool{retried.ID: true, exhausted.ID: true}}
+
+		worker := NewSendMessage(store, producer, sender, retryPolicy, ClaimPolicy{BatchSize: 10, LeaseDuration: time.Minute}, log)
+		worker.Send()
+
+		assert.Equal(t, []string{delivered.ID.String()}, producer.delivered)
+		assert.Equal(t, map[uuid.UUID]string{delivered.ID: "provider-" + delivered.ID.String()}, store.accepted)
+		assert.Equal(t, map[uuid.UUID]int{retried.ID: 2}, store.retried)
+		assert.Equal(t, map[uuid.UUID]string{exhausted.ID: "provider is unavailable"}, store.failed)
+	})
+
+	t.Run("when provider reports delivery then message is accepted only", func(t *testing.T) {
+		sms := models.MessageEntity{ID: uuid.New(), Type: models.ContactTypeSMS}
+		email := models.MessageEntity{ID: uuid.New(), Type: models.ContactTypeEmail}
+		telegram := models.MessageEntity{ID: uuid.New(), Type: models.ContactTypeTelegram}
+
+		store := &fakeMessageStore{batches: [][]models.MessageEntity{{sms, email, telegram}}}
+		producer := &fakeProducer{}
+		sender := &fakeSender{reporting: map[models.ContactType]bool{models.ContactTypeSMS: true, models.ContactTypeEmail: true}}
+
+		worker := NewSendMessage(store, producer, sender, retryPolicy, DefaultClaimPolicy(), log)
+		worker.Send()
+
+		assert.Len(t, store.accepted, 3)
+		assert.Equal(t, "provider-"+sms.ID.String(), store.accepted[sms.ID])
+		assert.Equal(t, map[uuid.UUID]models.MessageStatus{
+			sms.ID:      models.Accepted,
+			email.ID:    models.Accepted,
+			telegram.ID: models.Delivered,
+		}, store.statuses)
+		assert.Equal(t, []string{telegram.ID.String()}, producer.delivered)
+	})
+
+	t.Run("when provider callbacks are not configured then message is delivered", func(t *testing.T) {
+		sms := models.MessageEntity{ID: uuid.New(), Type: models.ContactTypeSMS}
+		email := models.MessageEntity{ID: uuid.New(), Type: models.ContactTypeEmail}
+
+		store := &fakeMessageStore{batches: [][]models.MessageEntity{{sms, email}}}
+		producer := &fakeProducer{}
+		sender := &fakeSender{reporting: map[models.ContactType]bool{models.ContactTypeEmail: true}}
+
+		worker := NewSendMessage(store, producer, sender, retryPolicy, DefaultClaimPolicy(), log)
+		worker.Send()
+
+		assert.Equal(t, map[uuid.UUID]models.MessageStatus{
+			sms.ID:   models.Delivered,
+			email.ID: models.Accepted,
+		}, store.statuses)
+		assert.Equal(t, []string{sms.ID.String()}, producer.delivered)
+	})
+
+	t.Run("when batch is full then next batch is claimed", func(t *testing.T) {
+		store := &fakeMessageStore{
+			batches: [][]models.MessageEntity{
+				{{ID: uuid.New()}, {ID: uuid.New()}},
+				{{ID: uuid.New()}},
+			},
+			retried: make(map[uuid.UUID]int),
+			failed:  make(map[uuid.UUID]string),
+		}
+		producer := &fakeProducer{}
+
+		worker := NewSendMessage(store, producer, &fakeSender{}, retryPolicy, ClaimPolicy{BatchSize: 2, LeaseDuration: time.Minute}, log)
+		worker.Send()
+
+		assert.Equal(t, []int{2, 2}, store.limits)
+		assert.Len(t, producer.delivered, 3)
+	})
+
+	t.Run("when claiming fails then nothing is sent", func(t *testing.T) {
+		store := &fakeMessageStore{claimErr: errors.New("")}
+		producer := &fakeProducer{}
+
+		worker := NewSendMessage(store, producer, &fakeSender{}, retryPolicy, DefaultClaimPolicy(), log)
+		worker.Send()
+
+		assert.Len(t, store.limits, 1)
+		assert.Empty(t, producer.delivered)
+	})
+}
@@ -0,0 +1,393 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v4.25.2
+// source: receiver.proto
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ReceiverClient is the client API for Receiver service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ReceiverClient interface {
+	Create(ctx context.Context, in *CreateReceiverRequest, opts ...grpc.CallOption) (*ReceiverResponse, error)
+	Get(ctx context.Context, in *GetReceiverRequest, opts ...grpc.CallOption) (*ReceiverResponse, error)
+	List(ctx context.Context, in *ListReceiversRequest, opts ...grpc.CallOption) (*ListReceiversResponse, error)
+	Update(ctx context.Context, in *UpdateReceiverRequest, opts ...grpc.CallOption) (*ReceiverResponse, error)
+	Delete(ctx context.Context, in *DeleteReceiverRequest, opts ...grpc.CallOption) (*DeleteReceiverResponse, error)
+	AddContact(ctx context.Context, in *AddContactRequest, opts ...grpc.CallOption) (*ReceiverResponse, error)
+	RemoveContact(ctx context.Context, in *ContactRequest, opts ...grpc.CallOption) (*ReceiverResponse, error)
+	ActivateContact(ctx context.Context, in *ContactRequest, opts ...grpc.CallOption) (*ReceiverResponse, error)
+	DeactivateContact(ctx context.Context, in *ContactRequest, opts ...grpc.CallOption) (*ReceiverResponse, error)
+}
+
+type receiverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReceiverClient(cc grpc.ClientConnInterface) ReceiverClient {
+	return &receiverClient{cc}
+}
+
+func (c *receiverClient) Create(ctx context.Context, in *CreateReceiverRequest, opts ...grpc.CallOption) (*ReceiverResponse, error) {
+	out := new(ReceiverResponse)
+	err := c.cc.Invoke(ctx, "/receiver.Receiver/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverClient) Get(ctx context.Context, in *GetReceiverRequest, opts ...grpc.CallOption) (*ReceiverResponse, error) {
+	out := new(ReceiverResponse)
+	err := c.cc.Invoke(ctx, "/receiver.Receiver/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverClient) List(ctx context.Context, in *ListReceiversRequest, opts ...grpc.CallOption) (*ListReceiversResponse, error) {
+	out := new(ListReceiversResponse)
+	err := c.cc.Invoke(ctx, "/receiver.Receiver/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverClient) Update(ctx context.Context, in *UpdateReceiverRequest, opts ...grpc.CallOption) (*ReceiverResponse, error) {
+	out := new(ReceiverResponse)
+	err := c.cc.Invoke(ctx, "/receiver.Receiver/Update", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverClient) Delete(ctx context.Context, in *DeleteReceiverRequest, opts ...grpc.CallOption) (*DeleteReceiverResponse, error) {
+	out := new(DeleteReceiverResponse)
+	err := c.cc.Invoke(ctx, "/receiver.Receiver/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverClient) AddContact(ctx context.Context, in *AddContactRequest, opts ...grpc.CallOption) (*ReceiverResponse, error) {
+	out := new(ReceiverResponse)
+	err := c.cc.Invoke(ctx, "/receiver.Receiver/AddContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverClient) RemoveContact(ctx context.Context, in *ContactRequest, opts ...grpc.CallOption) (*ReceiverResponse, error) {
+	out := new(ReceiverResponse)
+	err := c.cc.Invoke(ctx, "/receiver.Receiver/RemoveContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverClient) ActivateContact(ctx context.Context, in *ContactRequest, opts ...grpc.CallOption) (*ReceiverResponse, error) {
+	out := new(ReceiverResponse)
+	err := c.cc.Invoke(ctx, "/receiver.Receiver/ActivateContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverClient) DeactivateContact(ctx context.Context, in *ContactRequest, opts ...grpc.CallOption) (*ReceiverResponse, error) {
+	out := new(ReceiverResponse)
+	err := c.cc.Invoke(ctx, "/receiver.Receiver/DeactivateContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReceiverServer is the server API for Receiver service.
+// All implementations must embed UnimplementedReceiverServer
+// for forward compatibility
+type ReceiverServer interface {
+	Create(context.Context, *CreateReceiverRequest) (*ReceiverResponse, error)
+	Get(context.Context, *GetReceiverRequest) (*ReceiverResponse, error)
+	List(context.Context, *ListReceiversRequest) (*ListReceiversResponse, error)
+	Update(context.Context, *UpdateReceiverRequest) (*ReceiverResponse, error)
+	Delete(context.Context, *DeleteReceiverRequest) (*DeleteReceiverResponse, error)
+	AddContact(context.Context, *AddContactRequest) (*ReceiverResponse, error)
+	RemoveContact(context.Context, *ContactRequest) (*ReceiverResponse, error)
+	ActivateContact(context.Context, *ContactRequest) (*ReceiverResponse, error)
+	DeactivateContact(context.Context, *ContactRequest) (*ReceiverResponse, error)
+	mustEmbedUnimplementedReceiverServer()
+}
+
+// UnimplementedReceiverServer must be embedded to have forward compatible implementations.
+type UnimplementedReceiverServer struct {
+}
+
+func (UnimplementedReceiverServer) Create(context.Context, *CreateReceiverRequest) (*ReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedReceiverServer) Get(context.Context, *GetReceiverRequest) (*ReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedReceiverServer) List(context.Context, *ListReceiversRequest) (*ListReceiversResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedReceiverServer) Update(context.Context, *UpdateReceiverRequest) (*ReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedReceiverServer) Delete(context.Context, *DeleteReceiverRequest) (*DeleteReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedReceiverServer) AddContact(context.Context, *AddContactRequest) (*ReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddContact not implemented")
+}
+func (UnimplementedReceiverServer) RemoveContact(context.Context, *ContactRequest) (*ReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveContact not implemented")
+}
+func (UnimplementedReceiverServer) ActivateContact(context.Context, *ContactRequest) (*ReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActivateContact not implemented")
+}
+func (UnimplementedReceiverServer) DeactivateContact(context.Context, *ContactRequest) (*ReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeactivateContact not implemented")
+}
+func (UnimplementedReceiverServer) mustEmbedUnimplementedReceiverServer() {}
+
+// UnsafeReceiverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReceiverServer will
+// result in compilation errors.
+type UnsafeReceiverServer interface {
+	mustEmbedUnimplementedReceiverServer()
+}
+
+func RegisterReceiverServer(s grpc.ServiceRegistrar, srv ReceiverServer) {
+	s.RegisterService(&Receiver_ServiceDesc, srv)
+}
+
+func _Receiver_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReceiverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/receiver.Receiver/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServer).Create(ctx, req.(*CreateReceiverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Receiver_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReceiverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/receiver.Receiver/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServer).Get(ctx, req.(*GetReceiverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Receiver_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReceiversRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/receiver.Receiver/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServer).List(ctx, req.(*ListReceiversRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Receiver_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReceiverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/receiver.Receiver/Update",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServer).Update(ctx, req.(*UpdateReceiverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Receiver_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteReceiverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/receiver.Receiver/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServer).Delete(ctx, req.(*DeleteReceiverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Receiver_AddContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServer).AddContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/receiver.Receiver/AddContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServer).AddContact(ctx, req.(*AddContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Receiver_RemoveContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServer).RemoveContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/receiver.Receiver/RemoveContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServer).RemoveContact(ctx, req.(*ContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Receiver_ActivateContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServer).ActivateContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/receiver.Receiver/ActivateContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServer).ActivateContact(ctx, req.(*ContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Receiver_DeactivateContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServer).DeactivateContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/receiver.Receiver/DeactivateContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServer).DeactivateContact(ctx, req.(*ContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Receiver_ServiceDesc is the grpc.ServiceDesc for Receiver service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Receiver_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "receiver.Receiver",
+	HandlerType: (*ReceiverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _Receiver_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Receiver_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Receiver_List_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Receiver_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Receiver_Delete_Handler,
+		},
+		{
+			MethodName: "AddContact",
+			Handler:    _Receiver_AddContact_Handler,
+		},
+		{
+			MethodName: "RemoveContact",
+			Handler:    _Receiver_RemoveContact_Handler,
+		},
+		{
+			MethodName: "ActivateContact",
+			Handler:    _Receiver_ActivateContact_Handler,
+		},
+		{
+			MethodName: "DeactivateContact",
+			Handler:    _Receiver_DeactivateContact_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "receiver.proto",
+}